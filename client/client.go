@@ -18,7 +18,9 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"io/ioutil"
+	"math/rand"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -31,6 +33,7 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 )
 
 // Client is a PD (Placement Driver) client.
@@ -42,6 +45,11 @@ type Client interface {
 	GetTS(ctx context.Context) (int64, int64, error)
 	// GetTSAsync gets a timestamp from PD, without block the caller.
 	GetTSAsync(ctx context.Context) TSFuture
+	// GetLocalTS gets a timestamp from the local TSO allocator of the zone,
+	// which is served by a PD member in the zone. It requires the local TSO
+	// to be enabled in PD. It fails at once if the zone has no local TSO
+	// leader known to the client.
+	GetLocalTS(ctx context.Context, zone string) (int64, int64, error)
 	// GetRegion gets a region and its leader Peer from PD by key.
 	// The region may expire after split. Caller is responsible for caching and
 	// taking care of region change.
@@ -99,6 +107,38 @@ type tsoRequest struct {
 	logical  int64
 }
 
+// The gRPC metadata keys used by the local TSO, which should be the same as
+// the ones used by the server.
+const (
+	tsoZoneMetadataKey                = "pd-tso-zone"
+	tsoSuffixBitsMetadataKey          = "pd-tso-suffix-bits"
+	tsoSuffixBitsSupportedMetadataKey = "pd-tso-suffix-bits-supported"
+	localTSOLeadersMetadataKey        = "pd-local-tso-leaders"
+	tsoProxyMetadataKey               = "pd-tso-proxy"
+)
+
+// The gRPC metadata keys used by the follower read, which should be the same
+// as the ones used by the server.
+const (
+	followerReadMetadataKey          = "pd-follower-read"
+	followerReadStalenessMetadataKey = "pd-follower-read-staleness"
+)
+
+// tsoDispatcher batches the timestamp requests to the global or a local TSO
+// allocator, and sends them over the streams of the client.
+type tsoDispatcher struct {
+	// zone is empty for the global allocator.
+	zone     string
+	requests chan *tsoRequest
+}
+
+func newTSODispatcher(zone string) *tsoDispatcher {
+	return &tsoDispatcher{
+		zone:     zone,
+		requests: make(chan *tsoRequest, maxMergeTSORequests),
+	}
+}
+
 const (
 	pdTimeout             = 3 * time.Second
 	updateLeaderTimeout   = time.Second // Use a shorter timeout to recover faster from network isolation.
@@ -116,17 +156,25 @@ var (
 )
 
 type client struct {
-	urls        []string
-	clusterID   uint64
-	tsoRequests chan *tsoRequest
+	urls          []string
+	clusterID     uint64
+	tsoDispatcher *tsoDispatcher
 
 	connMu struct {
 		sync.RWMutex
 		clientConns map[string]*grpc.ClientConn
 		leader      string
+		// followers are the URLs of the members other than the leader.
+		followers []string
+		// localTSOLeaders are the URLs of the local TSO allocators of the zones.
+		localTSOLeaders map[string]string
+	}
+
+	localTSOMu struct {
+		sync.Mutex
+		dispatchers map[string]*tsoDispatcher
 	}
 
-	tsDeadlineCh  chan deadline
 	checkLeaderCh chan struct{}
 
 	wg     sync.WaitGroup
@@ -134,6 +182,20 @@ type client struct {
 	cancel context.CancelFunc
 
 	security SecurityOption
+
+	// tsoFollowerProxy makes the global TSO requests sent to a follower, which
+	// forwards them to the leader.
+	tsoFollowerProxy bool
+	// tsoMaxBatchWait is how long a batch of TSO requests waits for more
+	// requests before being sent.
+	tsoMaxBatchWait time.Duration
+	// tsoParallelStreams is the number of the streams used by each TSO
+	// allocator.
+	tsoParallelStreams int
+	// followerReadMaxStaleness is the max staleness allowed for the region and
+	// store lookups answered by the followers. The follower read is disabled
+	// if it is zero.
+	followerReadMaxStaleness time.Duration
 }
 
 // SecurityOption records options about tls
@@ -143,20 +205,85 @@ type SecurityOption struct {
 	KeyPath  string
 }
 
+// ClientOption configures the PD client.
+type ClientOption func(c *client)
+
+// WithTSOFollowerProxy makes the client send the global TSO requests to a
+// random follower, which merges the requests of its clients and forwards them
+// to the leader. It reduces the load of the leader when there are lots of
+// clients, at the cost of a little more latency.
+func WithTSOFollowerProxy() ClientOption {
+	return func(c *client) {
+		c.tsoFollowerProxy = true
+	}
+}
+
+// WithTSOMaxBatchWaitInterval makes a batch of TSO requests wait for more
+// requests up to the interval before being sent, so that fewer RPCs are sent
+// at the cost of latency. By default, a batch is sent without waiting.
+func WithTSOMaxBatchWaitInterval(interval time.Duration) ClientOption {
+	return func(c *client) {
+		if interval > 0 {
+			c.tsoMaxBatchWait = interval
+		}
+	}
+}
+
+// WithTSOParallelStreams makes the client send the TSO requests over n streams
+// in parallel for each TSO allocator. By default, there is only one stream.
+// Note that with more than one stream, the requests are answered out of order
+// across the streams, so the timestamps of the requests issued one after
+// another by GetTSAsync are not monotonic in the order they are issued. A
+// timestamp is only guaranteed to be greater than the ones returned before
+// the request is issued, so the callers needing monotonic timestamps should
+// wait for the previous request before issuing the next one.
+func WithTSOParallelStreams(n int) ClientOption {
+	return func(c *client) {
+		if n > 0 {
+			c.tsoParallelStreams = n
+		}
+	}
+}
+
+// WithFollowerRead makes the client send the region and store lookups to a
+// random follower, which answers them with the regions synchronized from the
+// leader if they fall behind the leader no more than maxStaleness. Note that
+// the leaders of the regions answered by the followers are unknown, so they
+// are nil, or empty peers for ScanRegions. The lookups are sent to the leader
+// if the follower fails to answer them, including the regions not
+// synchronized to the follower yet. The followers answer the lookups only if
+// enable-follower-read is set for them.
+// The staleness of a follower is measured from the last time the leader
+// confirms it is synchronized, which happens at least every 10 seconds even if
+// no region changes. So a maxStaleness below 10 seconds makes the lookups fall
+// back to the leader from time to time on an idle cluster.
+func WithFollowerRead(maxStaleness time.Duration) ClientOption {
+	return func(c *client) {
+		if maxStaleness > 0 {
+			c.followerReadMaxStaleness = maxStaleness
+		}
+	}
+}
+
 // NewClient creates a PD client.
-func NewClient(pdAddrs []string, security SecurityOption) (Client, error) {
+func NewClient(pdAddrs []string, security SecurityOption, opts ...ClientOption) (Client, error) {
 	log.Info("[pd] create pd client with endpoints", zap.Strings("pd-address", pdAddrs))
 	ctx, cancel := context.WithCancel(context.Background())
 	c := &client{
-		urls:          addrsToUrls(pdAddrs),
-		tsoRequests:   make(chan *tsoRequest, maxMergeTSORequests),
-		tsDeadlineCh:  make(chan deadline, 1),
-		checkLeaderCh: make(chan struct{}, 1),
-		ctx:           ctx,
-		cancel:        cancel,
-		security:      security,
+		urls:               addrsToUrls(pdAddrs),
+		tsoDispatcher:      newTSODispatcher(""),
+		checkLeaderCh:      make(chan struct{}, 1),
+		ctx:                ctx,
+		cancel:             cancel,
+		security:           security,
+		tsoParallelStreams: 1,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
 	c.connMu.clientConns = make(map[string]*grpc.ClientConn)
+	c.connMu.localTSOLeaders = make(map[string]string)
+	c.localTSOMu.dispatchers = make(map[string]*tsoDispatcher)
 
 	if err := c.initRetry(c.initClusterID); err != nil {
 		return nil, err
@@ -166,14 +293,22 @@ func NewClient(pdAddrs []string, security SecurityOption) (Client, error) {
 	}
 	log.Info("[pd] init cluster id", zap.Uint64("cluster-id", c.clusterID))
 
-	c.wg.Add(3)
-	go c.tsLoop()
-	go c.tsCancelLoop()
+	c.wg.Add(1)
 	go c.leaderLoop()
+	c.startTSODispatcher(c.tsoDispatcher)
 
 	return c, nil
 }
 
+func (c *client) startTSODispatcher(d *tsoDispatcher) {
+	c.wg.Add(2 * c.tsoParallelStreams)
+	for i := 0; i < c.tsoParallelStreams; i++ {
+		deadlineCh := make(chan deadline, 1)
+		go c.tsLoop(d, deadlineCh)
+		go c.tsCancelLoop(d, deadlineCh)
+	}
+}
+
 func (c *client) updateURLs(members []*pdpb.Member) {
 	urls := make([]string, 0, len(members))
 	for _, m := range members {
@@ -213,7 +348,8 @@ func (c *client) initClusterID() error {
 func (c *client) updateLeader() error {
 	for _, u := range c.urls {
 		ctx, cancel := context.WithTimeout(c.ctx, updateLeaderTimeout)
-		members, err := c.getMembers(ctx, u)
+		var header metadata.MD
+		members, err := c.getMembers(ctx, u, grpc.Header(&header))
 		cancel()
 		if err != nil || members.GetLeader() == nil || len(members.GetLeader().GetClientUrls()) == 0 {
 			select {
@@ -224,17 +360,19 @@ func (c *client) updateLeader() error {
 			}
 		}
 		c.updateURLs(members.GetMembers())
+		c.updateFollowers(members.GetMembers(), members.GetLeader())
+		c.updateLocalTSOLeaders(header)
 		return c.switchLeader(members.GetLeader().GetClientUrls())
 	}
 	return errors.Errorf("failed to get leader from %v", c.urls)
 }
 
-func (c *client) getMembers(ctx context.Context, url string) (*pdpb.GetMembersResponse, error) {
+func (c *client) getMembers(ctx context.Context, url string, opts ...grpc.CallOption) (*pdpb.GetMembersResponse, error) {
 	cc, err := c.getOrCreateGRPCConn(url)
 	if err != nil {
 		return nil, err
 	}
-	members, err := pdpb.NewPDClient(cc).GetMembers(ctx, &pdpb.GetMembersRequest{})
+	members, err := pdpb.NewPDClient(cc).GetMembers(ctx, &pdpb.GetMembersRequest{}, opts...)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -264,6 +402,36 @@ func (c *client) switchLeader(addrs []string) error {
 	return nil
 }
 
+func (c *client) updateFollowers(members []*pdpb.Member, leader *pdpb.Member) {
+	followers := make([]string, 0, len(members))
+	for _, m := range members {
+		if m.GetMemberId() != leader.GetMemberId() && len(m.GetClientUrls()) > 0 {
+			followers = append(followers, m.GetClientUrls()[0])
+		}
+	}
+
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	c.connMu.followers = followers
+}
+
+// updateLocalTSOLeaders updates the URLs of the local TSO allocators, which are
+// "<zone>=<client-urls>" in the header of GetMembers.
+func (c *client) updateLocalTSOLeaders(header metadata.MD) {
+	leaders := make(map[string]string)
+	for _, v := range header.Get(localTSOLeadersMetadataKey) {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			continue
+		}
+		leaders[kv[0]] = strings.Split(kv[1], ",")[0]
+	}
+
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	c.connMu.localTSOLeaders = leaders
+}
+
 func (c *client) getOrCreateGRPCConn(addr string) (*grpc.ClientConn, error) {
 	c.connMu.RLock()
 	conn, ok := c.connMu.clientConns[addr]
@@ -349,7 +517,7 @@ type deadline struct {
 	cancel context.CancelFunc
 }
 
-func (c *client) tsCancelLoop() {
+func (c *client) tsCancelLoop(d *tsoDispatcher, deadlineCh <-chan deadline) {
 	defer c.wg.Done()
 
 	ctx, cancel := context.WithCancel(c.ctx)
@@ -357,12 +525,12 @@ func (c *client) tsCancelLoop() {
 
 	for {
 		select {
-		case d := <-c.tsDeadlineCh:
+		case dl := <-deadlineCh:
 			select {
-			case <-d.timer:
-				log.Error("tso request is canceled due to timeout")
-				d.cancel()
-			case <-d.done:
+			case <-dl.timer:
+				log.Error("tso request is canceled due to timeout", zap.String("zone", d.zone))
+				dl.cancel()
+			case <-dl.done:
 			case <-ctx.Done():
 				return
 			}
@@ -372,7 +540,7 @@ func (c *client) tsCancelLoop() {
 	}
 }
 
-func (c *client) tsLoop() {
+func (c *client) tsLoop(d *tsoDispatcher, deadlineCh chan<- deadline) {
 	defer c.wg.Done()
 
 	loopCtx, loopCancel := context.WithCancel(c.ctx)
@@ -389,7 +557,7 @@ func (c *client) tsLoop() {
 		if stream == nil {
 			var ctx context.Context
 			ctx, cancel = context.WithCancel(loopCtx)
-			stream, err = c.leaderClient().Tso(ctx)
+			stream, err = c.createTSOStream(ctx, d.zone)
 			if err != nil {
 				select {
 				case <-loopCtx.Done():
@@ -397,10 +565,10 @@ func (c *client) tsLoop() {
 					return
 				default:
 				}
-				log.Error("[pd] create tso stream error", zap.Error(err))
+				log.Error("[pd] create tso stream error", zap.String("zone", d.zone), zap.Error(err))
 				c.ScheduleCheckLeader()
 				cancel()
-				c.revokeTSORequest(errors.WithStack(err))
+				c.revokeTSORequest(d, errors.WithStack(err))
 				select {
 				case <-time.After(time.Second):
 				case <-loopCtx.Done():
@@ -411,11 +579,14 @@ func (c *client) tsLoop() {
 		}
 
 		select {
-		case first := <-c.tsoRequests:
+		case first := <-d.requests:
 			requests = append(requests, first)
-			pending := len(c.tsoRequests)
+			pending := len(d.requests)
 			for i := 0; i < pending; i++ {
-				requests = append(requests, <-c.tsoRequests)
+				requests = append(requests, <-d.requests)
+			}
+			if c.tsoMaxBatchWait > 0 {
+				requests = c.waitTSORequests(loopCtx, d, requests)
 			}
 			done := make(chan struct{})
 			dl := deadline{
@@ -424,7 +595,7 @@ func (c *client) tsLoop() {
 				cancel: cancel,
 			}
 			select {
-			case c.tsDeadlineCh <- dl:
+			case deadlineCh <- dl:
 			case <-loopCtx.Done():
 				cancel()
 				return
@@ -445,7 +616,7 @@ func (c *client) tsLoop() {
 				return
 			default:
 			}
-			log.Error("[pd] getTS error", zap.Error(err))
+			log.Error("[pd] getTS error", zap.String("zone", d.zone), zap.Error(err))
 			c.ScheduleCheckLeader()
 			cancel()
 			stream, cancel = nil, nil
@@ -453,6 +624,85 @@ func (c *client) tsLoop() {
 	}
 }
 
+// waitTSORequests collects more requests into the batch until the max batch
+// wait interval is reached or the batch is full.
+func (c *client) waitTSORequests(ctx context.Context, d *tsoDispatcher, requests []*tsoRequest) []*tsoRequest {
+	timer := time.NewTimer(c.tsoMaxBatchWait)
+	defer timer.Stop()
+	for len(requests) < maxMergeTSORequests {
+		select {
+		case req := <-d.requests:
+			requests = append(requests, req)
+		case <-timer.C:
+			return requests
+		case <-ctx.Done():
+			return requests
+		}
+	}
+	return requests
+}
+
+// createTSOStream creates the stream to the global TSO allocator, or to the
+// local TSO allocator if the zone is not empty.
+func (c *client) createTSOStream(ctx context.Context, zone string) (pdpb.PD_TsoClient, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, tsoSuffixBitsSupportedMetadataKey, "true")
+	if zone == "" {
+		if c.tsoFollowerProxy {
+			if cc, ok := c.followerConn(); ok {
+				ctx = metadata.AppendToOutgoingContext(ctx, tsoProxyMetadataKey, "true")
+				return pdpb.NewPDClient(cc).Tso(ctx)
+			}
+		}
+		return c.leaderClient().Tso(ctx)
+	}
+	c.connMu.RLock()
+	addr, ok := c.connMu.localTSOLeaders[zone]
+	c.connMu.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("[pd] no local tso leader of zone %s", zone)
+	}
+	cc, err := c.getOrCreateGRPCConn(addr)
+	if err != nil {
+		return nil, err
+	}
+	ctx = metadata.AppendToOutgoingContext(ctx, tsoZoneMetadataKey, zone)
+	return pdpb.NewPDClient(cc).Tso(ctx)
+}
+
+// followerConn returns the connection to a random follower.
+func (c *client) followerConn() (*grpc.ClientConn, bool) {
+	c.connMu.RLock()
+	followers := c.connMu.followers
+	c.connMu.RUnlock()
+	if len(followers) == 0 {
+		return nil, false
+	}
+	cc, err := c.getOrCreateGRPCConn(followers[rand.Intn(len(followers))])
+	if err != nil {
+		log.Warn("[pd] failed to connect to follower", zap.Error(err))
+		return nil, false
+	}
+	return cc, true
+}
+
+// tsoSuffixBits returns the bits of the logical time used by PD to tell the
+// TSO allocators apart, which are set in the header of the stream.
+func tsoSuffixBits(stream pdpb.PD_TsoClient) (uint, error) {
+	header, err := stream.Header()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	values := header.Get(tsoSuffixBitsMetadataKey)
+	if len(values) == 0 {
+		return 0, nil
+	}
+	bits, err := strconv.ParseUint(values[0], 10, 32)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return uint(bits), nil
+}
+
 func extractSpanReference(requests []*tsoRequest, opts []opentracing.StartSpanOption) []opentracing.StartSpanOption {
 	for _, req := range requests {
 		if span := opentracing.SpanFromContext(req.ctx); span != nil {
@@ -473,46 +723,52 @@ func (c *client) processTSORequests(stream pdpb.PD_TsoClient, requests []*tsoReq
 		Header: c.requestHeader(),
 		Count:  uint32(len(requests)),
 	}
+	tsoBatchSize.Observe(float64(len(requests)))
 
 	if err := stream.Send(req); err != nil {
 		err = errors.WithStack(err)
-		c.finishTSORequest(requests, 0, 0, err)
+		c.finishTSORequest(requests, 0, 0, 0, err)
 		return err
 	}
 	resp, err := stream.Recv()
 	if err != nil {
 		err = errors.WithStack(err)
-		c.finishTSORequest(requests, 0, 0, err)
+		c.finishTSORequest(requests, 0, 0, 0, err)
 		return err
 	}
 	requestDuration.WithLabelValues("tso").Observe(time.Since(start).Seconds())
 	if resp.GetCount() != uint32(len(requests)) {
 		err = errors.WithStack(errTSOLength)
-		c.finishTSORequest(requests, 0, 0, err)
+		c.finishTSORequest(requests, 0, 0, 0, err)
+		return err
+	}
+	suffixBits, err := tsoSuffixBits(stream)
+	if err != nil {
+		c.finishTSORequest(requests, 0, 0, 0, err)
 		return err
 	}
 
 	physical, logical := resp.GetTimestamp().GetPhysical(), resp.GetTimestamp().GetLogical()
 	// Server returns the highest ts.
-	logical -= int64(resp.GetCount() - 1)
-	c.finishTSORequest(requests, physical, logical, nil)
+	logical -= int64(resp.GetCount()-1) << suffixBits
+	c.finishTSORequest(requests, physical, logical, suffixBits, nil)
 	return nil
 }
 
-func (c *client) finishTSORequest(requests []*tsoRequest, physical, firstLogical int64, err error) {
+func (c *client) finishTSORequest(requests []*tsoRequest, physical, firstLogical int64, suffixBits uint, err error) {
 	for i := 0; i < len(requests); i++ {
 		if span := opentracing.SpanFromContext(requests[i].ctx); span != nil {
 			span.Finish()
 		}
-		requests[i].physical, requests[i].logical = physical, firstLogical+int64(i)
+		requests[i].physical, requests[i].logical = physical, firstLogical+int64(i)<<suffixBits
 		requests[i].done <- err
 	}
 }
 
-func (c *client) revokeTSORequest(err error) {
-	n := len(c.tsoRequests)
+func (c *client) revokeTSORequest(d *tsoDispatcher, err error) {
+	n := len(d.requests)
 	for i := 0; i < n; i++ {
-		req := <-c.tsoRequests
+		req := <-d.requests
 		req.done <- err
 	}
 }
@@ -521,7 +777,12 @@ func (c *client) Close() {
 	c.cancel()
 	c.wg.Wait()
 
-	c.revokeTSORequest(errors.WithStack(errClosing))
+	c.revokeTSORequest(c.tsoDispatcher, errors.WithStack(errClosing))
+	c.localTSOMu.Lock()
+	for _, d := range c.localTSOMu.dispatchers {
+		c.revokeTSORequest(d, errors.WithStack(errClosing))
+	}
+	c.localTSOMu.Unlock()
 
 	c.connMu.Lock()
 	defer c.connMu.Unlock()
@@ -540,6 +801,28 @@ func (c *client) leaderClient() pdpb.PDClient {
 	return pdpb.NewPDClient(c.connMu.clientConns[c.connMu.leader])
 }
 
+// followerRead sends the request to a random follower if the follower read is
+// enabled, and falls back to the leader if the follower fails to answer it.
+func (c *client) followerRead(ctx context.Context, f func(ctx context.Context, cli pdpb.PDClient, opts ...grpc.CallOption) error) error {
+	if c.followerReadMaxStaleness > 0 {
+		if cc, ok := c.followerConn(); ok {
+			var header metadata.MD
+			followerCtx := metadata.AppendToOutgoingContext(ctx, followerReadMetadataKey, c.followerReadMaxStaleness.String())
+			err := f(followerCtx, pdpb.NewPDClient(cc), grpc.Header(&header))
+			if err == nil {
+				if values := header.Get(followerReadStalenessMetadataKey); len(values) > 0 {
+					if staleness, err := time.ParseDuration(values[0]); err == nil {
+						followerReadStaleness.Observe(staleness.Seconds())
+					}
+				}
+				return nil
+			}
+			log.Debug("[pd] failed to read from follower, fall back to leader", zap.Error(err))
+		}
+	}
+	return f(ctx, c.leaderClient())
+}
+
 func (c *client) ScheduleCheckLeader() {
 	select {
 	case c.checkLeaderCh <- struct{}{}:
@@ -576,16 +859,46 @@ func (c *client) GetTSAsync(ctx context.Context) TSFuture {
 		span = opentracing.StartSpan("GetTSAsync", opentracing.ChildOf(span.Context()))
 		ctx = opentracing.ContextWithSpan(ctx, span)
 	}
+	return c.dispatchTSORequest(ctx, c.tsoDispatcher)
+}
+
+func (c *client) dispatchTSORequest(ctx context.Context, d *tsoDispatcher) *tsoRequest {
 	req := tsoReqPool.Get().(*tsoRequest)
 	req.start = time.Now()
 	req.ctx = ctx
 	req.physical = 0
 	req.logical = 0
-	c.tsoRequests <- req
+	d.requests <- req
 
 	return req
 }
 
+// getLocalTSODispatcher returns the dispatcher of the zone, which is created
+// at the first time the zone is requested. It fails at once for the zones
+// without a local TSO leader, so no dispatcher is left retrying them forever.
+func (c *client) getLocalTSODispatcher(zone string) (*tsoDispatcher, error) {
+	c.localTSOMu.Lock()
+	defer c.localTSOMu.Unlock()
+	if d, ok := c.localTSOMu.dispatchers[zone]; ok {
+		return d, nil
+	}
+	if c.ctx.Err() != nil {
+		return nil, errors.WithStack(errClosing)
+	}
+	c.connMu.RLock()
+	_, ok := c.connMu.localTSOLeaders[zone]
+	c.connMu.RUnlock()
+	if !ok {
+		// The zone may have elected its leader after the last update.
+		c.ScheduleCheckLeader()
+		return nil, errors.Errorf("[pd] no local tso leader of zone %s", zone)
+	}
+	d := newTSODispatcher(zone)
+	c.localTSOMu.dispatchers[zone] = d
+	c.startTSODispatcher(d)
+	return d, nil
+}
+
 // TSFuture is a future which promises to return a TSO.
 type TSFuture interface {
 	// Wait gets the physical and logical time, it would block caller if data is not available yet.
@@ -617,6 +930,21 @@ func (c *client) GetTS(ctx context.Context) (physical int64, logical int64, err
 	return resp.Wait()
 }
 
+func (c *client) GetLocalTS(ctx context.Context, zone string) (physical int64, logical int64, err error) {
+	if zone == "" {
+		return 0, 0, errors.New("[pd] zone should not be empty")
+	}
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("GetLocalTS", opentracing.ChildOf(span.Context()))
+		ctx = opentracing.ContextWithSpan(ctx, span)
+	}
+	d, err := c.getLocalTSODispatcher(zone)
+	if err != nil {
+		return 0, 0, err
+	}
+	return c.dispatchTSORequest(ctx, d).Wait()
+}
+
 func (c *client) GetRegion(ctx context.Context, key []byte) (*metapb.Region, *metapb.Peer, error) {
 	if span := opentracing.SpanFromContext(ctx); span != nil {
 		span = opentracing.StartSpan("pdclient.GetRegion", opentracing.ChildOf(span.Context()))
@@ -626,9 +954,14 @@ func (c *client) GetRegion(ctx context.Context, key []byte) (*metapb.Region, *me
 	defer func() { cmdDuration.WithLabelValues("get_region").Observe(time.Since(start).Seconds()) }()
 
 	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
-	resp, err := c.leaderClient().GetRegion(ctx, &pdpb.GetRegionRequest{
+	req := &pdpb.GetRegionRequest{
 		Header:    c.requestHeader(),
 		RegionKey: key,
+	}
+	var resp *pdpb.GetRegionResponse
+	err := c.followerRead(ctx, func(ctx context.Context, cli pdpb.PDClient, opts ...grpc.CallOption) (err error) {
+		resp, err = cli.GetRegion(ctx, req, opts...)
+		return err
 	})
 	cancel()
 
@@ -649,9 +982,14 @@ func (c *client) GetPrevRegion(ctx context.Context, key []byte) (*metapb.Region,
 	defer func() { cmdDuration.WithLabelValues("get_prev_region").Observe(time.Since(start).Seconds()) }()
 
 	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
-	resp, err := c.leaderClient().GetPrevRegion(ctx, &pdpb.GetRegionRequest{
+	req := &pdpb.GetRegionRequest{
 		Header:    c.requestHeader(),
 		RegionKey: key,
+	}
+	var resp *pdpb.GetRegionResponse
+	err := c.followerRead(ctx, func(ctx context.Context, cli pdpb.PDClient, opts ...grpc.CallOption) (err error) {
+		resp, err = cli.GetPrevRegion(ctx, req, opts...)
+		return err
 	})
 	cancel()
 
@@ -672,9 +1010,14 @@ func (c *client) GetRegionByID(ctx context.Context, regionID uint64) (*metapb.Re
 	defer func() { cmdDuration.WithLabelValues("get_region_byid").Observe(time.Since(start).Seconds()) }()
 
 	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
-	resp, err := c.leaderClient().GetRegionByID(ctx, &pdpb.GetRegionByIDRequest{
+	req := &pdpb.GetRegionByIDRequest{
 		Header:   c.requestHeader(),
 		RegionId: regionID,
+	}
+	var resp *pdpb.GetRegionResponse
+	err := c.followerRead(ctx, func(ctx context.Context, cli pdpb.PDClient, opts ...grpc.CallOption) (err error) {
+		resp, err = cli.GetRegionByID(ctx, req, opts...)
+		return err
 	})
 	cancel()
 
@@ -694,10 +1037,15 @@ func (c *client) ScanRegions(ctx context.Context, key []byte, limit int) ([]*met
 	start := time.Now()
 	defer cmdDuration.WithLabelValues("scan_regions").Observe(time.Since(start).Seconds())
 	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
-	resp, err := c.leaderClient().ScanRegions(ctx, &pdpb.ScanRegionsRequest{
+	req := &pdpb.ScanRegionsRequest{
 		Header:   c.requestHeader(),
 		StartKey: key,
 		Limit:    int32(limit),
+	}
+	var resp *pdpb.ScanRegionsResponse
+	err := c.followerRead(ctx, func(ctx context.Context, cli pdpb.PDClient, opts ...grpc.CallOption) (err error) {
+		resp, err = cli.ScanRegions(ctx, req, opts...)
+		return err
 	})
 	cancel()
 	if err != nil {
@@ -717,9 +1065,14 @@ func (c *client) GetStore(ctx context.Context, storeID uint64) (*metapb.Store, e
 	defer func() { cmdDuration.WithLabelValues("get_store").Observe(time.Since(start).Seconds()) }()
 
 	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
-	resp, err := c.leaderClient().GetStore(ctx, &pdpb.GetStoreRequest{
+	req := &pdpb.GetStoreRequest{
 		Header:  c.requestHeader(),
 		StoreId: storeID,
+	}
+	var resp *pdpb.GetStoreResponse
+	err := c.followerRead(ctx, func(ctx context.Context, cli pdpb.PDClient, opts ...grpc.CallOption) (err error) {
+		resp, err = cli.GetStore(ctx, req, opts...)
+		return err
 	})
 	cancel()
 
@@ -36,20 +36,34 @@ type Cluster struct {
 	*mockoption.ScheduleOptions
 	*statistics.HotSpotCache
 	*statistics.StoresStats
-	ID uint64
+	ID                uint64
+	LeaderPolicies    *core.LeaderPolicies
+	RangeReplications *core.RangeReplications
 }
 
 // NewCluster creates a new Cluster
 func NewCluster(opt *mockoption.ScheduleOptions) *Cluster {
 	return &Cluster{
-		BasicCluster:    core.NewBasicCluster(),
-		IDAllocator:     mockid.NewIDAllocator(),
-		ScheduleOptions: opt,
-		HotSpotCache:    statistics.NewHotSpotCache(),
-		StoresStats:     statistics.NewStoresStats(),
+		BasicCluster:      core.NewBasicCluster(),
+		IDAllocator:       mockid.NewIDAllocator(),
+		ScheduleOptions:   opt,
+		HotSpotCache:      statistics.NewHotSpotCache(),
+		StoresStats:       statistics.NewStoresStats(),
+		LeaderPolicies:    core.NewLeaderPolicies(),
+		RangeReplications: core.NewRangeReplications(),
 	}
 }
 
+// GetLeaderPolicy returns the leader policy that applies to the region.
+func (mc *Cluster) GetLeaderPolicy(region *core.RegionInfo) *core.LeaderPolicy {
+	return mc.LeaderPolicies.GetLeaderPolicy(region)
+}
+
+// GetRangeReplication returns the range replication that applies to the region.
+func (mc *Cluster) GetRangeReplication(region *core.RegionInfo) *core.RangeReplication {
+	return mc.RangeReplications.GetRangeReplication(region)
+}
+
 func (mc *Cluster) allocID() (uint64, error) {
 	return mc.Alloc()
 }
@@ -158,6 +172,13 @@ func (mc *Cluster) SetStoreBusy(storeID uint64, busy bool) {
 	mc.PutStore(newStore)
 }
 
+// SetStoreMaintenance puts the store in maintenance until the deadline.
+func (mc *Cluster) SetStoreMaintenance(storeID uint64, deadline time.Time) {
+	store := mc.GetStore(storeID)
+	newStore := store.Clone(core.SetMaintenanceDeadline(deadline))
+	mc.PutStore(newStore)
+}
+
 // AddLeaderStore adds store with specified count of leader.
 func (mc *Cluster) AddLeaderStore(storeID uint64, leaderCount int) {
 	stats := &pdpb.StoreStats{}
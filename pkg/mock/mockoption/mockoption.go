@@ -55,6 +55,7 @@ type ScheduleOptions struct {
 	MaxMergeRegionSize           uint64
 	MaxMergeRegionKeys           uint64
 	SchedulerMaxWaitingOperator  uint64
+	OperatorPriorityClasses      []string
 	SplitMergeInterval           time.Duration
 	EnableOneWayMerge            bool
 	MaxStoreDownTime             time.Duration
@@ -72,6 +73,7 @@ type ScheduleOptions struct {
 	DisableRemoveExtraReplica    bool
 	DisableLocationReplacement   bool
 	DisableNamespaceRelocation   bool
+	EnablePlacementRules         bool
 	LabelProperties              map[string][]*metapb.StoreLabel
 }
 
@@ -205,6 +207,11 @@ func (mso *ScheduleOptions) GetSchedulerMaxWaitingOperator() uint64 {
 	return mso.SchedulerMaxWaitingOperator
 }
 
+// GetOperatorPriorityClasses mocks method.
+func (mso *ScheduleOptions) GetOperatorPriorityClasses() []string {
+	return mso.OperatorPriorityClasses
+}
+
 // SetMaxReplicas mocks method
 func (mso *ScheduleOptions) SetMaxReplicas(replicas int) {
 	mso.MaxReplicas = replicas
@@ -244,3 +251,8 @@ func (mso *ScheduleOptions) IsLocationReplacementEnabled() bool {
 func (mso *ScheduleOptions) IsNamespaceRelocationEnabled() bool {
 	return !mso.DisableNamespaceRelocation
 }
+
+// IsPlacementRulesEnabled mocks method.
+func (mso *ScheduleOptions) IsPlacementRulesEnabled() bool {
+	return mso.EnablePlacementRules
+}
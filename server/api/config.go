@@ -22,6 +22,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/pingcap/errcode"
 	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/schedule"
 	"github.com/pkg/errors"
 	"github.com/unrolled/render"
 )
@@ -174,6 +175,14 @@ func (h *confHandler) SetLabelProperty(w http.ResponseWriter, r *http.Request) {
 	var err error
 	switch input["action"] {
 	case "set":
+		if !schedule.IsLabelPropertyType(input["type"]) {
+			errorResp(h.rd, w, errcode.NewInvalidInputErr(errors.Errorf("unknown label property type %q", input["type"])))
+			return
+		}
+		if input["label-key"] == "" || input["label-value"] == "" {
+			errorResp(h.rd, w, errcode.NewInvalidInputErr(errors.New("label-key and label-value should not be empty")))
+			return
+		}
 		err = h.svr.SetLabelProperty(input["type"], input["label-key"], input["label-value"])
 	case "delete":
 		err = h.svr.DeleteLabelProperty(input["type"], input["label-key"], input["label-value"])
@@ -143,10 +143,19 @@ func (s *testConfigSuite) TestConfigLabelProperty(c *C) {
 	cfg := loadProperties()
 	c.Assert(cfg, HasLen, 0)
 
-	cmds := []string{
+	invalidCmds := []string{
 		`{"type": "foo", "action": "set", "label-key": "zone", "label-value": "cn1"}`,
-		`{"type": "foo", "action": "set", "label-key": "zone", "label-value": "cn2"}`,
-		`{"type": "bar", "action": "set", "label-key": "host", "label-value": "h1"}`,
+		`{"type": "reject-peer", "action": "set", "label-key": "", "label-value": "cn1"}`,
+	}
+	for _, cmd := range invalidCmds {
+		err := postJSON(addr, []byte(cmd))
+		c.Assert(err, NotNil)
+	}
+
+	cmds := []string{
+		`{"type": "reject-leader", "action": "set", "label-key": "zone", "label-value": "cn1"}`,
+		`{"type": "reject-leader", "action": "set", "label-key": "zone", "label-value": "cn2"}`,
+		`{"type": "read-only", "action": "set", "label-key": "host", "label-value": "h1"}`,
 	}
 	for _, cmd := range cmds {
 		err := postJSON(addr, []byte(cmd))
@@ -154,15 +163,15 @@ func (s *testConfigSuite) TestConfigLabelProperty(c *C) {
 	}
 	cfg = loadProperties()
 	c.Assert(cfg, HasLen, 2)
-	c.Assert(cfg["foo"], DeepEquals, []server.StoreLabel{
+	c.Assert(cfg["reject-leader"], DeepEquals, []server.StoreLabel{
 		{Key: "zone", Value: "cn1"},
 		{Key: "zone", Value: "cn2"},
 	})
-	c.Assert(cfg["bar"], DeepEquals, []server.StoreLabel{{Key: "host", Value: "h1"}})
+	c.Assert(cfg["read-only"], DeepEquals, []server.StoreLabel{{Key: "host", Value: "h1"}})
 
 	cmds = []string{
-		`{"type": "foo", "action": "delete", "label-key": "zone", "label-value": "cn1"}`,
-		`{"type": "bar", "action": "delete", "label-key": "host", "label-value": "h1"}`,
+		`{"type": "reject-leader", "action": "delete", "label-key": "zone", "label-value": "cn1"}`,
+		`{"type": "read-only", "action": "delete", "label-key": "host", "label-value": "h1"}`,
 	}
 	for _, cmd := range cmds {
 		err := postJSON(addr, []byte(cmd))
@@ -170,5 +179,5 @@ func (s *testConfigSuite) TestConfigLabelProperty(c *C) {
 	}
 	cfg = loadProperties()
 	c.Assert(cfg, HasLen, 1)
-	c.Assert(cfg["foo"], DeepEquals, []server.StoreLabel{{Key: "zone", Value: "cn2"}})
+	c.Assert(cfg["reject-leader"], DeepEquals, []server.StoreLabel{{Key: "zone", Value: "cn2"}})
 }
@@ -0,0 +1,95 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/errcode"
+	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/core"
+	"github.com/pkg/errors"
+	"github.com/unrolled/render"
+)
+
+type leaderPolicyHandler struct {
+	svr *server.Server
+	rd  *render.Render
+}
+
+func newLeaderPolicyHandler(svr *server.Server, rd *render.Render) *leaderPolicyHandler {
+	return &leaderPolicyHandler{
+		svr: svr,
+		rd:  rd,
+	}
+}
+
+func (h *leaderPolicyHandler) List(w http.ResponseWriter, r *http.Request) {
+	cluster := h.svr.GetRaftCluster()
+	if cluster == nil {
+		h.rd.JSON(w, http.StatusInternalServerError, server.ErrNotBootstrapped.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, cluster.GetLeaderPolicies())
+}
+
+func (h *leaderPolicyHandler) Get(w http.ResponseWriter, r *http.Request) {
+	cluster := h.svr.GetRaftCluster()
+	if cluster == nil {
+		h.rd.JSON(w, http.StatusInternalServerError, server.ErrNotBootstrapped.Error())
+		return
+	}
+	id := mux.Vars(r)["id"]
+	policy := cluster.GetLeaderPolicy(id)
+	if policy == nil {
+		h.rd.JSON(w, http.StatusNotFound, errors.Errorf("leader policy %s not found", id).Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, policy)
+}
+
+func (h *leaderPolicyHandler) Set(w http.ResponseWriter, r *http.Request) {
+	cluster := h.svr.GetRaftCluster()
+	if cluster == nil {
+		h.rd.JSON(w, http.StatusInternalServerError, server.ErrNotBootstrapped.Error())
+		return
+	}
+	var policy core.LeaderPolicy
+	if err := readJSONRespondError(h.rd, w, r.Body, &policy); err != nil {
+		return
+	}
+	if err := policy.Adjust(); err != nil {
+		errorResp(h.rd, w, errcode.NewInvalidInputErr(err))
+		return
+	}
+	if err := cluster.SetLeaderPolicy(&policy); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
+
+func (h *leaderPolicyHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	cluster := h.svr.GetRaftCluster()
+	if cluster == nil {
+		h.rd.JSON(w, http.StatusInternalServerError, server.ErrNotBootstrapped.Error())
+		return
+	}
+	if err := cluster.DeleteLeaderPolicy(mux.Vars(r)["id"]); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
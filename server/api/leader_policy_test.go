@@ -0,0 +1,79 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/core"
+)
+
+var _ = Suite(&testLeaderPolicySuite{})
+
+type testLeaderPolicySuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testLeaderPolicySuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1/config/leader-policies", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testLeaderPolicySuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testLeaderPolicySuite) TestLeaderPolicies(c *C) {
+	var policies []*core.LeaderPolicy
+	c.Assert(readJSONWithURL(s.urlPrefix, &policies), IsNil)
+	c.Assert(policies, HasLen, 0)
+
+	policy := &core.LeaderPolicy{
+		ID:          "foo",
+		StartKeyHex: "7480",
+		EndKeyHex:   "7481",
+		Preferences: []core.LeaderPreference{{Key: "zone", Value: "z1"}, {Key: "zone", Value: "z2"}},
+	}
+	postData, err := json.Marshal(policy)
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix, postData), IsNil)
+
+	var got core.LeaderPolicy
+	c.Assert(readJSONWithURL(s.urlPrefix+"/foo", &got), IsNil)
+	c.Assert(got.StartKeyHex, Equals, policy.StartKeyHex)
+	c.Assert(got.EndKeyHex, Equals, policy.EndKeyHex)
+	c.Assert(got.Preferences, DeepEquals, policy.Preferences)
+	c.Assert(readJSONWithURL(s.urlPrefix, &policies), IsNil)
+	c.Assert(policies, HasLen, 1)
+
+	// Invalid policies are rejected.
+	policy.Preferences = nil
+	postData, err = json.Marshal(policy)
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix, postData), NotNil)
+
+	c.Assert(doDelete(s.urlPrefix+"/foo"), IsNil)
+	_, err = doGet(s.urlPrefix + "/foo")
+	c.Assert(err, NotNil)
+}
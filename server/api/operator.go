@@ -14,15 +14,29 @@
 package api
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/pingcap/errcode"
 	"github.com/pingcap/pd/server"
 	"github.com/pingcap/pd/server/schedule"
+	"github.com/pkg/errors"
 	"github.com/unrolled/render"
 )
 
+const (
+	// defaultOperatorHistoryLimit is the default number of the operator records
+	// returned by the history API.
+	defaultOperatorHistoryLimit = 100
+	// operatorEventContentType is the content type of the operator event
+	// stream, in which each line is an event in JSON.
+	operatorEventContentType = "application/x-ndjson"
+)
+
 type operatorHandler struct {
 	*server.Handler
 	r *render.Render
@@ -267,6 +281,112 @@ func (h *operatorHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	h.r.JSON(w, http.StatusOK, nil)
 }
 
+// rejectedOperator is an operator rejected in a batch.
+type rejectedOperator struct {
+	Index    int    `json:"index"`
+	Name     string `json:"name"`
+	RegionID uint64 `json:"region_id"`
+	Reason   string `json:"reason"`
+}
+
+// PostBatch adds a batch of admin operators atomically. If any of them can not
+// be added, none of them is added and the reasons are responded.
+func (h *operatorHandler) PostBatch(w http.ResponseWriter, r *http.Request) {
+	var input []map[string]interface{}
+	if err := readJSONRespondError(h.r, w, r.Body, &input); err != nil {
+		return
+	}
+	if len(input) == 0 {
+		h.r.JSON(w, http.StatusBadRequest, "missing operators")
+		return
+	}
+
+	reqs := make([]*server.AdminOperatorRequest, 0, len(input))
+	for i, item := range input {
+		req, err := parseAdminOperatorRequest(item)
+		if err != nil {
+			h.r.JSON(w, http.StatusBadRequest, fmt.Sprintf("operator %d: %s", i, err))
+			return
+		}
+		reqs = append(reqs, req)
+	}
+
+	reasons, err := h.AddOperatorBatch(reqs)
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if reasons != nil {
+		var rejected []rejectedOperator
+		for i, reason := range reasons {
+			if reason != nil {
+				rejected = append(rejected, rejectedOperator{
+					Index:    i,
+					Name:     reqs[i].Name,
+					RegionID: reqs[i].RegionID,
+					Reason:   reason.Error(),
+				})
+			}
+		}
+		h.r.JSON(w, http.StatusConflict, rejected)
+		return
+	}
+
+	h.r.JSON(w, http.StatusOK, nil)
+}
+
+func parseAdminOperatorRequest(input map[string]interface{}) (*server.AdminOperatorRequest, error) {
+	name, ok := input["name"].(string)
+	if !ok {
+		return nil, errors.New("missing operator name")
+	}
+	req := &server.AdminOperatorRequest{Name: name}
+	regionKey := "region_id"
+	if name == server.BatchMergeRegion {
+		regionKey = "source_region_id"
+	}
+	regionID, ok := input[regionKey].(float64)
+	if !ok {
+		return nil, errors.New("missing region id")
+	}
+	req.RegionID = uint64(regionID)
+
+	switch name {
+	case server.BatchTransferLeader:
+		storeID, ok := input["to_store_id"].(float64)
+		if !ok {
+			return nil, errors.New("missing store id to transfer leader to")
+		}
+		req.ToStoreID = uint64(storeID)
+	case server.BatchTransferPeer:
+		fromID, ok := input["from_store_id"].(float64)
+		if !ok {
+			return nil, errors.New("invalid store id to transfer peer from")
+		}
+		toID, ok := input["to_store_id"].(float64)
+		if !ok {
+			return nil, errors.New("invalid store id to transfer peer to")
+		}
+		req.FromStoreID, req.ToStoreID = uint64(fromID), uint64(toID)
+	case server.BatchMergeRegion:
+		targetID, ok := input["target_region_id"].(float64)
+		if !ok {
+			return nil, errors.New("invalid target region id to merge to")
+		}
+		req.TargetRegionID = uint64(targetID)
+	case server.BatchSplitRegion:
+		policy, ok := input["policy"].(string)
+		if !ok {
+			return nil, errors.New("missing split policy")
+		}
+		req.Policy = policy
+	case server.BatchScatterRegion:
+	default:
+		return nil, errors.Errorf("operator %s can not be added in a batch", name)
+	}
+	return req, nil
+}
+
 func parseStoreIDs(v interface{}) (map[uint64]struct{}, bool) {
 	items, ok := v.([]interface{})
 	if !ok {
@@ -282,3 +402,147 @@ func parseStoreIDs(v interface{}) (map[uint64]struct{}, bool) {
 	}
 	return ids, true
 }
+
+// GetQueue returns the status of the waiting operators of each priority
+// class.
+func (h *operatorHandler) GetQueue(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.GetWaitingOperatorStats()
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, stats)
+}
+
+func (h *operatorHandler) GetDryRun(w http.ResponseWriter, r *http.Request) {
+	records, err := h.GetDryRunOperators()
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, records)
+}
+
+func (h *operatorHandler) ClearDryRun(w http.ResponseWriter, r *http.Request) {
+	if err := h.ClearDryRunOperators(); err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, nil)
+}
+
+func (h *operatorHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseOperatorAuditFilter(r)
+	if err != nil {
+		errorResp(h.r, w, errcode.NewInvalidInputErr(err))
+		return
+	}
+	records, err := h.GetOperatorAuditRecords(*filter)
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, records)
+}
+
+func parseOperatorAuditFilter(r *http.Request) (*schedule.OperatorAuditFilter, error) {
+	query := r.URL.Query()
+	filter := &schedule.OperatorAuditFilter{Limit: defaultOperatorHistoryLimit}
+	for name, id := range map[string]*uint64{"region_id": &filter.RegionID, "store_id": &filter.StoreID} {
+		if s := query.Get(name); s != "" {
+			v, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				return nil, errors.Errorf("invalid %s %s", name, s)
+			}
+			*id = v
+		}
+	}
+	for name, t := range map[string]*time.Time{"start": &filter.Start, "end": &filter.End} {
+		if s := query.Get(name); s != "" {
+			v, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || v < 0 {
+				return nil, errors.Errorf("invalid %s %s", name, s)
+			}
+			*t = time.Unix(v, 0)
+		}
+	}
+	if s := query.Get("kind"); s != "" {
+		kind, err := schedule.ParseOperatorKind(s)
+		if err != nil {
+			return nil, err
+		}
+		filter.Kind = kind
+	}
+	if s := query.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit <= 0 {
+			return nil, errors.Errorf("invalid limit %s", s)
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
+// WatchEvents streams the operator events as newline-delimited JSON until the
+// client disconnects. The stream ends when the client is too slow to receive
+// the events, or the PD leader changes.
+func (h *operatorHandler) WatchEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.r.JSON(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+	filter, err := parseOperatorEventFilter(r)
+	if err != nil {
+		errorResp(h.r, w, errcode.NewInvalidInputErr(err))
+		return
+	}
+	s, err := h.SubscribeOperatorEvents(*filter)
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer h.UnsubscribeOperatorEvents(s)
+
+	w.Header().Set("Content-Type", operatorEventContentType)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	encoder := json.NewEncoder(w)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-s.Events():
+			if !ok {
+				return
+			}
+			if err := encoder.Encode(event); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func parseOperatorEventFilter(r *http.Request) (*schedule.OperatorEventFilter, error) {
+	query := r.URL.Query()
+	filter := &schedule.OperatorEventFilter{
+		StartKey: []byte(query.Get("start_key")),
+		EndKey:   []byte(query.Get("end_key")),
+	}
+	if s := query.Get("store_id"); s != "" {
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid store_id %s", s)
+		}
+		filter.StoreID = id
+	}
+	if s := query.Get("kind"); s != "" {
+		kind, err := schedule.ParseOperatorKind(s)
+		if err != nil {
+			return nil, err
+		}
+		filter.Kind = kind
+	}
+	return filter, nil
+}
@@ -15,6 +15,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -25,6 +26,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/server"
 	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/server/schedule"
 )
 
 var _ = Suite(&testOperatorSuite{})
@@ -135,3 +137,131 @@ func mustReadURL(c *C, url string) string {
 	c.Assert(err, IsNil)
 	return string(data)
 }
+
+func (s *testOperatorSuite) TestDryRun(c *C) {
+	url := fmt.Sprintf("%s/operators/dry-run", s.urlPrefix)
+	var records []*schedule.DryRunRecord
+	c.Assert(readJSONWithURL(url, &records), IsNil)
+	c.Assert(records, HasLen, 0)
+	c.Assert(doDelete(url), IsNil)
+}
+
+func (s *testOperatorSuite) TestHistory(c *C) {
+	mustPutStore(c, s.svr, 1, metapb.StoreState_Up, nil)
+	mustPutStore(c, s.svr, 2, metapb.StoreState_Up, nil)
+	peer := &metapb.Peer{Id: 11, StoreId: 1}
+	region := &metapb.Region{
+		Id:          10,
+		Peers:       []*metapb.Peer{peer},
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+	}
+	mustRegionHeartbeat(c, s.svr, core.NewRegionInfo(region, peer))
+
+	c.Assert(postJSON(fmt.Sprintf("%s/operators", s.urlPrefix), []byte(`{"name":"add-peer", "region_id": 10, "store_id": 2}`)), IsNil)
+	c.Assert(doDelete(fmt.Sprintf("%s/operators/10", s.urlPrefix)), IsNil)
+
+	url := fmt.Sprintf("%s/operators/history", s.urlPrefix)
+	var records []*schedule.OperatorAuditRecord
+	c.Assert(readJSONWithURL(url+"?region_id=10&store_id=2&kind=admin,region", &records), IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].Source, Equals, "admin")
+	c.Assert(records[0].Status, Equals, "CANCEL")
+	c.Assert(readJSONWithURL(url+"?region_id=10&kind=leader", &records), IsNil)
+	c.Assert(records, HasLen, 0)
+	_, err := doGet(url + "?kind=unknown")
+	c.Assert(err, NotNil)
+	_, err = doGet(url + "?limit=0")
+	c.Assert(err, NotNil)
+}
+
+func (s *testOperatorSuite) TestEvents(c *C) {
+	mustPutStore(c, s.svr, 1, metapb.StoreState_Up, nil)
+	mustPutStore(c, s.svr, 5, metapb.StoreState_Up, nil)
+	peer := &metapb.Peer{Id: 21, StoreId: 1}
+	region := &metapb.Region{
+		Id:          20,
+		Peers:       []*metapb.Peer{peer},
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+	}
+	mustRegionHeartbeat(c, s.svr, core.NewRegionInfo(region, peer))
+
+	_, err := doGet(fmt.Sprintf("%s/operators/events?store_id=x", s.urlPrefix))
+	c.Assert(err, NotNil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/operators/events?store_id=5", s.urlPrefix), nil)
+	c.Assert(err, IsNil)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+
+	c.Assert(postJSON(fmt.Sprintf("%s/operators", s.urlPrefix), []byte(`{"name":"add-peer", "region_id": 20, "store_id": 5}`)), IsNil)
+	c.Assert(doDelete(fmt.Sprintf("%s/operators/20", s.urlPrefix)), IsNil)
+
+	decoder := json.NewDecoder(resp.Body)
+	var event schedule.OperatorEvent
+	c.Assert(decoder.Decode(&event), IsNil)
+	c.Assert(event.Type, Equals, schedule.OperatorEventCreate)
+	c.Assert(event.RegionID, Equals, uint64(20))
+	c.Assert(event.Stores, DeepEquals, []uint64{5})
+	c.Assert(decoder.Decode(&event), IsNil)
+	c.Assert(event.Type, Equals, schedule.OperatorEventCancel)
+}
+
+func (s *testOperatorSuite) TestBatch(c *C) {
+	mustPutStore(c, s.svr, 1, metapb.StoreState_Up, nil)
+	mustPutStore(c, s.svr, 6, metapb.StoreState_Up, nil)
+	for _, id := range []uint64{30, 31} {
+		peer := &metapb.Peer{Id: id + 100, StoreId: 1}
+		region := &metapb.Region{
+			Id:          id,
+			StartKey:    []byte{byte(id)},
+			EndKey:      []byte{byte(id + 1)},
+			Peers:       []*metapb.Peer{peer},
+			RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+		}
+		mustRegionHeartbeat(c, s.svr, core.NewRegionInfo(region, peer))
+	}
+	url := fmt.Sprintf("%s/operators/batch", s.urlPrefix)
+
+	err := postJSON(url, []byte(`[{"name":"add-peer", "region_id": 30, "store_id": 6}]`))
+	c.Assert(err, ErrorMatches, "(?s).*operator 0: operator add-peer can not be added in a batch.*")
+	c.Assert(postJSON(url, []byte(`[]`)), NotNil)
+
+	// None of the operators is added if any of them is rejected.
+	resp, err := dialClient.Post(url, "application/json", strings.NewReader(`[
+		{"name":"transfer-peer", "region_id": 30, "from_store_id": 1, "to_store_id": 6},
+		{"name":"transfer-leader", "region_id": 31, "to_store_id": 6}
+	]`))
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusConflict)
+	var rejected []rejectedOperator
+	c.Assert(json.NewDecoder(resp.Body).Decode(&rejected), IsNil)
+	c.Assert(rejected, DeepEquals, []rejectedOperator{{Index: 1, Name: "transfer-leader", RegionID: 31, Reason: "region has no voter in store 6"}})
+	_, err = s.svr.GetHandler().GetOperator(30)
+	c.Assert(err, NotNil)
+
+	c.Assert(postJSON(url, []byte(`[
+		{"name":"transfer-peer", "region_id": 30, "from_store_id": 1, "to_store_id": 6},
+		{"name":"split-region", "region_id": 31, "policy": "scan"}
+	]`)), IsNil)
+	_, err = s.svr.GetHandler().GetOperator(30)
+	c.Assert(err, IsNil)
+	_, err = s.svr.GetHandler().GetOperator(31)
+	c.Assert(err, IsNil)
+	c.Assert(doDelete(fmt.Sprintf("%s/operators/30", s.urlPrefix)), IsNil)
+	c.Assert(doDelete(fmt.Sprintf("%s/operators/31", s.urlPrefix)), IsNil)
+}
+
+func (s *testOperatorSuite) TestQueue(c *C) {
+	var stats []schedule.WaitingClassStats
+	c.Assert(readJSONWithURL(fmt.Sprintf("%s/operators/queue", s.urlPrefix), &stats), IsNil)
+	c.Assert(stats, HasLen, len(schedule.DefaultPriorityClasses))
+	for i, class := range schedule.DefaultPriorityClasses {
+		c.Assert(stats[i].Class, Equals, class)
+		c.Assert(stats[i].Weight, Equals, 1<<uint(len(stats)-1-i))
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/errcode"
+	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/core"
+	"github.com/pkg/errors"
+	"github.com/unrolled/render"
+)
+
+type rangeReplicationHandler struct {
+	svr *server.Server
+	rd  *render.Render
+}
+
+func newRangeReplicationHandler(svr *server.Server, rd *render.Render) *rangeReplicationHandler {
+	return &rangeReplicationHandler{
+		svr: svr,
+		rd:  rd,
+	}
+}
+
+func (h *rangeReplicationHandler) List(w http.ResponseWriter, r *http.Request) {
+	cluster := h.svr.GetRaftCluster()
+	if cluster == nil {
+		h.rd.JSON(w, http.StatusInternalServerError, server.ErrNotBootstrapped.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, cluster.GetRangeReplications())
+}
+
+func (h *rangeReplicationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	cluster := h.svr.GetRaftCluster()
+	if cluster == nil {
+		h.rd.JSON(w, http.StatusInternalServerError, server.ErrNotBootstrapped.Error())
+		return
+	}
+	id := mux.Vars(r)["id"]
+	replication := cluster.GetRangeReplication(id)
+	if replication == nil {
+		h.rd.JSON(w, http.StatusNotFound, errors.Errorf("range replication %s not found", id).Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, replication)
+}
+
+func (h *rangeReplicationHandler) Set(w http.ResponseWriter, r *http.Request) {
+	cluster := h.svr.GetRaftCluster()
+	if cluster == nil {
+		h.rd.JSON(w, http.StatusInternalServerError, server.ErrNotBootstrapped.Error())
+		return
+	}
+	var replication core.RangeReplication
+	if err := readJSONRespondError(h.rd, w, r.Body, &replication); err != nil {
+		return
+	}
+	if err := replication.Adjust(); err != nil {
+		errorResp(h.rd, w, errcode.NewInvalidInputErr(err))
+		return
+	}
+	for _, label := range replication.LocationLabels {
+		if err := server.ValidateLabelString(label); err != nil {
+			errorResp(h.rd, w, errcode.NewInvalidInputErr(err))
+			return
+		}
+	}
+	if err := cluster.SetRangeReplication(&replication); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
+
+func (h *rangeReplicationHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	cluster := h.svr.GetRaftCluster()
+	if cluster == nil {
+		h.rd.JSON(w, http.StatusInternalServerError, server.ErrNotBootstrapped.Error())
+		return
+	}
+	if err := cluster.DeleteRangeReplication(mux.Vars(r)["id"]); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
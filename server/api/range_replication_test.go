@@ -0,0 +1,85 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/core"
+)
+
+var _ = Suite(&testRangeReplicationSuite{})
+
+type testRangeReplicationSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testRangeReplicationSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1/config/replicate/ranges", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testRangeReplicationSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testRangeReplicationSuite) TestRangeReplications(c *C) {
+	var replications []*core.RangeReplication
+	c.Assert(readJSONWithURL(s.urlPrefix, &replications), IsNil)
+	c.Assert(replications, HasLen, 0)
+
+	replication := &core.RangeReplication{
+		ID:             "foo",
+		StartKeyHex:    "7480",
+		EndKeyHex:      "7481",
+		MaxReplicas:    5,
+		LocationLabels: []string{"zone", "host"},
+	}
+	postData, err := json.Marshal(replication)
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix, postData), IsNil)
+
+	var got core.RangeReplication
+	c.Assert(readJSONWithURL(s.urlPrefix+"/foo", &got), IsNil)
+	c.Assert(got.StartKeyHex, Equals, replication.StartKeyHex)
+	c.Assert(got.EndKeyHex, Equals, replication.EndKeyHex)
+	c.Assert(got.MaxReplicas, Equals, replication.MaxReplicas)
+	c.Assert(got.LocationLabels, DeepEquals, replication.LocationLabels)
+	c.Assert(readJSONWithURL(s.urlPrefix, &replications), IsNil)
+	c.Assert(replications, HasLen, 1)
+
+	// Invalid overrides are rejected.
+	replication.LocationLabels = []string{"zone", "-host"}
+	postData, err = json.Marshal(replication)
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix, postData), NotNil)
+	replication.MaxReplicas, replication.LocationLabels = 0, nil
+	postData, err = json.Marshal(replication)
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix, postData), NotNil)
+
+	c.Assert(doDelete(s.urlPrefix+"/foo"), IsNil)
+	_, err = doGet(s.urlPrefix + "/foo")
+	c.Assert(err, NotNil)
+}
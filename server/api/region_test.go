@@ -22,8 +22,10 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/pkg/testutil"
 	"github.com/pingcap/pd/server"
 	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/server/statistics"
 )
 
 var _ = Suite(&testRegionSuite{})
@@ -107,6 +109,23 @@ func (s *testRegionSuite) TestRegionCheck(c *C) {
 	err = readJSONWithURL(url, r3)
 	c.Assert(err, IsNil)
 	c.Assert(r3, DeepEquals, &RegionsInfo{Count: 1, Regions: []*RegionInfo{NewRegionInfo(r)}})
+
+	// No location label is set, so the replicas are not isolated. The
+	// isolation levels are updated by patrolling the regions.
+	url = fmt.Sprintf("%s/regions/check/isolation", s.urlPrefix)
+	testutil.WaitUntil(c, func(c *C) bool {
+		counts := make(map[string]int)
+		c.Assert(readJSONWithURL(url, &counts), IsNil)
+		return counts[statistics.NoneIsolationLevel] > 0
+	})
+	url = fmt.Sprintf("%s/regions/check/isolation/%s", s.urlPrefix, statistics.NoneIsolationLevel)
+	r4 := &RegionsInfo{}
+	c.Assert(readJSONWithURL(url, r4), IsNil)
+	var ids []uint64
+	for _, region := range r4.Regions {
+		ids = append(ids, region.ID)
+	}
+	c.Assert(ids, DeepEquals, []uint64{r.GetID()})
 }
 
 func (s *testRegionSuite) TestRegions(c *C) {
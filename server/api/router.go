@@ -35,6 +35,12 @@ func createRouter(prefix string, svr *server.Server) *mux.Router {
 	operatorHandler := newOperatorHandler(handler, rd)
 	router.HandleFunc("/api/v1/operators", operatorHandler.List).Methods("GET")
 	router.HandleFunc("/api/v1/operators", operatorHandler.Post).Methods("POST")
+	router.HandleFunc("/api/v1/operators/batch", operatorHandler.PostBatch).Methods("POST")
+	router.HandleFunc("/api/v1/operators/dry-run", operatorHandler.GetDryRun).Methods("GET")
+	router.HandleFunc("/api/v1/operators/dry-run", operatorHandler.ClearDryRun).Methods("DELETE")
+	router.HandleFunc("/api/v1/operators/history", operatorHandler.GetHistory).Methods("GET")
+	router.HandleFunc("/api/v1/operators/queue", operatorHandler.GetQueue).Methods("GET")
+	router.HandleFunc("/api/v1/operators/events", operatorHandler.WatchEvents).Methods("GET")
 	router.HandleFunc("/api/v1/operators/{region_id}", operatorHandler.Get).Methods("GET")
 	router.HandleFunc("/api/v1/operators/{region_id}", operatorHandler.Delete).Methods("DELETE")
 
@@ -42,6 +48,12 @@ func createRouter(prefix string, svr *server.Server) *mux.Router {
 	router.HandleFunc("/api/v1/schedulers", schedulerHandler.List).Methods("GET")
 	router.HandleFunc("/api/v1/schedulers", schedulerHandler.Post).Methods("POST")
 	router.HandleFunc("/api/v1/schedulers/{name}", schedulerHandler.Delete).Methods("DELETE")
+	router.HandleFunc("/api/v1/schedulers/{name}/pause", schedulerHandler.Pause).Methods("POST")
+	router.HandleFunc("/api/v1/schedulers/{name}/resume", schedulerHandler.Resume).Methods("POST")
+	router.HandleFunc("/api/v1/schedulers/{name}/explain", schedulerHandler.Explain).Methods("GET")
+	router.HandleFunc("/api/v1/schedulers/{name}/config", schedulerHandler.GetConfig).Methods("GET")
+	router.HandleFunc("/api/v1/schedulers/{name}/config", schedulerHandler.SetConfig).Methods("POST")
+	router.HandleFunc("/api/v1/schedulers/{name}/config/{store_id}", schedulerHandler.RemoveStore).Methods("DELETE")
 
 	clusterHandler := newClusterHandler(svr, rd)
 	router.Handle("/api/v1/cluster", clusterHandler).Methods("GET")
@@ -62,6 +74,24 @@ func createRouter(prefix string, svr *server.Server) *mux.Router {
 	router.HandleFunc("/api/v1/config/cluster-version", confHandler.GetClusterVersion).Methods("GET")
 	router.HandleFunc("/api/v1/config/cluster-version", confHandler.SetClusterVersion).Methods("POST")
 
+	rulesHandler := newRulesHandler(svr, rd)
+	router.HandleFunc("/api/v1/config/rules", rulesHandler.List).Methods("GET")
+	router.HandleFunc("/api/v1/config/rules", rulesHandler.Set).Methods("POST")
+	router.HandleFunc("/api/v1/config/rules/{id}", rulesHandler.Get).Methods("GET")
+	router.HandleFunc("/api/v1/config/rules/{id}", rulesHandler.Delete).Methods("DELETE")
+
+	leaderPolicyHandler := newLeaderPolicyHandler(svr, rd)
+	router.HandleFunc("/api/v1/config/leader-policies", leaderPolicyHandler.List).Methods("GET")
+	router.HandleFunc("/api/v1/config/leader-policies", leaderPolicyHandler.Set).Methods("POST")
+	router.HandleFunc("/api/v1/config/leader-policies/{id}", leaderPolicyHandler.Get).Methods("GET")
+	router.HandleFunc("/api/v1/config/leader-policies/{id}", leaderPolicyHandler.Delete).Methods("DELETE")
+
+	rangeReplicationHandler := newRangeReplicationHandler(svr, rd)
+	router.HandleFunc("/api/v1/config/replicate/ranges", rangeReplicationHandler.List).Methods("GET")
+	router.HandleFunc("/api/v1/config/replicate/ranges", rangeReplicationHandler.Set).Methods("POST")
+	router.HandleFunc("/api/v1/config/replicate/ranges/{id}", rangeReplicationHandler.Get).Methods("GET")
+	router.HandleFunc("/api/v1/config/replicate/ranges/{id}", rangeReplicationHandler.Delete).Methods("DELETE")
+
 	storeHandler := newStoreHandler(handler, rd)
 	router.HandleFunc("/api/v1/store/{id}", storeHandler.Get).Methods("GET")
 	router.HandleFunc("/api/v1/store/{id}", storeHandler.Delete).Methods("DELETE")
@@ -69,6 +99,9 @@ func createRouter(prefix string, svr *server.Server) *mux.Router {
 	router.HandleFunc("/api/v1/store/{id}/label", storeHandler.SetLabels).Methods("POST")
 	router.HandleFunc("/api/v1/store/{id}/weight", storeHandler.SetWeight).Methods("POST")
 	router.HandleFunc("/api/v1/store/{id}/limit", storeHandler.SetLimit).Methods("POST")
+	router.HandleFunc("/api/v1/store/{id}/maintenance", storeHandler.SetMaintenance).Methods("POST")
+	router.HandleFunc("/api/v1/store/{id}/maintenance", storeHandler.DeleteMaintenance).Methods("DELETE")
+	router.HandleFunc("/api/v1/store/{id}/progress", storeHandler.GetProgress).Methods("GET")
 	storesHandler := newStoresHandler(handler, rd)
 	router.Handle("/api/v1/stores", storesHandler).Methods("GET")
 	router.HandleFunc("/api/v1/stores/remove-tombstone", storesHandler.RemoveTombStone).Methods("DELETE")
@@ -98,14 +131,17 @@ func createRouter(prefix string, svr *server.Server) *mux.Router {
 	router.HandleFunc("/api/v1/regions/version", regionsHandler.GetTopVersion).Methods("GET")
 	router.HandleFunc("/api/v1/regions/size", regionsHandler.GetTopSize).Methods("GET")
 	router.HandleFunc("/api/v1/regions/check/miss-peer", regionsHandler.GetMissPeerRegions).Methods("GET")
+	router.HandleFunc("/api/v1/regions/check/miss-learner-peer", regionsHandler.GetMissLearnerPeerRegions).Methods("GET")
 	router.HandleFunc("/api/v1/regions/check/extra-peer", regionsHandler.GetExtraPeerRegions).Methods("GET")
 	router.HandleFunc("/api/v1/regions/check/pending-peer", regionsHandler.GetPendingPeerRegions).Methods("GET")
 	router.HandleFunc("/api/v1/regions/check/down-peer", regionsHandler.GetDownPeerRegions).Methods("GET")
 	router.HandleFunc("/api/v1/regions/sibling/{id}", regionsHandler.GetRegionSiblings).Methods("GET")
 	router.HandleFunc("/api/v1/regions/check/incorrect-ns", regionsHandler.GetIncorrectNamespaceRegions).Methods("GET")
+	router.HandleFunc("/api/v1/regions/check/isolation", regionsHandler.GetIsolationLevelCounts).Methods("GET")
+	router.HandleFunc("/api/v1/regions/check/isolation/{level}", regionsHandler.GetIsolationLevelRegions).Methods("GET")
 
 	router.Handle("/api/v1/version", newVersionHandler(rd)).Methods("GET")
-	router.Handle("/api/v1/status", newStatusHandler(rd)).Methods("GET")
+	router.Handle("/api/v1/status", newStatusHandler(svr, rd)).Methods("GET")
 
 	memberHandler := newMemberHandler(svr, rd)
 	router.HandleFunc("/api/v1/members", memberHandler.ListMembers).Methods("GET")
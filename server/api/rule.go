@@ -0,0 +1,106 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/errcode"
+	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/placement"
+	"github.com/pkg/errors"
+	"github.com/unrolled/render"
+)
+
+var errPlacementDisabled = errors.New("placement rules feature is disabled")
+
+type ruleHandler struct {
+	svr *server.Server
+	rd  *render.Render
+}
+
+func newRulesHandler(svr *server.Server, rd *render.Render) *ruleHandler {
+	return &ruleHandler{
+		svr: svr,
+		rd:  rd,
+	}
+}
+
+func (h *ruleHandler) getRuleManager(w http.ResponseWriter) *placement.RuleManager {
+	cluster := h.svr.GetRaftCluster()
+	if cluster == nil {
+		h.rd.JSON(w, http.StatusInternalServerError, server.ErrNotBootstrapped.Error())
+		return nil
+	}
+	if !h.svr.GetReplicationConfig().EnablePlacementRules {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return nil
+	}
+	return cluster.GetRuleManager()
+}
+
+func (h *ruleHandler) List(w http.ResponseWriter, r *http.Request) {
+	manager := h.getRuleManager(w)
+	if manager == nil {
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, manager.GetAllRules())
+}
+
+func (h *ruleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	manager := h.getRuleManager(w)
+	if manager == nil {
+		return
+	}
+	id := mux.Vars(r)["id"]
+	rule := manager.GetRule(id)
+	if rule == nil {
+		h.rd.JSON(w, http.StatusNotFound, errors.Errorf("rule %s not found", id).Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, rule)
+}
+
+func (h *ruleHandler) Set(w http.ResponseWriter, r *http.Request) {
+	manager := h.getRuleManager(w)
+	if manager == nil {
+		return
+	}
+	var rule placement.Rule
+	if err := readJSONRespondError(h.rd, w, r.Body, &rule); err != nil {
+		return
+	}
+	if err := rule.Adjust(); err != nil {
+		errorResp(h.rd, w, errcode.NewInvalidInputErr(err))
+		return
+	}
+	if err := manager.SetRule(&rule); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
+
+func (h *ruleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	manager := h.getRuleManager(w)
+	if manager == nil {
+		return
+	}
+	if err := manager.DeleteRule(mux.Vars(r)["id"]); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
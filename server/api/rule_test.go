@@ -0,0 +1,93 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/placement"
+)
+
+var _ = Suite(&testRuleSuite{})
+
+type testRuleSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testRuleSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1/config", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testRuleSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testRuleSuite) TestRules(c *C) {
+	// Rules are not available before the feature is enabled.
+	_, err := doGet(s.urlPrefix + "/rules")
+	c.Assert(err, NotNil)
+
+	postData, err := json.Marshal(map[string]string{"enable-placement-rules": "true"})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix, postData), IsNil)
+
+	var rules []*placement.Rule
+	c.Assert(readJSONWithURL(s.urlPrefix+"/rules", &rules), IsNil)
+	c.Assert(rules, HasLen, 1)
+	c.Assert(rules[0].ID, Equals, placement.DefaultRuleID)
+	c.Assert(rules[0].Count, Equals, int(s.svr.GetReplicationConfig().MaxReplicas))
+
+	rule := &placement.Rule{
+		ID:               "foo",
+		Index:            1,
+		StartKeyHex:      "7480",
+		EndKeyHex:        "7481",
+		Role:             placement.Leader,
+		Count:            1,
+		LabelConstraints: []placement.Filter{{Key: "zone", Value: "z1"}},
+	}
+	postData, err = json.Marshal(rule)
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix+"/rules", postData), IsNil)
+
+	var got placement.Rule
+	c.Assert(readJSONWithURL(s.urlPrefix+"/rules/foo", &got), IsNil)
+	c.Assert(got.ID, Equals, rule.ID)
+	c.Assert(got.Role, Equals, rule.Role)
+	c.Assert(got.StartKeyHex, Equals, rule.StartKeyHex)
+	c.Assert(got.LabelConstraints, DeepEquals, rule.LabelConstraints)
+	c.Assert(readJSONWithURL(s.urlPrefix+"/rules", &rules), IsNil)
+	c.Assert(rules, HasLen, 2)
+
+	// Invalid rules are rejected.
+	rule.Count = 2
+	postData, err = json.Marshal(rule)
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix+"/rules", postData), NotNil)
+
+	c.Assert(doDelete(s.urlPrefix+"/rules/foo"), IsNil)
+	_, err = doGet(s.urlPrefix + "/rules/foo")
+	c.Assert(err, NotNil)
+}
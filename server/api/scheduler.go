@@ -14,13 +14,22 @@
 package api
 
 import (
+	"io/ioutil"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/pingcap/errcode"
 	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/schedule"
+	"github.com/pkg/errors"
 	"github.com/unrolled/render"
 )
 
+// maxSchedulerPauseDelay is the max number of seconds to pause a scheduler.
+const maxSchedulerPauseDelay = int64(30 * 24 * time.Hour / time.Second)
+
 type schedulerHandler struct {
 	*server.Handler
 	r *render.Render
@@ -34,7 +43,19 @@ func newSchedulerHandler(handler *server.Handler, r *render.Render) *schedulerHa
 }
 
 func (h *schedulerHandler) List(w http.ResponseWriter, r *http.Request) {
-	schedulers, err := h.GetSchedulers()
+	var (
+		schedulers []string
+		err        error
+	)
+	switch status := r.URL.Query().Get("status"); status {
+	case "":
+		schedulers, err = h.GetSchedulers()
+	case "paused":
+		schedulers, err = h.GetPausedSchedulers()
+	default:
+		errorResp(h.r, w, errcode.NewInvalidInputErr(errors.Errorf("unknown status %s", status)))
+		return
+	}
 	if err != nil {
 		h.r.JSON(w, http.StatusInternalServerError, err.Error())
 		return
@@ -172,3 +193,84 @@ func (h *schedulerHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	h.r.JSON(w, http.StatusOK, nil)
 }
+
+func (h *schedulerHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Delay int64 `json:"delay"`
+	}
+	if err := readJSONRespondError(h.r, w, r.Body, &input); err != nil {
+		return
+	}
+	if input.Delay <= 0 || input.Delay > maxSchedulerPauseDelay {
+		errorResp(h.r, w, errcode.NewInvalidInputErr(errors.Errorf("delay should be in (0, %d]", maxSchedulerPauseDelay)))
+		return
+	}
+	if err := h.PauseScheduler(mux.Vars(r)["name"], time.Duration(input.Delay)*time.Second); err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, nil)
+}
+
+func (h *schedulerHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	if err := h.ResumeScheduler(mux.Vars(r)["name"]); err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, nil)
+}
+
+func (h *schedulerHandler) Explain(w http.ResponseWriter, r *http.Request) {
+	limit := schedule.DefaultExplainRounds
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 {
+			errorResp(h.r, w, errcode.NewInvalidInputErr(errors.Errorf("invalid limit %s", limitStr)))
+			return
+		}
+		limit = l
+	}
+	rounds, err := h.GetSchedulerExplanations(mux.Vars(r)["name"], limit)
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, rounds)
+}
+
+func (h *schedulerHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	config, err := h.GetSchedulerConfig(mux.Vars(r)["name"])
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, config)
+}
+
+func (h *schedulerHandler) SetConfig(w http.ResponseWriter, r *http.Request) {
+	data, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := h.SetSchedulerConfig(mux.Vars(r)["name"], data); err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, nil)
+}
+
+func (h *schedulerHandler) RemoveStore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	storeID, err := strconv.ParseUint(vars["store_id"], 10, 64)
+	if err != nil {
+		errorResp(h.r, w, errcode.NewInvalidInputErr(err))
+		return
+	}
+	if err := h.RemoveSchedulerStore(vars["name"], storeID); err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, nil)
+}
@@ -20,6 +20,7 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/schedule"
 	_ "github.com/pingcap/pd/server/schedulers"
 )
 
@@ -62,14 +63,12 @@ func (s *testScheduleSuite) TestAPI(c *C) {
 		{name: "shuffle-leader-scheduler"},
 		{name: "shuffle-region-scheduler"},
 		{
-			name:        "grant-leader-scheduler",
-			createdName: "grant-leader-scheduler-1",
-			args:        []arg{{"store_id", 1}},
+			name: "grant-leader-scheduler",
+			args: []arg{{"store_id", 1}},
 		},
 		{
-			name:        "evict-leader-scheduler",
-			createdName: "evict-leader-scheduler-1",
-			args:        []arg{{"store_id", 1}},
+			name: "evict-leader-scheduler",
+			args: []arg{{"store_id", 1}},
 		},
 	}
 	for _, ca := range cases {
@@ -100,3 +99,107 @@ func (s *testScheduleSuite) testAddAndRemoveScheduler(name, createdName string,
 	err = doDelete(deleteURL)
 	c.Assert(err, IsNil)
 }
+
+func (s *testScheduleSuite) TestStoreScheduler(c *C) {
+	mustPutStore(c, s.svr, 2, metapb.StoreState_Up, nil)
+	for _, id := range []uint64{1, 2} {
+		body, err := json.Marshal(map[string]interface{}{"name": "evict-leader-scheduler", "store_id": id})
+		c.Assert(err, IsNil)
+		c.Assert(postJSON(s.urlPrefix, body), IsNil)
+	}
+	handler := s.svr.GetHandler()
+	sches, err := handler.GetSchedulers()
+	c.Assert(err, IsNil)
+	c.Assert(sches, HasLen, 1)
+
+	configURL := fmt.Sprintf("%s/%s/config", s.urlPrefix, "evict-leader-scheduler")
+	var stores map[uint64][]schedule.KeyRange
+	c.Assert(readJSONWithURL(configURL, &stores), IsNil)
+	c.Assert(stores, HasLen, 2)
+
+	// Update the ranges of store 2.
+	ranges := []schedule.KeyRange{{StartKey: "7480", EndKey: "7481"}}
+	body, err := json.Marshal(map[string]interface{}{"store_id": 2, "ranges": ranges})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(configURL, body), IsNil)
+	c.Assert(readJSONWithURL(configURL, &stores), IsNil)
+	c.Assert(stores[2], DeepEquals, ranges)
+	body, err = json.Marshal(map[string]interface{}{"ranges": ranges})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(configURL, body), NotNil)
+
+	// Remove the stores, and the scheduler is removed with the last store.
+	c.Assert(doDelete(configURL+"/1"), IsNil)
+	c.Assert(doDelete(s.urlPrefix+"/evict-leader-scheduler-2"), IsNil)
+	sches, err = handler.GetSchedulers()
+	c.Assert(err, IsNil)
+	c.Assert(sches, HasLen, 0)
+	_, err = doGet(configURL)
+	c.Assert(err, NotNil)
+}
+
+func (s *testScheduleSuite) TestConfig(c *C) {
+	body, err := json.Marshal(map[string]interface{}{"name": "shuffle-hot-region-scheduler", "limit": 1})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix, body), IsNil)
+	defer doDelete(s.urlPrefix + "/shuffle-hot-region-scheduler")
+
+	configURL := s.urlPrefix + "/shuffle-hot-region-scheduler/config"
+	var config map[string]interface{}
+	c.Assert(readJSONWithURL(configURL, &config), IsNil)
+	c.Assert(config["limit"], Equals, 1.0)
+	c.Assert(postJSON(configURL, []byte(`{"limit":3}`)), IsNil)
+	c.Assert(readJSONWithURL(configURL, &config), IsNil)
+	c.Assert(config["limit"], Equals, 3.0)
+	c.Assert(postJSON(configURL, []byte(`{"limit":0}`)), NotNil)
+
+	// Schedulers that are not configurable.
+	body, err = json.Marshal(map[string]interface{}{"name": "shuffle-leader-scheduler"})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix, body), IsNil)
+	defer doDelete(s.urlPrefix + "/shuffle-leader-scheduler")
+	_, err = doGet(s.urlPrefix + "/shuffle-leader-scheduler/config")
+	c.Assert(err, NotNil)
+}
+
+func (s *testScheduleSuite) TestPause(c *C) {
+	body, err := json.Marshal(map[string]interface{}{"name": "shuffle-leader-scheduler"})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix, body), IsNil)
+	defer doDelete(s.urlPrefix + "/shuffle-leader-scheduler")
+
+	var paused []string
+	c.Assert(readJSONWithURL(s.urlPrefix+"?status=paused", &paused), IsNil)
+	c.Assert(paused, HasLen, 0)
+
+	pauseURL := s.urlPrefix + "/shuffle-leader-scheduler/pause"
+	c.Assert(postJSON(pauseURL, []byte(`{"delay":0}`)), NotNil)
+	c.Assert(postJSON(pauseURL, []byte(`{"delay":9223372036854775807}`)), ErrorMatches, "(?s).*delay should be in.*")
+	c.Assert(postJSON(s.urlPrefix+"/unknown-scheduler/pause", []byte(`{"delay":60}`)), NotNil)
+	c.Assert(postJSON(pauseURL, []byte(`{"delay":60}`)), IsNil)
+	c.Assert(readJSONWithURL(s.urlPrefix+"?status=paused", &paused), IsNil)
+	c.Assert(paused, DeepEquals, []string{"shuffle-leader-scheduler"})
+
+	c.Assert(postJSON(s.urlPrefix+"/shuffle-leader-scheduler/resume", nil), IsNil)
+	c.Assert(readJSONWithURL(s.urlPrefix+"?status=paused", &paused), IsNil)
+	c.Assert(paused, HasLen, 0)
+	_, err = doGet(s.urlPrefix + "?status=unknown")
+	c.Assert(err, NotNil)
+}
+
+func (s *testScheduleSuite) TestExplain(c *C) {
+	for _, name := range []string{"balance-region-scheduler", "label-scheduler"} {
+		body, err := json.Marshal(map[string]interface{}{"name": name})
+		c.Assert(err, IsNil)
+		c.Assert(postJSON(s.urlPrefix, body), IsNil)
+		defer doDelete(s.urlPrefix + "/" + name)
+	}
+
+	var rounds []*schedule.ExplainRound
+	c.Assert(readJSONWithURL(s.urlPrefix+"/balance-region-scheduler/explain?limit=1", &rounds), IsNil)
+	c.Assert(len(rounds), LessEqual, 1)
+	_, err := doGet(s.urlPrefix + "/balance-region-scheduler/explain?limit=x")
+	c.Assert(err, NotNil)
+	_, err = doGet(s.urlPrefix + "/label-scheduler/explain")
+	c.Assert(err, NotNil)
+}
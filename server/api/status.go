@@ -21,17 +21,20 @@ import (
 )
 
 type statusHandler struct {
-	rd *render.Render
+	svr *server.Server
+	rd  *render.Render
 }
 
 type status struct {
-	BuildTS string `json:"build_ts"`
-	GitHash string `json:"git_hash"`
+	BuildTS string            `json:"build_ts"`
+	GitHash string            `json:"git_hash"`
+	TSO     *server.TSOStatus `json:"tso,omitempty"`
 }
 
-func newStatusHandler(rd *render.Render) *statusHandler {
+func newStatusHandler(svr *server.Server, rd *render.Render) *statusHandler {
 	return &statusHandler{
-		rd: rd,
+		svr: svr,
+		rd:  rd,
 	}
 }
 
@@ -39,6 +42,7 @@ func (h *statusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	version := status{
 		BuildTS: server.PDBuildTS,
 		GitHash: server.PDGitHash,
+		TSO:     h.svr.GetTSOStatus(),
 	}
 
 	h.rd.JSON(w, http.StatusOK, version)
@@ -38,6 +38,10 @@ func checkStatusResponse(c *C, body []byte, cfgs []*server.Config) {
 
 	c.Assert(got.BuildTS, Equals, server.PDBuildTS)
 	c.Assert(got.GitHash, Equals, server.PDGitHash)
+	// The status is served by the leader, so the TSO window is displayed.
+	c.Assert(got.TSO, NotNil)
+	c.Assert(got.TSO.SavedPhysical.After(got.TSO.Physical), IsTrue)
+	c.Assert(got.TSO.SaveInterval, Equals, cfgs[0].TsoSaveInterval)
 }
 
 func (s *testStatusAPISuite) TestStatus(c *C) {
@@ -39,23 +39,26 @@ type MetaStore struct {
 
 // StoreStatus contains status about a store.
 type StoreStatus struct {
-	Capacity           typeutil.ByteSize  `json:"capacity,omitempty"`
-	Available          typeutil.ByteSize  `json:"available,omitempty"`
-	LeaderCount        int                `json:"leader_count,omitempty"`
-	LeaderWeight       float64            `json:"leader_weight,omitempty"`
-	LeaderScore        float64            `json:"leader_score,omitempty"`
-	LeaderSize         int64              `json:"leader_size,omitempty"`
-	RegionCount        int                `json:"region_count,omitempty"`
-	RegionWeight       float64            `json:"region_weight,omitempty"`
-	RegionScore        float64            `json:"region_score,omitempty"`
-	RegionSize         int64              `json:"region_size,omitempty"`
-	SendingSnapCount   uint32             `json:"sending_snap_count,omitempty"`
-	ReceivingSnapCount uint32             `json:"receiving_snap_count,omitempty"`
-	ApplyingSnapCount  uint32             `json:"applying_snap_count,omitempty"`
-	IsBusy             bool               `json:"is_busy,omitempty"`
-	StartTS            *time.Time         `json:"start_ts,omitempty"`
-	LastHeartbeatTS    *time.Time         `json:"last_heartbeat_ts,omitempty"`
-	Uptime             *typeutil.Duration `json:"uptime,omitempty"`
+	Capacity            typeutil.ByteSize  `json:"capacity,omitempty"`
+	Available           typeutil.ByteSize  `json:"available,omitempty"`
+	LeaderCount         int                `json:"leader_count,omitempty"`
+	LeaderWeight        float64            `json:"leader_weight,omitempty"`
+	LeaderScore         float64            `json:"leader_score,omitempty"`
+	LeaderSize          int64              `json:"leader_size,omitempty"`
+	RegionCount         int                `json:"region_count,omitempty"`
+	RegionWeight        float64            `json:"region_weight,omitempty"`
+	RegionScore         float64            `json:"region_score,omitempty"`
+	RegionSize          int64              `json:"region_size,omitempty"`
+	SendingSnapCount    uint32             `json:"sending_snap_count,omitempty"`
+	ReceivingSnapCount  uint32             `json:"receiving_snap_count,omitempty"`
+	ApplyingSnapCount   uint32             `json:"applying_snap_count,omitempty"`
+	SnapshotThroughput  typeutil.ByteSize  `json:"snapshot_throughput,omitempty"`
+	IsSnapshotSlow      bool               `json:"is_snapshot_slow,omitempty"`
+	IsBusy              bool               `json:"is_busy,omitempty"`
+	MaintenanceDeadline *time.Time         `json:"maintenance_deadline,omitempty"`
+	StartTS             *time.Time         `json:"start_ts,omitempty"`
+	LastHeartbeatTS     *time.Time         `json:"last_heartbeat_ts,omitempty"`
+	Uptime              *typeutil.Duration `json:"uptime,omitempty"`
 }
 
 // StoreInfo contains information about a store.
@@ -89,6 +92,8 @@ func newStoreInfo(opt *server.ScheduleConfig, store *core.StoreInfo) *StoreInfo
 			SendingSnapCount:   store.GetSendingSnapCount(),
 			ReceivingSnapCount: store.GetReceivingSnapCount(),
 			ApplyingSnapCount:  store.GetApplyingSnapCount(),
+			SnapshotThroughput: typeutil.ByteSize(store.GetSnapshotStats().Throughput),
+			IsSnapshotSlow:     store.IsSnapshotSlow(),
 			IsBusy:             store.GetIsBusy(),
 		},
 	}
@@ -100,6 +105,10 @@ func newStoreInfo(opt *server.ScheduleConfig, store *core.StoreInfo) *StoreInfo
 	if lastHeartbeat := store.GetLastHeartbeatTS(); !lastHeartbeat.IsZero() {
 		s.Status.LastHeartbeatTS = &lastHeartbeat
 	}
+	if store.IsInMaintenance() {
+		deadline := store.GetMaintenanceDeadline()
+		s.Status.MaintenanceDeadline = &deadline
+	}
 	if upTime := store.GetUptime(); upTime > 0 {
 		duration := typeutil.NewDuration(upTime)
 		s.Status.Uptime = &duration
@@ -157,6 +166,28 @@ func (h *storeHandler) Get(w http.ResponseWriter, r *http.Request) {
 	h.rd.JSON(w, http.StatusOK, storeInfo)
 }
 
+func (h *storeHandler) GetProgress(w http.ResponseWriter, r *http.Request) {
+	cluster := h.GetRaftCluster()
+	if cluster == nil {
+		errorResp(h.rd, w, errcode.NewInternalErr(server.ErrNotBootstrapped))
+		return
+	}
+
+	vars := mux.Vars(r)
+	storeID, errParse := apiutil.ParseUint64VarsField(vars, "id")
+	if errParse != nil {
+		errorResp(h.rd, w, errcode.NewInvalidInputErr(errParse))
+		return
+	}
+
+	progress, err := cluster.GetStoreProgress(storeID)
+	if err != nil {
+		errorResp(h.rd, w, err)
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, progress)
+}
+
 func (h *storeHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	cluster := h.GetRaftCluster()
 	if cluster == nil {
@@ -305,7 +336,13 @@ func (h *storeHandler) SetWeight(w http.ResponseWriter, r *http.Request) {
 	h.rd.JSON(w, http.StatusOK, nil)
 }
 
-func (h *storeHandler) SetLimit(w http.ResponseWriter, r *http.Request) {
+func (h *storeHandler) SetMaintenance(w http.ResponseWriter, r *http.Request) {
+	cluster := h.GetRaftCluster()
+	if cluster == nil {
+		h.rd.JSON(w, http.StatusInternalServerError, server.ErrNotBootstrapped.Error())
+		return
+	}
+
 	vars := mux.Vars(r)
 	storeID, errParse := apiutil.ParseUint64VarsField(vars, "id")
 	if errParse != nil {
@@ -313,23 +350,66 @@ func (h *storeHandler) SetLimit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var input map[string]interface{}
+	var input map[string]string
 	if err := readJSONRespondError(h.rd, w, r.Body, &input); err != nil {
 		return
 	}
+	duration, err := time.ParseDuration(input["duration"])
+	if err != nil || duration <= 0 {
+		h.rd.JSON(w, http.StatusBadRequest, "badformat duration")
+		return
+	}
 
-	rateVal, ok := input["rate"]
-	if !ok {
-		h.rd.JSON(w, http.StatusBadRequest, "rate unset")
+	if err := cluster.SetStoreMaintenance(storeID, time.Now().Add(duration)); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	rate, ok := rateVal.(float64)
-	if !ok || rate < 0 {
-		h.rd.JSON(w, http.StatusBadRequest, "badformat rate")
+
+	h.rd.JSON(w, http.StatusOK, nil)
+}
+
+func (h *storeHandler) DeleteMaintenance(w http.ResponseWriter, r *http.Request) {
+	cluster := h.GetRaftCluster()
+	if cluster == nil {
+		h.rd.JSON(w, http.StatusInternalServerError, server.ErrNotBootstrapped.Error())
+		return
+	}
+
+	vars := mux.Vars(r)
+	storeID, errParse := apiutil.ParseUint64VarsField(vars, "id")
+	if errParse != nil {
+		errorResp(h.rd, w, errcode.NewInvalidInputErr(errParse))
 		return
 	}
 
-	if err := h.SetStoreLimit(storeID, rate/schedule.StoreBalanceBaseTime); err != nil {
+	if err := cluster.SetStoreMaintenance(storeID, time.Time{}); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.rd.JSON(w, http.StatusOK, nil)
+}
+
+func (h *storeHandler) SetLimit(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	storeID, errParse := apiutil.ParseUint64VarsField(vars, "id")
+	if errParse != nil {
+		errorResp(h.rd, w, errcode.NewInvalidInputErr(errParse))
+		return
+	}
+
+	var input map[string]interface{}
+	if err := readJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+
+	rate, types, err := parseStoreLimitInput(input)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.SetStoreLimit(storeID, rate/schedule.StoreBalanceBaseTime, types...); err != nil {
 		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -371,18 +451,21 @@ func (h *storesHandler) SetAllLimit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rateVal, ok := input["rate"]
-	if !ok {
-		h.rd.JSON(w, http.StatusBadRequest, "rate unset")
-		return
-	}
-	rate, ok := rateVal.(float64)
-	if !ok || rate < 0 {
-		h.rd.JSON(w, http.StatusBadRequest, "badformat rate")
+	rate, types, err := parseStoreLimitInput(input)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	rate /= schedule.StoreBalanceBaseTime
 
-	if err := h.SetAllStoresLimit(rate / schedule.StoreBalanceBaseTime); err != nil {
+	labelKey, _ := input["label_key"].(string)
+	labelValue, _ := input["label_value"].(string)
+	if labelKey != "" {
+		err = h.SetLabelStoresLimit(labelKey, labelValue, rate, types...)
+	} else {
+		err = h.SetAllStoresLimit(rate, types...)
+	}
+	if err != nil {
 		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -390,22 +473,63 @@ func (h *storesHandler) SetAllLimit(w http.ResponseWriter, r *http.Request) {
 	h.rd.JSON(w, http.StatusOK, nil)
 }
 
+// parseStoreLimitInput parses the rate and the types of the store limits. All
+// types are returned if the type is not specified.
+func parseStoreLimitInput(input map[string]interface{}) (float64, []schedule.StoreLimitType, error) {
+	rateVal, ok := input["rate"]
+	if !ok {
+		return 0, nil, errors.New("rate unset")
+	}
+	rate, ok := rateVal.(float64)
+	if !ok || rate <= 0 {
+		return 0, nil, errors.New("badformat rate")
+	}
+	typeVal, ok := input["type"]
+	if !ok {
+		return rate, schedule.StoreLimitTypes, nil
+	}
+	typeName, ok := typeVal.(string)
+	if !ok {
+		return 0, nil, errors.New("badformat type")
+	}
+	typ, err := schedule.ParseStoreLimitType(typeName)
+	if err != nil {
+		return 0, nil, err
+	}
+	return rate, []schedule.StoreLimitType{typ}, nil
+}
+
 func (h *storesHandler) GetAllLimit(w http.ResponseWriter, r *http.Request) {
 	limit, err := h.GetAllStoresLimit()
 	if err != nil {
 		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	ret := make(map[uint64]interface{})
-	for s, l := range limit {
-		ret[s] = struct {
-			Rate float64 `json:"rate"`
-		}{Rate: l * schedule.StoreBalanceBaseTime}
+	// The rates are the number of the operator steps per minute, the same as
+	// the input.
+	ret := make(map[uint64]*storeLimitResponse, len(limit))
+	for storeID, statuses := range limit {
+		resp := &storeLimitResponse{Types: statuses}
+		for i, typ := range schedule.StoreLimitTypes {
+			status := statuses[typ.String()]
+			status.Rate *= schedule.StoreBalanceBaseTime
+			if i == 0 || status.Rate < resp.Rate {
+				resp.Rate = status.Rate
+			}
+		}
+		ret[storeID] = resp
 	}
 
 	h.rd.JSON(w, http.StatusOK, ret)
 }
 
+// storeLimitResponse is the limits of a store. Rate is the lower rate of the
+// limit types, which is kept for the clients unaware of the limit types.
+type storeLimitResponse struct {
+	Rate  float64                               `json:"rate"`
+	Types map[string]*schedule.StoreLimitStatus `json:"types"`
+}
+
 func (h *storesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	cluster := h.GetRaftCluster()
 	if cluster == nil {
@@ -17,6 +17,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/url"
 	"strings"
@@ -27,6 +28,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/server"
 	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/server/schedule"
 )
 
 var _ = Suite(&testStoreSuite{})
@@ -251,6 +253,59 @@ func (s *testStoreSuite) TestStoreSetState(c *C) {
 	c.Assert(info.Store.State, Equals, metapb.StoreState_Up)
 }
 
+func (s *testStoreSuite) TestStoreProgress(c *C) {
+	url := fmt.Sprintf("%s/store/4", s.urlPrefix)
+	client := newHTTPClient()
+
+	// The store is not being removed.
+	status, _ := requestStatusBody(c, client, http.MethodGet, url+"/progress")
+	c.Assert(status, Equals, http.StatusBadRequest)
+	status, _ = requestStatusBody(c, client, http.MethodGet, fmt.Sprintf("%s/store/100/progress", s.urlPrefix))
+	c.Assert(status, Equals, http.StatusNotFound)
+
+	err := postJSON(url+"/state?state=Offline", nil)
+	c.Assert(err, IsNil)
+	progress := server.StoreProgress{}
+	err = readJSONWithURL(url+"/progress", &progress)
+	c.Assert(err, IsNil)
+	c.Assert(progress.StoreID, Equals, uint64(4))
+	c.Assert(progress.StartRegionCount, Equals, 0)
+	c.Assert(progress.Progress, Equals, 1.0)
+	c.Assert(progress.BlockedRegionCount, Equals, 0)
+
+	// Set back to Up.
+	err = postJSON(url+"/state?state=Up", nil)
+	c.Assert(err, IsNil)
+}
+
+func (s *testStoreSuite) TestStoreLimit(c *C) {
+	url := fmt.Sprintf("%s/stores/limit", s.urlPrefix)
+	err := postJSON(fmt.Sprintf("%s/store/4/limit", s.urlPrefix), []byte(`{"rate": 30, "type": "remove-peer"}`))
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(fmt.Sprintf("%s/store/4/limit", s.urlPrefix), []byte(`{"rate": 30, "type": "unknown"}`)), NotNil)
+	c.Assert(postJSON(url, []byte(`{"rate": 0}`)), NotNil)
+
+	limits := make(map[uint64]*storeLimitResponse)
+	err = readJSONWithURL(url, &limits)
+	c.Assert(err, IsNil)
+	c.Assert(limits[4].Types["remove-peer"].Rate, Equals, 30.0)
+	c.Assert(limits[4].Types["remove-peer"].Scope, Equals, schedule.StoreLimitScopeStore)
+	c.Assert(limits[4].Types["remove-peer"].Capacity, Equals, 1.0)
+	c.Assert(limits[4].Types["add-peer"].Scope, Not(Equals), schedule.StoreLimitScopeStore)
+	// The rate without the limit type is the lower one.
+	c.Assert(limits[4].Rate, Equals, math.Min(30, limits[4].Types["add-peer"].Rate))
+	c.Assert(limits[7], IsNil)
+
+	c.Assert(postJSON(url, []byte(`{"rate": 120, "type": "add-peer"}`)), IsNil)
+	err = readJSONWithURL(url, &limits)
+	c.Assert(err, IsNil)
+	c.Assert(limits[4].Types["add-peer"].Rate, Equals, 120.0)
+	c.Assert(limits[4].Types["add-peer"].Scope, Equals, schedule.StoreLimitScopeAll)
+	c.Assert(limits[4].Types["add-peer"].Capacity, Equals, 2.0)
+	c.Assert(limits[4].Types["remove-peer"].Rate, Equals, 30.0)
+	c.Assert(limits[4].Rate, Equals, 30.0)
+}
+
 func (s *testStoreSuite) TestUrlStoreFilter(c *C) {
 	table := []struct {
 		u    string
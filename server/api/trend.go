@@ -161,6 +161,10 @@ func (h *trendHandler) getTrendHistory(start time.Time) (*trendHistory, error) {
 	// Use a tmp map to merge same histories together.
 	historyMap := make(map[trendHistoryEntry]int)
 	for _, entry := range operatorHistory {
+		// The orphaned peers removed by rollback are not moved to any store.
+		if entry.To == 0 {
+			continue
+		}
 		historyMap[trendHistoryEntry{
 			From: entry.From,
 			To:   entry.To,
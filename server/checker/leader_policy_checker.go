@@ -0,0 +1,97 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/server/schedule"
+)
+
+// LeaderPolicyChecker transfers the leader of a region to the most preferred
+// store by the leader policy of the region, or to a store marked as
+// preferLeader by its labels if the region has no leader policy.
+type LeaderPolicyChecker struct {
+	cluster schedule.Cluster
+	filters []schedule.Filter
+}
+
+// NewLeaderPolicyChecker creates a leader policy checker.
+func NewLeaderPolicyChecker(cluster schedule.Cluster) *LeaderPolicyChecker {
+	filters := []schedule.Filter{
+		schedule.StoreStateFilter{TransferLeader: true},
+		schedule.NewRejectLeaderFilter(),
+	}
+	return &LeaderPolicyChecker{
+		cluster: cluster,
+		filters: filters,
+	}
+}
+
+// Check verifies if the leader is on a preferred store, creating an Operator
+// if need.
+func (l *LeaderPolicyChecker) Check(region *core.RegionInfo) *schedule.Operator {
+	policy := l.cluster.GetLeaderPolicy(region)
+	if policy == nil {
+		return l.checkPreferLeader(region)
+	}
+	checkerCounter.WithLabelValues("leader_policy_checker", "check").Inc()
+
+	leaderStore := l.cluster.GetLeaderStore(region)
+	if leaderStore == nil {
+		return nil
+	}
+	best, bestRank := uint64(0), policy.GetStoreRank(leaderStore)
+	for _, store := range l.cluster.GetFollowerStores(region) {
+		peer := region.GetStoreVoter(store.GetID())
+		if peer == nil || region.GetDownPeer(peer.GetId()) != nil || region.GetPendingPeer(peer.GetId()) != nil {
+			continue
+		}
+		if schedule.FilterTarget(l.cluster, store, l.filters) {
+			continue
+		}
+		if rank := policy.GetStoreRank(store); rank < bestRank {
+			best, bestRank = store.GetID(), rank
+		}
+	}
+	if best == 0 {
+		checkerCounter.WithLabelValues("leader_policy_checker", "all_right").Inc()
+		return nil
+	}
+	checkerCounter.WithLabelValues("leader_policy_checker", "new_operator").Inc()
+	return schedule.CreateTransferLeaderOperator("transfer-leader-to-preferred", region, leaderStore.GetID(), best, schedule.OpLeader)
+}
+
+// checkPreferLeader transfers the leader to a follower on a store marked as
+// preferLeader if the leader store is not.
+func (l *LeaderPolicyChecker) checkPreferLeader(region *core.RegionInfo) *schedule.Operator {
+	leaderStore := l.cluster.GetLeaderStore(region)
+	if leaderStore == nil || l.cluster.CheckLabelProperty(schedule.PreferLeader, leaderStore.GetLabels()) {
+		return nil
+	}
+	for _, store := range l.cluster.GetFollowerStores(region) {
+		if !l.cluster.CheckLabelProperty(schedule.PreferLeader, store.GetLabels()) {
+			continue
+		}
+		peer := region.GetStoreVoter(store.GetID())
+		if peer == nil || region.GetDownPeer(peer.GetId()) != nil || region.GetPendingPeer(peer.GetId()) != nil {
+			continue
+		}
+		if schedule.FilterTarget(l.cluster, store, l.filters) {
+			continue
+		}
+		checkerCounter.WithLabelValues("leader_policy_checker", "new_operator").Inc()
+		return schedule.CreateTransferLeaderOperator("transfer-leader-to-prefer-leader-store", region, leaderStore.GetID(), store.GetID(), schedule.OpLeader)
+	}
+	return nil
+}
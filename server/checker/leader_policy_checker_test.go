@@ -0,0 +1,90 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/pkg/mock/mockoption"
+	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/server/schedule"
+)
+
+var _ = Suite(&testLeaderPolicyCheckerSuite{})
+
+type testLeaderPolicyCheckerSuite struct {
+	cluster *mockcluster.Cluster
+	lc      *LeaderPolicyChecker
+}
+
+func (s *testLeaderPolicyCheckerSuite) SetUpTest(c *C) {
+	cfg := mockoption.NewScheduleOptions()
+	s.cluster = mockcluster.NewCluster(cfg)
+	s.lc = NewLeaderPolicyChecker(s.cluster)
+	s.cluster.AddLabelsStore(1, 1, map[string]string{"zone": "z1"})
+	s.cluster.AddLabelsStore(2, 1, map[string]string{"zone": "z2"})
+	s.cluster.AddLabelsStore(3, 1, map[string]string{"zone": "z3"})
+	s.cluster.AddLeaderRegion(1, 3, 1, 2)
+}
+
+func (s *testLeaderPolicyCheckerSuite) setPolicy(c *C, zones ...string) {
+	policy := &core.LeaderPolicy{ID: "test"}
+	for _, zone := range zones {
+		policy.Preferences = append(policy.Preferences, core.LeaderPreference{Key: "zone", Value: zone})
+	}
+	c.Assert(policy.Adjust(), IsNil)
+	s.cluster.LeaderPolicies.SetLeaderPolicy(policy)
+}
+
+func (s *testLeaderPolicyCheckerSuite) TestCheck(c *C) {
+	// No policy.
+	c.Assert(s.lc.Check(s.cluster.GetRegion(1)), IsNil)
+
+	s.setPolicy(c, "z2", "z1")
+	op := s.lc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "transfer-leader-to-preferred")
+	c.Assert(op.Step(0), DeepEquals, schedule.TransferLeader{FromStore: 3, ToStore: 2})
+
+	// Fall back to the next preference if the store rejects leaders.
+	s.cluster.SetStoreBusy(2, true)
+	s.cluster.SetStoreDisconnect(2)
+	op = s.lc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Step(0), DeepEquals, schedule.TransferLeader{FromStore: 3, ToStore: 1})
+
+	// The leader is already on the most preferred store.
+	s.setPolicy(c, "z3")
+	c.Assert(s.lc.Check(s.cluster.GetRegion(1)), IsNil)
+}
+
+func (s *testLeaderPolicyCheckerSuite) TestPreferLeader(c *C) {
+	s.cluster.LabelProperties = map[string][]*metapb.StoreLabel{
+		schedule.PreferLeader: {{Key: "zone", Value: "z2"}},
+	}
+	op := s.lc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "transfer-leader-to-prefer-leader-store")
+	c.Assert(op.Step(0), DeepEquals, schedule.TransferLeader{FromStore: 3, ToStore: 2})
+
+	// The leader policy takes precedence.
+	s.setPolicy(c, "z3")
+	c.Assert(s.lc.Check(s.cluster.GetRegion(1)), IsNil)
+	s.cluster.LeaderPolicies.DeleteLeaderPolicy("test")
+
+	// The leader is already on the preferred store.
+	s.cluster.AddLeaderRegion(2, 2, 1, 3)
+	c.Assert(s.lc.Check(s.cluster.GetRegion(2)), IsNil)
+}
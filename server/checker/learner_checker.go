@@ -14,28 +14,121 @@
 package checker
 
 import (
+	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/server/namespace"
+	"github.com/pingcap/pd/server/placement"
 	"github.com/pingcap/pd/server/schedule"
 )
 
-// LearnerChecker ensures region has a learner will be promoted.
-type LearnerChecker struct{}
+// LearnerChecker ensures region has a learner will be promoted. When
+// placement rules are enabled, it also maintains the learners required by the
+// rules, which are never promoted.
+type LearnerChecker struct {
+	cluster     schedule.Cluster
+	ruleManager *placement.RuleManager
+	classifier  namespace.Classifier
+}
 
 // NewLearnerChecker creates a learner checker.
-func NewLearnerChecker() *LearnerChecker { return &LearnerChecker{} }
+func NewLearnerChecker(cluster schedule.Cluster, ruleManager *placement.RuleManager, classifier namespace.Classifier) *LearnerChecker {
+	return &LearnerChecker{
+		cluster:     cluster,
+		ruleManager: ruleManager,
+		classifier:  classifier,
+	}
+}
 
 // Check verifies a region's namespace, creating an Operator if need.
 func (l *LearnerChecker) Check(region *core.RegionInfo) *schedule.Operator {
+	if l.cluster.IsPlacementRulesEnabled() && l.ruleManager.IsInitialized() {
+		return l.checkRuleLearners(region)
+	}
 	for _, p := range region.GetLearners() {
 		if region.GetPendingLearner(p.GetId()) != nil {
 			continue
 		}
-		step := schedule.PromoteLearner{
-			ToStore: p.GetStoreId(),
-			PeerID:  p.GetId(),
+		return schedule.CreatePromoteLearnerOperator("promote-learner", region, p)
+	}
+	return nil
+}
+
+func (l *LearnerChecker) checkRuleLearners(region *core.RegionInfo) *schedule.Operator {
+	checkerCounter.WithLabelValues("learner_checker", "check").Inc()
+	fit := l.ruleManager.FitRegion(l.cluster.GetRegionStores(region), region, isHealthyPeer(l.cluster, region))
+	if len(fit.RuleFits) == 0 {
+		return nil
+	}
+
+	for _, rf := range fit.RuleFits {
+		if rf.Rule.Role != placement.Learner || rf.IsSatisfied() {
+			continue
+		}
+		if op := l.addRuleLearner(region, rf); op != nil {
+			return op
+		}
+	}
+
+	// The learners which do not belong to any rule are left by other
+	// operators. Promote them if they are needed by the rules of voters,
+	// otherwise remove them.
+	isHealthy := isHealthyPeer(l.cluster, region)
+	for _, p := range fit.OrphanPeers {
+		if !p.GetIsLearner() || region.GetPendingLearner(p.GetId()) != nil {
+			continue
+		}
+		if isHealthy(p) && l.isNeededByVoters(fit, p) {
+			return schedule.CreatePromoteLearnerOperator("promote-learner", region, p)
 		}
-		op := schedule.NewOperator("promote-learner", region.GetID(), region.GetRegionEpoch(), schedule.OpRegion, step)
+		if !l.cluster.IsRemoveExtraReplicaEnabled() {
+			continue
+		}
+		op, err := schedule.CreateRemovePeerOperator("remove-orphan-learner", l.cluster, schedule.OpReplica, region, p.GetStoreId())
+		if err != nil {
+			checkerCounter.WithLabelValues("learner_checker", "create_operator_fail").Inc()
+			continue
+		}
+		checkerCounter.WithLabelValues("learner_checker", "remove_orphan_learner").Inc()
 		return op
 	}
 	return nil
 }
+
+func (l *LearnerChecker) addRuleLearner(region *core.RegionInfo, rf *placement.RuleFit) *schedule.Operator {
+	if !l.cluster.IsMakeUpReplicaEnabled() {
+		return nil
+	}
+	var filters []schedule.Filter
+	if l.classifier != nil {
+		filters = append(filters, schedule.NewNamespaceFilter(l.classifier, l.classifier.GetRegionNamespace(region)))
+	}
+	store := selectRuleStore(l.cluster, region, rf.Rule, getRuleFitStores(l.cluster, rf), filters...)
+	if store == nil {
+		checkerCounter.WithLabelValues("learner_checker", "no_target_store").Inc()
+		return nil
+	}
+	newPeer, err := l.cluster.AllocPeer(store.GetID())
+	if err != nil {
+		return nil
+	}
+	checkerCounter.WithLabelValues("learner_checker", "add_rule_learner").Inc()
+	return schedule.CreateAddLearnerOperator("add-rule-learner", l.cluster, region, newPeer.GetId(), newPeer.GetStoreId(), schedule.OpReplica)
+}
+
+// isNeededByVoters checks if the learner can be counted toward an unsatisfied
+// rule of voters after it is promoted.
+func (l *LearnerChecker) isNeededByVoters(fit *placement.RegionFit, peer *metapb.Peer) bool {
+	store := l.cluster.GetStore(peer.GetStoreId())
+	if store == nil {
+		return false
+	}
+	for _, rf := range fit.RuleFits {
+		if rf.Rule.Role == placement.Learner || rf.Rule.Role == placement.Leader || rf.IsSatisfied() {
+			continue
+		}
+		if rf.Rule.MatchStore(store) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/pkg/mock/mockoption"
+	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/server/namespace"
+	"github.com/pingcap/pd/server/placement"
+	"github.com/pingcap/pd/server/schedule"
+)
+
+var _ = Suite(&testLearnerCheckerSuite{})
+
+type testLearnerCheckerSuite struct {
+	cluster     *mockcluster.Cluster
+	ruleManager *placement.RuleManager
+	lc          *LearnerChecker
+}
+
+func (s *testLearnerCheckerSuite) SetUpTest(c *C) {
+	cfg := mockoption.NewScheduleOptions()
+	s.cluster = mockcluster.NewCluster(cfg)
+	s.ruleManager = placement.NewRuleManager(core.NewKV(core.NewMemoryKV()))
+	c.Assert(s.ruleManager.Initialize(3, nil), IsNil)
+	s.lc = NewLearnerChecker(s.cluster, s.ruleManager, namespace.DefaultClassifier)
+	for i := uint64(1); i <= 3; i++ {
+		s.cluster.AddLabelsStore(i, 1, map[string]string{"zone": "z1"})
+	}
+	s.cluster.AddLabelsStore(4, 1, map[string]string{"zone": "analytics"})
+	s.cluster.AddLabelsStore(5, 1, map[string]string{"zone": "analytics"})
+}
+
+func (s *testLearnerCheckerSuite) newRegion(voters []uint64, learners ...uint64) *core.RegionInfo {
+	var peers []*metapb.Peer
+	for _, id := range voters {
+		peers = append(peers, &metapb.Peer{Id: id + 100, StoreId: id})
+	}
+	for _, id := range learners {
+		peers = append(peers, &metapb.Peer{Id: id + 100, StoreId: id, IsLearner: true})
+	}
+	region := core.NewRegionInfo(&metapb.Region{Id: 1, Peers: peers}, peers[0])
+	s.cluster.PutRegion(region)
+	return region
+}
+
+func (s *testLearnerCheckerSuite) setLearnerRule(c *C) {
+	c.Assert(s.ruleManager.SetRule(&placement.Rule{
+		ID:               "analytics",
+		Index:            1,
+		Role:             placement.Learner,
+		Count:            1,
+		LabelConstraints: []placement.Filter{{Key: "zone", Value: "analytics"}},
+	}), IsNil)
+}
+
+func (s *testLearnerCheckerSuite) TestPromoteLearner(c *C) {
+	region := s.newRegion([]uint64{1, 2}, 3)
+	op := s.lc.Check(region)
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "promote-learner")
+	c.Assert(op.Step(0).(schedule.PromoteLearner).ToStore, Equals, uint64(3))
+
+	region = region.Clone(core.WithPendingPeers([]*metapb.Peer{region.GetStorePeer(3)}))
+	c.Assert(s.lc.Check(region), IsNil)
+}
+
+func (s *testLearnerCheckerSuite) TestRuleLearner(c *C) {
+	s.cluster.EnablePlacementRules = true
+	s.setLearnerRule(c)
+
+	// Add a learner for the rule.
+	region := s.newRegion([]uint64{1, 2, 3})
+	op := s.lc.Check(region)
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "add-rule-learner")
+	c.Assert(op.Step(0).(schedule.AddLearner).ToStore >= 4, IsTrue)
+
+	// The learner of the rule is not promoted.
+	region = s.newRegion([]uint64{1, 2, 3}, 4)
+	c.Assert(s.lc.Check(region), IsNil)
+
+	// Extra learners are removed.
+	region = s.newRegion([]uint64{1, 2, 3}, 4, 5)
+	op = s.lc.Check(region)
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "remove-orphan-learner")
+
+	// A learner left by other operators is promoted if voters are needed.
+	region = s.newRegion([]uint64{1, 2}, 3, 4)
+	op = s.lc.Check(region)
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "promote-learner")
+	c.Assert(op.Step(0).(schedule.PromoteLearner).ToStore, Equals, uint64(3))
+
+	// Replace the learner on an offline store.
+	s.cluster.SetStoreOffline(4)
+	region = s.newRegion([]uint64{1, 2, 3}, 4)
+	op = s.lc.Check(region)
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "add-rule-learner")
+	c.Assert(op.Step(0).(schedule.AddLearner).ToStore, Equals, uint64(5))
+	region = s.newRegion([]uint64{1, 2, 3}, 4, 5)
+	op = s.lc.Check(region)
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "remove-orphan-learner")
+	c.Assert(op.Step(0).(schedule.RemovePeer).FromStore, Equals, uint64(4))
+}
+
+func (s *testLearnerCheckerSuite) TestRuleCheckerIgnoreLearners(c *C) {
+	s.cluster.EnablePlacementRules = true
+	s.setLearnerRule(c)
+	rc := NewRuleChecker(s.cluster, s.ruleManager, namespace.DefaultClassifier)
+
+	// Learners are not counted as voters.
+	region := s.newRegion([]uint64{1, 2}, 4)
+	op := rc.Check(region)
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "add-rule-peer")
+	c.Assert(op.Step(0).(schedule.AddLearner).ToStore, Not(Equals), uint64(4))
+
+	region = s.newRegion([]uint64{1, 2, 3}, 4, 5)
+	c.Assert(rc.Check(region), IsNil)
+}
@@ -88,7 +88,7 @@ func (m *MergeChecker) Check(region *core.RegionInfo) []*schedule.Operator {
 		return nil
 	}
 
-	if len(region.GetPeers()) != m.cluster.GetMaxReplicas() {
+	if len(region.GetPeers()) != schedule.GetRegionMaxReplicas(m.cluster, region) {
 		checkerCounter.WithLabelValues("merge_checker", "abnormal_replica").Inc()
 		return nil
 	}
@@ -128,17 +128,38 @@ func (m *MergeChecker) Check(region *core.RegionInfo) []*schedule.Operator {
 }
 
 func (m *MergeChecker) checkTarget(region, adjacent, target *core.RegionInfo) *core.RegionInfo {
-	// if is not hot region and under same namespace
+	// if is not hot region and under same namespace, leader policy and range replication
 	if adjacent != nil && !m.cluster.IsRegionHot(adjacent.GetID()) &&
-		m.classifier.AllowMerge(region, adjacent) &&
+		m.classifier.AllowMerge(region, adjacent) && m.isSameLeaderPolicy(region, adjacent) &&
+		m.isSameRangeReplication(region, adjacent) &&
 		len(adjacent.GetDownPeers()) == 0 && len(adjacent.GetPendingPeers()) == 0 && len(adjacent.GetLearners()) == 0 {
 		// if both region is not hot, prefer the one with smaller size
 		if target == nil || target.GetApproximateSize() > adjacent.GetApproximateSize() {
 			// peer count should equal
-			if len(adjacent.GetPeers()) == m.cluster.GetMaxReplicas() {
+			if len(adjacent.GetPeers()) == schedule.GetRegionMaxReplicas(m.cluster, adjacent) {
 				target = adjacent
 			}
 		}
 	}
 	return target
 }
+
+// isSameLeaderPolicy checks if both regions are under the same leader policy,
+// so that the policy still applies to the merged region.
+func (m *MergeChecker) isSameLeaderPolicy(region, adjacent *core.RegionInfo) bool {
+	p1, p2 := m.cluster.GetLeaderPolicy(region), m.cluster.GetLeaderPolicy(adjacent)
+	if p1 == nil || p2 == nil {
+		return p1 == nil && p2 == nil
+	}
+	return p1.ID == p2.ID
+}
+
+// isSameRangeReplication checks if both regions are under the same range
+// replication, so that the merged region keeps its replication config.
+func (m *MergeChecker) isSameRangeReplication(region, adjacent *core.RegionInfo) bool {
+	r1, r2 := m.cluster.GetRangeReplication(region), m.cluster.GetRangeReplication(adjacent)
+	if r1 == nil || r2 == nil {
+		return r1 == nil && r2 == nil
+	}
+	return r1.ID == r2.ID
+}
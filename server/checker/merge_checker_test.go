@@ -258,3 +258,39 @@ func (s *testMergeCheckerSuite) TestMatchPeers(c *C) {
 		},
 	})
 }
+
+func (s *testMergeCheckerSuite) TestLeaderPolicy(c *C) {
+	policy := &core.LeaderPolicy{
+		ID:          "test",
+		StartKeyHex: "74",
+		Preferences: []core.LeaderPreference{{Key: "zone", Value: "z1"}},
+	}
+	c.Assert(policy.Adjust(), IsNil)
+	s.cluster.LeaderPolicies.SetLeaderPolicy(policy)
+
+	// Region 3 can be merged into region 2 only, which is in another policy.
+	ops := s.mc.Check(s.regions[2])
+	c.Assert(ops, IsNil)
+
+	s.cluster.LeaderPolicies.DeleteLeaderPolicy("test")
+	ops = s.mc.Check(s.regions[2])
+	c.Assert(ops, NotNil)
+}
+
+func (s *testMergeCheckerSuite) TestRangeReplication(c *C) {
+	r := &core.RangeReplication{
+		ID:          "test",
+		StartKeyHex: "74",
+		MaxReplicas: 3,
+	}
+	c.Assert(r.Adjust(), IsNil)
+	s.cluster.RangeReplications.SetRangeReplication(r)
+
+	// Region 3 can be merged into region 2 only, which is in another range.
+	ops := s.mc.Check(s.regions[2])
+	c.Assert(ops, IsNil)
+
+	s.cluster.RangeReplications.DeleteRangeReplication("test")
+	ops = s.mc.Check(s.regions[2])
+	c.Assert(ops, NotNil)
+}
@@ -41,6 +41,7 @@ func NewReplicaChecker(cluster schedule.Cluster, classifier namespace.Classifier
 		schedule.NewOverloadFilter(),
 		schedule.NewHealthFilter(),
 		schedule.NewSnapshotCountFilter(),
+		schedule.NewRejectPeerFilter(),
 	}
 
 	return &ReplicaChecker{
@@ -63,8 +64,12 @@ func (r *ReplicaChecker) Check(region *core.RegionInfo) *schedule.Operator {
 		op.SetPriorityLevel(core.HighPriority)
 		return op
 	}
+	if op := r.checkRejectPeer(region); op != nil {
+		checkerCounter.WithLabelValues("replica_checker", "new_operator").Inc()
+		return op
+	}
 
-	if len(region.GetPeers()) < r.cluster.GetMaxReplicas() && r.cluster.IsMakeUpReplicaEnabled() {
+	if len(region.GetPeers()) < schedule.GetRegionMaxReplicas(r.cluster, region) && r.cluster.IsMakeUpReplicaEnabled() {
 		log.Debug("region has fewer than max replicas", zap.Uint64("region-id", region.GetID()), zap.Int("peers", len(region.GetPeers())))
 		newPeer, _ := r.selectBestPeerToAddReplica(region, schedule.NewStorageThresholdFilter())
 		if newPeer == nil {
@@ -77,7 +82,7 @@ func (r *ReplicaChecker) Check(region *core.RegionInfo) *schedule.Operator {
 
 	// when add learner peer, the number of peer will exceed max replicas for a while,
 	// just comparing the the number of voters to avoid too many cancel add operator log.
-	if len(region.GetVoters()) > r.cluster.GetMaxReplicas() && r.cluster.IsRemoveExtraReplicaEnabled() {
+	if len(region.GetVoters()) > schedule.GetRegionMaxReplicas(r.cluster, region) && r.cluster.IsRemoveExtraReplicaEnabled() {
 		log.Debug("region has more than max replicas", zap.Uint64("region-id", region.GetID()), zap.Int("peers", len(region.GetPeers())))
 		oldPeer, _ := r.selectWorstPeer(region)
 		if oldPeer == nil {
@@ -117,8 +122,20 @@ func (r *ReplicaChecker) selectBestPeerToAddReplica(region *core.RegionInfo, fil
 	return newPeer, score
 }
 
-// selectBestStoreToAddReplica returns the store to add a replica.
+// selectBestStoreToAddReplica returns the store to add a replica. The stores
+// slow at applying snapshots are only selected if there is no other store, so
+// they do not block the replica repair.
 func (r *ReplicaChecker) selectBestStoreToAddReplica(region *core.RegionInfo, filters ...schedule.Filter) (uint64, float64) {
+	preferred := make([]schedule.Filter, 0, len(filters)+1)
+	preferred = append(preferred, filters...)
+	preferred = append(preferred, schedule.NewSnapshotThroughputFilter())
+	if storeID, score := r.selectStoreToAddReplica(region, preferred...); storeID != 0 {
+		return storeID, score
+	}
+	return r.selectStoreToAddReplica(region, filters...)
+}
+
+func (r *ReplicaChecker) selectStoreToAddReplica(region *core.RegionInfo, filters ...schedule.Filter) (uint64, float64) {
 	// Add some must have filters.
 	newFilters := []schedule.Filter{
 		schedule.NewStateFilter(),
@@ -131,24 +148,24 @@ func (r *ReplicaChecker) selectBestStoreToAddReplica(region *core.RegionInfo, fi
 		filters = append(filters, schedule.NewNamespaceFilter(r.classifier, r.classifier.GetRegionNamespace(region)))
 	}
 	regionStores := r.cluster.GetRegionStores(region)
-	selector := schedule.NewReplicaSelector(regionStores, r.cluster.GetLocationLabels(), r.filters...)
+	selector := schedule.NewReplicaSelector(regionStores, schedule.GetRegionLocationLabels(r.cluster, region), r.filters...)
 	target := selector.SelectTarget(r.cluster, r.cluster.GetStores(), filters...)
 	if target == nil {
 		return 0, 0
 	}
-	return target.GetID(), schedule.DistinctScore(r.cluster.GetLocationLabels(), regionStores, target)
+	return target.GetID(), schedule.DistinctScore(schedule.GetRegionLocationLabels(r.cluster, region), regionStores, target)
 }
 
 // selectWorstPeer returns the worst peer in the region.
 func (r *ReplicaChecker) selectWorstPeer(region *core.RegionInfo) (*metapb.Peer, float64) {
 	regionStores := r.cluster.GetRegionStores(region)
-	selector := schedule.NewReplicaSelector(regionStores, r.cluster.GetLocationLabels(), r.filters...)
+	selector := schedule.NewReplicaSelector(regionStores, schedule.GetRegionLocationLabels(r.cluster, region), r.filters...)
 	worstStore := selector.SelectSource(r.cluster, regionStores)
 	if worstStore == nil {
 		log.Debug("no worst store", zap.Uint64("region-id", region.GetID()))
 		return nil, 0
 	}
-	return region.GetStorePeer(worstStore.GetID()), schedule.DistinctScore(r.cluster.GetLocationLabels(), regionStores, worstStore)
+	return region.GetStorePeer(worstStore.GetID()), schedule.DistinctScore(schedule.GetRegionLocationLabels(r.cluster, region), regionStores, worstStore)
 }
 
 func (r *ReplicaChecker) checkDownPeer(region *core.RegionInfo) *schedule.Operator {
@@ -166,7 +183,8 @@ func (r *ReplicaChecker) checkDownPeer(region *core.RegionInfo) *schedule.Operat
 			log.Info("lost the store, maybe you are recovering the PD cluster", zap.Uint64("store-id", peer.GetStoreId()))
 			return nil
 		}
-		if store.DownTime() < r.cluster.GetMaxStoreDownTime() {
+		// The store is expected to be back after its maintenance.
+		if store.IsInMaintenance() || store.DownTime() < r.cluster.GetMaxStoreDownTime() {
 			continue
 		}
 		if stats.GetDownSeconds() < uint64(r.cluster.GetMaxStoreDownTime().Seconds()) {
@@ -204,6 +222,19 @@ func (r *ReplicaChecker) checkOfflinePeer(region *core.RegionInfo) *schedule.Ope
 	return nil
 }
 
+// checkRejectPeer moves the peers away from the stores which are marked as
+// rejectPeer by their labels.
+func (r *ReplicaChecker) checkRejectPeer(region *core.RegionInfo) *schedule.Operator {
+	for _, peer := range region.GetPeers() {
+		store := r.cluster.GetStore(peer.GetStoreId())
+		if store == nil || !r.cluster.CheckLabelProperty(schedule.RejectPeer, store.GetLabels()) {
+			continue
+		}
+		return r.fixPeer(region, peer, "rejected")
+	}
+	return nil
+}
+
 func (r *ReplicaChecker) checkBestReplacement(region *core.RegionInfo) *schedule.Operator {
 	if !r.cluster.IsLocationReplacementEnabled() {
 		return nil
@@ -241,7 +272,7 @@ func (r *ReplicaChecker) checkBestReplacement(region *core.RegionInfo) *schedule
 func (r *ReplicaChecker) fixPeer(region *core.RegionInfo, peer *metapb.Peer, status string) *schedule.Operator {
 	removeExtra := fmt.Sprintf("remove-extra-%s-replica", status)
 	// Check the number of replicas first.
-	if len(region.GetPeers()) > r.cluster.GetMaxReplicas() {
+	if len(region.GetPeers()) > schedule.GetRegionMaxReplicas(r.cluster, region) {
 		op, err := schedule.CreateRemovePeerOperator(removeExtra, r.cluster, schedule.OpReplica, region, peer.GetStoreId())
 		if err != nil {
 			checkerCounter.WithLabelValues("replica_checker", "create_operator_fail").Inc()
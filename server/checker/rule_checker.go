@@ -0,0 +1,370 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"github.com/pingcap/kvproto/pkg/metapb"
+	log "github.com/pingcap/log"
+	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/server/namespace"
+	"github.com/pingcap/pd/server/placement"
+	"github.com/pingcap/pd/server/schedule"
+	"go.uber.org/zap"
+)
+
+// RuleChecker fixes the replicas of a region according to the placement
+// rules. It is used instead of ReplicaChecker when placement rules are
+// enabled.
+type RuleChecker struct {
+	cluster     schedule.Cluster
+	ruleManager *placement.RuleManager
+	classifier  namespace.Classifier
+	filters     []schedule.Filter
+}
+
+// NewRuleChecker creates a checker instance.
+func NewRuleChecker(cluster schedule.Cluster, ruleManager *placement.RuleManager, classifier namespace.Classifier) *RuleChecker {
+	filters := []schedule.Filter{
+		schedule.NewOverloadFilter(),
+		schedule.NewHealthFilter(),
+		schedule.NewSnapshotCountFilter(),
+		schedule.NewRejectPeerFilter(),
+	}
+	return &RuleChecker{
+		cluster:     cluster,
+		ruleManager: ruleManager,
+		classifier:  classifier,
+		filters:     filters,
+	}
+}
+
+// Check checks if the region matches placement rules, creating an
+// schedule.Operator if need.
+func (c *RuleChecker) Check(region *core.RegionInfo) *schedule.Operator {
+	checkerCounter.WithLabelValues("rule_checker", "check").Inc()
+
+	fit := c.ruleManager.FitRegion(c.cluster.GetRegionStores(region), region, isHealthyPeer(c.cluster, region))
+	if len(fit.RuleFits) == 0 {
+		checkerCounter.WithLabelValues("rule_checker", "no_rule").Inc()
+		return nil
+	}
+	if op := c.fixLeader(region, fit); op != nil {
+		return c.newOperator(op)
+	}
+	if op := c.fixRulePeers(region, fit); op != nil {
+		return c.newOperator(op)
+	}
+	if op := c.fixOrphanPeers(region, fit); op != nil {
+		return c.newOperator(op)
+	}
+	if op := c.fixBetterLocation(region, fit); op != nil {
+		return c.newOperator(op)
+	}
+	checkerCounter.WithLabelValues("rule_checker", "all_right").Inc()
+	return nil
+}
+
+func (c *RuleChecker) newOperator(op *schedule.Operator) *schedule.Operator {
+	checkerCounter.WithLabelValues("rule_checker", "new_operator").Inc()
+	return op
+}
+
+// fixRulePeers adds peers for the rules which do not have enough peers. An
+// orphan peer is replaced by the new peer if there is any. Learners are
+// maintained by LearnerChecker.
+func (c *RuleChecker) fixRulePeers(region *core.RegionInfo, fit *placement.RegionFit) *schedule.Operator {
+	orphans := orphanVoters(fit)
+	for _, rf := range fit.RuleFits {
+		if rf.IsSatisfied() || rf.Rule.Role == placement.Learner {
+			continue
+		}
+		if rf.Rule.Role == placement.Leader && c.hasLeaderCandidate(region, rf.Rule) {
+			// The leader can be transferred to an existing peer by fixLeader.
+			continue
+		}
+		if len(orphans) == 0 && !c.cluster.IsMakeUpReplicaEnabled() {
+			continue
+		}
+		storeID := c.selectStoreToAdd(region, rf)
+		if storeID == 0 {
+			checkerCounter.WithLabelValues("rule_checker", "no_target_store").Inc()
+			continue
+		}
+		newPeer, err := c.cluster.AllocPeer(storeID)
+		if err != nil {
+			return nil
+		}
+		if len(orphans) > 0 {
+			oldPeer := c.selectOrphanToReplace(region, orphans)
+			op, err := schedule.CreateMovePeerOperator("replace-rule-peer", c.cluster, region, schedule.OpReplica, oldPeer.GetStoreId(), newPeer.GetStoreId(), newPeer.GetId())
+			if err != nil {
+				checkerCounter.WithLabelValues("rule_checker", "create_operator_fail").Inc()
+				return nil
+			}
+			op.SetPriorityLevel(core.HighPriority)
+			return op
+		}
+		log.Debug("rule has fewer than expected peers", zap.Uint64("region-id", region.GetID()), zap.String("rule-id", rf.Rule.ID))
+		return schedule.CreateAddPeerOperator("add-rule-peer", c.cluster, region, newPeer.GetId(), newPeer.GetStoreId(), schedule.OpReplica)
+	}
+	return nil
+}
+
+// fixOrphanPeers removes the voters which do not belong to any rule.
+func (c *RuleChecker) fixOrphanPeers(region *core.RegionInfo, fit *placement.RegionFit) *schedule.Operator {
+	orphans := orphanVoters(fit)
+	if len(orphans) == 0 || !c.cluster.IsRemoveExtraReplicaEnabled() {
+		return nil
+	}
+	// Only remove orphan peers when all rules are satisfied, otherwise the
+	// peer may be needed to replace another one or to take the leadership.
+	if !fit.IsVotersSatisfied() {
+		return nil
+	}
+	peer := c.selectOrphanToReplace(region, orphans)
+	op, err := schedule.CreateRemovePeerOperator("remove-orphan-peer", c.cluster, schedule.OpReplica, region, peer.GetStoreId())
+	if err != nil {
+		checkerCounter.WithLabelValues("rule_checker", "create_operator_fail").Inc()
+		return nil
+	}
+	return op
+}
+
+// fixLeader transfers the leader to a peer of the leader rule, or away from a
+// peer of a follower rule.
+func (c *RuleChecker) fixLeader(region *core.RegionInfo, fit *placement.RegionFit) *schedule.Operator {
+	leaderID := region.GetLeader().GetId()
+	for _, rf := range fit.RuleFits {
+		if rf.Rule.Role != placement.Leader || rf.IsSatisfied() {
+			continue
+		}
+		if target := c.selectLeaderCandidate(region, rf.Rule); target != nil {
+			return schedule.CreateTransferLeaderOperator("transfer-leader-to-rule", region, region.GetLeader().GetStoreId(), target.GetStoreId(), schedule.OpLeader)
+		}
+		checkerCounter.WithLabelValues("rule_checker", "no_leader_target").Inc()
+	}
+
+	// The leader can not be divided to a follower rule. If it is the peer the
+	// follower rule lacks, transfer the leader away rather than moving it.
+	if rf := fit.GetRuleFit(leaderID); (rf != nil && rf.Rule.Role == placement.Leader) || !isHealthyPeer(c.cluster, region)(region.GetLeader()) {
+		return nil
+	}
+	leaderStore := c.cluster.GetStore(region.GetLeader().GetStoreId())
+	for _, rf := range fit.RuleFits {
+		if rf.Rule.Role != placement.Follower || rf.IsSatisfied() || !rf.Rule.MatchStore(leaderStore) {
+			continue
+		}
+		filters := []schedule.Filter{schedule.NewStateFilter(), schedule.NewRejectLeaderFilter()}
+		for _, p := range region.GetVoters() {
+			if p.GetId() == leaderID {
+				continue
+			}
+			if r := fit.GetRuleFit(p.GetId()); r == nil || r.Rule.Role == placement.Follower {
+				continue
+			}
+			store := c.cluster.GetStore(p.GetStoreId())
+			if store == nil || schedule.FilterTarget(c.cluster, store, filters) {
+				continue
+			}
+			return schedule.CreateTransferLeaderOperator("transfer-leader-from-follower-rule", region, leaderStore.GetID(), p.GetStoreId(), schedule.OpLeader)
+		}
+	}
+	return nil
+}
+
+func (c *RuleChecker) hasLeaderCandidate(region *core.RegionInfo, rule *placement.Rule) bool {
+	return c.selectLeaderCandidate(region, rule) != nil
+}
+
+// selectLeaderCandidate returns a healthy follower which is able to become the
+// leader of the leader rule.
+func (c *RuleChecker) selectLeaderCandidate(region *core.RegionInfo, rule *placement.Rule) *metapb.Peer {
+	isHealthy := isHealthyPeer(c.cluster, region)
+	filters := []schedule.Filter{schedule.NewStateFilter(), schedule.NewRejectLeaderFilter()}
+	for _, p := range region.GetVoters() {
+		if p.GetId() == region.GetLeader().GetId() || !isHealthy(p) {
+			continue
+		}
+		store := c.cluster.GetStore(p.GetStoreId())
+		if store == nil || !rule.MatchStore(store) || schedule.FilterTarget(c.cluster, store, filters) {
+			continue
+		}
+		return p
+	}
+	return nil
+}
+
+// fixBetterLocation moves a peer of a rule to a store that is better isolated
+// by the rule's location labels.
+func (c *RuleChecker) fixBetterLocation(region *core.RegionInfo, fit *placement.RegionFit) *schedule.Operator {
+	if !c.cluster.IsLocationReplacementEnabled() {
+		return nil
+	}
+	for _, rf := range fit.RuleFits {
+		if len(rf.Rule.LocationLabels) == 0 || rf.Rule.Role == placement.Leader || rf.Rule.Role == placement.Learner {
+			continue
+		}
+		ruleStores := getRuleFitStores(c.cluster, rf)
+		selector := schedule.NewReplicaSelector(ruleStores, rf.Rule.LocationLabels, c.filters...)
+		oldStore := selector.SelectSource(c.cluster, ruleStores)
+		if oldStore == nil {
+			continue
+		}
+		oldScore := schedule.DistinctScore(rf.Rule.LocationLabels, ruleStores, oldStore)
+
+		var otherStores []*core.StoreInfo
+		for _, s := range ruleStores {
+			if s.GetID() != oldStore.GetID() {
+				otherStores = append(otherStores, s)
+			}
+		}
+		newStore := c.selectStore(region, rf.Rule, otherStores)
+		if newStore == nil {
+			continue
+		}
+		newScore := schedule.DistinctScore(rf.Rule.LocationLabels, otherStores, newStore)
+		if newScore <= oldScore {
+			checkerCounter.WithLabelValues("rule_checker", "not_better").Inc()
+			continue
+		}
+		newPeer, err := c.cluster.AllocPeer(newStore.GetID())
+		if err != nil {
+			return nil
+		}
+		op, err := schedule.CreateMovePeerOperator("move-to-better-location", c.cluster, region, schedule.OpReplica, oldStore.GetID(), newPeer.GetStoreId(), newPeer.GetId())
+		if err != nil {
+			checkerCounter.WithLabelValues("rule_checker", "create_operator_fail").Inc()
+			return nil
+		}
+		return op
+	}
+	return nil
+}
+
+// HasReplacementStore checks if the peer of the region can be replaced by a
+// peer on another store according to the rules, assuming that the peer is
+// unhealthy. The peer which does not belong to any rule is always replaceable,
+// as it is removed directly.
+func (c *RuleChecker) HasReplacementStore(region *core.RegionInfo, peer *metapb.Peer) bool {
+	stores := c.cluster.GetRegionStores(region)
+	rf := c.ruleManager.FitRegion(stores, region, nil).GetRuleFit(peer.GetId())
+	if rf == nil {
+		return true
+	}
+	isHealthy := isHealthyPeer(c.cluster, region)
+	fit := c.ruleManager.FitRegion(stores, region, func(p *metapb.Peer) bool {
+		return p.GetId() != peer.GetId() && isHealthy(p)
+	})
+	for _, f := range fit.RuleFits {
+		if f.Rule.ID != rf.Rule.ID {
+			continue
+		}
+		if f.IsSatisfied() || (f.Rule.Role == placement.Leader && c.hasLeaderCandidate(region, f.Rule)) {
+			return true
+		}
+		return c.selectStoreToAdd(region, f) != 0
+	}
+	return true
+}
+
+// selectStoreToAdd returns the best store to add a peer for the rule.
+func (c *RuleChecker) selectStoreToAdd(region *core.RegionInfo, rf *placement.RuleFit) uint64 {
+	store := c.selectStore(region, rf.Rule, getRuleFitStores(c.cluster, rf))
+	if store == nil {
+		return 0
+	}
+	return store.GetID()
+}
+
+func (c *RuleChecker) selectStore(region *core.RegionInfo, rule *placement.Rule, ruleStores []*core.StoreInfo) *core.StoreInfo {
+	filters := append([]schedule.Filter(nil), c.filters...)
+	if c.classifier != nil {
+		filters = append(filters, schedule.NewNamespaceFilter(c.classifier, c.classifier.GetRegionNamespace(region)))
+	}
+	return selectRuleStore(c.cluster, region, rule, ruleStores, filters...)
+}
+
+// selectOrphanToReplace prefers the orphan peer on an unhealthy store.
+func (c *RuleChecker) selectOrphanToReplace(region *core.RegionInfo, orphans []*metapb.Peer) *metapb.Peer {
+	isHealthy := isHealthyPeer(c.cluster, region)
+	for _, p := range orphans {
+		if !isHealthy(p) {
+			return p
+		}
+	}
+	return orphans[0]
+}
+
+// isHealthyPeer returns a function that reports whether a peer can still be
+// counted toward the rules. Peers on down or offline stores are not counted
+// so that they are replaced, unless the down stores are in maintenance.
+func isHealthyPeer(cluster schedule.Cluster, region *core.RegionInfo) func(*metapb.Peer) bool {
+	return func(peer *metapb.Peer) bool {
+		store := cluster.GetStore(peer.GetStoreId())
+		if store == nil {
+			return false
+		}
+		if !store.IsUp() && cluster.IsReplaceOfflineReplicaEnabled() {
+			return false
+		}
+		if cluster.IsRemoveDownReplicaEnabled() && !store.IsInMaintenance() && store.DownTime() >= cluster.GetMaxStoreDownTime() {
+			for _, stats := range region.GetDownPeers() {
+				if stats.GetPeer().GetId() == peer.GetId() &&
+					stats.GetDownSeconds() >= uint64(cluster.GetMaxStoreDownTime().Seconds()) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+}
+
+// selectRuleStore selects a store that matches the rule's label constraints
+// and is the most isolated from the ruleStores.
+func selectRuleStore(cluster schedule.Cluster, region *core.RegionInfo, rule *placement.Rule, ruleStores []*core.StoreInfo, filters ...schedule.Filter) *core.StoreInfo {
+	filters = append([]schedule.Filter{
+		schedule.NewStateFilter(),
+		schedule.NewPendingPeerCountFilter(),
+		schedule.NewStorageThresholdFilter(),
+		schedule.NewExcludedFilter(nil, region.GetStoreIds()),
+	}, filters...)
+	var candidates []*core.StoreInfo
+	for _, s := range cluster.GetStores() {
+		if rule.MatchStore(s) {
+			candidates = append(candidates, s)
+		}
+	}
+	selector := schedule.NewReplicaSelector(ruleStores, rule.LocationLabels)
+	return selector.SelectTarget(cluster, candidates, filters...)
+}
+
+func getRuleFitStores(cluster schedule.Cluster, rf *placement.RuleFit) []*core.StoreInfo {
+	var stores []*core.StoreInfo
+	for _, p := range rf.Peers {
+		if s := cluster.GetStore(p.GetStoreId()); s != nil {
+			stores = append(stores, s)
+		}
+	}
+	return stores
+}
+
+func orphanVoters(fit *placement.RegionFit) []*metapb.Peer {
+	var voters []*metapb.Peer
+	for _, p := range fit.OrphanPeers {
+		if !p.GetIsLearner() {
+			voters = append(voters, p)
+		}
+	}
+	return voters
+}
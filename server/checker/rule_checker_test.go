@@ -0,0 +1,213 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/pkg/mock/mockoption"
+	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/server/namespace"
+	"github.com/pingcap/pd/server/placement"
+	"github.com/pingcap/pd/server/schedule"
+)
+
+func TestChecker(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testRuleCheckerSuite{})
+
+type testRuleCheckerSuite struct {
+	cluster     *mockcluster.Cluster
+	ruleManager *placement.RuleManager
+	rc          *RuleChecker
+}
+
+func (s *testRuleCheckerSuite) SetUpTest(c *C) {
+	cfg := mockoption.NewScheduleOptions()
+	cfg.EnablePlacementRules = true
+	s.cluster = mockcluster.NewCluster(cfg)
+	s.ruleManager = placement.NewRuleManager(core.NewKV(core.NewMemoryKV()))
+	c.Assert(s.ruleManager.Initialize(3, []string{"zone", "rack", "host"}), IsNil)
+	s.rc = NewRuleChecker(s.cluster, s.ruleManager, namespace.DefaultClassifier)
+}
+
+func (s *testRuleCheckerSuite) TestAddRulePeer(c *C) {
+	s.cluster.AddLeaderStore(1, 1)
+	s.cluster.AddLeaderStore(2, 1)
+	s.cluster.AddLeaderStore(3, 1)
+	s.cluster.AddLeaderRegion(1, 1, 2)
+	op := s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "add-rule-peer")
+	c.Assert(op.Step(0).(schedule.AddLearner).ToStore, Equals, uint64(3))
+
+	s.cluster.DisableMakeUpReplica = true
+	c.Assert(s.rc.Check(s.cluster.GetRegion(1)), IsNil)
+}
+
+func (s *testRuleCheckerSuite) TestRemoveOrphanPeer(c *C) {
+	s.cluster.AddLeaderStore(1, 1)
+	s.cluster.AddLeaderStore(2, 1)
+	s.cluster.AddLeaderStore(3, 1)
+	s.cluster.AddLeaderStore(4, 1)
+	s.cluster.AddLeaderRegion(1, 1, 2, 3, 4)
+	op := s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "remove-orphan-peer")
+
+	s.cluster.DisableRemoveExtraReplica = true
+	c.Assert(s.rc.Check(s.cluster.GetRegion(1)), IsNil)
+}
+
+func (s *testRuleCheckerSuite) TestReplaceDownPeer(c *C) {
+	s.cluster.AddLeaderStore(1, 1)
+	s.cluster.AddLeaderStore(2, 1)
+	s.cluster.AddLeaderStore(3, 1)
+	s.cluster.AddLeaderStore(4, 1)
+	s.cluster.AddLeaderRegion(1, 1, 2, 3)
+	s.cluster.SetStoreDown(3)
+	region := s.cluster.GetRegion(1).Clone(core.WithDownPeers([]*pdpb.PeerStats{{
+		Peer:        s.cluster.GetRegion(1).GetStorePeer(3),
+		DownSeconds: 24 * 60 * 60,
+	}}))
+	op := s.rc.Check(region)
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "replace-rule-peer")
+	c.Assert(op.GetPriorityLevel(), Equals, core.HighPriority)
+	c.Assert(op.Step(0).(schedule.AddLearner).ToStore, Equals, uint64(4))
+}
+
+func (s *testRuleCheckerSuite) TestReplaceOfflinePeer(c *C) {
+	s.cluster.AddLeaderStore(1, 1)
+	s.cluster.AddLeaderStore(2, 1)
+	s.cluster.AddLeaderStore(3, 1)
+	s.cluster.AddLeaderStore(4, 1)
+	s.cluster.AddLeaderRegion(1, 1, 2, 3)
+	s.cluster.SetStoreOffline(3)
+	op := s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "replace-rule-peer")
+	c.Assert(op.Step(0).(schedule.AddLearner).ToStore, Equals, uint64(4))
+
+	s.cluster.DisableReplaceOfflineReplica = true
+	c.Assert(s.rc.Check(s.cluster.GetRegion(1)), IsNil)
+}
+
+func (s *testRuleCheckerSuite) TestHasReplacementStore(c *C) {
+	s.cluster.AddLabelsStore(1, 1, map[string]string{"zone": "z1"})
+	s.cluster.AddLabelsStore(2, 1, map[string]string{"zone": "z2"})
+	s.cluster.AddLabelsStore(3, 1, map[string]string{"zone": "z3"})
+	s.cluster.AddLabelsStore(4, 1, map[string]string{"zone": "z2"})
+	s.cluster.AddLeaderRegion(1, 1, 2, 3)
+	s.cluster.SetStoreOffline(3)
+	region := s.cluster.GetRegion(1)
+	c.Assert(s.rc.HasReplacementStore(region, region.GetStorePeer(3)), IsTrue)
+
+	// No other store matches the rule of the peer on store 3.
+	c.Assert(s.ruleManager.SetRule(&placement.Rule{ID: placement.DefaultRuleID, Role: placement.Voter, Count: 2}), IsNil)
+	c.Assert(s.ruleManager.SetRule(&placement.Rule{
+		ID:               "z3",
+		Index:            1,
+		Role:             placement.Voter,
+		Count:            1,
+		LabelConstraints: []placement.Filter{{Key: "zone", Value: "z3"}},
+	}), IsNil)
+	c.Assert(s.rc.HasReplacementStore(region, region.GetStorePeer(3)), IsFalse)
+	c.Assert(s.rc.HasReplacementStore(region, region.GetStorePeer(2)), IsTrue)
+}
+
+func (s *testRuleCheckerSuite) TestLeaderRule(c *C) {
+	s.cluster.AddLabelsStore(1, 1, map[string]string{"zone": "z1"})
+	s.cluster.AddLabelsStore(2, 1, map[string]string{"zone": "z2"})
+	s.cluster.AddLabelsStore(3, 1, map[string]string{"zone": "z3"})
+	s.cluster.AddLeaderRegion(1, 1, 2, 3)
+	c.Assert(s.ruleManager.SetRule(&placement.Rule{ID: placement.DefaultRuleID, Role: placement.Voter, Count: 2}), IsNil)
+	c.Assert(s.ruleManager.SetRule(&placement.Rule{
+		ID:               "leader",
+		Index:            1,
+		Role:             placement.Leader,
+		Count:            1,
+		LabelConstraints: []placement.Filter{{Key: "zone", Value: "z2"}},
+	}), IsNil)
+	op := s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "transfer-leader-to-rule")
+	c.Assert(op.Step(0).(schedule.TransferLeader).ToStore, Equals, uint64(2))
+
+	s.cluster.AddLeaderRegion(1, 2, 1, 3)
+	c.Assert(s.rc.Check(s.cluster.GetRegion(1)), IsNil)
+}
+
+func (s *testRuleCheckerSuite) TestFollowerRule(c *C) {
+	s.cluster.AddLabelsStore(1, 1, map[string]string{"zone": "z1"})
+	s.cluster.AddLabelsStore(2, 1, map[string]string{"zone": "z2"})
+	s.cluster.AddLabelsStore(3, 1, map[string]string{"zone": "z3"})
+	s.cluster.AddLeaderRegion(1, 1, 2, 3)
+	c.Assert(s.ruleManager.SetRule(&placement.Rule{ID: placement.DefaultRuleID, Role: placement.Voter, Count: 2}), IsNil)
+	c.Assert(s.ruleManager.SetRule(&placement.Rule{
+		ID:               "follower",
+		Index:            1,
+		Role:             placement.Follower,
+		Count:            1,
+		LabelConstraints: []placement.Filter{{Key: "zone", Value: "z1"}},
+	}), IsNil)
+	op := s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "transfer-leader-from-follower-rule")
+	c.Assert(op.Step(0).(schedule.TransferLeader).FromStore, Equals, uint64(1))
+}
+
+func (s *testRuleCheckerSuite) TestKeyRangeRule(c *C) {
+	for i := uint64(1); i <= 6; i++ {
+		s.cluster.AddLabelsStore(i, 1, map[string]string{"zone": fmt.Sprintf("z%d", (i+1)/2), "host": fmt.Sprintf("h%d", i)})
+	}
+	c.Assert(s.ruleManager.SetRule(&placement.Rule{
+		ID:             "range",
+		Index:          1,
+		Override:       true,
+		StartKeyHex:    hex.EncodeToString([]byte("a")),
+		EndKeyHex:      hex.EncodeToString([]byte("b")),
+		Role:           placement.Voter,
+		Count:          5,
+		LocationLabels: []string{"zone", "host"},
+	}), IsNil)
+
+	// Regions outside the range follow the default rule.
+	s.cluster.AddLeaderRegionWithRange(1, "", "a", 1, 3, 5)
+	c.Assert(s.rc.Check(s.cluster.GetRegion(1)), IsNil)
+
+	s.cluster.AddLeaderRegionWithRange(2, "a", "b", 1, 3, 5)
+	region := s.cluster.GetRegion(2)
+	stores := map[uint64]struct{}{1: {}, 3: {}, 5: {}}
+	for i := 0; i < 2; i++ {
+		op := s.rc.Check(region)
+		c.Assert(op, NotNil)
+		c.Assert(op.Desc(), Equals, "add-rule-peer")
+		storeID := op.Step(0).(schedule.AddLearner).ToStore
+		_, ok := stores[storeID]
+		c.Assert(ok, IsFalse)
+		stores[storeID] = struct{}{}
+		peer := &metapb.Peer{Id: op.Step(0).(schedule.AddLearner).PeerID, StoreId: storeID}
+		region = region.Clone(core.WithAddPeer(peer))
+	}
+	c.Assert(s.rc.Check(region), IsNil)
+}
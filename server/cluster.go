@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/pd/pkg/logutil"
 	"github.com/pingcap/pd/server/core"
 	"github.com/pingcap/pd/server/namespace"
+	"github.com/pingcap/pd/server/placement"
 	syncer "github.com/pingcap/pd/server/region_syncer"
 	"github.com/pingcap/pd/server/statistics"
 	"github.com/pkg/errors"
@@ -57,6 +58,8 @@ type RaftCluster struct {
 	cachedCluster *clusterInfo
 
 	coordinator *coordinator
+	// storeProgress tracks the progress of the stores being removed.
+	storeProgress *storeProgressTracker
 
 	wg           sync.WaitGroup
 	quit         chan struct{}
@@ -70,12 +73,16 @@ type ClusterStatus struct {
 }
 
 func newRaftCluster(s *Server, clusterID uint64) *RaftCluster {
+	regionSyncer := syncer.NewRegionSyncer(s)
+	if s.cfg.EnableFollowerRead {
+		regionSyncer.EnableFollowerRead()
+	}
 	return &RaftCluster{
 		s:            s,
 		running:      false,
 		clusterID:    clusterID,
 		clusterRoot:  s.getClusterRootPath(),
-		regionSyncer: syncer.NewRegionSyncer(s),
+		regionSyncer: regionSyncer,
 	}
 }
 
@@ -143,7 +150,8 @@ func (c *RaftCluster) start() error {
 
 	c.cachedCluster = cluster
 	c.coordinator = newCoordinator(c.cachedCluster, c.s.hbStreams, c.s.classifier)
-	c.cachedCluster.regionStats = statistics.NewRegionStatistics(c.s.scheduleOpt, c.s.classifier)
+	c.storeProgress = newStoreProgressTracker()
+	c.cachedCluster.regionStats = statistics.NewRegionStatistics(c.s.scheduleOpt, c.s.classifier, c.cachedCluster.ruleManager, c.cachedCluster.rangeReplications)
 	c.quit = make(chan struct{})
 
 	c.wg.Add(3)
@@ -489,7 +497,11 @@ func (c *RaftCluster) RemoveStore(storeID uint64) error {
 	log.Warn("store has been offline",
 		zap.Uint64("store-id", newStore.GetID()),
 		zap.String("store-address", newStore.GetAddress()))
-	return cluster.putStore(newStore)
+	if err := cluster.putStore(newStore); err != nil {
+		return err
+	}
+	c.storeProgress.observe(storeID, cluster.GetStoreRegionCount(storeID), newStore.GetLeaderCount(), time.Now())
+	return nil
 }
 
 // BuryStore marks a store as tombstone in cluster.
@@ -567,6 +579,33 @@ func (c *RaftCluster) SetStoreWeight(storeID uint64, leaderWeight, regionWeight
 	return c.cachedCluster.putStore(newStore)
 }
 
+// SetStoreMaintenance puts a store in maintenance until the deadline, or ends
+// its maintenance if the deadline is zero.
+func (c *RaftCluster) SetStoreMaintenance(storeID uint64, deadline time.Time) error {
+	c.RLock()
+	defer c.RUnlock()
+
+	store := c.cachedCluster.GetStore(storeID)
+	if store == nil {
+		return core.NewStoreNotFoundErr(storeID)
+	}
+
+	var err error
+	if deadline.IsZero() {
+		err = c.s.kv.DeleteStoreMaintenance(storeID)
+	} else {
+		err = c.s.kv.SaveStoreMaintenance(storeID, deadline)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Info("store maintenance is updated",
+		zap.Uint64("store-id", storeID),
+		zap.Time("deadline", deadline))
+	return c.cachedCluster.putStore(store.Clone(core.SetMaintenanceDeadline(deadline)))
+}
+
 func (c *RaftCluster) checkStores() {
 	var offlineStores []*metapb.Store
 	var upStoreCount int
@@ -576,10 +615,12 @@ func (c *RaftCluster) checkStores() {
 	for _, store := range cluster.GetStores() {
 		// the store has already been tombstone
 		if store.IsTombstone() {
+			c.storeProgress.remove(store.GetID())
 			continue
 		}
 
 		if store.IsUp() {
+			c.storeProgress.remove(store.GetID())
 			if !store.IsLowSpace(cluster.GetLowSpaceRatio()) {
 				upStoreCount++
 			}
@@ -587,8 +628,11 @@ func (c *RaftCluster) checkStores() {
 		}
 
 		offlineStore := store.GetMeta()
+		regionCount := cluster.GetStoreRegionCount(offlineStore.GetId())
+		c.storeProgress.observe(offlineStore.GetId(), regionCount, store.GetLeaderCount(), time.Now())
+		c.checkBlockedRegions(offlineStore.GetId())
 		// If the store is empty, it can be buried.
-		if cluster.GetStoreRegionCount(offlineStore.GetId()) == 0 {
+		if regionCount == 0 {
 			if err := c.BuryStore(offlineStore.GetId(), false); err != nil {
 				log.Error("bury store failed",
 					zap.Stringer("store", offlineStore),
@@ -727,3 +771,98 @@ func (c *RaftCluster) putConfig(meta *metapb.Cluster) error {
 func (c *RaftCluster) GetNamespaceClassifier() namespace.Classifier {
 	return c.s.classifier
 }
+
+// GetRuleManager returns the placement rule manager.
+func (c *RaftCluster) GetRuleManager() *placement.RuleManager {
+	c.RLock()
+	defer c.RUnlock()
+	return c.cachedCluster.ruleManager
+}
+
+// GetLeaderPolicies returns all leader policies.
+func (c *RaftCluster) GetLeaderPolicies() []*core.LeaderPolicy {
+	c.RLock()
+	defer c.RUnlock()
+	return c.cachedCluster.leaderPolicies.GetLeaderPolicies()
+}
+
+// GetLeaderPolicy returns the leader policy with the ID, or nil if not found.
+func (c *RaftCluster) GetLeaderPolicy(id string) *core.LeaderPolicy {
+	c.RLock()
+	defer c.RUnlock()
+	return c.cachedCluster.leaderPolicies.GetLeaderPolicyByID(id)
+}
+
+// SetLeaderPolicy validates, persists and applies a leader policy.
+func (c *RaftCluster) SetLeaderPolicy(policy *core.LeaderPolicy) error {
+	if err := policy.Adjust(); err != nil {
+		return err
+	}
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.s.kv.SaveLeaderPolicy(policy); err != nil {
+		return err
+	}
+	c.cachedCluster.leaderPolicies.SetLeaderPolicy(policy)
+	log.Info("leader policy updated", zap.Reflect("policy", policy))
+	return nil
+}
+
+// DeleteLeaderPolicy removes a leader policy.
+func (c *RaftCluster) DeleteLeaderPolicy(id string) error {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.s.kv.DeleteLeaderPolicy(id); err != nil {
+		return err
+	}
+	c.cachedCluster.leaderPolicies.DeleteLeaderPolicy(id)
+	log.Info("leader policy removed", zap.String("policy-id", id))
+	return nil
+}
+
+// GetRangeReplications returns all range replications.
+func (c *RaftCluster) GetRangeReplications() []*core.RangeReplication {
+	c.RLock()
+	defer c.RUnlock()
+	return c.cachedCluster.rangeReplications.GetRangeReplications()
+}
+
+// GetRangeReplication returns the range replication with the ID, or nil if
+// not found.
+func (c *RaftCluster) GetRangeReplication(id string) *core.RangeReplication {
+	c.RLock()
+	defer c.RUnlock()
+	return c.cachedCluster.rangeReplications.GetRangeReplicationByID(id)
+}
+
+// SetRangeReplication validates, persists and applies a range replication.
+func (c *RaftCluster) SetRangeReplication(r *core.RangeReplication) error {
+	if err := r.Adjust(); err != nil {
+		return err
+	}
+	for _, label := range r.LocationLabels {
+		if err := ValidateLabelString(label); err != nil {
+			return err
+		}
+	}
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.s.kv.SaveRangeReplication(r); err != nil {
+		return err
+	}
+	c.cachedCluster.rangeReplications.SetRangeReplication(r)
+	log.Info("range replication updated", zap.Reflect("replication", r))
+	return nil
+}
+
+// DeleteRangeReplication removes a range replication.
+func (c *RaftCluster) DeleteRangeReplication(id string) error {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.s.kv.DeleteRangeReplication(id); err != nil {
+		return err
+	}
+	c.cachedCluster.rangeReplications.DeleteRangeReplication(id)
+	log.Info("range replication removed", zap.String("replication-id", id))
+	return nil
+}
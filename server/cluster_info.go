@@ -24,38 +24,46 @@ import (
 	log "github.com/pingcap/log"
 	"github.com/pingcap/pd/server/core"
 	"github.com/pingcap/pd/server/namespace"
+	"github.com/pingcap/pd/server/placement"
+	"github.com/pingcap/pd/server/schedule"
 	"github.com/pingcap/pd/server/statistics"
 	"go.uber.org/zap"
 )
 
 type clusterInfo struct {
 	sync.RWMutex
-	core            *core.BasicCluster
-	id              core.IDAllocator
-	kv              *core.KV
-	meta            *metapb.Cluster
-	opt             *scheduleOption
-	regionStats     *statistics.RegionStatistics
-	labelLevelStats *statistics.LabelLevelStatistics
-	storesStats     *statistics.StoresStats
-	prepareChecker  *prepareChecker
-	changedRegions  chan *core.RegionInfo
-	hotSpotCache    *statistics.HotSpotCache
+	core              *core.BasicCluster
+	id                core.IDAllocator
+	kv                *core.KV
+	meta              *metapb.Cluster
+	opt               *scheduleOption
+	regionStats       *statistics.RegionStatistics
+	labelLevelStats   *statistics.LabelLevelStatistics
+	storesStats       *statistics.StoresStats
+	prepareChecker    *prepareChecker
+	changedRegions    chan *core.RegionInfo
+	hotSpotCache      *statistics.HotSpotCache
+	ruleManager       *placement.RuleManager
+	leaderPolicies    *core.LeaderPolicies
+	rangeReplications *core.RangeReplications
 }
 
 var defaultChangedRegionsLimit = 10000
 
 func newClusterInfo(id core.IDAllocator, opt *scheduleOption, kv *core.KV) *clusterInfo {
 	return &clusterInfo{
-		core:            core.NewBasicCluster(),
-		id:              id,
-		opt:             opt,
-		kv:              kv,
-		labelLevelStats: statistics.NewLabelLevelStatistics(),
-		storesStats:     statistics.NewStoresStats(),
-		prepareChecker:  newPrepareChecker(),
-		changedRegions:  make(chan *core.RegionInfo, defaultChangedRegionsLimit),
-		hotSpotCache:    statistics.NewHotSpotCache(),
+		core:              core.NewBasicCluster(),
+		id:                id,
+		opt:               opt,
+		kv:                kv,
+		labelLevelStats:   statistics.NewLabelLevelStatistics(),
+		storesStats:       statistics.NewStoresStats(),
+		prepareChecker:    newPrepareChecker(),
+		changedRegions:    make(chan *core.RegionInfo, defaultChangedRegionsLimit),
+		hotSpotCache:      statistics.NewHotSpotCache(),
+		ruleManager:       placement.NewRuleManager(kv),
+		leaderPolicies:    core.NewLeaderPolicies(),
+		rangeReplications: core.NewRangeReplications(),
 	}
 }
 
@@ -92,6 +100,19 @@ func loadClusterInfo(id core.IDAllocator, kv *core.KV, opt *scheduleOption) (*cl
 	for _, store := range c.core.Stores.GetStores() {
 		c.storesStats.CreateRollingStoreStats(store.GetID())
 	}
+
+	if err := kv.LoadLeaderPolicies(c.leaderPolicies); err != nil {
+		return nil, err
+	}
+	if err := kv.LoadRangeReplications(c.rangeReplications); err != nil {
+		return nil, err
+	}
+
+	if opt.IsPlacementRulesEnabled() {
+		if err := c.ruleManager.Initialize(opt.rep.GetMaxReplicas(), opt.GetLocationLabels()); err != nil {
+			return nil, err
+		}
+	}
 	return c, nil
 }
 
@@ -247,6 +268,14 @@ func (c *clusterInfo) AttachOverloadStatus(storeID uint64, f func() bool) {
 	c.core.AttachOverloadStatus(storeID, f)
 }
 
+// ObserveSnapshot records that a store applies a snapshot of size bytes in the
+// duration.
+func (c *clusterInfo) ObserveSnapshot(storeID uint64, size uint64, duration time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.core.ObserveSnapshot(storeID, size, duration)
+}
+
 // GetStores returns all stores in the cluster.
 func (c *clusterInfo) GetStores() []*core.StoreInfo {
 	c.RLock()
@@ -304,6 +333,16 @@ func (c *clusterInfo) GetAdjacentRegions(region *core.RegionInfo) (*core.RegionI
 	return c.core.GetAdjacentRegions(region)
 }
 
+// GetLeaderPolicy returns the leader policy that applies to the region.
+func (c *clusterInfo) GetLeaderPolicy(region *core.RegionInfo) *core.LeaderPolicy {
+	return c.leaderPolicies.GetLeaderPolicy(region)
+}
+
+// GetRangeReplication returns the range replication that applies to the region.
+func (c *clusterInfo) GetRangeReplication(region *core.RegionInfo) *core.RangeReplication {
+	return c.rangeReplications.GetRangeReplication(region)
+}
+
 // GetRegion searches for a region by ID.
 func (c *clusterInfo) GetRegion(regionID uint64) *core.RegionInfo {
 	c.RLock()
@@ -654,10 +693,24 @@ func (c *clusterInfo) updateRegionsLabelLevelStats(regions []*core.RegionInfo) {
 	c.Lock()
 	defer c.Unlock()
 	for _, region := range regions {
-		c.labelLevelStats.Observe(region, c.takeRegionStoresLocked(region), c.GetLocationLabels())
+		c.labelLevelStats.Observe(region, c.takeRegionStoresLocked(region), schedule.GetRegionLocationLabels(c, region))
 	}
 }
 
+// GetLabelLevelCounts returns the number of regions of each isolation level.
+func (c *clusterInfo) GetLabelLevelCounts() map[string]int {
+	c.RLock()
+	defer c.RUnlock()
+	return c.labelLevelStats.GetLabelLevelCounts()
+}
+
+// GetLabelLevelRegions returns the regions of the isolation level.
+func (c *clusterInfo) GetLabelLevelRegions(level string) []*core.RegionInfo {
+	c.RLock()
+	defer c.RUnlock()
+	return c.labelLevelStats.GetLabelLevelRegions(level)
+}
+
 func (c *clusterInfo) collectMetrics() {
 	if c.regionStats == nil {
 		return
@@ -723,6 +776,10 @@ func (c *clusterInfo) GetSchedulerMaxWaitingOperator() uint64 {
 	return c.opt.GetSchedulerMaxWaitingOperator()
 }
 
+func (c *clusterInfo) GetOperatorPriorityClasses() []string {
+	return c.opt.GetOperatorPriorityClasses()
+}
+
 func (c *clusterInfo) GetMaxSnapshotCount() uint64 {
 	return c.opt.GetMaxSnapshotCount()
 }
@@ -755,6 +812,10 @@ func (c *clusterInfo) GetMaxStoreDownTime() time.Duration {
 	return c.opt.GetMaxStoreDownTime()
 }
 
+func (c *clusterInfo) GetOperatorHistoryRetention() time.Duration {
+	return c.opt.GetOperatorHistoryRetention()
+}
+
 func (c *clusterInfo) GetMaxReplicas() int {
 	return c.opt.GetMaxReplicas(namespace.DefaultNamespace)
 }
@@ -802,6 +863,14 @@ func (c *clusterInfo) IsNamespaceRelocationEnabled() bool {
 	return c.opt.IsNamespaceRelocationEnabled()
 }
 
+func (c *clusterInfo) IsDryRunEnabled() bool {
+	return c.opt.IsDryRunEnabled()
+}
+
+func (c *clusterInfo) IsPlacementRulesEnabled() bool {
+	return c.opt.IsPlacementRulesEnabled()
+}
+
 func (c *clusterInfo) CheckLabelProperty(typ string, labels []*metapb.StoreLabel) bool {
 	return c.opt.CheckLabelProperty(typ, labels)
 }
@@ -76,6 +76,18 @@ type Config struct {
 	// TsoSaveInterval is the interval to save timestamp.
 	TsoSaveInterval typeutil.Duration `toml:"tso-save-interval" json:"tso-save-interval"`
 
+	// Labels are the labels of the PD member, such as its zone.
+	Labels map[string]string `toml:"labels" json:"labels"`
+	// EnableLocalTSO makes the PD members with the same zone label elect a
+	// local TSO allocator for the zone. It should be the same for all members.
+	// All the clients should be upgraded to handle the suffix bits of the
+	// timestamps before it is enabled, or their Tso streams are rejected.
+	EnableLocalTSO bool `toml:"enable-local-tso" json:"enable-local-tso"`
+	// EnableFollowerRead makes the follower cache the regions synchronized
+	// from the leader to answer the region lookups of the clients. It only
+	// works with the region storage.
+	EnableFollowerRead bool `toml:"enable-follower-read" json:"enable-follower-read"`
+
 	Metric metricutil.MetricConfig `toml:"metric" json:"metric"`
 
 	Schedule ScheduleConfig `toml:"schedule" json:"schedule"`
@@ -136,6 +148,14 @@ type Config struct {
 	logProps *log.ZapProperties
 }
 
+// zoneLabelKey is the label key of the zone of a PD member.
+const zoneLabelKey = "zone"
+
+// GetZone returns the zone of the PD member.
+func (c *Config) GetZone() string {
+	return c.Labels[zoneLabelKey]
+}
+
 // NewConfig creates a new config.
 func NewConfig() *Config {
 	cfg := &Config{}
@@ -507,6 +527,10 @@ type ScheduleConfig struct {
 	HighSpaceRatio float64 `toml:"high-space-ratio,omitempty" json:"high-space-ratio"`
 	// SchedulerMaxWaitingOperator is the max coexist operators for each scheduler.
 	SchedulerMaxWaitingOperator uint64 `toml:"scheduler-max-waiting-operator,omitempty" json:"scheduler-max-waiting-operator"`
+	// OperatorPriorityClasses is the priority classes of the waiting
+	// operators from the highest priority to the lowest one. A class of higher
+	// priority gets a larger share when the waiting operators are promoted.
+	OperatorPriorityClasses typeutil.StringSlice `toml:"operator-priority-classes,omitempty" json:"operator-priority-classes"`
 	// DisableLearner is the option to disable using AddLearnerNode instead of AddNode.
 	DisableLearner bool `toml:"disable-raft-learner" json:"disable-raft-learner,string"`
 
@@ -528,6 +552,12 @@ type ScheduleConfig struct {
 	// DisableNamespaceRelocation is the option to prevent namespace checker
 	// from moving replica to the target namespace.
 	DisableNamespaceRelocation bool `toml:"disable-namespace-relocation" json:"disable-namespace-relocation,string"`
+	// EnableDryRun is the option to make the schedulers and checkers record
+	// the operators they generate instead of executing them.
+	EnableDryRun bool `toml:"enable-dry-run" json:"enable-dry-run,string"`
+	// OperatorHistoryRetention is the duration to keep the records of the
+	// finished operators.
+	OperatorHistoryRetention typeutil.Duration `toml:"operator-history-retention,omitempty" json:"operator-history-retention"`
 
 	// Schedulers support for loading customized schedulers
 	Schedulers SchedulerConfigs `toml:"schedulers,omitempty" json:"schedulers-v2"` // json v2 is for the sake of compatible upgrade
@@ -536,6 +566,8 @@ type ScheduleConfig struct {
 func (c *ScheduleConfig) clone() *ScheduleConfig {
 	schedulers := make(SchedulerConfigs, len(c.Schedulers))
 	copy(schedulers, c.Schedulers)
+	priorityClasses := make(typeutil.StringSlice, len(c.OperatorPriorityClasses))
+	copy(priorityClasses, c.OperatorPriorityClasses)
 	return &ScheduleConfig{
 		MaxSnapshotCount:             c.MaxSnapshotCount,
 		MaxPendingPeerCount:          c.MaxPendingPeerCount,
@@ -556,6 +588,7 @@ func (c *ScheduleConfig) clone() *ScheduleConfig {
 		LowSpaceRatio:                c.LowSpaceRatio,
 		HighSpaceRatio:               c.HighSpaceRatio,
 		SchedulerMaxWaitingOperator:  c.SchedulerMaxWaitingOperator,
+		OperatorPriorityClasses:      priorityClasses,
 		DisableLearner:               c.DisableLearner,
 		DisableRemoveDownReplica:     c.DisableRemoveDownReplica,
 		DisableReplaceOfflineReplica: c.DisableReplaceOfflineReplica,
@@ -563,6 +596,8 @@ func (c *ScheduleConfig) clone() *ScheduleConfig {
 		DisableRemoveExtraReplica:    c.DisableRemoveExtraReplica,
 		DisableLocationReplacement:   c.DisableLocationReplacement,
 		DisableNamespaceRelocation:   c.DisableNamespaceRelocation,
+		EnableDryRun:                 c.EnableDryRun,
+		OperatorHistoryRetention:     c.OperatorHistoryRetention,
 		Schedulers:                   schedulers,
 	}
 }
@@ -589,6 +624,9 @@ const (
 	// hot region.
 	defaultHotRegionCacheHitsThreshold = 3
 	defaultSchedulerMaxWaitingOperator = 3
+	// defaultOperatorHistoryRetention is the default duration to keep the
+	// records of the finished operators.
+	defaultOperatorHistoryRetention = 24 * time.Hour
 )
 
 func (c *ScheduleConfig) adjust(meta *configMetaData) error {
@@ -607,6 +645,7 @@ func (c *ScheduleConfig) adjust(meta *configMetaData) error {
 	adjustDuration(&c.SplitMergeInterval, defaultSplitMergeInterval)
 	adjustDuration(&c.PatrolRegionInterval, defaultPatrolRegionInterval)
 	adjustDuration(&c.MaxStoreDownTime, defaultMaxStoreDownTime)
+	adjustDuration(&c.OperatorHistoryRetention, defaultOperatorHistoryRetention)
 	if !meta.IsDefined("leader-schedule-limit") {
 		adjustUint64(&c.LeaderScheduleLimit, defaultLeaderScheduleLimit)
 	}
@@ -635,6 +674,9 @@ func (c *ScheduleConfig) adjust(meta *configMetaData) error {
 	adjustFloat64(&c.LowSpaceRatio, defaultLowSpaceRatio)
 	adjustFloat64(&c.HighSpaceRatio, defaultHighSpaceRatio)
 	adjustSchedulers(&c.Schedulers, defaultSchedulers)
+	if len(c.OperatorPriorityClasses) == 0 {
+		c.OperatorPriorityClasses = append(typeutil.StringSlice(nil), schedule.DefaultPriorityClasses...)
+	}
 
 	return c.validate()
 }
@@ -652,6 +694,9 @@ func (c *ScheduleConfig) validate() error {
 	if c.LowSpaceRatio <= c.HighSpaceRatio {
 		return errors.New("low-space-ratio should be larger than high-space-ratio")
 	}
+	if err := schedule.ValidatePriorityClasses(c.OperatorPriorityClasses); err != nil {
+		return err
+	}
 	for _, scheduleConfig := range c.Schedulers {
 		if !schedule.IsSchedulerRegistered(scheduleConfig.Type) {
 			return errors.Errorf("create func of %v is not registered, maybe misspelled", scheduleConfig.Type)
@@ -699,15 +744,19 @@ type ReplicationConfig struct {
 	LocationLabels typeutil.StringSlice `toml:"location-labels,omitempty" json:"location-labels"`
 	// StrictlyMatchLabel strictly checks if the label of TiKV is matched with LocationLabels.
 	StrictlyMatchLabel bool `toml:"strictly-match-label,omitempty" json:"strictly-match-label,string"`
+
+	// When PlacementRules feature is enabled. MaxReplicas and LocationLabels are not used any more.
+	EnablePlacementRules bool `toml:"enable-placement-rules" json:"enable-placement-rules,string"`
 }
 
 func (c *ReplicationConfig) clone() *ReplicationConfig {
 	locationLabels := make(typeutil.StringSlice, len(c.LocationLabels))
 	copy(locationLabels, c.LocationLabels)
 	return &ReplicationConfig{
-		MaxReplicas:        c.MaxReplicas,
-		LocationLabels:     locationLabels,
-		StrictlyMatchLabel: c.StrictlyMatchLabel,
+		MaxReplicas:          c.MaxReplicas,
+		LocationLabels:       locationLabels,
+		StrictlyMatchLabel:   c.StrictlyMatchLabel,
+		EnablePlacementRules: c.EnablePlacementRules,
 	}
 }
 
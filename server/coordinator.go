@@ -15,8 +15,12 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	log "github.com/pingcap/log"
@@ -40,6 +44,9 @@ const (
 	hotRegionScheduleName      = "balance-hot-region-scheduler"
 
 	patrolScanRegionLimit = 128 // It takes about 14 minutes to iterate 1 million regions.
+
+	operatorAuditFlushInterval = 5 * time.Second
+	operatorAuditPruneInterval = time.Minute
 )
 
 var (
@@ -55,34 +62,46 @@ type coordinator struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	cluster          *clusterInfo
-	learnerChecker   *checker.LearnerChecker
-	replicaChecker   *checker.ReplicaChecker
-	namespaceChecker *checker.NamespaceChecker
-	mergeChecker     *checker.MergeChecker
-	regionScatterer  *schedule.RegionScatterer
-	schedulers       map[string]*scheduleController
-	opController     *schedule.OperatorController
-	classifier       namespace.Classifier
-	hbStreams        *heartbeatStreams
+	cluster             *clusterInfo
+	learnerChecker      *checker.LearnerChecker
+	replicaChecker      *checker.ReplicaChecker
+	ruleChecker         *checker.RuleChecker
+	namespaceChecker    *checker.NamespaceChecker
+	leaderPolicyChecker *checker.LeaderPolicyChecker
+	mergeChecker        *checker.MergeChecker
+	regionScatterer     *schedule.RegionScatterer
+	schedulers          map[string]*scheduleController
+	opController        *schedule.OperatorController
+	dryRunRecorder      *schedule.DryRunRecorder
+	classifier          namespace.Classifier
+	hbStreams           *heartbeatStreams
 }
 
 // newCoordinator creates a new coordinator.
 func newCoordinator(cluster *clusterInfo, hbStreams *heartbeatStreams, classifier namespace.Classifier) *coordinator {
 	ctx, cancel := context.WithCancel(context.Background())
+	dryRunCluster := newDryRunCluster(cluster)
+	opController := schedule.NewOperatorController(cluster, hbStreams)
+	opController.SetAuditLog(schedule.NewOperatorAuditLog(cluster.kv))
+	if err := opController.LoadStoreLimits(cluster.kv); err != nil {
+		log.Error("failed to load store limits", zap.Error(err))
+	}
 	return &coordinator{
-		ctx:              ctx,
-		cancel:           cancel,
-		cluster:          cluster,
-		learnerChecker:   checker.NewLearnerChecker(),
-		replicaChecker:   checker.NewReplicaChecker(cluster, classifier),
-		namespaceChecker: checker.NewNamespaceChecker(cluster, classifier),
-		mergeChecker:     checker.NewMergeChecker(cluster, classifier),
-		regionScatterer:  schedule.NewRegionScatterer(cluster, classifier),
-		schedulers:       make(map[string]*scheduleController),
-		opController:     schedule.NewOperatorController(cluster, hbStreams),
-		classifier:       classifier,
-		hbStreams:        hbStreams,
+		ctx:                 ctx,
+		cancel:              cancel,
+		cluster:             cluster,
+		learnerChecker:      checker.NewLearnerChecker(dryRunCluster, cluster.ruleManager, classifier),
+		replicaChecker:      checker.NewReplicaChecker(dryRunCluster, classifier),
+		ruleChecker:         checker.NewRuleChecker(dryRunCluster, cluster.ruleManager, classifier),
+		namespaceChecker:    checker.NewNamespaceChecker(dryRunCluster, classifier),
+		leaderPolicyChecker: checker.NewLeaderPolicyChecker(dryRunCluster),
+		mergeChecker:        checker.NewMergeChecker(dryRunCluster, classifier),
+		regionScatterer:     schedule.NewRegionScatterer(cluster, classifier),
+		schedulers:          make(map[string]*scheduleController),
+		opController:        opController,
+		dryRunRecorder:      schedule.NewDryRunRecorder(schedule.DefaultDryRunCapacity),
+		classifier:          classifier,
+		hbStreams:           hbStreams,
 	}
 }
 
@@ -154,14 +173,49 @@ func (c *coordinator) drivePushOperator() {
 	}
 }
 
+// driveOperatorAudit periodically saves the operator audit records and
+// removes the expired ones.
+func (c *coordinator) driveOperatorAudit() {
+	defer logutil.LogPanic()
+
+	defer c.wg.Done()
+	auditLog := c.opController.GetAuditLog()
+	flushTicker := time.NewTicker(operatorAuditFlushInterval)
+	defer flushTicker.Stop()
+	pruneTicker := time.NewTicker(operatorAuditPruneInterval)
+	defer pruneTicker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			if err := auditLog.Flush(); err != nil {
+				log.Error("failed to save operator audit records", zap.Error(err))
+			}
+			log.Info("drive operator audit has been stopped")
+			return
+		case <-flushTicker.C:
+			if err := auditLog.Flush(); err != nil {
+				log.Error("failed to save operator audit records", zap.Error(err))
+			}
+		case <-pruneTicker.C:
+			if err := auditLog.Prune(c.cluster.GetOperatorHistoryRetention()); err != nil {
+				log.Error("failed to prune operator audit records", zap.Error(err))
+			}
+		}
+	}
+}
+
 func (c *coordinator) checkRegion(region *core.RegionInfo) bool {
 	// If PD has restarted, it need to check learners added before and promote them.
 	// Don't check isRaftLearnerEnabled cause it maybe disable learner feature but there are still some learners to promote.
 	opController := c.opController
 
 	if op := c.learnerChecker.Check(region); op != nil {
-		if opController.AddOperator(op) {
-			return true
+		// Learners required by placement rules are added under the replica
+		// schedule limit, while promotion is always allowed.
+		if op.Kind()&schedule.OpReplica == 0 || opController.OperatorCount(schedule.OpReplica) < c.cluster.GetReplicaScheduleLimit() {
+			if c.addOperator("learner-checker", op) {
+				return true
+			}
 		}
 	}
 
@@ -169,15 +223,28 @@ func (c *coordinator) checkRegion(region *core.RegionInfo) bool {
 		opController.OperatorCount(schedule.OpRegion) < c.cluster.GetRegionScheduleLimit() &&
 		opController.OperatorCount(schedule.OpReplica) < c.cluster.GetReplicaScheduleLimit() {
 		if op := c.namespaceChecker.Check(region); op != nil {
-			if opController.AddWaitingOperator(op) {
+			if c.addWaitingOperator("namespace-checker", op) {
 				return true
 			}
 		}
 	}
 
 	if opController.OperatorCount(schedule.OpReplica) < c.cluster.GetReplicaScheduleLimit() {
-		if op := c.replicaChecker.Check(region); op != nil {
-			if opController.AddWaitingOperator(op) {
+		if c.cluster.IsPlacementRulesEnabled() && c.cluster.ruleManager.IsInitialized() {
+			if op := c.ruleChecker.Check(region); op != nil {
+				if c.addWaitingOperator("rule-checker", op) {
+					return true
+				}
+			}
+		} else if op := c.replicaChecker.Check(region); op != nil {
+			if c.addWaitingOperator("replica-checker", op) {
+				return true
+			}
+		}
+	}
+	if opController.OperatorCount(schedule.OpLeader) < c.cluster.GetLeaderScheduleLimit() {
+		if op := c.leaderPolicyChecker.Check(region); op != nil {
+			if c.addWaitingOperator("leader-policy-checker", op) {
 				return true
 			}
 		}
@@ -185,7 +252,7 @@ func (c *coordinator) checkRegion(region *core.RegionInfo) bool {
 	if c.cluster.IsFeatureSupported(RegionMerge) && opController.OperatorCount(schedule.OpMerge) < c.cluster.GetMergeScheduleLimit() {
 		if ops := c.mergeChecker.Check(region); ops != nil {
 			// It makes sure that two operators can be added successfully altogether.
-			if opController.AddWaitingOperator(ops...) {
+			if c.addWaitingOperator("merge-checker", ops...) {
 				return true
 			}
 		}
@@ -193,6 +260,41 @@ func (c *coordinator) checkRegion(region *core.RegionInfo) bool {
 	return false
 }
 
+// addOperator adds the operators generated by the source to the operator
+// controller. In dry-run mode, the operators are recorded instead.
+func (c *coordinator) addOperator(source string, ops ...*schedule.Operator) bool {
+	if c.cluster.IsDryRunEnabled() {
+		c.dryRunRecorder.Record(source, c.cluster, ops...)
+		return true
+	}
+	for _, op := range ops {
+		// The operators generated before the dry-run mode is disabled are
+		// dropped, as their new peers have no ID.
+		if isDryRunOperator(op) {
+			return false
+		}
+		op.SetSource(source)
+	}
+	return c.opController.AddOperator(ops...)
+}
+
+// addWaitingOperator adds the operators generated by the source to the
+// waiting list of the operator controller. In dry-run mode, the operators are
+// recorded instead.
+func (c *coordinator) addWaitingOperator(source string, ops ...*schedule.Operator) bool {
+	if c.cluster.IsDryRunEnabled() {
+		c.dryRunRecorder.Record(source, c.cluster, ops...)
+		return true
+	}
+	for _, op := range ops {
+		if isDryRunOperator(op) {
+			return false
+		}
+		op.SetSource(source)
+	}
+	return c.opController.AddWaitingOperator(ops...)
+}
+
 func (c *coordinator) run() {
 	ticker := time.NewTicker(runSchedulerCheckInterval)
 	defer ticker.Stop()
@@ -212,6 +314,7 @@ func (c *coordinator) run() {
 	log.Info("coordinator starts to run schedulers")
 
 	k := 0
+	recorded := make(map[string]bool)
 	scheduleCfg := c.cluster.opt.load().clone()
 	for _, schedulerCfg := range scheduleCfg.Schedulers {
 		if schedulerCfg.Disable {
@@ -220,19 +323,30 @@ func (c *coordinator) run() {
 			log.Info("skip create scheduler", zap.String("scheduler-type", schedulerCfg.Type))
 			continue
 		}
-		s, err := schedule.CreateScheduler(schedulerCfg.Type, c.opController, schedulerCfg.Args...)
+		s, err := schedule.CreateScheduler(schedulerCfg.Type, c.opController, c.cluster.kv, schedulerCfg.Args...)
 		if err != nil {
 			log.Error("can not create scheduler", zap.String("scheduler-type", schedulerCfg.Type), zap.Error(err))
 			continue
 		}
 		log.Info("create scheduler", zap.String("scheduler-name", s.GetName()))
+		if storeScheduler, ok := s.(schedule.StoreScheduler); ok {
+			if len(storeScheduler.GetStores()) == 0 {
+				log.Info("skip scheduler without stores", zap.String("scheduler-name", s.GetName()))
+				continue
+			}
+			// The stores are persisted by the scheduler itself. The configs
+			// with a store ID in args are created by old versions, and are
+			// merged into one config without args.
+			schedulerCfg.Args = nil
+		}
 		if err = c.addScheduler(s, schedulerCfg.Args...); err != nil {
 			log.Error("can not add scheduler", zap.String("scheduler-name", s.GetName()), zap.Error(err))
 		}
 
 		// Only records the valid scheduler config.
-		if err == nil {
+		if err == nil && !recorded[s.GetName()] {
 			scheduleCfg.Schedulers[k] = schedulerCfg
+			recorded[s.GetName()] = true
 			k++
 		}
 	}
@@ -244,14 +358,16 @@ func (c *coordinator) run() {
 		log.Error("cannot persist schedule config", zap.Error(err))
 	}
 
-	c.wg.Add(2)
+	c.wg.Add(3)
 	// Starts to patrol regions.
 	go c.patrolRegions()
 	go c.drivePushOperator()
+	go c.driveOperatorAudit()
 }
 
 func (c *coordinator) stop() {
 	c.cancel()
+	c.opController.CloseEvents()
 }
 
 // Hack to retrieve info from scheduler.
@@ -309,7 +425,56 @@ func (c *coordinator) collectSchedulerMetrics() {
 			allowScheduler = 1
 		}
 		schedulerStatusGauge.WithLabelValues(s.GetName(), "allow").Set(allowScheduler)
+		var pausedScheduler float64
+		if s.IsPaused() {
+			pausedScheduler = 1
+		}
+		schedulerStatusGauge.WithLabelValues(s.GetName(), "paused").Set(pausedScheduler)
+	}
+}
+
+// getPausedSchedulers returns the names of the paused schedulers.
+func (c *coordinator) getPausedSchedulers() []string {
+	c.RLock()
+	defer c.RUnlock()
+
+	names := make([]string, 0, len(c.schedulers))
+	for name, s := range c.schedulers {
+		if s.IsPaused() {
+			names = append(names, name)
+		}
 	}
+	return names
+}
+
+// pauseScheduler stops a scheduler from scheduling for a duration. The
+// scheduler resumes automatically when the duration is over.
+func (c *coordinator) pauseScheduler(name string, d time.Duration) error {
+	if d <= 0 {
+		return errors.Errorf("invalid pause duration %v", d)
+	}
+	c.RLock()
+	defer c.RUnlock()
+	s, ok := c.schedulers[name]
+	if !ok {
+		return errSchedulerNotFound
+	}
+	s.Pause(d)
+	log.Info("scheduler is paused", zap.String("scheduler-name", name), zap.Duration("duration", d))
+	return nil
+}
+
+// resumeScheduler resumes a paused scheduler.
+func (c *coordinator) resumeScheduler(name string) error {
+	c.RLock()
+	defer c.RUnlock()
+	s, ok := c.schedulers[name]
+	if !ok {
+		return errSchedulerNotFound
+	}
+	s.Resume()
+	log.Info("scheduler is resumed", zap.String("scheduler-name", name))
+	return nil
 }
 
 func (c *coordinator) collectHotSpotMetrics() {
@@ -380,8 +545,8 @@ func (c *coordinator) addScheduler(scheduler schedule.Scheduler, args ...string)
 	c.Lock()
 	defer c.Unlock()
 
-	if _, ok := c.schedulers[scheduler.GetName()]; ok {
-		return errSchedulerExisted
+	if old, ok := c.schedulers[scheduler.GetName()]; ok {
+		return c.mergeStoreScheduler(old.Scheduler, scheduler)
 	}
 
 	s := newScheduleController(c, scheduler)
@@ -392,27 +557,169 @@ func (c *coordinator) addScheduler(scheduler schedule.Scheduler, args ...string)
 	c.wg.Add(1)
 	go c.runScheduler(s)
 	c.schedulers[s.GetName()] = s
+	if _, ok := scheduler.(schedule.StoreScheduler); ok {
+		args = nil
+	}
 	c.cluster.opt.AddSchedulerCfg(s.GetType(), args)
 
 	return nil
 }
 
+// mergeStoreScheduler adds the stores of a newly created store scheduler to
+// the running one with the same name.
+func (c *coordinator) mergeStoreScheduler(old, new schedule.Scheduler) error {
+	oldScheduler, ok := old.(schedule.StoreScheduler)
+	if !ok {
+		return errSchedulerExisted
+	}
+	newScheduler, ok := new.(schedule.StoreScheduler)
+	if !ok {
+		return errSchedulerExisted
+	}
+	for storeID, ranges := range newScheduler.GetStores() {
+		if err := oldScheduler.AddStore(c.cluster, storeID, ranges); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *coordinator) removeScheduler(name string) error {
 	c.Lock()
 	defer c.Unlock()
 
-	s, ok := c.schedulers[name]
-	if !ok {
+	if _, ok := c.schedulers[name]; !ok {
+		// A store scheduler used to be named with a store ID suffix, such as
+		// evict-leader-scheduler-1, which means removing the store from it.
+		if i := strings.LastIndex(name, "-"); i > 0 {
+			if storeID, err := strconv.ParseUint(name[i+1:], 10, 64); err == nil {
+				if _, err := c.getStoreScheduler(name[:i]); err == nil {
+					return c.removeSchedulerStoreLocked(name[:i], storeID)
+				}
+			}
+		}
 		return errSchedulerNotFound
 	}
+	return c.removeSchedulerLocked(name)
+}
 
+func (c *coordinator) removeSchedulerLocked(name string) error {
+	s := c.schedulers[name]
 	s.Stop()
 	schedulerStatusGauge.WithLabelValues(name, "allow").Set(0)
+	schedulerStatusGauge.WithLabelValues(name, "paused").Set(0)
 	delete(c.schedulers, name)
 
+	// Clean the persisted config, so that the scheduler starts with the
+	// default config if it is added again.
+	if err := c.cluster.kv.RemoveSchedulerConfig(name); err != nil {
+		return err
+	}
 	return c.cluster.opt.RemoveSchedulerCfg(name)
 }
 
+func (c *coordinator) getStoreScheduler(name string) (schedule.StoreScheduler, error) {
+	s, ok := c.schedulers[name]
+	if !ok {
+		return nil, errSchedulerNotFound
+	}
+	storeScheduler, ok := s.Scheduler.(schedule.StoreScheduler)
+	if !ok {
+		return nil, errors.Errorf("scheduler %s does not support stores", name)
+	}
+	return storeScheduler, nil
+}
+
+// getSchedulerExplanations returns at most limit latest scheduling rounds of
+// a scheduler, which explain how the scheduler makes decisions.
+func (c *coordinator) getSchedulerExplanations(name string, limit int) ([]*schedule.ExplainRound, error) {
+	c.RLock()
+	defer c.RUnlock()
+	s, ok := c.schedulers[name]
+	if !ok {
+		return nil, errSchedulerNotFound
+	}
+	explainable, ok := s.Scheduler.(schedule.ExplainableScheduler)
+	if !ok {
+		return nil, errors.Errorf("scheduler %s does not support explanation", name)
+	}
+	return explainable.GetExplanations(limit), nil
+}
+
+// schedulerStore is the config input of a store scheduler, which adds the
+// store to it.
+type schedulerStore struct {
+	StoreID uint64              `json:"store_id"`
+	Ranges  []schedule.KeyRange `json:"ranges"`
+}
+
+// getSchedulerConfig returns the config of a scheduler. The config of a store
+// scheduler is its stores and their key ranges.
+func (c *coordinator) getSchedulerConfig(name string) (interface{}, error) {
+	c.RLock()
+	defer c.RUnlock()
+	s, ok := c.schedulers[name]
+	if !ok {
+		return nil, errSchedulerNotFound
+	}
+	switch s := s.Scheduler.(type) {
+	case schedule.StoreScheduler:
+		return s.GetStores(), nil
+	case schedule.ConfigurableScheduler:
+		return s.GetConfig(), nil
+	}
+	return nil, errors.Errorf("scheduler %s is not configurable", name)
+}
+
+// setSchedulerConfig updates the config of a scheduler by JSON data. For a
+// store scheduler, the data is a store with its key ranges, which is added to
+// the scheduler.
+func (c *coordinator) setSchedulerConfig(name string, data []byte) error {
+	c.Lock()
+	defer c.Unlock()
+	s, ok := c.schedulers[name]
+	if !ok {
+		return errSchedulerNotFound
+	}
+	switch s := s.Scheduler.(type) {
+	case schedule.StoreScheduler:
+		var input schedulerStore
+		if err := json.Unmarshal(data, &input); err != nil {
+			return errors.WithStack(err)
+		}
+		if input.StoreID == 0 {
+			return errors.New("missing store id")
+		}
+		return s.AddStore(c.cluster, input.StoreID, input.Ranges)
+	case schedule.ConfigurableScheduler:
+		return s.SetConfig(data)
+	}
+	return errors.Errorf("scheduler %s is not configurable", name)
+}
+
+// removeSchedulerStore removes a store from a store scheduler. The scheduler
+// is removed if there is no store left.
+func (c *coordinator) removeSchedulerStore(name string, storeID uint64) error {
+	c.Lock()
+	defer c.Unlock()
+	return c.removeSchedulerStoreLocked(name, storeID)
+}
+
+func (c *coordinator) removeSchedulerStoreLocked(name string, storeID uint64) error {
+	s, err := c.getStoreScheduler(name)
+	if err != nil {
+		return err
+	}
+	empty, err := s.RemoveStore(c.cluster, storeID)
+	if err != nil {
+		return err
+	}
+	if empty {
+		return c.removeSchedulerLocked(name)
+	}
+	return nil
+}
+
 func (c *coordinator) runScheduler(s *scheduleController) {
 	defer logutil.LogPanic()
 	defer c.wg.Done()
@@ -429,7 +736,7 @@ func (c *coordinator) runScheduler(s *scheduleController) {
 				continue
 			}
 			if op := s.Schedule(); op != nil {
-				c.opController.AddWaitingOperator(op...)
+				c.addWaitingOperator(s.GetName(), op...)
 			}
 
 		case <-s.Ctx().Done():
@@ -450,6 +757,9 @@ type scheduleController struct {
 	nextInterval time.Duration
 	ctx          context.Context
 	cancel       context.CancelFunc
+	// pausedUntil is the unix time in nanoseconds until which the scheduler
+	// is paused. It is accessed atomically.
+	pausedUntil int64
 }
 
 // newScheduleController creates a new scheduleController.
@@ -477,7 +787,7 @@ func (s *scheduleController) Stop() {
 func (s *scheduleController) Schedule() []*schedule.Operator {
 	for i := 0; i < maxScheduleRetries; i++ {
 		// If we have schedule, reset interval to the minimal interval.
-		if op := scheduleByNamespace(s.cluster, s.classifier, s.Scheduler); op != nil {
+		if op := scheduleByNamespace(newDryRunCluster(s.cluster), s.classifier, s.Scheduler); op != nil {
 			s.nextInterval = s.Scheduler.GetMinInterval()
 			return op
 		}
@@ -493,5 +803,20 @@ func (s *scheduleController) GetInterval() time.Duration {
 
 // AllowSchedule returns if a scheduler is allowed to schedule.
 func (s *scheduleController) AllowSchedule() bool {
-	return s.Scheduler.IsScheduleAllowed(s.cluster)
+	return !s.IsPaused() && s.Scheduler.IsScheduleAllowed(s.cluster)
+}
+
+// Pause stops the scheduler from scheduling for a duration.
+func (s *scheduleController) Pause(d time.Duration) {
+	atomic.StoreInt64(&s.pausedUntil, time.Now().Add(d).UnixNano())
+}
+
+// Resume lets the paused scheduler schedule again.
+func (s *scheduleController) Resume() {
+	atomic.StoreInt64(&s.pausedUntil, 0)
+}
+
+// IsPaused returns if the scheduler is paused.
+func (s *scheduleController) IsPaused() bool {
+	return time.Now().UnixNano() < atomic.LoadInt64(&s.pausedUntil)
 }
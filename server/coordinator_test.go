@@ -318,6 +318,47 @@ func (s *testCoordinatorSuite) TestCheckRegion(c *C) {
 	c.Assert(co.checkRegion(tc.GetRegion(1)), IsFalse)
 }
 
+func (s *testCoordinatorSuite) TestDryRun(c *C) {
+	cfg, opt, err := newTestScheduleConfig()
+	c.Assert(err, IsNil)
+	cfg.EnableDryRun = true
+	tc := newTestClusterInfo(opt)
+	hbStreams := getHeartBeatStreams(c, tc)
+	defer hbStreams.Close()
+
+	co := newCoordinator(tc.clusterInfo, hbStreams, namespace.DefaultClassifier)
+	defer co.stop()
+
+	c.Assert(tc.addRegionStore(4, 4), IsNil)
+	c.Assert(tc.addRegionStore(3, 3), IsNil)
+	c.Assert(tc.addRegionStore(2, 2), IsNil)
+	c.Assert(tc.addRegionStore(1, 1), IsNil)
+	c.Assert(tc.addLeaderRegion(1, 2, 3), IsNil)
+
+	// The operator is recorded instead of being added, and no peer ID is
+	// allocated for it.
+	id, err := tc.allocID()
+	c.Assert(err, IsNil)
+	c.Assert(co.checkRegion(tc.GetRegion(1)), IsTrue)
+	c.Assert(co.opController.GetOperator(1), IsNil)
+	records := co.dryRunRecorder.GetRecords()
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].Source, Equals, "replica-checker")
+	c.Assert(records[0].RegionID, Equals, uint64(1))
+	c.Assert(records[0].Influence[1].RegionCount, Equals, int64(1))
+	nextID, err := tc.allocID()
+	c.Assert(err, IsNil)
+	c.Assert(nextID, Equals, id+1)
+
+	// The operators generated in dry-run mode are never added.
+	op := co.replicaChecker.Check(tc.GetRegion(1))
+	c.Assert(op, NotNil)
+	cfg.EnableDryRun = false
+	c.Assert(co.addWaitingOperator("replica-checker", op), IsFalse)
+	c.Assert(co.checkRegion(tc.GetRegion(1)), IsTrue)
+	c.Assert(co.opController.GetOperator(1), NotNil)
+}
+
 func (s *testCoordinatorSuite) TestReplica(c *C) {
 	// Turn off balance.
 	cfg, opt, err := newTestScheduleConfig()
@@ -566,12 +607,12 @@ func (s *testCoordinatorSuite) TestAddScheduler(c *C) {
 	c.Assert(tc.addLeaderRegion(3, 3, 1, 2), IsNil)
 
 	oc := co.opController
-	gls, err := schedule.CreateScheduler("grant-leader", oc, "0")
+	gls, err := schedule.CreateScheduler("grant-leader", oc, core.NewKV(core.NewMemoryKV()), "0")
 	c.Assert(err, IsNil)
 	c.Assert(co.addScheduler(gls), NotNil)
 	c.Assert(co.removeScheduler(gls.GetName()), NotNil)
 
-	gls, err = schedule.CreateScheduler("grant-leader", oc, "1")
+	gls, err = schedule.CreateScheduler("grant-leader", oc, core.NewKV(core.NewMemoryKV()), "1")
 	c.Assert(err, IsNil)
 	c.Assert(co.addScheduler(gls), IsNil)
 
@@ -591,6 +632,39 @@ func (s *testCoordinatorSuite) TestAddScheduler(c *C) {
 	waitNoResponse(c, stream)
 }
 
+func (s *testCoordinatorSuite) TestPauseScheduler(c *C) {
+	_, opt, err := newTestScheduleConfig()
+	c.Assert(err, IsNil)
+	tc := newTestClusterInfo(opt)
+	hbStreams := getHeartBeatStreams(c, tc)
+	defer hbStreams.Close()
+	co := newCoordinator(tc.clusterInfo, hbStreams, namespace.DefaultClassifier)
+	co.run()
+	defer co.wg.Wait()
+	defer co.stop()
+
+	name := "balance-leader-scheduler"
+	sc := co.schedulers[name]
+	c.Assert(sc.AllowSchedule(), IsTrue)
+	c.Assert(co.pauseScheduler(name, 0), NotNil)
+	c.Assert(co.pauseScheduler("unknown-scheduler", time.Minute), NotNil)
+
+	c.Assert(co.pauseScheduler(name, time.Minute), IsNil)
+	c.Assert(sc.IsPaused(), IsTrue)
+	c.Assert(sc.AllowSchedule(), IsFalse)
+	c.Assert(co.getPausedSchedulers(), DeepEquals, []string{name})
+	c.Assert(co.resumeScheduler(name), IsNil)
+	c.Assert(sc.IsPaused(), IsFalse)
+	c.Assert(co.getPausedSchedulers(), HasLen, 0)
+
+	// The scheduler resumes automatically when the delay is over.
+	c.Assert(co.pauseScheduler(name, 100*time.Millisecond), IsNil)
+	c.Assert(sc.IsPaused(), IsTrue)
+	time.Sleep(200 * time.Millisecond)
+	c.Assert(sc.IsPaused(), IsFalse)
+	c.Assert(sc.AllowSchedule(), IsTrue)
+}
+
 func (s *testCoordinatorSuite) TestPersistScheduler(c *C) {
 	cfg, opt, err := newTestScheduleConfig()
 	c.Assert(err, IsNil)
@@ -609,19 +683,20 @@ func (s *testCoordinatorSuite) TestPersistScheduler(c *C) {
 
 	c.Assert(co.schedulers, HasLen, 4)
 	oc := co.opController
-	gls1, err := schedule.CreateScheduler("grant-leader", oc, "1")
+	gls1, err := schedule.CreateScheduler("grant-leader", oc, tc.kv, "1")
 	c.Assert(err, IsNil)
 	c.Assert(co.addScheduler(gls1, "1"), IsNil)
-	gls2, err := schedule.CreateScheduler("grant-leader", oc, "2")
+	// The stores are merged into one scheduler.
+	gls2, err := schedule.CreateScheduler("grant-leader", oc, tc.kv, "2")
 	c.Assert(err, IsNil)
 	c.Assert(co.addScheduler(gls2, "2"), IsNil)
-	c.Assert(co.schedulers, HasLen, 6)
+	c.Assert(co.schedulers, HasLen, 5)
 	fmt.Println(opt)
 	c.Assert(co.removeScheduler("balance-leader-scheduler"), IsNil)
 	c.Assert(co.removeScheduler("balance-region-scheduler"), IsNil)
 	c.Assert(co.removeScheduler("balance-hot-region-scheduler"), IsNil)
 	c.Assert(co.removeScheduler("label-scheduler"), IsNil)
-	c.Assert(co.schedulers, HasLen, 2)
+	c.Assert(co.schedulers, HasLen, 1)
 	c.Assert(co.cluster.opt.persist(co.cluster.kv), IsNil)
 	co.stop()
 	co.wg.Wait()
@@ -629,18 +704,18 @@ func (s *testCoordinatorSuite) TestPersistScheduler(c *C) {
 	// whether the schedulers added or removed in dynamic way are recorded in opt
 	_, newOpt, err := newTestScheduleConfig()
 	c.Assert(err, IsNil)
-	_, err = schedule.CreateScheduler("adjacent-region", oc)
+	_, err = schedule.CreateScheduler("adjacent-region", oc, core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 	// suppose we add a new default enable scheduler
 	newOpt.AddSchedulerCfg("adjacent-region", []string{})
 	c.Assert(newOpt.GetSchedulers(), HasLen, 5)
 	c.Assert(newOpt.reload(co.cluster.kv), IsNil)
-	c.Assert(newOpt.GetSchedulers(), HasLen, 7)
+	c.Assert(newOpt.GetSchedulers(), HasLen, 6)
 	tc.clusterInfo.opt = newOpt
 
 	co = newCoordinator(tc.clusterInfo, hbStreams, namespace.DefaultClassifier)
 	co.run()
-	c.Assert(co.schedulers, HasLen, 3)
+	c.Assert(co.schedulers, HasLen, 2)
 	co.stop()
 	co.wg.Wait()
 	// suppose restart PD again
@@ -650,19 +725,19 @@ func (s *testCoordinatorSuite) TestPersistScheduler(c *C) {
 	tc.clusterInfo.opt = newOpt
 	co = newCoordinator(tc.clusterInfo, hbStreams, namespace.DefaultClassifier)
 	co.run()
-	c.Assert(co.schedulers, HasLen, 3)
-	bls, err := schedule.CreateScheduler("balance-leader", oc)
+	c.Assert(co.schedulers, HasLen, 2)
+	bls, err := schedule.CreateScheduler("balance-leader", oc, core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 	c.Assert(co.addScheduler(bls), IsNil)
-	brs, err := schedule.CreateScheduler("balance-region", oc)
+	brs, err := schedule.CreateScheduler("balance-region", oc, core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 	c.Assert(co.addScheduler(brs), IsNil)
-	c.Assert(co.schedulers, HasLen, 5)
-	// the scheduler option should contain 7 items
+	c.Assert(co.schedulers, HasLen, 4)
+	// the scheduler option should contain 6 items
 	// the `hot scheduler` and `label scheduler` are disabled
-	c.Assert(co.cluster.opt.GetSchedulers(), HasLen, 7)
+	c.Assert(co.cluster.opt.GetSchedulers(), HasLen, 6)
+	// only store 1 is removed from the grant-leader scheduler
 	c.Assert(co.removeScheduler("grant-leader-scheduler-1"), IsNil)
-	// the scheduler that is not enable by default will be completely deleted
 	c.Assert(co.cluster.opt.GetSchedulers(), HasLen, 6)
 	c.Assert(co.schedulers, HasLen, 4)
 	c.Assert(co.cluster.opt.persist(co.cluster.kv), IsNil)
@@ -679,7 +754,14 @@ func (s *testCoordinatorSuite) TestPersistScheduler(c *C) {
 	defer co.wg.Wait()
 	defer co.stop()
 	c.Assert(co.schedulers, HasLen, 4)
+	schedulerCfg, err := co.getSchedulerConfig("grant-leader-scheduler")
+	c.Assert(err, IsNil)
+	stores := schedulerCfg.(map[uint64][]schedule.KeyRange)
+	c.Assert(stores, HasLen, 1)
+	c.Assert(stores, HasKey, uint64(2))
 	c.Assert(co.removeScheduler("grant-leader-scheduler-2"), IsNil)
+	// the scheduler that is not enable by default will be completely deleted
+	c.Assert(co.cluster.opt.GetSchedulers(), HasLen, 5)
 	c.Assert(co.schedulers, HasLen, 3)
 }
 
@@ -773,7 +855,7 @@ func (s *testOperatorControllerSuite) TestStoreOverloaded(c *C) {
 	hbStreams := getHeartBeatStreams(c, tc)
 	defer hbStreams.Close()
 	oc := schedule.NewOperatorController(tc.clusterInfo, hbStreams)
-	lb, err := schedule.CreateScheduler("balance-region", oc)
+	lb, err := schedule.CreateScheduler("balance-region", oc, core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 
 	c.Assert(tc.addRegionStore(4, 40), IsNil)
@@ -801,7 +883,7 @@ func (s *testOperatorControllerSuite) TestStoreOverloadedWithReplace(c *C) {
 	hbStreams := getHeartBeatStreams(c, tc)
 	defer hbStreams.Close()
 	oc := schedule.NewOperatorController(tc.clusterInfo, hbStreams)
-	lb, err := schedule.CreateScheduler("balance-region", oc)
+	lb, err := schedule.CreateScheduler("balance-region", oc, core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 
 	c.Assert(tc.addRegionStore(4, 40), IsNil)
@@ -850,7 +932,7 @@ func (s *testScheduleControllerSuite) TestController(c *C) {
 
 	co := newCoordinator(tc.clusterInfo, hbStreams, namespace.DefaultClassifier)
 	oc := co.opController
-	scheduler, err := schedule.CreateScheduler("balance-leader", oc)
+	scheduler, err := schedule.CreateScheduler("balance-leader", oc, core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 	lb := &mockLimitScheduler{
 		Scheduler: scheduler,
@@ -924,7 +1006,7 @@ func (s *testScheduleControllerSuite) TestInterval(c *C) {
 	defer hbStreams.Close()
 
 	co := newCoordinator(tc.clusterInfo, hbStreams, namespace.DefaultClassifier)
-	lb, err := schedule.CreateScheduler("balance-leader", co.opController)
+	lb, err := schedule.CreateScheduler("balance-leader", co.opController, core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 	sc := newScheduleController(co, lb)
 
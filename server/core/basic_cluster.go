@@ -13,6 +13,8 @@
 
 package core
 
+import "time"
+
 // BasicCluster provides basic data member and interface for a tikv cluster.
 type BasicCluster struct {
 	Stores  *StoresInfo
@@ -89,6 +91,12 @@ func (bc *BasicCluster) AttachOverloadStatus(storeID uint64, f func() bool) {
 	bc.Stores.AttachOverloadStatus(storeID, f)
 }
 
+// ObserveSnapshot records that a store applies a snapshot of size bytes in the
+// duration.
+func (bc *BasicCluster) ObserveSnapshot(storeID uint64, size uint64, duration time.Duration) {
+	bc.Stores.ObserveSnapshot(storeID, size, duration)
+}
+
 // RandFollowerRegion returns a random region that has a follower on the store.
 func (bc *BasicCluster) RandFollowerRegion(storeID uint64, opts ...RegionOption) *RegionInfo {
 	return bc.Regions.RandFollowerRegion(storeID, opts...)
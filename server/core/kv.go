@@ -19,11 +19,15 @@ import (
 	"math"
 	"path"
 	"strconv"
+	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/pingcap/kvproto/pkg/metapb"
+	log "github.com/pingcap/log"
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
 )
 
 const (
@@ -31,6 +35,12 @@ const (
 	configPath   = "config"
 	schedulePath = "schedule"
 	gcPath       = "gc"
+	rulesPath    = "rules"
+
+	leaderPoliciesPath   = "leader_policies"
+	operatorHistoryPath  = "operator_history"
+	storeLimitPath       = "store_limit"
+	rangeReplicationPath = "range_replication"
 )
 
 const (
@@ -94,6 +104,14 @@ func (kv *KV) storeRegionWeightPath(storeID uint64) string {
 	return path.Join(schedulePath, "store_weight", fmt.Sprintf("%020d", storeID), "region")
 }
 
+func (kv *KV) storeMaintenancePath(storeID uint64) string {
+	return path.Join(schedulePath, "store_maintenance", fmt.Sprintf("%020d", storeID))
+}
+
+func (kv *KV) schedulerConfigPath(name string) string {
+	return path.Join(schedulePath, "scheduler_config", name)
+}
+
 // LoadMeta loads cluster meta from KV store.
 func (kv *KV) LoadMeta(meta *metapb.Cluster) (bool, error) {
 	return loadProto(kv.KVBase, clusterPath, meta)
@@ -176,6 +194,158 @@ func (kv *KV) LoadConfig(cfg interface{}) (bool, error) {
 	return true, nil
 }
 
+// SaveSchedulerConfig stores the marshalable config of a scheduler.
+func (kv *KV) SaveSchedulerConfig(name string, cfg interface{}) error {
+	return saveJSON(kv.KVBase, kv.schedulerConfigPath(name), cfg)
+}
+
+// LoadSchedulerConfig loads the config of a scheduler then unmarshal it to cfg.
+func (kv *KV) LoadSchedulerConfig(name string, cfg interface{}) (bool, error) {
+	value, err := kv.Load(kv.schedulerConfigPath(name))
+	if err != nil {
+		return false, err
+	}
+	if value == "" {
+		return false, nil
+	}
+	if err = json.Unmarshal([]byte(value), cfg); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+// RemoveSchedulerConfig removes the config of a scheduler from storage.
+func (kv *KV) RemoveSchedulerConfig(name string) error {
+	return kv.Delete(kv.schedulerConfigPath(name))
+}
+
+// SaveRule stores a placement rule to the rulesPath.
+func (kv *KV) SaveRule(ruleKey string, rule interface{}) error {
+	return saveJSON(kv.KVBase, path.Join(rulesPath, ruleKey), rule)
+}
+
+// DeleteRule removes a placement rule from storage.
+func (kv *KV) DeleteRule(ruleKey string) error {
+	return kv.Delete(path.Join(rulesPath, ruleKey))
+}
+
+// LoadRules loads all placement rules from storage.
+func (kv *KV) LoadRules(f func(k, v string)) error {
+	return loadRangeByPrefix(kv.KVBase, rulesPath+"/", f)
+}
+
+// SaveStoreLimit stores a store limit setting to the storeLimitPath.
+func (kv *KV) SaveStoreLimit(key string, setting interface{}) error {
+	return saveJSON(kv.KVBase, path.Join(storeLimitPath, key), setting)
+}
+
+// DeleteStoreLimit removes a store limit setting from storage.
+func (kv *KV) DeleteStoreLimit(key string) error {
+	return kv.Delete(path.Join(storeLimitPath, key))
+}
+
+// LoadStoreLimits loads all store limit settings from storage.
+func (kv *KV) LoadStoreLimits(f func(k, v string)) error {
+	return loadRangeByPrefix(kv.KVBase, storeLimitPath+"/", f)
+}
+
+// SaveLeaderPolicy stores a leader policy to the leaderPoliciesPath.
+func (kv *KV) SaveLeaderPolicy(policy *LeaderPolicy) error {
+	return saveJSON(kv.KVBase, path.Join(leaderPoliciesPath, policy.ID), policy)
+}
+
+// DeleteLeaderPolicy removes a leader policy from storage.
+func (kv *KV) DeleteLeaderPolicy(id string) error {
+	return kv.Delete(path.Join(leaderPoliciesPath, id))
+}
+
+// LoadLeaderPolicies loads all leader policies from storage. The invalid ones
+// are skipped, so they do not keep the cluster from starting.
+func (kv *KV) LoadLeaderPolicies(policies *LeaderPolicies) error {
+	return loadRangeByPrefix(kv.KVBase, leaderPoliciesPath+"/", func(k, v string) {
+		policy := &LeaderPolicy{}
+		if err := json.Unmarshal([]byte(v), policy); err != nil {
+			log.Error("invalid leader policy in storage", zap.String("policy-key", k), zap.Error(err))
+			return
+		}
+		if err := policy.Adjust(); err != nil {
+			log.Error("invalid leader policy in storage", zap.String("policy-key", k), zap.Error(err))
+			return
+		}
+		policies.SetLeaderPolicy(policy)
+	})
+}
+
+// SaveRangeReplication stores a range replication to the rangeReplicationPath.
+func (kv *KV) SaveRangeReplication(r *RangeReplication) error {
+	return saveJSON(kv.KVBase, path.Join(rangeReplicationPath, r.ID), r)
+}
+
+// DeleteRangeReplication removes a range replication from storage.
+func (kv *KV) DeleteRangeReplication(id string) error {
+	return kv.Delete(path.Join(rangeReplicationPath, id))
+}
+
+// LoadRangeReplications loads all range replications from storage. The invalid
+// ones are skipped, so they do not keep the cluster from starting.
+func (kv *KV) LoadRangeReplications(replications *RangeReplications) error {
+	return loadRangeByPrefix(kv.KVBase, rangeReplicationPath+"/", func(k, v string) {
+		r := &RangeReplication{}
+		if err := json.Unmarshal([]byte(v), r); err != nil {
+			log.Error("invalid range replication in storage", zap.String("replication-key", k), zap.Error(err))
+			return
+		}
+		if err := r.Adjust(); err != nil {
+			log.Error("invalid range replication in storage", zap.String("replication-key", k), zap.Error(err))
+			return
+		}
+		replications.SetRangeReplication(r)
+	})
+}
+
+// operatorHistoryKey returns the key of an operator history. The histories
+// are ordered by their finish time, and the ones finishing at the same time
+// are told apart by their sequence numbers.
+func operatorHistoryKey(finishTime time.Time, seq uint64) string {
+	return path.Join(operatorHistoryPath, fmt.Sprintf("%020d", finishTime.UnixNano()), fmt.Sprintf("%020d", seq))
+}
+
+// SaveOperatorHistory stores the history of an operator which finishes at
+// finishTime, and seq should be unique among the histories finishing at the
+// same time.
+func (kv *KV) SaveOperatorHistory(finishTime time.Time, seq uint64, history interface{}) error {
+	return saveJSON(kv.KVBase, operatorHistoryKey(finishTime, seq), history)
+}
+
+// DeleteOperatorHistory removes an operator history by the key passed to the
+// function of LoadOperatorHistory.
+func (kv *KV) DeleteOperatorHistory(key string) error {
+	return kv.Delete(key)
+}
+
+// LoadOperatorHistory iterates the keys and the histories of the operators
+// which finish in [start, end) from the oldest to the latest, until f returns
+// false.
+func (kv *KV) LoadOperatorHistory(start, end time.Time, f func(k, v string) bool) error {
+	nextKey := operatorHistoryKey(start, 0)
+	endKey := operatorHistoryKey(end, 0)
+	for {
+		keys, values, err := kv.LoadRange(nextKey, endKey, minKVRangeLimit)
+		if err != nil {
+			return err
+		}
+		for i, v := range values {
+			if !f(keys[i], v) {
+				return nil
+			}
+		}
+		if len(keys) < minKVRangeLimit {
+			return nil
+		}
+		nextKey = keys[len(keys)-1] + "\x00"
+	}
+}
+
 // LoadStores loads all stores from KV to StoresInfo.
 func (kv *KV) LoadStores(stores *StoresInfo) error {
 	nextID := uint64(0)
@@ -199,7 +369,11 @@ func (kv *KV) LoadStores(stores *StoresInfo) error {
 			if err != nil {
 				return err
 			}
-			newStoreInfo := NewStoreInfo(store, SetLeaderWeight(leaderWeight), SetRegionWeight(regionWeight))
+			maintenanceDeadline, err := kv.loadStoreMaintenance(store.GetId())
+			if err != nil {
+				return err
+			}
+			newStoreInfo := NewStoreInfo(store, SetLeaderWeight(leaderWeight), SetRegionWeight(regionWeight), SetMaintenanceDeadline(maintenanceDeadline))
 
 			nextID = store.GetId() + 1
 			stores.SetStore(newStoreInfo)
@@ -220,6 +394,29 @@ func (kv *KV) SaveStoreWeight(storeID uint64, leader, region float64) error {
 	return kv.Save(kv.storeRegionWeightPath(storeID), regionValue)
 }
 
+// SaveStoreMaintenance saves the time when the maintenance of a store ends to
+// KV.
+func (kv *KV) SaveStoreMaintenance(storeID uint64, deadline time.Time) error {
+	return kv.Save(kv.storeMaintenancePath(storeID), deadline.Format(time.RFC3339Nano))
+}
+
+// DeleteStoreMaintenance deletes the maintenance of a store from KV.
+func (kv *KV) DeleteStoreMaintenance(storeID uint64) error {
+	return kv.Delete(kv.storeMaintenancePath(storeID))
+}
+
+func (kv *KV) loadStoreMaintenance(storeID uint64) (time.Time, error) {
+	res, err := kv.Load(kv.storeMaintenancePath(storeID))
+	if err != nil || res == "" {
+		return time.Time{}, err
+	}
+	deadline, err := time.Parse(time.RFC3339Nano, res)
+	if err != nil {
+		return time.Time{}, errors.WithStack(err)
+	}
+	return deadline, nil
+}
+
 func (kv *KV) loadFloatWithDefaultValue(path string, def float64) (float64, error) {
 	res, err := kv.Load(path)
 	if err != nil {
@@ -294,3 +491,30 @@ func saveProto(kv KVBase, key string, msg proto.Message) error {
 	}
 	return kv.Save(key, string(value))
 }
+
+func saveJSON(kv KVBase, key string, data interface{}) error {
+	value, err := json.Marshal(data)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return kv.Save(key, string(value))
+}
+
+// loadRangeByPrefix iterates all key-value pairs in the storage that has the prefix.
+func loadRangeByPrefix(kv KVBase, prefix string, f func(k, v string)) error {
+	nextKey := prefix
+	endKey := prefix[:len(prefix)-1] + string(prefix[len(prefix)-1]+1)
+	for {
+		keys, values, err := kv.LoadRange(nextKey, endKey, minKVRangeLimit)
+		if err != nil {
+			return err
+		}
+		for i := range keys {
+			f(strings.TrimPrefix(keys[i], prefix), values[i])
+		}
+		if len(keys) < minKVRangeLimit {
+			return nil
+		}
+		nextKey = keys[len(keys)-1] + "\x00"
+	}
+}
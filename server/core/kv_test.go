@@ -16,6 +16,7 @@ package core
 import (
 	"fmt"
 	"math"
+	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
@@ -117,6 +118,23 @@ func (s *testKVSuite) TestStoreWeight(c *C) {
 	}
 }
 
+func (s *testKVSuite) TestStoreMaintenance(c *C) {
+	kv := NewKV(NewMemoryKV())
+	cache := NewStoresInfo()
+	const n = 3
+
+	mustSaveStores(c, kv, n)
+	deadline := time.Now().Add(time.Hour)
+	c.Assert(kv.SaveStoreMaintenance(1, deadline), IsNil)
+	c.Assert(kv.SaveStoreMaintenance(2, deadline), IsNil)
+	c.Assert(kv.DeleteStoreMaintenance(2), IsNil)
+	c.Assert(kv.LoadStores(cache), IsNil)
+	c.Assert(cache.GetStore(0).IsInMaintenance(), IsFalse)
+	c.Assert(cache.GetStore(1).IsInMaintenance(), IsTrue)
+	c.Assert(cache.GetStore(1).GetMaintenanceDeadline().Equal(deadline), IsTrue)
+	c.Assert(cache.GetStore(2).IsInMaintenance(), IsFalse)
+}
+
 func mustSaveRegions(c *C, kv *KV, n int) []*metapb.Region {
 	regions := make([]*metapb.Region, 0, n)
 	for i := 0; i < n; i++ {
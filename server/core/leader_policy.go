@@ -0,0 +1,187 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var policyIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_\-.]+$`)
+
+// LeaderPreference is a store label that leaders prefer.
+type LeaderPreference struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// LeaderPolicy describes which stores the leaders of the regions in the key
+// range [StartKey, EndKey) prefer. Preferences are ordered, stores matching
+// an earlier preference are preferred, and stores matching none of them are
+// the last choice.
+type LeaderPolicy struct {
+	ID          string             `json:"id"`
+	StartKeyHex string             `json:"start_key"` // Hex-encoded start key of the key range.
+	EndKeyHex   string             `json:"end_key"`   // Hex-encoded end key of the key range, empty means +inf.
+	Preferences []LeaderPreference `json:"preferences"`
+
+	StartKey []byte `json:"-"`
+	EndKey   []byte `json:"-"`
+}
+
+// Adjust validates the policy and decodes its key range.
+func (p *LeaderPolicy) Adjust() error {
+	if !policyIDPattern.MatchString(p.ID) {
+		return errors.Errorf("invalid leader policy ID '%s'", p.ID)
+	}
+	if len(p.Preferences) == 0 {
+		return errors.New("leader policy should have at least one preference")
+	}
+	for _, pref := range p.Preferences {
+		if pref.Key == "" || pref.Value == "" {
+			return errors.Errorf("invalid leader preference %s=%s", pref.Key, pref.Value)
+		}
+	}
+	var err error
+	p.StartKey, p.EndKey, err = decodeKeyRange(p.StartKeyHex, p.EndKeyHex)
+	return err
+}
+
+// Clone returns a copy of the policy.
+func (p *LeaderPolicy) Clone() *LeaderPolicy {
+	clone := *p
+	clone.Preferences = append([]LeaderPreference(nil), p.Preferences...)
+	clone.StartKey = append([]byte(nil), p.StartKey...)
+	clone.EndKey = append([]byte(nil), p.EndKey...)
+	return &clone
+}
+
+// GetStoreRank returns the index of the first preference the store matches.
+// A smaller rank means the store is more preferred. Stores matching none of
+// the preferences get the largest rank.
+func (p *LeaderPolicy) GetStoreRank(store *StoreInfo) int {
+	for i, pref := range p.Preferences {
+		if store.GetLabelValue(pref.Key) == pref.Value {
+			return i
+		}
+	}
+	return len(p.Preferences)
+}
+
+// CoverRegion checks if the policy's key range covers the whole region.
+func (p *LeaderPolicy) CoverRegion(region *RegionInfo) bool {
+	return keyRangeCoverRegion(p.StartKey, p.EndKey, region)
+}
+
+// keyRangeCoverRegion checks if the key range [startKey, endKey) covers the
+// whole region. An empty endKey means +inf.
+func keyRangeCoverRegion(startKey, endKey []byte, region *RegionInfo) bool {
+	if bytes.Compare(region.GetStartKey(), startKey) < 0 {
+		return false
+	}
+	if len(endKey) == 0 {
+		return true
+	}
+	return len(region.GetEndKey()) > 0 && bytes.Compare(region.GetEndKey(), endKey) <= 0
+}
+
+// decodeKeyRange decodes the hex-encoded key range [startKeyHex, endKeyHex).
+func decodeKeyRange(startKeyHex, endKeyHex string) (startKey, endKey []byte, err error) {
+	if startKey, err = hex.DecodeString(startKeyHex); err != nil {
+		return nil, nil, errors.Wrap(err, "start key is not hex format")
+	}
+	if endKey, err = hex.DecodeString(endKeyHex); err != nil {
+		return nil, nil, errors.Wrap(err, "end key is not hex format")
+	}
+	if len(endKey) > 0 && bytes.Compare(endKey, startKey) <= 0 {
+		return nil, nil, errors.New("end key should be greater than start key")
+	}
+	return startKey, endKey, nil
+}
+
+// LeaderPolicies is a thread safe container of leader policies.
+type LeaderPolicies struct {
+	sync.RWMutex
+	policies map[string]*LeaderPolicy
+}
+
+// NewLeaderPolicies creates an empty LeaderPolicies.
+func NewLeaderPolicies() *LeaderPolicies {
+	return &LeaderPolicies{
+		policies: make(map[string]*LeaderPolicy),
+	}
+}
+
+// SetLeaderPolicy inserts or updates a policy. The policy should be adjusted.
+func (l *LeaderPolicies) SetLeaderPolicy(policy *LeaderPolicy) {
+	l.Lock()
+	defer l.Unlock()
+	l.policies[policy.ID] = policy.Clone()
+}
+
+// DeleteLeaderPolicy removes a policy.
+func (l *LeaderPolicies) DeleteLeaderPolicy(id string) {
+	l.Lock()
+	defer l.Unlock()
+	delete(l.policies, id)
+}
+
+// GetLeaderPolicyByID returns the policy with the ID, or nil if not found.
+func (l *LeaderPolicies) GetLeaderPolicyByID(id string) *LeaderPolicy {
+	l.RLock()
+	defer l.RUnlock()
+	if p, ok := l.policies[id]; ok {
+		return p.Clone()
+	}
+	return nil
+}
+
+// GetLeaderPolicies returns all policies sorted by ID.
+func (l *LeaderPolicies) GetLeaderPolicies() []*LeaderPolicy {
+	l.RLock()
+	defer l.RUnlock()
+	policies := make([]*LeaderPolicy, 0, len(l.policies))
+	for _, p := range l.policies {
+		policies = append(policies, p.Clone())
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].ID < policies[j].ID })
+	return policies
+}
+
+// GetLeaderPolicy returns the policy that applies to the region, or nil if
+// there is none. If multiple policies cover the region, the one with the
+// largest start key, which is the most specific one, is used.
+func (l *LeaderPolicies) GetLeaderPolicy(region *RegionInfo) *LeaderPolicy {
+	l.RLock()
+	defer l.RUnlock()
+	var res *LeaderPolicy
+	for _, p := range l.policies {
+		if !p.CoverRegion(region) {
+			continue
+		}
+		if res == nil {
+			res = p
+			continue
+		}
+		if c := bytes.Compare(p.StartKey, res.StartKey); c > 0 || (c == 0 && p.ID < res.ID) {
+			res = p
+		}
+	}
+	return res
+}
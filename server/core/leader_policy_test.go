@@ -0,0 +1,111 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+)
+
+var _ = Suite(&testLeaderPolicySuite{})
+
+type testLeaderPolicySuite struct{}
+
+func newTestLeaderPolicy(id, start, end string, prefs ...string) *LeaderPolicy {
+	p := &LeaderPolicy{ID: id, StartKeyHex: start, EndKeyHex: end}
+	for _, zone := range prefs {
+		p.Preferences = append(p.Preferences, LeaderPreference{Key: "zone", Value: zone})
+	}
+	return p
+}
+
+func (s *testLeaderPolicySuite) TestAdjust(c *C) {
+	c.Assert(newTestLeaderPolicy("p1", "", "", "z1").Adjust(), IsNil)
+	c.Assert(newTestLeaderPolicy("p1", "61", "62", "z1", "z2").Adjust(), IsNil)
+	c.Assert(newTestLeaderPolicy("", "", "", "z1").Adjust(), NotNil)
+	c.Assert(newTestLeaderPolicy("p/1", "", "", "z1").Adjust(), NotNil)
+	c.Assert(newTestLeaderPolicy("p1", "", "").Adjust(), NotNil)
+	c.Assert(newTestLeaderPolicy("p1", "xx", "", "z1").Adjust(), NotNil)
+	c.Assert(newTestLeaderPolicy("p1", "62", "61", "z1").Adjust(), NotNil)
+	c.Assert(newTestLeaderPolicy("p1", "", "", "").Adjust(), NotNil)
+}
+
+func (s *testLeaderPolicySuite) TestStoreRank(c *C) {
+	p := newTestLeaderPolicy("p1", "", "", "z1", "z2")
+	c.Assert(p.Adjust(), IsNil)
+	newStore := func(zone string) *StoreInfo {
+		return NewStoreInfo(&metapb.Store{Labels: []*metapb.StoreLabel{{Key: "zone", Value: zone}}})
+	}
+	c.Assert(p.GetStoreRank(newStore("z1")), Equals, 0)
+	c.Assert(p.GetStoreRank(newStore("z2")), Equals, 1)
+	c.Assert(p.GetStoreRank(newStore("z3")), Equals, 2)
+}
+
+func (s *testLeaderPolicySuite) TestGetLeaderPolicy(c *C) {
+	policies := NewLeaderPolicies()
+	for _, p := range []*LeaderPolicy{
+		newTestLeaderPolicy("all", "", "", "z1"),
+		newTestLeaderPolicy("a-z", "61", "7a", "z2"),
+		newTestLeaderPolicy("b-c", "62", "63", "z3"),
+	} {
+		c.Assert(p.Adjust(), IsNil)
+		policies.SetLeaderPolicy(p)
+	}
+	newRegion := func(start, end string) *RegionInfo {
+		return NewRegionInfo(&metapb.Region{StartKey: []byte(start), EndKey: []byte(end)}, nil)
+	}
+	c.Assert(policies.GetLeaderPolicy(newRegion("", "a")).ID, Equals, "all")
+	c.Assert(policies.GetLeaderPolicy(newRegion("a", "b")).ID, Equals, "a-z")
+	c.Assert(policies.GetLeaderPolicy(newRegion("b", "c")).ID, Equals, "b-c")
+	// The region is not covered by b-c completely.
+	c.Assert(policies.GetLeaderPolicy(newRegion("b", "d")).ID, Equals, "a-z")
+	c.Assert(policies.GetLeaderPolicy(newRegion("y", "")).ID, Equals, "all")
+
+	policies.DeleteLeaderPolicy("all")
+	c.Assert(policies.GetLeaderPolicy(newRegion("y", "")), IsNil)
+	c.Assert(policies.GetLeaderPolicies(), HasLen, 2)
+	c.Assert(policies.GetLeaderPolicyByID("all"), IsNil)
+	c.Assert(policies.GetLeaderPolicyByID("b-c").Preferences[0].Value, Equals, "z3")
+}
+
+func (s *testLeaderPolicySuite) TestLoadInvalidLeaderPolicies(c *C) {
+	kv := NewKV(NewMemoryKV())
+	p := newTestLeaderPolicy("p1", "", "", "z1")
+	c.Assert(p.Adjust(), IsNil)
+	c.Assert(kv.SaveLeaderPolicy(p), IsNil)
+	c.Assert(kv.Save(leaderPoliciesPath+"/p2", "invalid"), IsNil)
+	c.Assert(kv.Save(leaderPoliciesPath+"/p3", `{"id":"p3"}`), IsNil)
+
+	// The invalid policies are skipped.
+	policies := NewLeaderPolicies()
+	c.Assert(kv.LoadLeaderPolicies(policies), IsNil)
+	c.Assert(policies.GetLeaderPolicies(), HasLen, 1)
+	c.Assert(policies.GetLeaderPolicyByID("p1"), NotNil)
+}
+
+func (s *testLeaderPolicySuite) TestSaveLoad(c *C) {
+	kv := NewKV(NewMemoryKV())
+	p1, p2 := newTestLeaderPolicy("p1", "", "", "z1"), newTestLeaderPolicy("p2", "61", "62", "z2", "z3")
+	c.Assert(p1.Adjust(), IsNil)
+	c.Assert(p2.Adjust(), IsNil)
+	c.Assert(kv.SaveLeaderPolicy(p1), IsNil)
+	c.Assert(kv.SaveLeaderPolicy(p2), IsNil)
+	c.Assert(kv.DeleteLeaderPolicy("p1"), IsNil)
+
+	policies := NewLeaderPolicies()
+	c.Assert(kv.LoadLeaderPolicies(policies), IsNil)
+	loaded := policies.GetLeaderPolicies()
+	c.Assert(loaded, HasLen, 1)
+	c.Assert(loaded[0], DeepEquals, p2)
+}
@@ -0,0 +1,138 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// RangeReplication overrides the replication config for the regions in the
+// key range [StartKey, EndKey). A zero MaxReplicas or a nil LocationLabels
+// means the cluster-wide (or namespace) setting is used.
+type RangeReplication struct {
+	ID             string   `json:"id"`
+	StartKeyHex    string   `json:"start_key"` // Hex-encoded start key of the key range.
+	EndKeyHex      string   `json:"end_key"`   // Hex-encoded end key of the key range, empty means +inf.
+	MaxReplicas    uint64   `json:"max_replicas"`
+	LocationLabels []string `json:"location_labels"`
+
+	StartKey []byte `json:"-"`
+	EndKey   []byte `json:"-"`
+}
+
+// Adjust validates the override and decodes its key range.
+func (r *RangeReplication) Adjust() error {
+	if !policyIDPattern.MatchString(r.ID) {
+		return errors.Errorf("invalid range replication ID '%s'", r.ID)
+	}
+	if r.MaxReplicas == 0 && r.LocationLabels == nil {
+		return errors.New("range replication should override max_replicas or location_labels")
+	}
+	var err error
+	r.StartKey, r.EndKey, err = decodeKeyRange(r.StartKeyHex, r.EndKeyHex)
+	return err
+}
+
+// Clone returns a copy of the override.
+func (r *RangeReplication) Clone() *RangeReplication {
+	clone := *r
+	if r.LocationLabels != nil {
+		clone.LocationLabels = append([]string{}, r.LocationLabels...)
+	}
+	clone.StartKey = append([]byte(nil), r.StartKey...)
+	clone.EndKey = append([]byte(nil), r.EndKey...)
+	return &clone
+}
+
+// CoverRegion checks if the override's key range covers the whole region.
+func (r *RangeReplication) CoverRegion(region *RegionInfo) bool {
+	return keyRangeCoverRegion(r.StartKey, r.EndKey, region)
+}
+
+// RangeReplications is a thread safe container of range replications.
+type RangeReplications struct {
+	sync.RWMutex
+	replications map[string]*RangeReplication
+}
+
+// NewRangeReplications creates an empty RangeReplications.
+func NewRangeReplications() *RangeReplications {
+	return &RangeReplications{
+		replications: make(map[string]*RangeReplication),
+	}
+}
+
+// SetRangeReplication inserts or updates an override. The override should be
+// adjusted.
+func (l *RangeReplications) SetRangeReplication(r *RangeReplication) {
+	l.Lock()
+	defer l.Unlock()
+	l.replications[r.ID] = r.Clone()
+}
+
+// DeleteRangeReplication removes an override.
+func (l *RangeReplications) DeleteRangeReplication(id string) {
+	l.Lock()
+	defer l.Unlock()
+	delete(l.replications, id)
+}
+
+// GetRangeReplicationByID returns the override with the ID, or nil if not
+// found.
+func (l *RangeReplications) GetRangeReplicationByID(id string) *RangeReplication {
+	l.RLock()
+	defer l.RUnlock()
+	if r, ok := l.replications[id]; ok {
+		return r.Clone()
+	}
+	return nil
+}
+
+// GetRangeReplications returns all overrides sorted by ID.
+func (l *RangeReplications) GetRangeReplications() []*RangeReplication {
+	l.RLock()
+	defer l.RUnlock()
+	replications := make([]*RangeReplication, 0, len(l.replications))
+	for _, r := range l.replications {
+		replications = append(replications, r.Clone())
+	}
+	sort.Slice(replications, func(i, j int) bool { return replications[i].ID < replications[j].ID })
+	return replications
+}
+
+// GetRangeReplication returns the override that applies to the region, or
+// nil if there is none. If multiple overrides cover the region, the one with
+// the largest start key, which is the most specific one, is used.
+func (l *RangeReplications) GetRangeReplication(region *RegionInfo) *RangeReplication {
+	l.RLock()
+	defer l.RUnlock()
+	var res *RangeReplication
+	for _, r := range l.replications {
+		if !r.CoverRegion(region) {
+			continue
+		}
+		if res == nil {
+			res = r
+			continue
+		}
+		if c := bytes.Compare(r.StartKey, res.StartKey); c > 0 || (c == 0 && r.ID < res.ID) {
+			res = r
+		}
+	}
+	return res
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+)
+
+var _ = Suite(&testRangeReplicationSuite{})
+
+type testRangeReplicationSuite struct{}
+
+func newTestRangeReplication(id, start, end string, maxReplicas uint64, labels ...string) *RangeReplication {
+	return &RangeReplication{ID: id, StartKeyHex: start, EndKeyHex: end, MaxReplicas: maxReplicas, LocationLabels: labels}
+}
+
+func (s *testRangeReplicationSuite) TestAdjust(c *C) {
+	c.Assert(newTestRangeReplication("r1", "", "", 5).Adjust(), IsNil)
+	c.Assert(newTestRangeReplication("r1", "61", "62", 0, "zone").Adjust(), IsNil)
+	c.Assert((&RangeReplication{ID: "r1", LocationLabels: []string{}}).Adjust(), IsNil)
+	c.Assert(newTestRangeReplication("", "", "", 5).Adjust(), NotNil)
+	c.Assert(newTestRangeReplication("r/1", "", "", 5).Adjust(), NotNil)
+	c.Assert(newTestRangeReplication("r1", "", "", 0).Adjust(), NotNil)
+	c.Assert(newTestRangeReplication("r1", "xx", "", 5).Adjust(), NotNil)
+	c.Assert(newTestRangeReplication("r1", "62", "61", 5).Adjust(), NotNil)
+}
+
+func (s *testRangeReplicationSuite) TestGetRangeReplication(c *C) {
+	replications := NewRangeReplications()
+	for _, r := range []*RangeReplication{
+		newTestRangeReplication("all", "", "", 3),
+		newTestRangeReplication("a-z", "61", "7a", 5),
+		newTestRangeReplication("b-c", "62", "63", 0, "zone", "host"),
+	} {
+		c.Assert(r.Adjust(), IsNil)
+		replications.SetRangeReplication(r)
+	}
+	newRegion := func(start, end string) *RegionInfo {
+		return NewRegionInfo(&metapb.Region{StartKey: []byte(start), EndKey: []byte(end)}, nil)
+	}
+	c.Assert(replications.GetRangeReplication(newRegion("", "a")).ID, Equals, "all")
+	c.Assert(replications.GetRangeReplication(newRegion("a", "b")).ID, Equals, "a-z")
+	c.Assert(replications.GetRangeReplication(newRegion("b", "c")).ID, Equals, "b-c")
+	// The region is not covered by b-c completely.
+	c.Assert(replications.GetRangeReplication(newRegion("b", "d")).ID, Equals, "a-z")
+	c.Assert(replications.GetRangeReplication(newRegion("y", "")).ID, Equals, "all")
+
+	replications.DeleteRangeReplication("all")
+	c.Assert(replications.GetRangeReplication(newRegion("y", "")), IsNil)
+	c.Assert(replications.GetRangeReplications(), HasLen, 2)
+	c.Assert(replications.GetRangeReplicationByID("all"), IsNil)
+	c.Assert(replications.GetRangeReplicationByID("b-c").LocationLabels, DeepEquals, []string{"zone", "host"})
+}
+
+func (s *testRangeReplicationSuite) TestLoadInvalidRangeReplications(c *C) {
+	kv := NewKV(NewMemoryKV())
+	r := newTestRangeReplication("r1", "", "", 5)
+	c.Assert(r.Adjust(), IsNil)
+	c.Assert(kv.SaveRangeReplication(r), IsNil)
+	c.Assert(kv.Save(rangeReplicationPath+"/r2", "invalid"), IsNil)
+	c.Assert(kv.Save(rangeReplicationPath+"/r3", `{"id":"r3"}`), IsNil)
+
+	// The invalid range replications are skipped.
+	replications := NewRangeReplications()
+	c.Assert(kv.LoadRangeReplications(replications), IsNil)
+	c.Assert(replications.GetRangeReplications(), HasLen, 1)
+	c.Assert(replications.GetRangeReplicationByID("r1"), NotNil)
+}
+
+func (s *testRangeReplicationSuite) TestSaveLoad(c *C) {
+	kv := NewKV(NewMemoryKV())
+	r1, r2 := newTestRangeReplication("r1", "", "", 5), newTestRangeReplication("r2", "61", "62", 0)
+	r2.LocationLabels = []string{}
+	c.Assert(r1.Adjust(), IsNil)
+	c.Assert(r2.Adjust(), IsNil)
+	c.Assert(kv.SaveRangeReplication(r1), IsNil)
+	c.Assert(kv.SaveRangeReplication(r2), IsNil)
+	c.Assert(kv.DeleteRangeReplication("r1"), IsNil)
+
+	replications := NewRangeReplications()
+	c.Assert(kv.LoadRangeReplications(replications), IsNil)
+	loaded := replications.GetRangeReplications()
+	c.Assert(loaded, HasLen, 1)
+	// An empty location labels list, which disables the isolation, is kept.
+	c.Assert(loaded[0], DeepEquals, r2)
+}
@@ -16,6 +16,7 @@ package core
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 	"time"
 
@@ -41,6 +42,44 @@ type StoreInfo struct {
 	leaderWeight     float64
 	regionWeight     float64
 	overloaded       func() bool
+	snapshotStats    SnapshotStats
+	// maintenanceDeadline is the time when the maintenance of the store ends.
+	maintenanceDeadline time.Time
+}
+
+const (
+	// MinSnapshotCount is the min number of the snapshots applied by a store
+	// before its snapshot throughput is compared with the other stores.
+	MinSnapshotCount = 3
+	// SlowSnapshotRatio is the ratio to the median of the snapshot throughput
+	// of the stores, under which a store is slow at applying snapshots.
+	SlowSnapshotRatio = 0.25
+	// snapshotThroughputWeight is the weight of the latest snapshot in the
+	// moving average of the snapshot throughput.
+	snapshotThroughputWeight = 0.3
+	// SnapshotStatsExpiry is the time after which the snapshot statistics of a
+	// store expire if it applies no snapshot. A slow store gets no snapshot, so
+	// its statistics expire to let it be measured again.
+	SnapshotStatsExpiry = 10 * time.Minute
+)
+
+// SnapshotStats is the statistics of the snapshots applied by a store, which
+// is measured by PD from the time the add-peer steps on the store take.
+type SnapshotStats struct {
+	Count         uint64
+	TotalBytes    uint64
+	TotalDuration time.Duration
+	// Throughput is the moving average of the bytes applied per second.
+	Throughput float64
+	// Slow is true if the store is much slower than the other stores at
+	// applying snapshots.
+	Slow bool
+	// LastTime is the time the store applied the last snapshot.
+	LastTime time.Time
+}
+
+func (s *SnapshotStats) isExpired(now time.Time) bool {
+	return now.Sub(s.LastTime) > SnapshotStatsExpiry
 }
 
 // NewStoreInfo creates StoreInfo with meta data.
@@ -60,18 +99,20 @@ func NewStoreInfo(store *metapb.Store, opts ...StoreCreateOption) *StoreInfo {
 // Clone creates a copy of current StoreInfo.
 func (s *StoreInfo) Clone(opts ...StoreCreateOption) *StoreInfo {
 	store := &StoreInfo{
-		meta:             s.meta,
-		stats:            s.stats,
-		blocked:          s.blocked,
-		leaderCount:      s.leaderCount,
-		regionCount:      s.regionCount,
-		leaderSize:       s.leaderSize,
-		regionSize:       s.regionSize,
-		pendingPeerCount: s.pendingPeerCount,
-		lastHeartbeatTS:  s.lastHeartbeatTS,
-		leaderWeight:     s.leaderWeight,
-		regionWeight:     s.regionWeight,
-		overloaded:       s.overloaded,
+		meta:                s.meta,
+		stats:               s.stats,
+		blocked:             s.blocked,
+		leaderCount:         s.leaderCount,
+		regionCount:         s.regionCount,
+		leaderSize:          s.leaderSize,
+		regionSize:          s.regionSize,
+		pendingPeerCount:    s.pendingPeerCount,
+		lastHeartbeatTS:     s.lastHeartbeatTS,
+		leaderWeight:        s.leaderWeight,
+		regionWeight:        s.regionWeight,
+		overloaded:          s.overloaded,
+		snapshotStats:       s.snapshotStats,
+		maintenanceDeadline: s.maintenanceDeadline,
 	}
 
 	for _, opt := range opts {
@@ -85,6 +126,19 @@ func (s *StoreInfo) IsBlocked() bool {
 	return s.blocked
 }
 
+// IsInMaintenance returns if the store is in maintenance. A store in
+// maintenance keeps its peers, but receives no new peers or leaders, and its
+// down peers are not replaced.
+func (s *StoreInfo) IsInMaintenance() bool {
+	return time.Now().Before(s.maintenanceDeadline)
+}
+
+// GetMaintenanceDeadline returns the time when the maintenance of the store
+// ends, which is zero if the store has never been in maintenance.
+func (s *StoreInfo) GetMaintenanceDeadline() time.Time {
+	return s.maintenanceDeadline
+}
+
 // IsOverloaded returns if the store is overloaded.
 func (s *StoreInfo) IsOverloaded() bool {
 	if s.overloaded == nil {
@@ -93,6 +147,18 @@ func (s *StoreInfo) IsOverloaded() bool {
 	return s.overloaded()
 }
 
+// IsSnapshotSlow returns if the store is much slower than the other stores
+// at applying snapshots.
+func (s *StoreInfo) IsSnapshotSlow() bool {
+	return s.snapshotStats.Slow && !s.snapshotStats.isExpired(time.Now())
+}
+
+// GetSnapshotStats returns the statistics of the snapshots applied by the
+// store.
+func (s *StoreInfo) GetSnapshotStats() SnapshotStats {
+	return s.snapshotStats
+}
+
 // IsUp checks if the store's state is Up.
 func (s *StoreInfo) IsUp() bool {
 	return s.GetState() == metapb.StoreState_Up
@@ -530,6 +596,60 @@ func (s *StoresInfo) AttachOverloadStatus(storeID uint64, f func() bool) {
 	}
 }
 
+// ObserveSnapshot records that a store applies a snapshot of size bytes in the
+// duration, and updates which stores are slow at applying snapshots.
+func (s *StoresInfo) ObserveSnapshot(storeID uint64, size uint64, duration time.Duration) {
+	s.observeSnapshot(storeID, size, duration, time.Now())
+}
+
+func (s *StoresInfo) observeSnapshot(storeID uint64, size uint64, duration time.Duration, now time.Time) {
+	store, ok := s.stores[storeID]
+	if !ok || duration <= 0 {
+		return
+	}
+	stats := store.snapshotStats
+	// The expired statistics are measured again from scratch.
+	if stats.isExpired(now) {
+		stats = SnapshotStats{}
+	}
+	throughput := float64(size) / duration.Seconds()
+	if stats.Count == 0 {
+		stats.Throughput = throughput
+	} else {
+		stats.Throughput += (throughput - stats.Throughput) * snapshotThroughputWeight
+	}
+	stats.Count++
+	stats.TotalBytes += size
+	stats.TotalDuration += duration
+	stats.LastTime = now
+	s.stores[storeID] = store.Clone(SetSnapshotStats(stats))
+	s.updateSnapshotSlow(now)
+}
+
+// updateSnapshotSlow marks the stores whose snapshot throughput is lower than
+// SlowSnapshotRatio of the median of the stores as slow.
+func (s *StoresInfo) updateSnapshotSlow(now time.Time) {
+	var throughputs []float64
+	for _, store := range s.stores {
+		if store.snapshotStats.Count >= MinSnapshotCount && !store.snapshotStats.isExpired(now) {
+			throughputs = append(throughputs, store.snapshotStats.Throughput)
+		}
+	}
+	var median float64
+	if len(throughputs) > 1 {
+		sort.Float64s(throughputs)
+		median = throughputs[len(throughputs)/2]
+	}
+	for id, store := range s.stores {
+		stats := store.snapshotStats
+		slow := stats.Count >= MinSnapshotCount && stats.Throughput < median*SlowSnapshotRatio
+		if slow != stats.Slow {
+			stats.Slow = slow
+			s.stores[id] = store.Clone(SetSnapshotStats(stats))
+		}
+	}
+}
+
 // GetStores gets a complete set of StoreInfo.
 func (s *StoresInfo) GetStores() []*StoreInfo {
 	stores := make([]*StoreInfo, 0, len(s.stores))
@@ -128,6 +128,21 @@ func SetStoreStats(stats *pdpb.StoreStats) StoreCreateOption {
 	}
 }
 
+// SetSnapshotStats sets the snapshot statistics for the store.
+func SetSnapshotStats(stats SnapshotStats) StoreCreateOption {
+	return func(store *StoreInfo) {
+		store.snapshotStats = stats
+	}
+}
+
+// SetMaintenanceDeadline sets the time when the maintenance of the store ends.
+// A zero time means the store is not in maintenance.
+func SetMaintenanceDeadline(deadline time.Time) StoreCreateOption {
+	return func(store *StoreInfo) {
+		store.maintenanceDeadline = deadline
+	}
+}
+
 // SetOverloadStatus sets the overload status for the store.
 func SetOverloadStatus(f func() bool) StoreCreateOption {
 	return func(store *StoreInfo) {
@@ -0,0 +1,52 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+)
+
+var _ = Suite(&testStoreSuite{})
+
+type testStoreSuite struct{}
+
+func (s *testStoreSuite) TestSnapshotStatsExpiry(c *C) {
+	stores := NewStoresInfo()
+	for id := uint64(1); id <= 4; id++ {
+		stores.SetStore(NewStoreInfo(&metapb.Store{Id: id}))
+	}
+	start := time.Now()
+	for i := 0; i < MinSnapshotCount; i++ {
+		for id := uint64(1); id <= 3; id++ {
+			stores.observeSnapshot(id, 100<<20, time.Second, start)
+		}
+		stores.observeSnapshot(4, 100<<20, 10*time.Second, start)
+	}
+	c.Assert(stores.GetStore(4).snapshotStats.Slow, IsTrue)
+
+	// The slow store gets no snapshot, and its statistics expire.
+	c.Assert(stores.GetStore(4).snapshotStats.isExpired(start.Add(SnapshotStatsExpiry/2)), IsFalse)
+	c.Assert(stores.GetStore(4).snapshotStats.isExpired(start.Add(2*SnapshotStatsExpiry)), IsTrue)
+
+	// It is measured again from scratch after the statistics expire.
+	now := start.Add(2 * SnapshotStatsExpiry)
+	stores.observeSnapshot(4, 100<<20, time.Second, now)
+	stats := stores.GetStore(4).snapshotStats
+	c.Assert(stats.Count, Equals, uint64(1))
+	c.Assert(stats.Slow, IsFalse)
+	c.Assert(stats.LastTime, Equals, now)
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/server/schedule"
+)
+
+// dryRunCluster is the cluster used by the checkers and schedulers. In dry-run
+// mode, the operators they generate are only recorded, so the new peers in
+// them are not allocated IDs.
+type dryRunCluster struct {
+	*clusterInfo
+}
+
+func newDryRunCluster(c *clusterInfo) *dryRunCluster {
+	return &dryRunCluster{clusterInfo: c}
+}
+
+// AllocPeer allocs a new peer on a store, or returns a peer without ID in
+// dry-run mode.
+func (c *dryRunCluster) AllocPeer(storeID uint64) (*metapb.Peer, error) {
+	if c.IsDryRunEnabled() {
+		return &metapb.Peer{StoreId: storeID}, nil
+	}
+	return c.clusterInfo.AllocPeer(storeID)
+}
+
+// isDryRunOperator returns true if the operator adds a peer without ID, which
+// is generated in dry-run mode and should never be executed.
+func isDryRunOperator(op *schedule.Operator) bool {
+	for i := 0; i < op.Len(); i++ {
+		var peerID uint64
+		switch step := op.Step(i).(type) {
+		case schedule.AddPeer:
+			peerID = step.PeerID
+		case schedule.AddLearner:
+			peerID = step.PeerID
+		case schedule.AddLightPeer:
+			peerID = step.PeerID
+		case schedule.AddLightLearner:
+			peerID = step.PeerID
+		default:
+			continue
+		}
+		if peerID == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// followerReadMetadataKey is set by the client to allow the region and
+	// store lookups to be answered by a follower, whose value is the max
+	// staleness allowed.
+	followerReadMetadataKey = "pd-follower-read"
+	// followerReadStalenessMetadataKey is set in the header of the responses
+	// answered by a follower, whose value is the staleness of the metadata.
+	followerReadStalenessMetadataKey = "pd-follower-read-staleness"
+)
+
+// checkFollowerRead checks whether the request should be answered by the
+// follower. It returns an error if the follower read is requested but the
+// regions synchronized from the leader are too stale to answer it.
+func (s *Server) checkFollowerRead(ctx context.Context, header *pdpb.RequestHeader) (bool, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false, nil
+	}
+	values := md.Get(followerReadMetadataKey)
+	if len(values) == 0 || s.IsLeader() {
+		return false, nil
+	}
+	maxStaleness, err := time.ParseDuration(values[0])
+	if err != nil {
+		return false, status.Errorf(codes.InvalidArgument, "invalid max staleness %s", values[0])
+	}
+	if s.isClosed() {
+		return false, status.Errorf(codes.Unavailable, "server is closed")
+	}
+	if header.GetClusterId() != s.clusterID {
+		return false, status.Errorf(codes.FailedPrecondition, "mismatch cluster id, need %d but got %d", s.clusterID, header.GetClusterId())
+	}
+	staleness, ok := s.cluster.regionSyncer.Staleness()
+	if !ok {
+		return false, status.Errorf(codes.Unavailable, "not synchronizing regions with leader")
+	}
+	if staleness > maxStaleness {
+		return false, status.Errorf(codes.Unavailable, "regions are %s stale, exceeding %s", staleness, maxStaleness)
+	}
+	if err := grpc.SetHeader(ctx, metadata.Pairs(followerReadStalenessMetadataKey, staleness.String())); err != nil {
+		return false, status.Errorf(codes.Unknown, err.Error())
+	}
+	return true, nil
+}
+
+// followerRegionResponse returns the region answered by the follower. As the
+// leaders of the regions are not synchronized to the follower, the leader is
+// left nil, which is the same as the leader answering a region whose leader is
+// unknown. The region may not be synchronized yet if it is not found, so an
+// error is returned to let the client ask the leader instead.
+func (s *Server) followerRegionResponse(region *metapb.Region) (*pdpb.GetRegionResponse, error) {
+	if region == nil {
+		return nil, status.Errorf(codes.NotFound, "region not found in the synchronized regions")
+	}
+	return &pdpb.GetRegionResponse{
+		Header: s.header(),
+		Region: region,
+	}, nil
+}
@@ -27,7 +27,9 @@ import (
 	"github.com/pingcap/pd/server/core"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -38,7 +40,7 @@ import (
 var notLeaderError = status.Errorf(codes.Unavailable, "not leader")
 
 // GetMembers implements gRPC PDServer.
-func (s *Server) GetMembers(context.Context, *pdpb.GetMembersRequest) (*pdpb.GetMembersResponse, error) {
+func (s *Server) GetMembers(ctx context.Context, request *pdpb.GetMembersRequest) (*pdpb.GetMembersResponse, error) {
 	if s.isClosed() {
 		return nil, status.Errorf(codes.Unknown, "server not started")
 	}
@@ -56,6 +58,20 @@ func (s *Server) GetMembers(context.Context, *pdpb.GetMembersRequest) (*pdpb.Get
 		}
 	}
 
+	if s.cfg.EnableLocalTSO {
+		leaders, err := s.GetLocalTSOLeaders()
+		if err != nil {
+			return nil, status.Errorf(codes.Unknown, err.Error())
+		}
+		md := metadata.MD{}
+		for zone, leader := range leaders {
+			md.Append(localTSOLeadersMetadataKey, formatLocalTSOLeader(zone, leader))
+		}
+		if err = grpc.SetHeader(ctx, md); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
 	return &pdpb.GetMembersResponse{
 		Header:     s.header(),
 		Members:    members,
@@ -66,6 +82,21 @@ func (s *Server) GetMembers(context.Context, *pdpb.GetMembersRequest) (*pdpb.Get
 
 // Tso implements gRPC PDServer.
 func (s *Server) Tso(stream pdpb.PD_TsoServer) error {
+	var (
+		zone                string
+		proxy               bool
+		suffixBitsSupported bool
+	)
+	if md, ok := metadata.FromIncomingContext(stream.Context()); ok {
+		if zones := md.Get(tsoZoneMetadataKey); len(zones) > 0 {
+			zone = zones[0]
+		}
+		proxy = len(md.Get(tsoProxyMetadataKey)) > 0
+		suffixBitsSupported = len(md.Get(tsoSuffixBitsSupportedMetadataKey)) > 0
+	}
+	// The header is sent along with the first response, as the suffix bits of
+	// the forwarded requests are decided by the leader.
+	headerSet := false
 	for {
 		request, err := stream.Recv()
 		if err == io.EOF {
@@ -75,14 +106,37 @@ func (s *Server) Tso(stream pdpb.PD_TsoServer) error {
 			return errors.WithStack(err)
 		}
 		start := time.Now()
-		if err = s.validateRequest(request.GetHeader()); err != nil {
-			return err
-		}
 		count := request.GetCount()
-		ts, err := s.getRespTS(count)
+		var ts pdpb.Timestamp
+		suffixBits := s.tsoSuffixBits()
+		if zone == "" && proxy && !s.IsLeader() {
+			if err = s.validateProxyTSORequest(request.GetHeader()); err != nil {
+				return err
+			}
+			ts, suffixBits, err = s.tsoProxy.getRespTS(stream.Context(), count)
+		} else if zone == "" {
+			if err = s.validateRequest(request.GetHeader()); err != nil {
+				return err
+			}
+			ts, err = s.getRespTS(count)
+		} else {
+			if err = s.validateLocalTSORequest(request.GetHeader(), zone); err != nil {
+				return err
+			}
+			ts, err = s.localTSO.getRespTS(count)
+		}
 		if err != nil {
 			return status.Errorf(codes.Unknown, err.Error())
 		}
+		if suffixBits > 0 && !suffixBitsSupported {
+			return status.Errorf(codes.FailedPrecondition, "the client does not support the tso suffix bits used by the local tso, please upgrade it")
+		}
+		if !headerSet && suffixBits > 0 {
+			if err := stream.SetHeader(metadata.Pairs(tsoSuffixBitsMetadataKey, strconv.Itoa(int(suffixBits)))); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		headerSet = true
 		response := &pdpb.TsoResponse{
 			Header:    s.header(),
 			Timestamp: &ts,
@@ -153,6 +207,23 @@ func (s *Server) AllocID(ctx context.Context, request *pdpb.AllocIDRequest) (*pd
 
 // GetStore implements gRPC PDServer.
 func (s *Server) GetStore(ctx context.Context, request *pdpb.GetStoreRequest) (*pdpb.GetStoreResponse, error) {
+	if ok, err := s.checkFollowerRead(ctx, request.GetHeader()); err != nil {
+		return nil, err
+	} else if ok {
+		// The stores are loaded from etcd, so the stats are not available.
+		store := &metapb.Store{}
+		ok, err = s.kv.LoadStore(request.GetStoreId(), store)
+		if err != nil {
+			return nil, status.Errorf(codes.Unknown, err.Error())
+		}
+		if !ok {
+			return nil, status.Errorf(codes.Unknown, "invalid store ID %d, not found", request.GetStoreId())
+		}
+		return &pdpb.GetStoreResponse{
+			Header: s.header(),
+			Store:  store,
+		}, nil
+	}
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
@@ -393,6 +464,11 @@ func (s *Server) RegionHeartbeat(stream pdpb.PD_RegionHeartbeatServer) error {
 
 // GetRegion implements gRPC PDServer.
 func (s *Server) GetRegion(ctx context.Context, request *pdpb.GetRegionRequest) (*pdpb.GetRegionResponse, error) {
+	if ok, err := s.checkFollowerRead(ctx, request.GetHeader()); err != nil {
+		return nil, err
+	} else if ok {
+		return s.followerRegionResponse(s.cluster.regionSyncer.GetSyncedRegionByKey(request.GetRegionKey()))
+	}
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
@@ -411,6 +487,11 @@ func (s *Server) GetRegion(ctx context.Context, request *pdpb.GetRegionRequest)
 
 // GetPrevRegion implements gRPC PDServer
 func (s *Server) GetPrevRegion(ctx context.Context, request *pdpb.GetRegionRequest) (*pdpb.GetRegionResponse, error) {
+	if ok, err := s.checkFollowerRead(ctx, request.GetHeader()); err != nil {
+		return nil, err
+	} else if ok {
+		return s.followerRegionResponse(s.cluster.regionSyncer.GetSyncedPrevRegionByKey(request.GetRegionKey()))
+	}
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
@@ -430,6 +511,11 @@ func (s *Server) GetPrevRegion(ctx context.Context, request *pdpb.GetRegionReque
 
 // GetRegionByID implements gRPC PDServer.
 func (s *Server) GetRegionByID(ctx context.Context, request *pdpb.GetRegionByIDRequest) (*pdpb.GetRegionResponse, error) {
+	if ok, err := s.checkFollowerRead(ctx, request.GetHeader()); err != nil {
+		return nil, err
+	} else if ok {
+		return s.followerRegionResponse(s.cluster.regionSyncer.GetSyncedRegionByID(request.GetRegionId()))
+	}
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
@@ -449,6 +535,16 @@ func (s *Server) GetRegionByID(ctx context.Context, request *pdpb.GetRegionByIDR
 
 // ScanRegions implements gRPC PDServer.
 func (s *Server) ScanRegions(ctx context.Context, request *pdpb.ScanRegionsRequest) (*pdpb.ScanRegionsResponse, error) {
+	if ok, err := s.checkFollowerRead(ctx, request.GetHeader()); err != nil {
+		return nil, err
+	} else if ok {
+		resp := &pdpb.ScanRegionsResponse{Header: s.header()}
+		for _, r := range s.cluster.regionSyncer.ScanSyncedRegions(request.GetStartKey(), int(request.GetLimit())) {
+			resp.Regions = append(resp.Regions, r)
+			resp.Leaders = append(resp.Leaders, &metapb.Peer{})
+		}
+		return resp, nil
+	}
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
@@ -758,6 +854,30 @@ func (s *Server) validateRequest(header *pdpb.RequestHeader) error {
 	return nil
 }
 
+// validateLocalTSORequest checks if the server is the local tso leader of the
+// zone, which is not necessarily the leader of the cluster.
+func (s *Server) validateLocalTSORequest(header *pdpb.RequestHeader, zone string) error {
+	if s.isClosed() || s.localTSO == nil || s.localTSO.zone != zone || s.localTSO.getTSO() == nil {
+		return status.Errorf(codes.Unavailable, "not local tso leader of zone %s", zone)
+	}
+	if header.GetClusterId() != s.clusterID {
+		return status.Errorf(codes.FailedPrecondition, "mismatch cluster id, need %d but got %d", s.clusterID, header.GetClusterId())
+	}
+	return nil
+}
+
+// validateProxyTSORequest checks if the server is able to forward the tso
+// request to the leader.
+func (s *Server) validateProxyTSORequest(header *pdpb.RequestHeader) error {
+	if s.isClosed() {
+		return status.Errorf(codes.Unavailable, "server is closed")
+	}
+	if header.GetClusterId() != s.clusterID {
+		return status.Errorf(codes.FailedPrecondition, "mismatch cluster id, need %d but got %d", s.clusterID, header.GetClusterId())
+	}
+	return nil
+}
+
 func (s *Server) header() *pdpb.ResponseHeader {
 	return &pdpb.ResponseHeader{ClusterId: s.clusterID}
 }
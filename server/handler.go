@@ -90,6 +90,88 @@ func (h *Handler) GetSchedulers() ([]string, error) {
 	return c.getSchedulers(), nil
 }
 
+// GetOperatorAuditRecords returns the persisted records of the operators
+// selected by the filter.
+func (h *Handler) GetOperatorAuditRecords(filter schedule.OperatorAuditFilter) ([]*schedule.OperatorAuditRecord, error) {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return nil, err
+	}
+	return c.opController.GetAuditLog().Query(filter)
+}
+
+// SubscribeOperatorEvents adds a subscriber of the operator events selected by
+// the filter. The subscriber should be removed by UnsubscribeOperatorEvents.
+func (h *Handler) SubscribeOperatorEvents(filter schedule.OperatorEventFilter) (*schedule.OperatorEventSubscription, error) {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return nil, err
+	}
+	return c.opController.SubscribeEvents(filter), nil
+}
+
+// UnsubscribeOperatorEvents removes a subscriber of the operator events.
+func (h *Handler) UnsubscribeOperatorEvents(s *schedule.OperatorEventSubscription) error {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return err
+	}
+	c.opController.UnsubscribeEvents(s)
+	return nil
+}
+
+// GetDryRunOperators returns the operators recorded in dry-run mode.
+func (h *Handler) GetDryRunOperators() ([]*schedule.DryRunRecord, error) {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return nil, err
+	}
+	return c.dryRunRecorder.GetRecords(), nil
+}
+
+// ClearDryRunOperators removes the operators recorded in dry-run mode.
+func (h *Handler) ClearDryRunOperators() error {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return err
+	}
+	c.dryRunRecorder.Clear()
+	return nil
+}
+
+// GetPausedSchedulers returns all names of paused schedulers.
+func (h *Handler) GetPausedSchedulers() ([]string, error) {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return nil, err
+	}
+	return c.getPausedSchedulers(), nil
+}
+
+// PauseScheduler pauses a scheduler for a duration.
+func (h *Handler) PauseScheduler(name string, d time.Duration) error {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return err
+	}
+	if err = c.pauseScheduler(name, d); err != nil {
+		log.Error("can not pause scheduler", zap.String("scheduler-name", name), zap.Error(err))
+	}
+	return err
+}
+
+// ResumeScheduler resumes a paused scheduler.
+func (h *Handler) ResumeScheduler(name string) error {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return err
+	}
+	if err = c.resumeScheduler(name); err != nil {
+		log.Error("can not resume scheduler", zap.String("scheduler-name", name), zap.Error(err))
+	}
+	return err
+}
+
 // GetStores returns all stores in the cluster.
 func (h *Handler) GetStores() ([]*core.StoreInfo, error) {
 	cluster := h.s.GetRaftCluster()
@@ -176,7 +258,7 @@ func (h *Handler) AddScheduler(name string, args ...string) error {
 	if err != nil {
 		return err
 	}
-	s, err := schedule.CreateScheduler(name, c.opController, args...)
+	s, err := schedule.CreateScheduler(name, c.opController, c.cluster.kv, args...)
 	if err != nil {
 		return err
 	}
@@ -203,6 +285,52 @@ func (h *Handler) RemoveScheduler(name string) error {
 	return err
 }
 
+// GetSchedulerExplanations returns at most limit latest scheduling rounds of a
+// scheduler.
+func (h *Handler) GetSchedulerExplanations(name string, limit int) ([]*schedule.ExplainRound, error) {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return nil, err
+	}
+	return c.getSchedulerExplanations(name, limit)
+}
+
+// GetSchedulerConfig returns the config of a scheduler.
+func (h *Handler) GetSchedulerConfig(name string) (interface{}, error) {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return nil, err
+	}
+	return c.getSchedulerConfig(name)
+}
+
+// SetSchedulerConfig updates the config of a scheduler by JSON data.
+func (h *Handler) SetSchedulerConfig(name string, data []byte) error {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return err
+	}
+	if err = c.setSchedulerConfig(name, data); err != nil {
+		log.Error("can not set scheduler config", zap.String("scheduler-name", name), zap.Error(err))
+	}
+	return err
+}
+
+// RemoveSchedulerStore removes a store from a scheduler working on a set of
+// stores. The scheduler is removed if there is no store left.
+func (h *Handler) RemoveSchedulerStore(name string, storeID uint64) error {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return err
+	}
+	if err = c.removeSchedulerStore(name, storeID); err != nil {
+		log.Error("can not remove store from scheduler", zap.String("scheduler-name", name), zap.Uint64("store-id", storeID), zap.Error(err))
+	} else if err = h.opt.persist(c.cluster.kv); err != nil {
+		log.Error("can not persist scheduler config", zap.Error(err))
+	}
+	return err
+}
+
 // AddBalanceLeaderScheduler adds a balance-leader-scheduler.
 func (h *Handler) AddBalanceLeaderScheduler() error {
 	return h.AddScheduler("balance-leader")
@@ -305,7 +433,7 @@ func (h *Handler) RemoveOperator(regionID uint64) error {
 		return ErrOperatorNotFound
 	}
 
-	c.opController.RemoveOperator(op)
+	c.opController.CancelOperator(op)
 	return nil
 }
 
@@ -327,6 +455,16 @@ func (h *Handler) GetWaitingOperators() ([]*schedule.Operator, error) {
 	return c.opController.GetWaitingOperators(), nil
 }
 
+// GetWaitingOperatorStats returns the status of the waiting operators of each
+// priority class.
+func (h *Handler) GetWaitingOperatorStats() ([]schedule.WaitingClassStats, error) {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return nil, err
+	}
+	return c.opController.GetWaitingOperatorStats(), nil
+}
+
 // GetAdminOperators returns the running admin operators.
 func (h *Handler) GetAdminOperators() ([]*schedule.Operator, error) {
 	return h.GetOperatorsOfKind(schedule.OpAdmin)
@@ -366,18 +504,28 @@ func (h *Handler) GetHistory(start time.Time) ([]schedule.OperatorHistory, error
 	return c.opController.GetHistory(start), nil
 }
 
-// SetAllStoresLimit is used to set limit of all stores.
-func (h *Handler) SetAllStoresLimit(rate float64) error {
+// SetAllStoresLimit is used to set the limits of the types of all stores, or
+// of all types if no type is given.
+func (h *Handler) SetAllStoresLimit(rate float64, types ...schedule.StoreLimitType) error {
 	c, err := h.getCoordinator()
 	if err != nil {
 		return err
 	}
-	c.opController.SetAllStoresLimit(rate)
-	return nil
+	return c.opController.SetAllStoresLimit(rate, types...)
+}
+
+// SetLabelStoresLimit is used to set the limits of the types of the stores
+// with the label, or of all types if no type is given.
+func (h *Handler) SetLabelStoresLimit(key, value string, rate float64, types ...schedule.StoreLimitType) error {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return err
+	}
+	return c.opController.SetLabelStoresLimit(key, value, rate, types...)
 }
 
-// GetAllStoresLimit is used to get limit of all stores.
-func (h *Handler) GetAllStoresLimit() (map[uint64]float64, error) {
+// GetAllStoresLimit is used to get the status of the limits of all stores.
+func (h *Handler) GetAllStoresLimit() (map[uint64]map[string]*schedule.StoreLimitStatus, error) {
 	c, err := h.getCoordinator()
 	if err != nil {
 		return nil, err
@@ -385,14 +533,14 @@ func (h *Handler) GetAllStoresLimit() (map[uint64]float64, error) {
 	return c.opController.GetAllStoresLimit(), nil
 }
 
-// SetStoreLimit is used to set the limit of a store.
-func (h *Handler) SetStoreLimit(storeID uint64, rate float64) error {
+// SetStoreLimit is used to set the limits of the types of a store, or of all
+// types if no type is given.
+func (h *Handler) SetStoreLimit(storeID uint64, rate float64, types ...schedule.StoreLimitType) error {
 	c, err := h.getCoordinator()
 	if err != nil {
 		return err
 	}
-	c.opController.SetStoreLimit(storeID, rate)
-	return nil
+	return c.opController.SetStoreLimit(storeID, rate, types...)
 }
 
 // AddTransferLeaderOperator adds an operator to transfer leader to the store.
@@ -402,21 +550,28 @@ func (h *Handler) AddTransferLeaderOperator(regionID uint64, storeID uint64) err
 		return err
 	}
 
+	op, err := createTransferLeaderOperator(c, regionID, storeID)
+	if err != nil {
+		return err
+	}
+	if ok := c.opController.AddOperator(op); !ok {
+		return errors.WithStack(ErrAddOperator)
+	}
+	return nil
+}
+
+func createTransferLeaderOperator(c *coordinator, regionID uint64, storeID uint64) (*schedule.Operator, error) {
 	region := c.cluster.GetRegion(regionID)
 	if region == nil {
-		return ErrRegionNotFound(regionID)
+		return nil, ErrRegionNotFound(regionID)
 	}
 
 	newLeader := region.GetStoreVoter(storeID)
 	if newLeader == nil {
-		return errors.Errorf("region has no voter in store %v", storeID)
+		return nil, errors.Errorf("region has no voter in store %v", storeID)
 	}
 
-	op := schedule.CreateTransferLeaderOperator("admin-transfer-leader", region, region.GetLeader().GetStoreId(), newLeader.GetStoreId(), schedule.OpAdmin)
-	if ok := c.opController.AddOperator(op); !ok {
-		return errors.WithStack(ErrAddOperator)
-	}
-	return nil
+	return schedule.CreateTransferLeaderOperator("admin-transfer-leader", region, region.GetLeader().GetStoreId(), newLeader.GetStoreId(), schedule.OpAdmin), nil
 }
 
 // AddTransferRegionOperator adds an operator to transfer region to the stores.
@@ -431,7 +586,7 @@ func (h *Handler) AddTransferRegionOperator(regionID uint64, storeIDs map[uint64
 		return ErrRegionNotFound(regionID)
 	}
 
-	if len(storeIDs) > c.cluster.GetMaxReplicas() {
+	if len(storeIDs) > schedule.GetRegionMaxReplicas(c.cluster, region) {
 		return errors.Errorf("the number of stores is %v, beyond the max replicas", len(storeIDs))
 	}
 
@@ -462,37 +617,41 @@ func (h *Handler) AddTransferPeerOperator(regionID uint64, fromStoreID, toStoreI
 		return err
 	}
 
+	op, err := createTransferPeerOperator(c, regionID, fromStoreID, toStoreID)
+	if err != nil {
+		return err
+	}
+	if ok := c.opController.AddOperator(op); !ok {
+		return errors.WithStack(ErrAddOperator)
+	}
+	return nil
+}
+
+func createTransferPeerOperator(c *coordinator, regionID uint64, fromStoreID, toStoreID uint64) (*schedule.Operator, error) {
 	region := c.cluster.GetRegion(regionID)
 	if region == nil {
-		return ErrRegionNotFound(regionID)
+		return nil, ErrRegionNotFound(regionID)
 	}
 
 	oldPeer := region.GetStorePeer(fromStoreID)
 	if oldPeer == nil {
-		return errors.Errorf("region has no peer in store %v", fromStoreID)
+		return nil, errors.Errorf("region has no peer in store %v", fromStoreID)
 	}
 
 	toStore := c.cluster.GetStore(toStoreID)
 	if toStore == nil {
-		return core.NewStoreNotFoundErr(toStoreID)
+		return nil, core.NewStoreNotFoundErr(toStoreID)
 	}
 	if toStore.IsTombstone() {
-		return errcode.Op("operator.add").AddTo(core.StoreTombstonedErr{StoreID: toStoreID})
+		return nil, errcode.Op("operator.add").AddTo(core.StoreTombstonedErr{StoreID: toStoreID})
 	}
 
 	newPeer, err := c.cluster.AllocPeer(toStoreID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	op, err := schedule.CreateMovePeerOperator("admin-move-peer", c.cluster, region, schedule.OpAdmin, fromStoreID, toStoreID, newPeer.GetId())
-	if err != nil {
-		return err
-	}
-	if ok := c.opController.AddOperator(op); !ok {
-		return errors.WithStack(ErrAddOperator)
-	}
-	return nil
+	return schedule.CreateMovePeerOperator("admin-move-peer", c.cluster, region, schedule.OpAdmin, fromStoreID, toStoreID, newPeer.GetId())
 }
 
 // checkAdminAddPeerOperator checks adminAddPeer operator with given region ID and store ID.
@@ -597,40 +756,44 @@ func (h *Handler) AddMergeRegionOperator(regionID uint64, targetID uint64) error
 		return err
 	}
 
+	ops, err := createMergeRegionOperators(c, regionID, targetID)
+	if err != nil {
+		return err
+	}
+	if ok := c.opController.AddOperator(ops...); !ok {
+		return errors.WithStack(ErrAddOperator)
+	}
+	return nil
+}
+
+func createMergeRegionOperators(c *coordinator, regionID uint64, targetID uint64) ([]*schedule.Operator, error) {
 	region := c.cluster.GetRegion(regionID)
 	if region == nil {
-		return ErrRegionNotFound(regionID)
+		return nil, ErrRegionNotFound(regionID)
 	}
 
 	target := c.cluster.GetRegion(targetID)
 	if target == nil {
-		return ErrRegionNotFound(targetID)
+		return nil, ErrRegionNotFound(targetID)
 	}
 
 	if len(region.GetDownPeers()) > 0 || len(region.GetPendingPeers()) > 0 || len(region.GetLearners()) > 0 ||
-		len(region.GetPeers()) != c.cluster.GetMaxReplicas() {
-		return ErrRegionAbnormalPeer(regionID)
+		len(region.GetPeers()) != schedule.GetRegionMaxReplicas(c.cluster, region) {
+		return nil, ErrRegionAbnormalPeer(regionID)
 	}
 
 	if len(target.GetDownPeers()) > 0 || len(target.GetPendingPeers()) > 0 || len(target.GetLearners()) > 0 ||
-		len(target.GetMeta().GetPeers()) != c.cluster.GetMaxReplicas() {
-		return ErrRegionAbnormalPeer(targetID)
+		len(target.GetMeta().GetPeers()) != schedule.GetRegionMaxReplicas(c.cluster, target) {
+		return nil, ErrRegionAbnormalPeer(targetID)
 	}
 
 	// for the case first region (start key is nil) with the last region (end key is nil) but not adjacent
 	if (bytes.Equal(region.GetStartKey(), target.GetEndKey()) || len(region.GetStartKey()) == 0) &&
 		(bytes.Equal(region.GetEndKey(), target.GetStartKey()) || len(region.GetEndKey()) == 0) {
-		return ErrRegionNotAdjacent
+		return nil, ErrRegionNotAdjacent
 	}
 
-	ops, err := schedule.CreateMergeRegionOperator("admin-merge-region", c.cluster, region, target, schedule.OpAdmin)
-	if err != nil {
-		return err
-	}
-	if ok := c.opController.AddOperator(ops...); !ok {
-		return errors.WithStack(ErrAddOperator)
-	}
-	return nil
+	return schedule.CreateMergeRegionOperator("admin-merge-region", c.cluster, region, target, schedule.OpAdmin)
 }
 
 // AddSplitRegionOperator adds an operator to split a region.
@@ -640,18 +803,25 @@ func (h *Handler) AddSplitRegionOperator(regionID uint64, policy string) error {
 		return err
 	}
 
-	region := c.cluster.GetRegion(regionID)
-	if region == nil {
-		return ErrRegionNotFound(regionID)
+	op, err := createSplitRegionOperator(c, regionID, policy)
+	if err != nil {
+		return err
 	}
-
-	op := schedule.CreateSplitRegionOperator("admin-split-region", region, schedule.OpAdmin, policy)
 	if ok := c.opController.AddOperator(op); !ok {
 		return errors.WithStack(ErrAddOperator)
 	}
 	return nil
 }
 
+func createSplitRegionOperator(c *coordinator, regionID uint64, policy string) (*schedule.Operator, error) {
+	region := c.cluster.GetRegion(regionID)
+	if region == nil {
+		return nil, ErrRegionNotFound(regionID)
+	}
+
+	return schedule.CreateSplitRegionOperator("admin-split-region", region, schedule.OpAdmin, policy), nil
+}
+
 // AddScatterRegionOperator adds an operator to scatter a region.
 func (h *Handler) AddScatterRegionOperator(regionID uint64) error {
 	c, err := h.getCoordinator()
@@ -659,12 +829,7 @@ func (h *Handler) AddScatterRegionOperator(regionID uint64) error {
 		return err
 	}
 
-	region := c.cluster.GetRegion(regionID)
-	if region == nil {
-		return ErrRegionNotFound(regionID)
-	}
-
-	op, err := c.regionScatterer.Scatter(region)
+	op, err := createScatterRegionOperator(c, regionID)
 	if err != nil {
 		return err
 	}
@@ -678,6 +843,80 @@ func (h *Handler) AddScatterRegionOperator(regionID uint64) error {
 	return nil
 }
 
+// createScatterRegionOperator creates an operator to scatter a region. It
+// returns nil if the region does not need to be scattered.
+func createScatterRegionOperator(c *coordinator, regionID uint64) (*schedule.Operator, error) {
+	region := c.cluster.GetRegion(regionID)
+	if region == nil {
+		return nil, ErrRegionNotFound(regionID)
+	}
+
+	return c.regionScatterer.Scatter(region)
+}
+
+// The names of the admin operators which can be added in a batch.
+const (
+	BatchTransferLeader = "transfer-leader"
+	BatchTransferPeer   = "transfer-peer"
+	BatchMergeRegion    = "merge-region"
+	BatchSplitRegion    = "split-region"
+	BatchScatterRegion  = "scatter-region"
+)
+
+// AdminOperatorRequest is a request to add an admin operator in a batch. The
+// fields used depend on the name of the operator.
+type AdminOperatorRequest struct {
+	Name           string
+	RegionID       uint64
+	TargetRegionID uint64
+	FromStoreID    uint64
+	ToStoreID      uint64
+	Policy         string
+}
+
+// AddOperatorBatch adds the admin operators atomically: all of them are
+// added, or none of them is added. It returns nil if the operators are added,
+// otherwise the reasons why the requests are rejected, which are nil for the
+// requests without problems found.
+func (h *Handler) AddOperatorBatch(reqs []*AdminOperatorRequest) ([]error, error) {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([][]*schedule.Operator, len(reqs))
+	reasons := make([]error, len(reqs))
+	rejected := false
+	for i, req := range reqs {
+		var op *schedule.Operator
+		switch req.Name {
+		case BatchTransferLeader:
+			op, err = createTransferLeaderOperator(c, req.RegionID, req.ToStoreID)
+		case BatchTransferPeer:
+			op, err = createTransferPeerOperator(c, req.RegionID, req.FromStoreID, req.ToStoreID)
+		case BatchMergeRegion:
+			groups[i], err = createMergeRegionOperators(c, req.RegionID, req.TargetRegionID)
+		case BatchSplitRegion:
+			op, err = createSplitRegionOperator(c, req.RegionID, req.Policy)
+		case BatchScatterRegion:
+			op, err = createScatterRegionOperator(c, req.RegionID)
+		default:
+			err = errors.Errorf("operator %s can not be added in a batch", req.Name)
+		}
+		if err != nil {
+			reasons[i], rejected = err, true
+			continue
+		}
+		if op != nil {
+			groups[i] = []*schedule.Operator{op}
+		}
+	}
+	if rejected {
+		return reasons, nil
+	}
+	return c.opController.AddOperatorBatch(groups...), nil
+}
+
 // GetDownPeerRegions gets the region with down peer.
 func (h *Handler) GetDownPeerRegions() ([]*core.RegionInfo, error) {
 	c := h.s.GetRaftCluster()
@@ -711,6 +950,18 @@ func (h *Handler) GetMissPeerRegions() ([]*core.RegionInfo, error) {
 	return c.cachedCluster.GetRegionStatsByType(statistics.MissPeer), nil
 }
 
+// GetMissLearnerPeerRegions gets the region less than the number of learners
+// required by placement rules.
+func (h *Handler) GetMissLearnerPeerRegions() ([]*core.RegionInfo, error) {
+	c := h.s.GetRaftCluster()
+	if c == nil {
+		return nil, ErrNotBootstrapped
+	}
+	c.RLock()
+	defer c.RUnlock()
+	return c.cachedCluster.GetRegionStatsByType(statistics.MissLearnerPeer), nil
+}
+
 // GetPendingPeerRegions gets the region with pending peer.
 func (h *Handler) GetPendingPeerRegions() ([]*core.RegionInfo, error) {
 	c := h.s.GetRaftCluster()
@@ -732,3 +983,31 @@ func (h *Handler) GetIncorrectNamespaceRegions() ([]*core.RegionInfo, error) {
 	defer c.RUnlock()
 	return c.cachedCluster.GetRegionStatsByType(statistics.IncorrectNamespace), nil
 }
+
+// GetIsolationLevelCounts gets the number of regions of each isolation level,
+// including the levels of the location labels which no region is at.
+func (h *Handler) GetIsolationLevelCounts() (map[string]int, error) {
+	c := h.s.GetRaftCluster()
+	if c == nil {
+		return nil, ErrNotBootstrapped
+	}
+	c.RLock()
+	defer c.RUnlock()
+	counts := c.cachedCluster.GetLabelLevelCounts()
+	for _, label := range c.cachedCluster.GetLocationLabels() {
+		counts[label] += 0
+	}
+	counts[statistics.NoneIsolationLevel] += 0
+	return counts, nil
+}
+
+// GetIsolationLevelRegions gets the regions of the isolation level.
+func (h *Handler) GetIsolationLevelRegions(level string) ([]*core.RegionInfo, error) {
+	c := h.s.GetRaftCluster()
+	if c == nil {
+		return nil, ErrNotBootstrapped
+	}
+	c.RLock()
+	defer c.RUnlock()
+	return c.cachedCluster.GetLabelLevelRegions(level), nil
+}
@@ -268,12 +268,10 @@ func (s *Server) campaignLeader() error {
 	defer s.stopRaftCluster()
 
 	log.Debug("sync timestamp for tso")
-	if err = s.syncTimestamp(); err != nil {
+	if err = s.tso.syncTimestamp(); err != nil {
 		return err
 	}
-	defer s.ts.Store(&atomicObject{
-		physical: zeroTime,
-	})
+	defer s.tso.resetTimestamp()
 
 	s.enableLeader()
 	defer s.disableLeader()
@@ -283,6 +281,8 @@ func (s *Server) campaignLeader() error {
 
 	tsTicker := time.NewTicker(updateTimestampStep)
 	defer tsTicker.Stop()
+	syncTicker := time.NewTicker(localTSOSyncInterval)
+	defer syncTicker.Stop()
 
 	for {
 		select {
@@ -291,8 +291,17 @@ func (s *Server) campaignLeader() error {
 				log.Info("keep alive channel is closed")
 				return nil
 			}
+		case <-s.tso.updateCh:
+			if err = s.tso.updateTimestamp(); err != nil {
+				log.Info("failed to update timestamp")
+				return err
+			}
+		case <-syncTicker.C:
+			if err = s.syncLocalTSO(); err != nil {
+				log.Error("failed to synchronize local tso", zap.Error(err))
+			}
 		case <-tsTicker.C:
-			if err = s.updateTimestamp(); err != nil {
+			if err = s.tso.updateTimestamp(); err != nil {
 				log.Info("failed to update timestamp")
 				return err
 			}
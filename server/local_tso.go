@@ -0,0 +1,438 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	log "github.com/pingcap/log"
+	"github.com/pingcap/pd/pkg/etcdutil"
+	"github.com/pingcap/pd/pkg/logutil"
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/mvcc/mvccpb"
+	"go.uber.org/zap"
+)
+
+const (
+	// localTSOSuffixBits is the number of the low bits of the logical time
+	// which tell the TSO allocators apart when the local TSO is enabled. The
+	// global allocator uses the suffix 0, and the local allocators of the
+	// zones use the suffixes from 1 to 1<<localTSOSuffixBits-1.
+	localTSOSuffixBits = 4
+	// localTSOSyncInterval is the interval to synchronize the global and the
+	// local allocators.
+	localTSOSyncInterval = time.Second
+)
+
+// The gRPC metadata keys used by the local TSO, which should be the same as
+// the ones used by the client.
+const (
+	// tsoZoneMetadataKey is set by the client to request the local timestamps
+	// of the zone.
+	tsoZoneMetadataKey = "pd-tso-zone"
+	// tsoSuffixBitsMetadataKey is set in the header of the Tso stream, so the
+	// client can tell the timestamps of a batch apart.
+	tsoSuffixBitsMetadataKey = "pd-tso-suffix-bits"
+	// tsoSuffixBitsSupportedMetadataKey is set by the client which can handle
+	// the suffix bits. The streams of the other clients are rejected when the
+	// suffix bits are used, as they compute colliding timestamps of a batch.
+	tsoSuffixBitsSupportedMetadataKey = "pd-tso-suffix-bits-supported"
+	// localTSOLeadersMetadataKey is set in the header of GetMembers, whose
+	// values are "<zone>=<client-url>" of the local allocators.
+	localTSOLeadersMetadataKey = "pd-local-tso-leaders"
+)
+
+func (s *Server) getLocalTSORootPath() string {
+	return path.Join(s.rootPath, "tso", "local")
+}
+
+// getLocalTSOSuffixRootPath returns the path reserving the suffixes of the
+// zones, whose keys are the suffixes and values are the zones.
+func (s *Server) getLocalTSOSuffixRootPath() string {
+	return path.Join(s.rootPath, "tso", "local_suffix")
+}
+
+func (s *Server) getGlobalPhysicalPath() string {
+	return path.Join(s.rootPath, "tso", "global_physical")
+}
+
+// loadPhysical loads the physical time saved in the path, or zeroTime if it
+// is not saved yet.
+func (s *Server) loadPhysical(key string) (time.Time, error) {
+	data, err := getValue(s.client, key)
+	if err != nil {
+		return zeroTime, err
+	}
+	if len(data) == 0 {
+		return zeroTime, nil
+	}
+	return parseTimestamp(data)
+}
+
+func (s *Server) savePhysical(key string, ts time.Time, cmp clientv3.Cmp) error {
+	data := uint64ToBytes(uint64(ts.UnixNano()))
+	resp, err := s.txn().If(cmp).Then(clientv3.OpPut(key, string(data))).Commit()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !resp.Succeeded {
+		return errors.New("save physical time failed, maybe we lost leader")
+	}
+	return nil
+}
+
+// syncLocalTSO advances the global timestamps to be greater than the local
+// ones published by the zones, and publishes the global physical time for the
+// local allocators. So a global timestamp is greater than the local ones
+// allocated before the last synchronization, and the other way round.
+func (s *Server) syncLocalTSO() error {
+	if !s.cfg.EnableLocalTSO {
+		return nil
+	}
+	resp, err := etcdutil.EtcdKVGet(s.client, s.getLocalTSORootPath()+"/", clientv3.WithPrefix())
+	if err != nil {
+		return err
+	}
+	var max time.Time
+	for _, kv := range resp.Kvs {
+		if path.Base(string(kv.Key)) != "physical" {
+			continue
+		}
+		ts, err := parseTimestamp(kv.Value)
+		if err != nil {
+			return err
+		}
+		if ts.After(max) {
+			max = ts
+		}
+	}
+	if err = s.tso.advanceTo(max); err != nil {
+		return err
+	}
+	return s.savePhysical(s.getGlobalPhysicalPath(), s.tso.getPhysical(), s.leaderCmp())
+}
+
+// GetLocalTSOLeaders returns the members serving the local timestamps of the
+// zones.
+func (s *Server) GetLocalTSOLeaders() (map[string]*pdpb.Member, error) {
+	resp, err := etcdutil.EtcdKVGet(s.client, s.getLocalTSORootPath()+"/", clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	leaders := make(map[string]*pdpb.Member)
+	for _, kv := range resp.Kvs {
+		key := string(kv.Key)
+		if path.Base(key) != "leader" {
+			continue
+		}
+		leader := &pdpb.Member{}
+		if err := leader.Unmarshal(kv.Value); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		leaders[path.Base(path.Dir(key))] = leader
+	}
+	return leaders, nil
+}
+
+// localTSOAllocator allocates the local timestamps of a zone. The PD members
+// with the same zone elect a leader to serve the local timestamps, whose
+// logical time has the suffix of the zone.
+type localTSOAllocator struct {
+	s    *Server
+	zone string
+	// leader is the member serving the local timestamps of the zone.
+	leader atomic.Value
+	// tso is set when the server is the leader of the zone.
+	tso atomic.Value
+}
+
+func newLocalTSOAllocator(s *Server, zone string) *localTSOAllocator {
+	a := &localTSOAllocator{
+		s:    s,
+		zone: zone,
+	}
+	a.leader.Store(&pdpb.Member{})
+	a.tso.Store((*timestampOracle)(nil))
+	return a
+}
+
+func (a *localTSOAllocator) getRootPath() string {
+	return path.Join(a.s.getLocalTSORootPath(), a.zone)
+}
+
+func (a *localTSOAllocator) getLeaderPath() string {
+	return path.Join(a.getRootPath(), "leader")
+}
+
+func (a *localTSOAllocator) getSuffixPath() string {
+	return path.Join(a.getRootPath(), "suffix")
+}
+
+func (a *localTSOAllocator) leaderCmp() clientv3.Cmp {
+	return clientv3.Compare(clientv3.Value(a.getLeaderPath()), "=", a.s.memberValue)
+}
+
+// getLeader returns the member serving the local timestamps of the zone, or
+// nil if there is none.
+func (a *localTSOAllocator) getLeader() *pdpb.Member {
+	leader := a.leader.Load().(*pdpb.Member)
+	if leader.GetMemberId() == 0 {
+		return nil
+	}
+	return leader
+}
+
+func (a *localTSOAllocator) getTSO() *timestampOracle {
+	return a.tso.Load().(*timestampOracle)
+}
+
+func (a *localTSOAllocator) getRespTS(count uint32) (pdpb.Timestamp, error) {
+	tso := a.getTSO()
+	if tso == nil {
+		return pdpb.Timestamp{}, errors.Errorf("not the local tso leader of zone %s", a.zone)
+	}
+	return tso.getRespTS(count)
+}
+
+func (a *localTSOAllocator) campaignLoop() {
+	defer logutil.LogPanic()
+	defer a.s.serverLoopWg.Done()
+
+	for {
+		if a.s.isClosed() {
+			log.Info("server is closed, return local tso loop", zap.String("zone", a.zone))
+			return
+		}
+
+		leader, rev, err := getLeader(a.s.client, a.getLeaderPath())
+		if err != nil {
+			log.Error("get local tso leader meet error", zap.String("zone", a.zone), zap.Error(err))
+			time.Sleep(200 * time.Millisecond)
+			continue
+		}
+		if leader != nil {
+			if !a.s.isSameLeader(leader) {
+				a.leader.Store(leader)
+				a.watchLeader(rev)
+				a.leader.Store(&pdpb.Member{})
+				continue
+			}
+			// We may meet something wrong in the previous campaign, so we
+			// delete the key and campaign again.
+			log.Warn("the local tso leader has not changed, delete and campaign again", zap.String("zone", a.zone))
+			if _, err = a.s.txn().If(a.leaderCmp()).Then(clientv3.OpDelete(a.getLeaderPath())).Commit(); err != nil {
+				log.Error("delete local tso leader key meet error", zap.String("zone", a.zone), zap.Error(err))
+				time.Sleep(200 * time.Millisecond)
+				continue
+			}
+		}
+
+		if err = a.campaign(); err != nil {
+			log.Error("campaign local tso leader meet error", zap.String("zone", a.zone), zap.Error(err))
+			time.Sleep(200 * time.Millisecond)
+		}
+	}
+}
+
+func (a *localTSOAllocator) watchLeader(revision int64) {
+	watcher := clientv3.NewWatcher(a.s.client)
+	defer watcher.Close()
+
+	ctx, cancel := context.WithCancel(a.s.serverLoopCtx)
+	defer cancel()
+
+	for {
+		rch := watcher.Watch(ctx, a.getLeaderPath(), clientv3.WithRev(revision))
+		for wresp := range rch {
+			if wresp.CompactRevision != 0 {
+				revision = wresp.CompactRevision
+				break
+			}
+			if wresp.Canceled {
+				return
+			}
+			for _, ev := range wresp.Events {
+				if ev.Type == mvccpb.DELETE {
+					return
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
+
+func (a *localTSOAllocator) campaign() error {
+	lessor := clientv3.NewLease(a.s.client)
+	defer lessor.Close()
+
+	ctx, cancel := context.WithTimeout(a.s.client.Ctx(), requestTimeout)
+	leaseResp, err := lessor.Grant(ctx, a.s.cfg.LeaderLease)
+	cancel()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	leaderKey := a.getLeaderPath()
+	// The leader key must not exist, so the CreateRevision is 0.
+	resp, err := a.s.txn().
+		If(clientv3.Compare(clientv3.CreateRevision(leaderKey), "=", 0)).
+		Then(clientv3.OpPut(leaderKey, a.s.memberValue, clientv3.WithLease(leaseResp.ID))).
+		Commit()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !resp.Succeeded {
+		return errors.New("failed to campaign local tso leader, other server may campaign ok")
+	}
+
+	ctx, cancel = context.WithCancel(a.s.serverLoopCtx)
+	defer cancel()
+	ch, err := lessor.KeepAlive(ctx, leaseResp.ID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	suffix, err := a.allocSuffix()
+	if err != nil {
+		return err
+	}
+	tso := newTimestampOracle(a.s.client, path.Join(a.getRootPath(), "timestamp"), a.s.cfg.TsoSaveInterval.Duration, a.leaderCmp, localTSOSuffixBits, suffix)
+	if err = tso.syncTimestamp(); err != nil {
+		return err
+	}
+	// The local timestamps should be greater than the global ones synchronized.
+	if err = a.syncWithGlobal(tso); err != nil {
+		return err
+	}
+
+	a.tso.Store(tso)
+	a.leader.Store(a.s.member)
+	defer func() {
+		a.tso.Store((*timestampOracle)(nil))
+		tso.resetTimestamp()
+		a.leader.Store(&pdpb.Member{})
+	}()
+	log.Info("local tso leader is ready to serve", zap.String("zone", a.zone), zap.Int64("suffix", suffix))
+
+	tsTicker := time.NewTicker(updateTimestampStep)
+	defer tsTicker.Stop()
+	syncTicker := time.NewTicker(localTSOSyncInterval)
+	defer syncTicker.Stop()
+
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				log.Info("local tso keep alive channel is closed", zap.String("zone", a.zone))
+				return nil
+			}
+		case <-tso.updateCh:
+			if err = tso.updateTimestamp(); err != nil {
+				return err
+			}
+		case <-tsTicker.C:
+			if err = tso.updateTimestamp(); err != nil {
+				return err
+			}
+		case <-syncTicker.C:
+			if err = a.syncWithGlobal(tso); err != nil {
+				log.Error("failed to synchronize with global tso", zap.String("zone", a.zone), zap.Error(err))
+			}
+		case <-ctx.Done():
+			log.Info("server is closed", zap.String("zone", a.zone))
+			return nil
+		}
+	}
+}
+
+// allocSuffix returns the suffix of the zone, which is allocated when the zone
+// elects its first leader and is kept since then. Each suffix is reserved by
+// its own key, so the zones campaigning at the same time never share one.
+func (a *localTSOAllocator) allocSuffix() (int64, error) {
+	suffixKey := a.getSuffixPath()
+	data, err := getValue(a.s.client, suffixKey)
+	if err != nil {
+		return 0, err
+	}
+	if data != nil {
+		suffix, err := strconv.ParseInt(string(data), 10, 64)
+		return suffix, errors.WithStack(err)
+	}
+
+	resp, err := etcdutil.EtcdKVGet(a.s.client, a.s.getLocalTSOSuffixRootPath()+"/", clientv3.WithPrefix())
+	if err != nil {
+		return 0, err
+	}
+	used := make(map[string]bool)
+	for _, kv := range resp.Kvs {
+		used[path.Base(string(kv.Key))] = true
+	}
+	for suffix := int64(1); suffix < 1<<localTSOSuffixBits; suffix++ {
+		value := strconv.FormatInt(suffix, 10)
+		if used[value] {
+			continue
+		}
+		reservedKey := path.Join(a.s.getLocalTSOSuffixRootPath(), value)
+		resp, err := a.s.txn().
+			If(clientv3.Compare(clientv3.CreateRevision(suffixKey), "=", 0),
+				clientv3.Compare(clientv3.CreateRevision(reservedKey), "=", 0),
+				a.leaderCmp()).
+			Then(clientv3.OpPut(reservedKey, a.zone), clientv3.OpPut(suffixKey, value)).
+			Else(clientv3.OpGet(reservedKey)).
+			Commit()
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		if resp.Succeeded {
+			return suffix, nil
+		}
+		// The suffix is reserved by another zone just now, try the next one.
+		if len(resp.Responses) > 0 && len(resp.Responses[0].GetResponseRange().GetKvs()) > 0 {
+			continue
+		}
+		return 0, errors.New("failed to allocate the suffix of the zone, maybe we lost leader")
+	}
+	return 0, errors.Errorf("no suffix left for zone %s, at most %d zones are supported", a.zone, 1<<localTSOSuffixBits-1)
+}
+
+// syncWithGlobal advances the local timestamps to be greater than the global
+// ones synchronized last time, and publishes the local physical time for the
+// global allocator.
+func (a *localTSOAllocator) syncWithGlobal(tso *timestampOracle) error {
+	global, err := a.s.loadPhysical(a.s.getGlobalPhysicalPath())
+	if err != nil {
+		return err
+	}
+	if err = tso.advanceTo(global); err != nil {
+		return err
+	}
+	return a.s.savePhysical(path.Join(a.getRootPath(), "physical"), tso.getPhysical(), a.leaderCmp())
+}
+
+// formatLocalTSOLeader formats a value of localTSOLeadersMetadataKey.
+func formatLocalTSOLeader(zone string, leader *pdpb.Member) string {
+	return zone + "=" + strings.Join(leader.GetClientUrls(), ",")
+}
@@ -0,0 +1,180 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/pkg/testutil"
+	"go.etcd.io/etcd/clientv3"
+	"google.golang.org/grpc/metadata"
+)
+
+var _ = Suite(&testLocalTSOSuite{})
+
+type testLocalTSOSuite struct {
+	svr          *Server
+	cleanup      CleanupFunc
+	grpcPDClient pdpb.PDClient
+}
+
+func (s *testLocalTSOSuite) SetUpSuite(c *C) {
+	cfg := NewTestSingleConfig(c)
+	cfg.Labels = map[string]string{zoneLabelKey: "z1"}
+	cfg.EnableLocalTSO = true
+	svr, err := CreateServer(cfg, nil)
+	c.Assert(err, IsNil)
+	c.Assert(svr.Run(context.TODO()), IsNil)
+	s.svr = svr
+	s.cleanup = func() {
+		svr.Close()
+		cleanServer(cfg)
+	}
+	mustWaitLeader(c, []*Server{s.svr})
+	s.grpcPDClient = mustNewGrpcClient(c, s.svr.GetAddr())
+	testutil.WaitUntil(c, func(c *C) bool {
+		return s.svr.localTSO.getTSO() != nil
+	})
+}
+
+func (s *testLocalTSOSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testLocalTSOSuite) getTimestamp(c *C, zone string, count uint32) (*pdpb.Timestamp, error) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), tsoSuffixBitsSupportedMetadataKey, "true")
+	if zone != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, tsoZoneMetadataKey, zone)
+	}
+	tsoClient, err := s.grpcPDClient.Tso(ctx)
+	c.Assert(err, IsNil)
+	defer tsoClient.CloseSend()
+	err = tsoClient.Send(&pdpb.TsoRequest{
+		Header: newRequestHeader(s.svr.clusterID),
+		Count:  count,
+	})
+	c.Assert(err, IsNil)
+	resp, err := tsoClient.Recv()
+	if err != nil {
+		return nil, err
+	}
+	header, err := tsoClient.Header()
+	c.Assert(err, IsNil)
+	c.Assert(header.Get(tsoSuffixBitsMetadataKey), DeepEquals, []string{"4"})
+	return resp.GetTimestamp(), nil
+}
+
+func (s *testLocalTSOSuite) TestLocalTSO(c *C) {
+	const suffixMask = 1<<localTSOSuffixBits - 1
+	leaders, err := s.svr.GetLocalTSOLeaders()
+	c.Assert(err, IsNil)
+	c.Assert(leaders, HasLen, 1)
+	c.Assert(leaders["z1"].GetMemberId(), Equals, s.svr.ID())
+
+	// The global timestamps have the suffix 0.
+	last, err := s.getTimestamp(c, "", 10)
+	c.Assert(err, IsNil)
+	c.Assert(last.GetLogical()&suffixMask, Equals, int64(0))
+	ts, err := s.getTimestamp(c, "", 10)
+	c.Assert(err, IsNil)
+	c.Assert(ts.GetPhysical()<<18+ts.GetLogical(), Greater, last.GetPhysical()<<18+last.GetLogical())
+
+	// The local timestamps have the suffix of the zone.
+	last, err = s.getTimestamp(c, "z1", 10)
+	c.Assert(err, IsNil)
+	c.Assert(last.GetLogical()&suffixMask, Equals, int64(1))
+	ts, err = s.getTimestamp(c, "z1", 10)
+	c.Assert(err, IsNil)
+	c.Assert(ts.GetLogical()&suffixMask, Equals, int64(1))
+	c.Assert(ts.GetPhysical()<<18+ts.GetLogical(), Greater, last.GetPhysical()<<18+last.GetLogical())
+
+	// The server is not in the zone.
+	_, err = s.getTimestamp(c, "z2", 1)
+	c.Assert(err, NotNil)
+
+	// The clients not supporting the suffix bits are rejected.
+	tsoClient, err := s.grpcPDClient.Tso(context.Background())
+	c.Assert(err, IsNil)
+	defer tsoClient.CloseSend()
+	err = tsoClient.Send(&pdpb.TsoRequest{
+		Header: newRequestHeader(s.svr.clusterID),
+		Count:  1,
+	})
+	c.Assert(err, IsNil)
+	_, err = tsoClient.Recv()
+	c.Assert(err, ErrorMatches, ".*does not support the tso suffix bits.*")
+}
+
+func (s *testLocalTSOSuite) TestSyncLocalTSO(c *C) {
+	a := s.svr.localTSO
+	localPath := path.Join(a.getRootPath(), "physical")
+	loadPhysical := func(c *C, key string) time.Time {
+		ts, err := s.svr.loadPhysical(key)
+		c.Assert(err, IsNil)
+		return ts
+	}
+	// The global and the local physical time are published.
+	var global, local time.Time
+	testutil.WaitUntil(c, func(c *C) bool {
+		global, local = loadPhysical(c, s.svr.getGlobalPhysicalPath()), loadPhysical(c, localPath)
+		return !global.IsZero() && !local.IsZero()
+	})
+	// They are advanced to be greater than each other after synchronizing.
+	testutil.WaitUntil(c, func(c *C) bool {
+		return loadPhysical(c, s.svr.getGlobalPhysicalPath()).After(local) &&
+			loadPhysical(c, localPath).After(global)
+	})
+}
+
+func (s *testLocalTSOSuite) TestConcurrentAllocSuffix(c *C) {
+	// The zones elect their first leaders at the same time.
+	var allocators []*localTSOAllocator
+	for i := 0; i < 8; i++ {
+		a := newLocalTSOAllocator(s.svr, fmt.Sprintf("concurrent-%d", i))
+		_, err := s.svr.txn().Then(clientv3.OpPut(a.getLeaderPath(), s.svr.memberValue)).Commit()
+		c.Assert(err, IsNil)
+		allocators = append(allocators, a)
+	}
+	suffixes := make([]int64, len(allocators))
+	var wg sync.WaitGroup
+	for i, a := range allocators {
+		wg.Add(1)
+		go func(i int, a *localTSOAllocator) {
+			defer wg.Done()
+			suffix, err := a.allocSuffix()
+			c.Assert(err, IsNil)
+			suffixes[i] = suffix
+		}(i, a)
+	}
+	wg.Wait()
+
+	used := make(map[int64]bool)
+	for i, a := range allocators {
+		c.Assert(suffixes[i], Greater, int64(0))
+		c.Assert(used[suffixes[i]], IsFalse)
+		used[suffixes[i]] = true
+		// The suffix is kept since then.
+		suffix, err := a.allocSuffix()
+		c.Assert(err, IsNil)
+		c.Assert(suffix, Equals, suffixes[i])
+		_, err = s.svr.txn().Then(clientv3.OpDelete(a.getLeaderPath())).Commit()
+		c.Assert(err, IsNil)
+	}
+}
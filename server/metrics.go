@@ -123,6 +123,15 @@ var (
 			Help:      "Bucketed histogram of processing time (s) of handled tso requests.",
 			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 13),
 		})
+
+	tsoProxyBatchSize = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "pd",
+			Subsystem: "server",
+			Name:      "tso_proxy_batch_size",
+			Help:      "Bucketed histogram of the number of the tso requests forwarded to leader in a batch.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 13),
+		})
 )
 
 func init() {
@@ -138,4 +147,5 @@ func init() {
 	prometheus.MustRegister(etcdStateGauge)
 	prometheus.MustRegister(patrolCheckRegionsHistogram)
 	prometheus.MustRegister(tsoHandleDuration)
+	prometheus.MustRegister(tsoProxyBatchSize)
 }
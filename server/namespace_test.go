@@ -130,7 +130,7 @@ func (s *testNamespaceSuite) TestSchedulerBalanceRegion(c *C) {
 	s.opt.SetMaxReplicas(1)
 
 	oc := schedule.NewOperatorController(nil, nil)
-	sched, _ := schedule.CreateScheduler("balance-region", oc)
+	sched, _ := schedule.CreateScheduler("balance-region", oc, core.NewKV(core.NewMemoryKV()))
 
 	// Balance is limited within a namespace.
 	c.Assert(s.tc.addLeaderRegion(1, 2), IsNil)
@@ -171,7 +171,7 @@ func (s *testNamespaceSuite) TestSchedulerBalanceLeader(c *C) {
 	s.classifier.setStore(4, "ns2")
 
 	oc := schedule.NewOperatorController(nil, nil)
-	sched, _ := schedule.CreateScheduler("balance-leader", oc)
+	sched, _ := schedule.CreateScheduler("balance-leader", oc, core.NewKV(core.NewMemoryKV()))
 
 	// Balance is limited within a namespace.
 	c.Assert(s.tc.addLeaderRegion(1, 2, 1), IsNil)
@@ -105,6 +105,10 @@ func (o *scheduleOption) GetLocationLabels() []string {
 	return o.rep.GetLocationLabels()
 }
 
+func (o *scheduleOption) IsPlacementRulesEnabled() bool {
+	return o.rep.IsPlacementRulesEnabled()
+}
+
 func (o *scheduleOption) GetMaxSnapshotCount() uint64 {
 	return o.load().MaxSnapshotCount
 }
@@ -192,6 +196,10 @@ func (o *scheduleOption) GetSchedulerMaxWaitingOperator() uint64 {
 	return o.load().SchedulerMaxWaitingOperator
 }
 
+func (o *scheduleOption) GetOperatorPriorityClasses() []string {
+	return o.load().OperatorPriorityClasses
+}
+
 func (o *scheduleOption) IsRaftLearnerEnabled() bool {
 	return !o.load().DisableLearner
 }
@@ -220,6 +228,14 @@ func (o *scheduleOption) IsNamespaceRelocationEnabled() bool {
 	return !o.load().DisableNamespaceRelocation
 }
 
+func (o *scheduleOption) IsDryRunEnabled() bool {
+	return o.load().EnableDryRun
+}
+
+func (o *scheduleOption) GetOperatorHistoryRetention() time.Duration {
+	return o.load().OperatorHistoryRetention.Duration
+}
+
 func (o *scheduleOption) GetSchedulers() SchedulerConfigs {
 	return o.load().Schedulers
 }
@@ -251,7 +267,7 @@ func (o *scheduleOption) RemoveSchedulerCfg(name string) error {
 	v := c.clone()
 	for i, schedulerCfg := range v.Schedulers {
 		// To create a temporary scheduler is just used to get scheduler's name
-		tmp, err := schedule.CreateScheduler(schedulerCfg.Type, schedule.NewOperatorController(nil, nil), schedulerCfg.Args...)
+		tmp, err := schedule.CreateScheduler(schedulerCfg.Type, schedule.NewOperatorController(nil, nil), core.NewKV(core.NewMemoryKV()), schedulerCfg.Args...)
 		if err != nil {
 			return err
 		}
@@ -443,6 +459,11 @@ func (r *Replication) GetStrictlyMatchLabel() bool {
 	return r.load().StrictlyMatchLabel
 }
 
+// IsPlacementRulesEnabled returns whether the feature is enabled.
+func (r *Replication) IsPlacementRulesEnabled() bool {
+	return r.load().EnablePlacementRules
+}
+
 // namespaceOption is a wrapper to access the configuration safely.
 type namespaceOption struct {
 	namespaceCfg atomic.Value
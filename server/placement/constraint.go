@@ -34,7 +34,8 @@ type Constraint struct {
 // configuration is "key:value", which appears in the function argument of the
 // expression.
 type Filter struct {
-	Key, Value string
+	Key   string `json:"key"`
+	Value string `json:"value"`
 }
 
 var functionList = []string{"count", "label_values", "count_leader", "isolation_level"}
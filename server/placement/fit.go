@@ -0,0 +1,190 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"sort"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/server/core"
+)
+
+// RegionFit is the result of fitting a region's peers to the rule list.
+// All peers are divided into corresponding rules according to the matching
+// rules, and the remaining peers are placed in the OrphanPeers list.
+type RegionFit struct {
+	RuleFits    []*RuleFit
+	OrphanPeers []*metapb.Peer
+}
+
+// IsSatisfied returns if the rules are properly satisfied.
+// It means all Rules are fulfilled and there is no orphan peers.
+func (f *RegionFit) IsSatisfied() bool {
+	if len(f.RuleFits) == 0 {
+		return false
+	}
+	for _, r := range f.RuleFits {
+		if !r.IsSatisfied() {
+			return false
+		}
+	}
+	return len(f.OrphanPeers) == 0
+}
+
+// GetRuleFit returns the RuleFit that contains the peer.
+func (f *RegionFit) GetRuleFit(peerID uint64) *RuleFit {
+	for _, rf := range f.RuleFits {
+		for _, p := range rf.Peers {
+			if p.GetId() == peerID {
+				return rf
+			}
+		}
+	}
+	return nil
+}
+
+// RuleFit is the result of fitting status of a Rule.
+type RuleFit struct {
+	Rule *Rule
+	// Peers of the Region that are divided to this Rule.
+	Peers []*metapb.Peer
+}
+
+// IsSatisfied returns if the rule is properly satisfied.
+func (f *RuleFit) IsSatisfied() bool {
+	return len(f.Peers) == f.Rule.Count
+}
+
+// IsVotersSatisfied returns if all rules of voters are satisfied.
+func (f *RegionFit) IsVotersSatisfied() bool {
+	for _, rf := range f.RuleFits {
+		if rf.Rule.Role != Learner && !rf.IsSatisfied() {
+			return false
+		}
+	}
+	return true
+}
+
+// IsLearnersSatisfied returns if all rules of learners are satisfied.
+func (f *RegionFit) IsLearnersSatisfied() bool {
+	for _, rf := range f.RuleFits {
+		if rf.Rule.Role == Learner && !rf.IsSatisfied() {
+			return false
+		}
+	}
+	return true
+}
+
+// FitRegion tries to divide the healthy peers of the region to the rules.
+// Rules with more label constraints are fitted first so that peers on
+// specially labelled stores are not taken by a more general rule. Within a
+// rule, peers that are better isolated by the rule's location labels are
+// preferred.
+func FitRegion(stores []*core.StoreInfo, region *core.RegionInfo, rules []*Rule, isHealthy func(*metapb.Peer) bool) *RegionFit {
+	fit := &RegionFit{RuleFits: make([]*RuleFit, len(rules))}
+	storeSet := make(map[uint64]*core.StoreInfo, len(stores))
+	for _, s := range stores {
+		storeSet[s.GetID()] = s
+	}
+
+	order := make([]int, len(rules))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(rules[order[i]].LabelConstraints) > len(rules[order[j]].LabelConstraints)
+	})
+
+	assigned := make(map[uint64]struct{})
+	for _, i := range order {
+		rule := rules[i]
+		var candidates []*metapb.Peer
+		for _, p := range region.GetPeers() {
+			if _, ok := assigned[p.GetId()]; ok {
+				continue
+			}
+			if isHealthy != nil && !isHealthy(p) {
+				continue
+			}
+			if !matchPeerRole(rule, region, p) {
+				continue
+			}
+			store := storeSet[p.GetStoreId()]
+			if store == nil || !rule.MatchStore(store) {
+				continue
+			}
+			candidates = append(candidates, p)
+		}
+		peers := selectIsolatedPeers(storeSet, candidates, rule.Count, rule.LocationLabels)
+		for _, p := range peers {
+			assigned[p.GetId()] = struct{}{}
+		}
+		fit.RuleFits[i] = &RuleFit{Rule: rule, Peers: peers}
+	}
+
+	for _, p := range region.GetPeers() {
+		if _, ok := assigned[p.GetId()]; !ok {
+			fit.OrphanPeers = append(fit.OrphanPeers, p)
+		}
+	}
+	return fit
+}
+
+func matchPeerRole(rule *Rule, region *core.RegionInfo, peer *metapb.Peer) bool {
+	if !rule.MatchPeer(peer) {
+		return false
+	}
+	isLeader := region.GetLeader().GetId() == peer.GetId()
+	switch rule.Role {
+	case Leader:
+		return isLeader
+	case Follower:
+		return !isLeader
+	}
+	return true
+}
+
+// selectIsolatedPeers greedily picks at most count peers from candidates, each
+// time choosing the one which is the most isolated from the picked ones.
+func selectIsolatedPeers(stores map[uint64]*core.StoreInfo, candidates []*metapb.Peer, count int, labels []string) []*metapb.Peer {
+	var (
+		selected       []*metapb.Peer
+		selectedStores []*core.StoreInfo
+	)
+	for len(selected) < count && len(candidates) > 0 {
+		best, bestScore := 0, -1
+		for i, p := range candidates {
+			score := isolationScore(labels, selectedStores, stores[p.GetStoreId()])
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		selected = append(selected, candidates[best])
+		selectedStores = append(selectedStores, stores[candidates[best].GetStoreId()])
+		candidates = append(candidates[:best], candidates[best+1:]...)
+	}
+	return selected
+}
+
+// isolationScore sums up the levels at which the store is separated from
+// each of the stores. A greater score means the store is more isolated.
+func isolationScore(labels []string, stores []*core.StoreInfo, other *core.StoreInfo) int {
+	var score int
+	for _, s := range stores {
+		if index := s.CompareLocation(other, labels); index != -1 {
+			score += len(labels) - index
+		}
+	}
+	return score
+}
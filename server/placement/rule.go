@@ -0,0 +1,161 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"bytes"
+	"encoding/hex"
+	"regexp"
+	"sort"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/server/core"
+	"github.com/pkg/errors"
+)
+
+// PeerRoleType is the expected peer type of the placement rule.
+type PeerRoleType string
+
+const (
+	// Voter can either match a leader peer or follower peer.
+	Voter PeerRoleType = "voter"
+	// Leader matches a leader.
+	Leader PeerRoleType = "leader"
+	// Follower matches a follower.
+	Follower PeerRoleType = "follower"
+	// Learner matches a learner. Learners of the rule are not promoted and
+	// are not counted toward the quorum.
+	Learner PeerRoleType = "learner"
+)
+
+func validateRole(s PeerRoleType) bool {
+	return s == Voter || s == Leader || s == Follower || s == Learner
+}
+
+// DefaultRuleID is the ID of the rule which is created when placement rules
+// are initialized for the first time.
+const DefaultRuleID = "default"
+
+var ruleIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_\-.]+$`)
+
+// Rule is the placement rule. It describes how many replicas of which role
+// should be placed on the stores matching the label constraints for the
+// regions in the key range [StartKey, EndKey).
+type Rule struct {
+	ID               string       `json:"id"`
+	Index            int          `json:"index,omitempty"`    // Rules are applied in the order of Index, then ID.
+	Override         bool         `json:"override,omitempty"` // When it is true, all rules with a smaller Index are ignored.
+	StartKeyHex      string       `json:"start_key"`          // Hex-encoded start key of the key range.
+	EndKeyHex        string       `json:"end_key"`            // Hex-encoded end key of the key range, empty means +inf.
+	Role             PeerRoleType `json:"role"`
+	Count            int          `json:"count"`
+	LabelConstraints []Filter     `json:"label_constraints,omitempty"` // Stores must match all the "key:value" constraints.
+	LocationLabels   []string     `json:"location_labels,omitempty"`   // Used to isolate the replicas of the rule.
+
+	StartKey []byte `json:"-"`
+	EndKey   []byte `json:"-"`
+}
+
+// Adjust validates the rule and decodes its key range.
+func (r *Rule) Adjust() error {
+	if !ruleIDPattern.MatchString(r.ID) {
+		return errors.Errorf("invalid rule ID '%s'", r.ID)
+	}
+	if !validateRole(r.Role) {
+		return errors.Errorf("invalid role '%s'", r.Role)
+	}
+	if r.Count <= 0 {
+		return errors.Errorf("invalid count %d", r.Count)
+	}
+	if r.Role == Leader && r.Count > 1 {
+		return errors.Errorf("define multiple leaders by count %d", r.Count)
+	}
+	var err error
+	if r.StartKey, err = hex.DecodeString(r.StartKeyHex); err != nil {
+		return errors.Wrap(err, "start key is not hex format")
+	}
+	if r.EndKey, err = hex.DecodeString(r.EndKeyHex); err != nil {
+		return errors.Wrap(err, "end key is not hex format")
+	}
+	if len(r.EndKey) > 0 && bytes.Compare(r.EndKey, r.StartKey) <= 0 {
+		return errors.New("end key should be greater than start key")
+	}
+	for _, c := range r.LabelConstraints {
+		if _, err := parseArgument(c.Key); err != nil {
+			return err
+		}
+		if _, err := parseArgument(c.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clone returns a copy of the rule.
+func (r *Rule) Clone() *Rule {
+	clone := *r
+	clone.LabelConstraints = append([]Filter(nil), r.LabelConstraints...)
+	clone.LocationLabels = append([]string(nil), r.LocationLabels...)
+	clone.StartKey = append([]byte(nil), r.StartKey...)
+	clone.EndKey = append([]byte(nil), r.EndKey...)
+	return &clone
+}
+
+// MatchStore checks if the store matches all label constraints of the rule.
+func (r *Rule) MatchStore(store *core.StoreInfo) bool {
+	return Constraint{Filters: r.LabelConstraints}.matchStore(store)
+}
+
+// MatchPeer checks if the peer is able to take the rule's role.
+func (r *Rule) MatchPeer(peer *metapb.Peer) bool {
+	return peer.GetIsLearner() == (r.Role == Learner)
+}
+
+// CoverRegion checks if the rule's key range covers the whole region.
+func (r *Rule) CoverRegion(region *core.RegionInfo) bool {
+	if bytes.Compare(region.GetStartKey(), r.StartKey) < 0 {
+		return false
+	}
+	if len(r.EndKey) == 0 {
+		return true
+	}
+	return len(region.GetEndKey()) > 0 && bytes.Compare(region.GetEndKey(), r.EndKey) <= 0
+}
+
+// sortRules sorts rules by the order they are applied.
+func sortRules(rules []*Rule) {
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Index != rules[j].Index {
+			return rules[i].Index < rules[j].Index
+		}
+		return rules[i].ID < rules[j].ID
+	})
+}
+
+// prepareRules removes the rules that are overridden by a rule with a greater
+// Index. The input rules should be sorted.
+func prepareRules(rules []*Rule) []*Rule {
+	var res []*Rule
+	for _, r := range rules {
+		if r.Override {
+			i := 0
+			for i < len(res) && res[i].Index < r.Index {
+				i++
+			}
+			res = res[i:]
+		}
+		res = append(res, r)
+	}
+	return res
+}
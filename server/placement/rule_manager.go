@@ -0,0 +1,181 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	log "github.com/pingcap/log"
+	"github.com/pingcap/pd/server/core"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// RuleManager is responsible for the lifecycle of all placement rules.
+// It is thread safe.
+type RuleManager struct {
+	sync.RWMutex
+	kv          *core.KV
+	initialized bool
+	rules       map[string]*Rule
+}
+
+// NewRuleManager creates a RuleManager instance.
+func NewRuleManager(kv *core.KV) *RuleManager {
+	return &RuleManager{
+		kv:    kv,
+		rules: make(map[string]*Rule),
+	}
+}
+
+// Initialize loads rules from storage. If there is no rule in storage, it
+// creates a default rule that covers the whole key space with the given
+// replica count and location labels.
+func (m *RuleManager) Initialize(maxReplica int, locationLabels []string) error {
+	m.Lock()
+	defer m.Unlock()
+	if m.initialized {
+		return nil
+	}
+
+	var loadErr error
+	err := m.kv.LoadRules(func(k, v string) {
+		var r Rule
+		if err := json.Unmarshal([]byte(v), &r); err != nil {
+			loadErr = errors.WithStack(err)
+			return
+		}
+		if err := r.Adjust(); err != nil {
+			log.Error("invalid placement rule in storage", zap.String("rule-key", k), zap.Error(err))
+			return
+		}
+		m.rules[r.ID] = &r
+	})
+	if err != nil {
+		return err
+	}
+	if loadErr != nil {
+		return loadErr
+	}
+
+	if len(m.rules) == 0 {
+		defaultRule := &Rule{
+			ID:             DefaultRuleID,
+			Role:           Voter,
+			Count:          maxReplica,
+			LocationLabels: locationLabels,
+		}
+		if err := defaultRule.Adjust(); err != nil {
+			return err
+		}
+		if err := m.kv.SaveRule(defaultRule.ID, defaultRule); err != nil {
+			return err
+		}
+		m.rules[defaultRule.ID] = defaultRule
+	}
+	m.initialized = true
+	return nil
+}
+
+// IsInitialized returns whether the rules have been loaded.
+func (m *RuleManager) IsInitialized() bool {
+	m.RLock()
+	defer m.RUnlock()
+	return m.initialized
+}
+
+// GetRule returns the rule with the same ID. Returns nil if it does not exist.
+func (m *RuleManager) GetRule(id string) *Rule {
+	m.RLock()
+	defer m.RUnlock()
+	if r, ok := m.rules[id]; ok {
+		return r.Clone()
+	}
+	return nil
+}
+
+// SetRule inserts or updates a rule.
+func (m *RuleManager) SetRule(rule *Rule) error {
+	if err := rule.Adjust(); err != nil {
+		return err
+	}
+	m.Lock()
+	defer m.Unlock()
+	if err := m.kv.SaveRule(rule.ID, rule); err != nil {
+		return err
+	}
+	m.rules[rule.ID] = rule.Clone()
+	log.Info("placement rule updated", zap.Reflect("rule", rule))
+	return nil
+}
+
+// DeleteRule removes a rule.
+func (m *RuleManager) DeleteRule(id string) error {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.rules[id]; !ok {
+		return nil
+	}
+	if err := m.kv.DeleteRule(id); err != nil {
+		return err
+	}
+	delete(m.rules, id)
+	log.Info("placement rule removed", zap.String("rule-id", id))
+	return nil
+}
+
+// GetAllRules returns sorted all rules.
+func (m *RuleManager) GetAllRules() []*Rule {
+	m.RLock()
+	defer m.RUnlock()
+	rules := make([]*Rule, 0, len(m.rules))
+	for _, r := range m.rules {
+		rules = append(rules, r.Clone())
+	}
+	sortRules(rules)
+	return rules
+}
+
+// GetRulesForApplyRegion returns the rules which cover the whole key range of
+// the region, with the overridden ones removed.
+func (m *RuleManager) GetRulesForApplyRegion(region *core.RegionInfo) []*Rule {
+	m.RLock()
+	defer m.RUnlock()
+	var rules []*Rule
+	for _, r := range m.rules {
+		if r.CoverRegion(region) {
+			rules = append(rules, r)
+		}
+	}
+	sortRules(rules)
+	return prepareRules(rules)
+}
+
+// FitRegion fits a region to the rules it matches. The stores should contain
+// all stores of the region's peers.
+func (m *RuleManager) FitRegion(stores []*core.StoreInfo, region *core.RegionInfo, isHealthy func(*metapb.Peer) bool) *RegionFit {
+	return FitRegion(stores, region, m.GetRulesForApplyRegion(region), isHealthy)
+}
+
+// CheckRegionPeers reports whether the region lacks voters or learners, or
+// has peers that do not belong to any rule.
+func (m *RuleManager) CheckRegionPeers(region *core.RegionInfo, stores []*core.StoreInfo) (missVoter, missLearner, extra bool) {
+	fit := m.FitRegion(stores, region, nil)
+	if len(fit.RuleFits) == 0 {
+		return false, false, false
+	}
+	return !fit.IsVotersSatisfied(), !fit.IsLearnersSatisfied(), len(fit.OrphanPeers) > 0
+}
@@ -0,0 +1,190 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"encoding/hex"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/pkg/mock/mockoption"
+	"github.com/pingcap/pd/server/core"
+)
+
+var _ = Suite(&testRuleSuite{})
+
+type testRuleSuite struct {
+	kv      *core.KV
+	manager *RuleManager
+}
+
+func (s *testRuleSuite) SetUpTest(c *C) {
+	s.kv = core.NewKV(core.NewMemoryKV())
+	s.manager = NewRuleManager(s.kv)
+	c.Assert(s.manager.Initialize(3, []string{"zone", "rack", "host"}), IsNil)
+}
+
+func (s *testRuleSuite) TestDefault(c *C) {
+	rules := s.manager.GetAllRules()
+	c.Assert(rules, HasLen, 1)
+	c.Assert(rules[0].ID, Equals, DefaultRuleID)
+	c.Assert(rules[0].Role, Equals, Voter)
+	c.Assert(rules[0].Count, Equals, 3)
+	c.Assert(rules[0].LocationLabels, DeepEquals, []string{"zone", "rack", "host"})
+}
+
+func (s *testRuleSuite) TestAdjust(c *C) {
+	cases := []struct {
+		rule *Rule
+		ok   bool
+	}{
+		{&Rule{ID: "a", Role: Voter, Count: 3}, true},
+		{&Rule{ID: "a", Role: Leader, Count: 1, StartKeyHex: "01", EndKeyHex: "02"}, true},
+		{&Rule{ID: "", Role: Voter, Count: 3}, false},
+		{&Rule{ID: "a b", Role: Voter, Count: 3}, false},
+		{&Rule{ID: "a", Role: Learner, Count: 3}, true},
+		{&Rule{ID: "a", Role: "witness", Count: 3}, false},
+		{&Rule{ID: "a", Role: Voter, Count: 0}, false},
+		{&Rule{ID: "a", Role: Leader, Count: 2}, false},
+		{&Rule{ID: "a", Role: Voter, Count: 3, StartKeyHex: "xx"}, false},
+		{&Rule{ID: "a", Role: Voter, Count: 3, StartKeyHex: "02", EndKeyHex: "01"}, false},
+		{&Rule{ID: "a", Role: Voter, Count: 3, LabelConstraints: []Filter{{Key: "zone", Value: "z1"}}}, true},
+		{&Rule{ID: "a", Role: Voter, Count: 3, LabelConstraints: []Filter{{Key: "zone:z1", Value: "z1"}}}, false},
+	}
+	for _, t := range cases {
+		if t.ok {
+			c.Assert(t.rule.Adjust(), IsNil)
+		} else {
+			c.Assert(t.rule.Adjust(), NotNil)
+		}
+	}
+}
+
+func (s *testRuleSuite) TestSaveLoad(c *C) {
+	rules := []*Rule{
+		{ID: DefaultRuleID, Role: Voter, Count: 5},
+		{ID: "foo", Index: 1, StartKeyHex: "01", EndKeyHex: "02", Role: Voter, Count: 1},
+		{ID: "bar", Role: Leader, Count: 1, LabelConstraints: []Filter{{Key: "zone", Value: "z1"}}},
+	}
+	for _, r := range rules {
+		c.Assert(s.manager.SetRule(r), IsNil)
+	}
+
+	m2 := NewRuleManager(s.kv)
+	c.Assert(m2.Initialize(3, []string{"no", "labels"}), IsNil)
+	c.Assert(m2.GetAllRules(), HasLen, 3)
+	c.Assert(m2.GetRule(DefaultRuleID).Count, Equals, 5)
+	c.Assert(m2.GetRule("foo").StartKey, DeepEquals, []byte{1})
+	c.Assert(m2.GetRule("bar").LabelConstraints, DeepEquals, rules[2].LabelConstraints)
+
+	c.Assert(s.manager.DeleteRule("foo"), IsNil)
+	c.Assert(s.manager.GetRule("foo"), IsNil)
+	m3 := NewRuleManager(s.kv)
+	c.Assert(m3.Initialize(3, nil), IsNil)
+	c.Assert(m3.GetAllRules(), HasLen, 2)
+}
+
+func (s *testRuleSuite) TestApplyRegion(c *C) {
+	c.Assert(s.manager.SetRule(&Rule{ID: "a", Index: 1, StartKeyHex: hexKey("a"), EndKeyHex: hexKey("m"), Role: Voter, Count: 1}), IsNil)
+	c.Assert(s.manager.SetRule(&Rule{ID: "b", Index: 2, Override: true, StartKeyHex: hexKey("b"), EndKeyHex: hexKey("c"), Role: Voter, Count: 1}), IsNil)
+	c.Assert(s.manager.SetRule(&Rule{ID: "c", Index: 2, StartKeyHex: hexKey("b"), EndKeyHex: hexKey("c"), Role: Leader, Count: 1}), IsNil)
+
+	cases := []struct {
+		start, end string
+		ruleIDs    []string
+	}{
+		{"", "", []string{DefaultRuleID}},
+		{"a", "b", []string{DefaultRuleID, "a"}},
+		{"a", "n", []string{DefaultRuleID}},
+		{"b", "bb", []string{"b", "c"}},
+		{"l", "", []string{DefaultRuleID}},
+	}
+	for _, t := range cases {
+		region := core.NewRegionInfo(&metapb.Region{StartKey: []byte(t.start), EndKey: []byte(t.end)}, nil)
+		var ids []string
+		for _, r := range s.manager.GetRulesForApplyRegion(region) {
+			ids = append(ids, r.ID)
+		}
+		c.Assert(ids, DeepEquals, t.ruleIDs)
+	}
+}
+
+func (s *testRuleSuite) TestFitRegion(c *C) {
+	cluster := mockcluster.NewCluster(mockoption.NewScheduleOptions())
+	cluster.AddLabelsStore(1, 0, map[string]string{"zone": "z1", "host": "h1"})
+	cluster.AddLabelsStore(2, 0, map[string]string{"zone": "z1", "host": "h2"})
+	cluster.AddLabelsStore(3, 0, map[string]string{"zone": "z2", "host": "h3"})
+	cluster.AddLabelsStore(4, 0, map[string]string{"zone": "z3", "host": "h4"})
+	cluster.AddLeaderRegion(1, 1, 2, 3, 4)
+	region := cluster.GetRegion(1)
+
+	rules := []*Rule{
+		{ID: "z1", Role: Voter, Count: 1, LabelConstraints: []Filter{{Key: "zone", Value: "z1"}}},
+		{ID: "others", Role: Voter, Count: 2, LocationLabels: []string{"zone", "host"}},
+	}
+	fit := FitRegion(cluster.GetRegionStores(region), region, rules, nil)
+	c.Assert(fit.RuleFits, HasLen, 2)
+	c.Assert(fit.RuleFits[0].IsSatisfied(), IsTrue)
+	c.Assert(fit.RuleFits[1].IsSatisfied(), IsTrue)
+	c.Assert(fit.OrphanPeers, HasLen, 1)
+	c.Assert(fit.IsSatisfied(), IsFalse)
+	// The rule with constraints takes a z1 peer, and the peers of the other
+	// rule are isolated by zone.
+	c.Assert(fit.RuleFits[0].Peers[0].GetStoreId() <= 2, IsTrue)
+	zone1 := cluster.GetStore(fit.RuleFits[1].Peers[0].GetStoreId()).GetLabelValue("zone")
+	zone2 := cluster.GetStore(fit.RuleFits[1].Peers[1].GetStoreId()).GetLabelValue("zone")
+	c.Assert(zone1, Not(Equals), zone2)
+
+	// Unhealthy peers are not counted.
+	fit = FitRegion(cluster.GetRegionStores(region), region, rules, func(p *metapb.Peer) bool { return p.GetStoreId() != 4 })
+	c.Assert(fit.RuleFits[1].Peers, HasLen, 2)
+	c.Assert(fit.GetRuleFit(region.GetStorePeer(4).GetId()), IsNil)
+	c.Assert(fit.IsSatisfied(), IsFalse)
+
+	// Leader and follower roles.
+	rules = []*Rule{
+		{ID: "leader", Role: Leader, Count: 1, LabelConstraints: []Filter{{Key: "zone", Value: "z2"}}},
+		{ID: "followers", Role: Follower, Count: 3},
+	}
+	fit = FitRegion(cluster.GetRegionStores(region), region, rules, nil)
+	c.Assert(fit.RuleFits[0].IsSatisfied(), IsFalse)
+	c.Assert(fit.RuleFits[1].IsSatisfied(), IsTrue)
+	c.Assert(fit.OrphanPeers, HasLen, 1)
+	c.Assert(fit.OrphanPeers[0].GetStoreId(), Equals, uint64(1))
+
+	// Learners only match the rules of learners.
+	peers := []*metapb.Peer{
+		{Id: 11, StoreId: 1},
+		{Id: 12, StoreId: 2},
+		{Id: 13, StoreId: 3},
+		{Id: 14, StoreId: 4, IsLearner: true},
+	}
+	region = core.NewRegionInfo(&metapb.Region{Id: 2, Peers: peers}, peers[0])
+	rules = []*Rule{
+		{ID: "voters", Role: Voter, Count: 3},
+		{ID: "learners", Role: Learner, Count: 1, LabelConstraints: []Filter{{Key: "zone", Value: "z3"}}},
+	}
+	fit = FitRegion(cluster.GetRegionStores(region), region, rules, nil)
+	c.Assert(fit.IsSatisfied(), IsTrue)
+	c.Assert(fit.RuleFits[1].Peers[0].GetStoreId(), Equals, uint64(4))
+	rules[0].Count = 4
+	fit = FitRegion(cluster.GetRegionStores(region), region, rules, nil)
+	c.Assert(fit.IsVotersSatisfied(), IsFalse)
+	c.Assert(fit.IsLearnersSatisfied(), IsTrue)
+}
+
+func hexKey(key string) string {
+	return hex.EncodeToString([]byte(key))
+}
@@ -16,8 +16,10 @@ package syncer
 import (
 	"context"
 	"net/url"
+	"sync"
 	"time"
 
+	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	log "github.com/pingcap/log"
 	"github.com/pingcap/pd/server/core"
@@ -27,8 +29,140 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// syncedRegions is the cache of the regions synchronized from the leader.
+type syncedRegions struct {
+	sync.RWMutex
+	// regions is only cached when the follower read is enabled.
+	enabled bool
+	regions *core.RegionsInfo
+	// loaded is set once the regions are loaded from the storage.
+	loaded bool
+	// lastSync is the last time the follower was confirmed to be at the same
+	// index as the leader. It is zero until the follower catches up with the
+	// leader after a (re)connection.
+	lastSync time.Time
+}
+
+func newSyncedRegions() *syncedRegions {
+	return &syncedRegions{}
+}
+
+// load reloads the regions from the storage, which are saved by the previous
+// leader or the synchronization.
+func (r *syncedRegions) load(kv *core.KV) error {
+	r.RLock()
+	enabled := r.enabled
+	r.RUnlock()
+	if !enabled {
+		return nil
+	}
+	regions := core.NewRegionsInfo()
+	if err := kv.LoadRegions(regions); err != nil {
+		return err
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.regions = regions
+	r.loaded = true
+	return nil
+}
+
+func (r *syncedRegions) update(regions []*metapb.Region) {
+	r.Lock()
+	defer r.Unlock()
+	if r.regions == nil {
+		return
+	}
+	for _, region := range regions {
+		r.regions.SetRegion(core.NewRegionInfo(region, nil))
+	}
+}
+
+// markSynced records that the follower is at the same index as the leader.
+func (r *syncedRegions) markSynced(now time.Time) {
+	r.Lock()
+	defer r.Unlock()
+	r.lastSync = now
+}
+
+func (r *syncedRegions) isSynced() bool {
+	r.RLock()
+	defer r.RUnlock()
+	return !r.lastSync.IsZero()
+}
+
+func (r *syncedRegions) reset() {
+	r.Lock()
+	defer r.Unlock()
+	r.lastSync = time.Time{}
+}
+
+// EnableFollowerRead makes the follower cache the regions synchronized from the
+// leader to answer the region lookups. It should be called before synchronizing
+// with the leader.
+func (s *RegionSyncer) EnableFollowerRead() {
+	s.synced.Lock()
+	defer s.synced.Unlock()
+	s.synced.enabled = true
+}
+
+// Staleness returns how long it is since the synchronized regions were last
+// confirmed to be at the same index as the leader, and false if the follower
+// read is disabled, or the regions are not loaded or have not caught up with
+// the leader yet.
+func (s *RegionSyncer) Staleness() (time.Duration, bool) {
+	s.synced.RLock()
+	defer s.synced.RUnlock()
+	if !s.synced.loaded || s.synced.lastSync.IsZero() {
+		return 0, false
+	}
+	return time.Since(s.synced.lastSync), true
+}
+
+// GetSyncedRegionByKey returns the synchronized region containing the key.
+func (s *RegionSyncer) GetSyncedRegionByKey(key []byte) *metapb.Region {
+	s.synced.RLock()
+	defer s.synced.RUnlock()
+	return regionMeta(s.synced.regions.SearchRegion(key))
+}
+
+// GetSyncedPrevRegionByKey returns the synchronized region before the one
+// containing the key.
+func (s *RegionSyncer) GetSyncedPrevRegionByKey(key []byte) *metapb.Region {
+	s.synced.RLock()
+	defer s.synced.RUnlock()
+	return regionMeta(s.synced.regions.SearchPrevRegion(key))
+}
+
+// GetSyncedRegionByID returns the synchronized region by its ID.
+func (s *RegionSyncer) GetSyncedRegionByID(regionID uint64) *metapb.Region {
+	s.synced.RLock()
+	defer s.synced.RUnlock()
+	return regionMeta(s.synced.regions.GetRegion(regionID))
+}
+
+// ScanSyncedRegions returns at most limit synchronized regions from the key.
+func (s *RegionSyncer) ScanSyncedRegions(startKey []byte, limit int) []*metapb.Region {
+	s.synced.RLock()
+	defer s.synced.RUnlock()
+	regions := s.synced.regions.ScanRange(startKey, limit)
+	metas := make([]*metapb.Region, 0, len(regions))
+	for _, r := range regions {
+		metas = append(metas, r.GetMeta())
+	}
+	return metas
+}
+
+func regionMeta(region *core.RegionInfo) *metapb.Region {
+	if region == nil {
+		return nil
+	}
+	return region.GetMeta()
+}
+
 // StopSyncWithLeader stop to sync the region with leader.
 func (s *RegionSyncer) StopSyncWithLeader() {
+	s.synced.reset()
 	s.reset()
 	s.Lock()
 	close(s.closed)
@@ -90,12 +224,17 @@ func (s *RegionSyncer) StartSyncWithLeader(addr string) {
 	s.RUnlock()
 	go func() {
 		defer s.wg.Done()
+		if err := s.synced.load(s.server.GetStorage()); err != nil {
+			log.Error("failed to load regions for follower read", zap.Error(err))
+		}
 		for {
 			select {
 			case <-closed:
 				return
 			default:
 			}
+			// The follower catches up with the leader again after connecting.
+			s.synced.reset()
 			// establish client
 			client, err := s.establish(addr)
 			if err != nil {
@@ -119,7 +258,20 @@ func (s *RegionSyncer) StartSyncWithLeader(addr string) {
 					time.Sleep(time.Second)
 					break
 				}
-				if s.history.GetNextIndex() != resp.GetStartIndex() {
+				contiguous := s.history.GetNextIndex() == resp.GetStartIndex()
+				if !contiguous && s.synced.isSynced() {
+					// Some regions are missed after catching up, so reconnect
+					// to sync them from the history of the leader.
+					log.Warn("server misses some regions from the leader, reconnect",
+						zap.String("server", s.server.Name()),
+						zap.Uint64("own", s.history.GetNextIndex()),
+						zap.Uint64("leader", resp.GetStartIndex()))
+					if err = client.CloseSend(); err != nil {
+						log.Error("failed to terminate client stream", zap.Error(err))
+					}
+					break
+				}
+				if !contiguous {
 					log.Warn("server sync index not match the leader",
 						zap.String("server", s.server.Name()),
 						zap.Uint64("own", s.history.GetNextIndex()),
@@ -134,6 +286,13 @@ func (s *RegionSyncer) StartSyncWithLeader(addr string) {
 						s.history.Record(core.NewRegionInfo(r, nil))
 					}
 				}
+				s.synced.update(resp.GetRegions())
+				// The leader sends a response without regions after
+				// synchronizing the history, and then keeps the follower at
+				// the same index as itself.
+				if len(resp.GetRegions()) == 0 || s.synced.isSynced() {
+					s.synced.markSynced(time.Now())
+				}
 			}
 		}
 	}()
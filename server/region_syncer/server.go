@@ -72,6 +72,10 @@ type RegionSyncer struct {
 	wg      sync.WaitGroup
 	history *historyBuffer
 	limit   *ratelimit.Bucket
+	// synced caches the regions synchronized from the leader to serve the
+	// region lookups on the follower. It is nil if the follower read is
+	// disabled.
+	synced *syncedRegions
 }
 
 // NewRegionSyncer returns a region syncer.
@@ -86,6 +90,7 @@ func NewRegionSyncer(s Server) *RegionSyncer {
 		closed:  make(chan struct{}),
 		history: newHistoryBuffer(defaultHistoryBufferSize, s.GetStorage().GetRegionKV()),
 		limit:   ratelimit.NewBucketWithRate(defaultBucketRate, defaultBucketCapacity),
+		synced:  newSyncedRegions(),
 	}
 }
 
@@ -145,53 +150,65 @@ func (s *RegionSyncer) Sync(stream pdpb.PD_SyncRegionsServer) error {
 			zap.String("requested-server", request.GetMember().GetName()),
 			zap.String("url", request.GetMember().GetClientUrls()[0]))
 
-		err = s.syncHistoryRegion(request, stream)
+		syncedIndex, err := s.syncHistoryRegion(request, stream)
 		if err != nil {
 			return err
 		}
+		// Tell the follower the history is synchronized up to the index, and
+		// the following responses are contiguous from it.
+		err = stream.Send(&pdpb.SyncRegionResponse{
+			Header:     &pdpb.ResponseHeader{ClusterId: s.server.ClusterID()},
+			StartIndex: syncedIndex,
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
 		s.bindStream(request.GetMember().GetName(), stream)
 	}
 }
 
-func (s *RegionSyncer) syncHistoryRegion(request *pdpb.SyncRegionRequest, stream pdpb.PD_SyncRegionsServer) error {
+// syncHistoryRegion returns the index of the history synchronized up to.
+func (s *RegionSyncer) syncHistoryRegion(request *pdpb.SyncRegionRequest, stream pdpb.PD_SyncRegionsServer) (uint64, error) {
 	startIndex := request.GetStartIndex()
 	name := request.GetMember().GetName()
+	// The regions changed during the full synchronization are recorded after
+	// the index, so the follower can sync them from the history later.
+	nextIndex := s.history.GetNextIndex()
 	records := s.history.RecordsFrom(startIndex)
 	if len(records) == 0 {
-		if s.history.GetNextIndex() == startIndex {
+		if nextIndex == startIndex {
 			log.Info("requested server has already in sync with server",
 				zap.String("requested-server", name), zap.String("server", s.server.Name()), zap.Uint64("last-index", startIndex))
-			return nil
+			return startIndex, nil
+		}
+		// do full synchronization, as the history does not cover the index
+		if startIndex != 0 {
+			log.Warn("no history regions from index, the leader may be restarted", zap.Uint64("index", startIndex))
 		}
-		// do full synchronization
-		if startIndex == 0 {
-			regions := s.server.GetMetaRegions()
-			lastIndex := 0
-			start := time.Now()
-			res := make([]*metapb.Region, 0, maxSyncRegionBatchSize)
-			for syncedIndex, r := range regions {
-				res = append(res, r)
-				if len(res) < maxSyncRegionBatchSize && syncedIndex < len(regions)-1 {
-					continue
-				}
-				resp := &pdpb.SyncRegionResponse{
-					Header:     &pdpb.ResponseHeader{ClusterId: s.server.ClusterID()},
-					Regions:    res,
-					StartIndex: uint64(lastIndex),
-				}
-				s.limit.Wait(int64(resp.Size()))
-				lastIndex += len(res)
-				if err := stream.Send(resp); err != nil {
-					log.Error("failed to send sync region response", zap.Error(err))
-				}
-				res = res[:0]
+		regions := s.server.GetMetaRegions()
+		lastIndex := 0
+		start := time.Now()
+		res := make([]*metapb.Region, 0, maxSyncRegionBatchSize)
+		for syncedIndex, r := range regions {
+			res = append(res, r)
+			if len(res) < maxSyncRegionBatchSize && syncedIndex < len(regions)-1 {
+				continue
 			}
-			log.Info("requested server has completed full synchronization with server",
-				zap.String("requested-server", name), zap.String("server", s.server.Name()), zap.Duration("cost", time.Since(start)))
-			return nil
+			resp := &pdpb.SyncRegionResponse{
+				Header:     &pdpb.ResponseHeader{ClusterId: s.server.ClusterID()},
+				Regions:    res,
+				StartIndex: uint64(lastIndex),
+			}
+			s.limit.Wait(int64(resp.Size()))
+			lastIndex += len(res)
+			if err := stream.Send(resp); err != nil {
+				log.Error("failed to send sync region response", zap.Error(err))
+			}
+			res = res[:0]
 		}
-		log.Warn("no history regions from index, the leader may be restarted", zap.Uint64("index", startIndex))
-		return nil
+		log.Info("requested server has completed full synchronization with server",
+			zap.String("requested-server", name), zap.String("server", s.server.Name()), zap.Duration("cost", time.Since(start)))
+		return nextIndex, nil
 	}
 	log.Info("sync the history regions with server",
 		zap.String("server", name),
@@ -207,7 +224,7 @@ func (s *RegionSyncer) syncHistoryRegion(request *pdpb.SyncRegionRequest, stream
 		Regions:    regions,
 		StartIndex: startIndex,
 	}
-	return stream.Send(resp)
+	return startIndex + uint64(len(records)), errors.WithStack(stream.Send(resp))
 }
 
 // bindStream binds the established server stream.
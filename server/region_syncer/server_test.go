@@ -0,0 +1,93 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncer
+
+import (
+	"context"
+
+	"github.com/juju/ratelimit"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/server/core"
+)
+
+var _ = Suite(&testRegionSyncerServer{})
+
+type testRegionSyncerServer struct{}
+
+type mockServer struct {
+	regions []*metapb.Region
+}
+
+func (s *mockServer) Context() context.Context         { return context.Background() }
+func (s *mockServer) ClusterID() uint64                { return 1 }
+func (s *mockServer) GetMemberInfo() *pdpb.Member      { return &pdpb.Member{} }
+func (s *mockServer) GetLeader() *pdpb.Member          { return &pdpb.Member{} }
+func (s *mockServer) GetStorage() *core.KV             { return core.NewKV(core.NewMemoryKV()) }
+func (s *mockServer) Name() string                     { return "mock" }
+func (s *mockServer) GetMetaRegions() []*metapb.Region { return s.regions }
+
+type mockSyncStream struct {
+	pdpb.PD_SyncRegionsServer
+	resps []*pdpb.SyncRegionResponse
+}
+
+func (s *mockSyncStream) Send(resp *pdpb.SyncRegionResponse) error {
+	s.resps = append(s.resps, resp)
+	return nil
+}
+
+func (t *testRegionSyncerServer) TestSyncHistoryRegion(c *C) {
+	server := &mockServer{}
+	for i := 0; i < 3; i++ {
+		server.regions = append(server.regions, &metapb.Region{Id: uint64(i + 1)})
+	}
+	s := &RegionSyncer{
+		streams: make(map[string]ServerStream),
+		server:  server,
+		history: newHistoryBuffer(2, core.NewMemoryKV()),
+		limit:   ratelimit.NewBucketWithRate(defaultBucketRate, defaultBucketCapacity),
+	}
+	for _, r := range server.regions {
+		s.history.Record(core.NewRegionInfo(r, nil))
+	}
+	request := func(index uint64) *pdpb.SyncRegionRequest {
+		return &pdpb.SyncRegionRequest{Member: &pdpb.Member{Name: "follower"}, StartIndex: index}
+	}
+
+	// The follower is in sync.
+	stream := &mockSyncStream{}
+	index, err := s.syncHistoryRegion(request(3), stream)
+	c.Assert(err, IsNil)
+	c.Assert(index, Equals, uint64(3))
+	c.Assert(stream.resps, HasLen, 0)
+
+	// The history covers the index.
+	stream = &mockSyncStream{}
+	index, err = s.syncHistoryRegion(request(2), stream)
+	c.Assert(err, IsNil)
+	c.Assert(index, Equals, uint64(3))
+	c.Assert(stream.resps, HasLen, 1)
+	c.Assert(stream.resps[0].GetStartIndex(), Equals, uint64(2))
+	c.Assert(stream.resps[0].GetRegions(), HasLen, 1)
+
+	// The history does not cover the index, so all the regions are synced.
+	stream = &mockSyncStream{}
+	index, err = s.syncHistoryRegion(request(0), stream)
+	c.Assert(err, IsNil)
+	c.Assert(index, Equals, uint64(3))
+	c.Assert(stream.resps, HasLen, 1)
+	c.Assert(stream.resps[0].GetRegions(), HasLen, 3)
+}
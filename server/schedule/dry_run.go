@@ -0,0 +1,109 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultDryRunCapacity is the default number of operators kept by a
+// DryRunRecorder.
+const DefaultDryRunCapacity = 1024
+
+// DryRunRecord is an operator generated in dry-run mode, which is recorded
+// instead of being executed.
+type DryRunRecord struct {
+	Source     string                    `json:"source"`
+	Desc       string                    `json:"desc"`
+	RegionID   uint64                    `json:"region_id"`
+	Kind       string                    `json:"kind"`
+	Steps      []string                  `json:"steps"`
+	Influence  map[uint64]StoreInfluence `json:"influence"`
+	CreateTime time.Time                 `json:"create_time"`
+}
+
+// DryRunRecorder keeps the latest operators generated in dry-run mode in a
+// bounded buffer. The checkers generate the same operators for a region
+// again and again, so only the latest record of each region is kept.
+type DryRunRecorder struct {
+	sync.RWMutex
+	capacity int
+	records  *list.List // The front is the latest record.
+	regions  map[uint64]*list.Element
+}
+
+// NewDryRunRecorder creates a DryRunRecorder which keeps at most capacity
+// records.
+func NewDryRunRecorder(capacity int) *DryRunRecorder {
+	return &DryRunRecorder{
+		capacity: capacity,
+		records:  list.New(),
+		regions:  make(map[uint64]*list.Element),
+	}
+}
+
+// Record records the operators generated by the source, with the influence
+// they would have on each store.
+func (r *DryRunRecorder) Record(source string, cluster Cluster, ops ...*Operator) {
+	r.Lock()
+	defer r.Unlock()
+	for _, op := range ops {
+		record := &DryRunRecord{
+			Source:     source,
+			Desc:       op.Desc(),
+			RegionID:   op.RegionID(),
+			Kind:       op.Kind().String(),
+			Steps:      make([]string, 0, op.Len()),
+			Influence:  make(map[uint64]StoreInfluence),
+			CreateTime: op.createTime,
+		}
+		for i := 0; i < op.Len(); i++ {
+			record.Steps = append(record.Steps, fmt.Sprint(op.Step(i)))
+		}
+		for storeID, influence := range NewTotalOpInfluence([]*Operator{op}, cluster).storesInfluence {
+			record.Influence[storeID] = *influence
+		}
+		if e, ok := r.regions[record.RegionID]; ok {
+			r.records.Remove(e)
+		}
+		r.regions[record.RegionID] = r.records.PushFront(record)
+		for r.records.Len() > r.capacity {
+			e := r.records.Back()
+			delete(r.regions, e.Value.(*DryRunRecord).RegionID)
+			r.records.Remove(e)
+		}
+	}
+}
+
+// GetRecords returns the records from the latest to the oldest.
+func (r *DryRunRecorder) GetRecords() []*DryRunRecord {
+	r.RLock()
+	defer r.RUnlock()
+	records := make([]*DryRunRecord, 0, r.records.Len())
+	for e := r.records.Front(); e != nil; e = e.Next() {
+		records = append(records, e.Value.(*DryRunRecord))
+	}
+	return records
+}
+
+// Clear removes all the records.
+func (r *DryRunRecorder) Clear() {
+	r.Lock()
+	defer r.Unlock()
+	r.records.Init()
+	r.regions = make(map[uint64]*list.Element)
+}
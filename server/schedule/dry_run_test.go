@@ -0,0 +1,67 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/pkg/mock/mockoption"
+)
+
+var _ = Suite(&testDryRunSuite{})
+
+type testDryRunSuite struct{}
+
+func (s *testDryRunSuite) TestRecord(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	for i := uint64(1); i <= 3; i++ {
+		tc.AddLeaderStore(i, 1)
+		tc.AddLeaderRegion(i, 1, 2)
+	}
+	r := NewDryRunRecorder(2)
+
+	transfer := NewOperator("transfer", 1, &metapb.RegionEpoch{}, OpLeader, TransferLeader{FromStore: 1, ToStore: 2})
+	r.Record("test-scheduler", tc, transfer)
+	records := r.GetRecords()
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].Source, Equals, "test-scheduler")
+	c.Assert(records[0].Desc, Equals, "transfer")
+	c.Assert(records[0].RegionID, Equals, uint64(1))
+	c.Assert(records[0].Steps, DeepEquals, []string{TransferLeader{FromStore: 1, ToStore: 2}.String()})
+	c.Assert(records[0].Influence[1].LeaderCount, Equals, int64(-1))
+	c.Assert(records[0].Influence[2].LeaderCount, Equals, int64(1))
+
+	// Only the latest record of a region is kept.
+	remove := NewOperator("remove", 1, &metapb.RegionEpoch{}, OpRegion, RemovePeer{FromStore: 2})
+	r.Record("test-checker", tc, remove)
+	records = r.GetRecords()
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].Desc, Equals, "remove")
+	c.Assert(records[0].Influence[2].RegionCount, Equals, int64(-1))
+
+	// The oldest records are dropped when the buffer is full.
+	r.Record("test-scheduler", tc,
+		NewOperator("transfer", 2, &metapb.RegionEpoch{}, OpLeader, TransferLeader{FromStore: 1, ToStore: 2}),
+		NewOperator("transfer", 3, &metapb.RegionEpoch{}, OpLeader, TransferLeader{FromStore: 1, ToStore: 2}),
+	)
+	records = r.GetRecords()
+	c.Assert(records, HasLen, 2)
+	c.Assert(records[0].RegionID, Equals, uint64(3))
+	c.Assert(records[1].RegionID, Equals, uint64(2))
+
+	r.Clear()
+	c.Assert(r.GetRecords(), HasLen, 0)
+}
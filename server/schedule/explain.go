@@ -0,0 +1,187 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultExplainRounds is the default number of scheduling rounds kept by
+	// an Explainer.
+	DefaultExplainRounds = 32
+	// maxExplainComparisons is the max number of score comparisons recorded
+	// in a scheduling round.
+	maxExplainComparisons = 64
+)
+
+// The roles of the candidate stores.
+const (
+	SourceCandidate = "source"
+	TargetCandidate = "target"
+)
+
+// ExplainCandidate is a store considered as the source or target store in a
+// scheduling round.
+type ExplainCandidate struct {
+	StoreID uint64  `json:"store_id"`
+	Role    string  `json:"role"`
+	Score   float64 `json:"score"`
+	// FilteredBy is the type of the filter which rejects the store, or the
+	// reason why the store is rejected. It is empty if the store is not
+	// rejected.
+	FilteredBy string `json:"filtered_by,omitempty"`
+}
+
+// ScoreComparison is a comparison between the scores of the source and
+// target stores, which decides whether a region should be moved between
+// them. The scores are the ones after the region is moved.
+type ScoreComparison struct {
+	RegionID      uint64  `json:"region_id"`
+	SourceStore   uint64  `json:"source_store"`
+	TargetStore   uint64  `json:"target_store"`
+	SourceScore   float64 `json:"source_score"`
+	TargetScore   float64 `json:"target_score"`
+	RegionSize    int64   `json:"region_size"`
+	ShouldBalance bool    `json:"should_balance"`
+}
+
+// ExplainRound describes how a scheduler makes its decision in a scheduling
+// round. All methods are safe to call on a nil round, which records nothing.
+type ExplainRound struct {
+	Time        time.Time           `json:"time"`
+	Result      string              `json:"result"`
+	SourceStore uint64              `json:"source_store,omitempty"`
+	TargetStore uint64              `json:"target_store,omitempty"`
+	RegionID    uint64              `json:"region_id,omitempty"`
+	Candidates  []*ExplainCandidate `json:"candidates,omitempty"`
+	Comparisons []*ScoreComparison  `json:"comparisons,omitempty"`
+	// Skipped is the number of regions skipped by reason.
+	Skipped map[string]int `json:"skipped,omitempty"`
+
+	candidates map[candidateKey]*ExplainCandidate
+}
+
+type candidateKey struct {
+	role    string
+	storeID uint64
+}
+
+// AddCandidate records a candidate store. filter is the filter which rejects
+// the store, or nil if the store is not rejected.
+func (r *ExplainRound) AddCandidate(role string, storeID uint64, score float64, filter Filter) {
+	if filter != nil {
+		r.AddRejectedCandidate(role, storeID, score, filter.Type())
+		return
+	}
+	r.AddRejectedCandidate(role, storeID, score, "")
+}
+
+// AddRejectedCandidate records a candidate store rejected by the reason. A
+// store is recorded once for each role, and the latest record wins.
+func (r *ExplainRound) AddRejectedCandidate(role string, storeID uint64, score float64, reason string) {
+	if r == nil {
+		return
+	}
+	if r.candidates == nil {
+		r.candidates = make(map[candidateKey]*ExplainCandidate)
+	}
+	key := candidateKey{role: role, storeID: storeID}
+	c, ok := r.candidates[key]
+	if !ok {
+		c = &ExplainCandidate{StoreID: storeID, Role: role}
+		r.candidates[key] = c
+		r.Candidates = append(r.Candidates, c)
+	}
+	c.Score, c.FilteredBy = score, reason
+}
+
+// AddComparison records a score comparison.
+func (r *ExplainRound) AddComparison(c *ScoreComparison) {
+	if r == nil || len(r.Comparisons) >= maxExplainComparisons {
+		return
+	}
+	r.Comparisons = append(r.Comparisons, c)
+}
+
+// Skip records that a region is skipped for the reason.
+func (r *ExplainRound) Skip(reason string) {
+	if r == nil {
+		return
+	}
+	if r.Skipped == nil {
+		r.Skipped = make(map[string]int)
+	}
+	r.Skipped[reason]++
+}
+
+// SetResult sets the result of the round.
+func (r *ExplainRound) SetResult(result string) {
+	if r == nil {
+		return
+	}
+	r.Result = result
+}
+
+// SetDecision sets the region and the stores chosen in the round.
+func (r *ExplainRound) SetDecision(regionID, sourceStore, targetStore uint64) {
+	if r == nil {
+		return
+	}
+	r.RegionID, r.SourceStore, r.TargetStore = regionID, sourceStore, targetStore
+}
+
+// Explainer keeps the latest scheduling rounds of a scheduler.
+type Explainer struct {
+	sync.RWMutex
+	capacity int
+	rounds   []*ExplainRound // The first is the oldest one.
+}
+
+// NewExplainer creates an Explainer which keeps at most capacity rounds.
+func NewExplainer(capacity int) *Explainer {
+	return &Explainer{capacity: capacity}
+}
+
+// NewRound starts a new scheduling round. The round should be recorded by
+// Record when it is finished.
+func (e *Explainer) NewRound() *ExplainRound {
+	return &ExplainRound{Time: time.Now()}
+}
+
+// Record records a finished round.
+func (e *Explainer) Record(round *ExplainRound) {
+	e.Lock()
+	defer e.Unlock()
+	e.rounds = append(e.rounds, round)
+	if len(e.rounds) > e.capacity {
+		e.rounds = append(e.rounds[:0], e.rounds[len(e.rounds)-e.capacity:]...)
+	}
+}
+
+// GetRounds returns at most limit rounds from the latest to the oldest. All
+// rounds are returned if limit is not positive.
+func (e *Explainer) GetRounds(limit int) []*ExplainRound {
+	e.RLock()
+	defer e.RUnlock()
+	if limit <= 0 || limit > len(e.rounds) {
+		limit = len(e.rounds)
+	}
+	rounds := make([]*ExplainRound, 0, limit)
+	for i := len(e.rounds) - 1; i >= len(e.rounds)-limit; i-- {
+		rounds = append(rounds, e.rounds[i])
+	}
+	return rounds
+}
@@ -0,0 +1,70 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testExplainSuite{})
+
+type testExplainSuite struct{}
+
+func (s *testExplainSuite) TestRound(c *C) {
+	// A nil round records nothing.
+	var round *ExplainRound
+	round.AddCandidate(SourceCandidate, 1, 1, nil)
+	round.AddComparison(&ScoreComparison{})
+	round.Skip("skip")
+	round.SetResult("skip")
+	round.SetDecision(1, 1, 2)
+
+	round = NewExplainer(1).NewRound()
+	round.AddCandidate(SourceCandidate, 1, 10, nil)
+	round.AddCandidate(TargetCandidate, 1, 10, NewHealthFilter())
+	round.AddRejectedCandidate(TargetCandidate, 2, 5, "store-not-up")
+	// The latest record of a store wins.
+	round.AddCandidate(TargetCandidate, 2, 6, nil)
+	c.Assert(round.Candidates, DeepEquals, []*ExplainCandidate{
+		{StoreID: 1, Role: SourceCandidate, Score: 10},
+		{StoreID: 1, Role: TargetCandidate, Score: 10, FilteredBy: "health-filter"},
+		{StoreID: 2, Role: TargetCandidate, Score: 6},
+	})
+
+	for i := 0; i < maxExplainComparisons+1; i++ {
+		round.AddComparison(&ScoreComparison{RegionID: uint64(i)})
+	}
+	c.Assert(round.Comparisons, HasLen, maxExplainComparisons)
+	round.Skip("region_hot")
+	round.Skip("region_hot")
+	c.Assert(round.Skipped, DeepEquals, map[string]int{"region_hot": 2})
+}
+
+func (s *testExplainSuite) TestExplainer(c *C) {
+	e := NewExplainer(3)
+	for i := 0; i < 5; i++ {
+		round := e.NewRound()
+		round.SetDecision(uint64(i), 1, 2)
+		e.Record(round)
+	}
+	rounds := e.GetRounds(0)
+	c.Assert(rounds, HasLen, 3)
+	for i, round := range rounds {
+		c.Assert(round.RegionID, Equals, uint64(4-i))
+	}
+	rounds = e.GetRounds(2)
+	c.Assert(rounds, HasLen, 2)
+	c.Assert(rounds[0].RegionID, Equals, uint64(4))
+	c.Assert(e.GetRounds(10), HasLen, 3)
+}
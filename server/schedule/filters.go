@@ -34,28 +34,40 @@ type Filter interface {
 
 // FilterSource checks if store can pass all Filters as source store.
 func FilterSource(opt Options, store *core.StoreInfo, filters []Filter) bool {
+	return SourceFilteredBy(opt, store, filters) != nil
+}
+
+// SourceFilteredBy returns the first filter which rejects the store as a
+// source store, or nil if the store passes all filters.
+func SourceFilteredBy(opt Options, store *core.StoreInfo, filters []Filter) Filter {
 	storeAddress := store.GetAddress()
 	storeID := fmt.Sprintf("%d", store.GetID())
 	for _, filter := range filters {
 		if filter.FilterSource(opt, store) {
 			filterCounter.WithLabelValues("filter-source", storeAddress, storeID, filter.Type()).Inc()
-			return true
+			return filter
 		}
 	}
-	return false
+	return nil
 }
 
 // FilterTarget checks if store can pass all Filters as target store.
 func FilterTarget(opt Options, store *core.StoreInfo, filters []Filter) bool {
+	return TargetFilteredBy(opt, store, filters) != nil
+}
+
+// TargetFilteredBy returns the first filter which rejects the store as a
+// target store, or nil if the store passes all filters.
+func TargetFilteredBy(opt Options, store *core.StoreInfo, filters []Filter) Filter {
 	storeAddress := store.GetAddress()
 	storeID := fmt.Sprintf("%d", store.GetID())
 	for _, filter := range filters {
 		if filter.FilterTarget(opt, store) {
 			filterCounter.WithLabelValues("filter-target", storeAddress, storeID, filter.Type()).Inc()
-			return true
+			return filter
 		}
 	}
-	return false
+	return nil
 }
 
 type excludedFilter struct {
@@ -125,7 +137,8 @@ func (f *overloadFilter) FilterTarget(opt Options, store *core.StoreInfo) bool {
 
 type stateFilter struct{}
 
-// NewStateFilter creates a Filter that filters all stores that are not UP.
+// NewStateFilter creates a Filter that filters all stores that are not UP, and
+// the stores in maintenance from being the target.
 func NewStateFilter() Filter {
 	return &stateFilter{}
 }
@@ -139,7 +152,7 @@ func (f *stateFilter) FilterSource(opt Options, store *core.StoreInfo) bool {
 }
 
 func (f *stateFilter) FilterTarget(opt Options, store *core.StoreInfo) bool {
-	return !store.IsUp()
+	return !store.IsUp() || store.IsInMaintenance()
 }
 
 type healthFilter struct{}
@@ -240,6 +253,27 @@ func (f *snapshotCountFilter) FilterTarget(opt Options, store *core.StoreInfo) b
 	return f.filter(opt, store)
 }
 
+type snapshotThroughputFilter struct{}
+
+// NewSnapshotThroughputFilter creates a Filter that filters all stores that
+// are much slower than the other stores at applying snapshots, so they are
+// not selected as the targets.
+func NewSnapshotThroughputFilter() Filter {
+	return &snapshotThroughputFilter{}
+}
+
+func (f *snapshotThroughputFilter) Type() string {
+	return "snapshot-throughput-filter"
+}
+
+func (f *snapshotThroughputFilter) FilterSource(opt Options, store *core.StoreInfo) bool {
+	return false
+}
+
+func (f *snapshotThroughputFilter) FilterTarget(opt Options, store *core.StoreInfo) bool {
+	return store.IsSnapshotSlow()
+}
+
 type cacheFilter struct {
 	cache *cache.TTLUint64
 }
@@ -365,7 +399,98 @@ func (f rejectLeaderFilter) FilterSource(opt Options, store *core.StoreInfo) boo
 }
 
 func (f rejectLeaderFilter) FilterTarget(opt Options, store *core.StoreInfo) bool {
-	return opt.CheckLabelProperty(RejectLeader, store.GetLabels())
+	return isLeaderRejected(opt, store)
+}
+
+// isLeaderRejected checks if a store should not become the leader of regions
+// by its label properties or its maintenance.
+func isLeaderRejected(opt Options, store *core.StoreInfo) bool {
+	return opt.CheckLabelProperty(RejectLeader, store.GetLabels()) ||
+		opt.CheckLabelProperty(ReadOnly, store.GetLabels()) ||
+		store.IsInMaintenance()
+}
+
+type rejectPeerFilter struct{}
+
+// NewRejectPeerFilter creates a Filter that filters stores that marked as
+// rejectPeer or readOnly from being the target of adding peers.
+func NewRejectPeerFilter() Filter {
+	return rejectPeerFilter{}
+}
+
+func (f rejectPeerFilter) Type() string {
+	return "reject-peer-filter"
+}
+
+func (f rejectPeerFilter) FilterSource(opt Options, store *core.StoreInfo) bool {
+	return false
+}
+
+func (f rejectPeerFilter) FilterTarget(opt Options, store *core.StoreInfo) bool {
+	return isPeerRejected(opt, store)
+}
+
+// isPeerRejected checks if a store should not receive new peers by its label
+// properties.
+func isPeerRejected(opt Options, store *core.StoreInfo) bool {
+	return opt.CheckLabelProperty(RejectPeer, store.GetLabels()) ||
+		opt.CheckLabelProperty(ReadOnly, store.GetLabels())
+}
+
+type preferLeaderFilter struct {
+	leaderStore *core.StoreInfo
+}
+
+// NewPreferLeaderFilter creates a Filter that filters stores that are not
+// marked as preferLeader from being the target of leader transfer, if the
+// current leader store is marked as preferLeader. The leader store can be nil.
+func NewPreferLeaderFilter(leaderStore *core.StoreInfo) Filter {
+	return &preferLeaderFilter{leaderStore: leaderStore}
+}
+
+func (f *preferLeaderFilter) Type() string {
+	return "prefer-leader-filter"
+}
+
+func (f *preferLeaderFilter) FilterSource(opt Options, store *core.StoreInfo) bool {
+	return false
+}
+
+func (f *preferLeaderFilter) FilterTarget(opt Options, store *core.StoreInfo) bool {
+	return f.leaderStore != nil &&
+		opt.CheckLabelProperty(PreferLeader, f.leaderStore.GetLabels()) &&
+		!opt.CheckLabelProperty(PreferLeader, store.GetLabels())
+}
+
+type leaderPolicyFilter struct {
+	policy *core.LeaderPolicy
+	rank   int
+}
+
+// NewLeaderPolicyFilter creates a Filter that filters stores which are less
+// preferred than the current leader store by the leader policy from being the
+// target of leader transfer. The policy can be nil.
+func NewLeaderPolicyFilter(policy *core.LeaderPolicy, leaderStore *core.StoreInfo) Filter {
+	f := &leaderPolicyFilter{policy: policy}
+	if policy != nil {
+		f.rank = len(policy.Preferences)
+		if leaderStore != nil {
+			f.rank = policy.GetStoreRank(leaderStore)
+		}
+	}
+	return f
+}
+
+func (f *leaderPolicyFilter) Type() string {
+	return "leader-policy-filter"
+}
+
+func (f *leaderPolicyFilter) FilterSource(opt Options, store *core.StoreInfo) bool {
+	return false
+}
+
+func (f *leaderPolicyFilter) FilterTarget(opt Options, store *core.StoreInfo) bool {
+	return f.policy != nil && f.policy.GetStoreRank(store) > f.rank
 }
 
 // StoreStateFilter is used to determine whether a store can be selected as the
@@ -404,6 +529,7 @@ func (f StoreStateFilter) FilterSource(opt Options, store *core.StoreInfo) bool
 func (f StoreStateFilter) FilterTarget(opt Options, store *core.StoreInfo) bool {
 	if store.IsTombstone() ||
 		store.IsOffline() ||
+		store.IsInMaintenance() ||
 		store.DownTime() > opt.GetMaxStoreDownTime() {
 		return true
 	}
@@ -411,11 +537,11 @@ func (f StoreStateFilter) FilterTarget(opt Options, store *core.StoreInfo) bool
 		(store.IsDisconnected() ||
 			store.IsBlocked() ||
 			store.GetIsBusy() ||
-			opt.CheckLabelProperty(RejectLeader, store.GetLabels())) {
+			isLeaderRejected(opt, store)) {
 		return true
 	}
 
-	if f.MoveRegion && f.filterMoveRegion(opt, store) {
+	if f.MoveRegion && (f.filterMoveRegion(opt, store) || isPeerRejected(opt, store)) {
 		return true
 	}
 	return false
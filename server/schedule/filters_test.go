@@ -38,3 +38,36 @@ func (s *testReplicationSuite) TestPendingPeerFilter(c *C) {
 	c.Assert(filter.FilterSource(tc, newStore), IsFalse)
 	c.Assert(filter.FilterTarget(tc, newStore), IsFalse)
 }
+
+func (s *testFiltersSuite) TestLabelPropertyFilters(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	opt.LabelProperties = map[string][]*metapb.StoreLabel{
+		RejectPeer:   {{Key: "disk", Value: "hdd"}},
+		ReadOnly:     {{Key: "zone", Value: "z3"}},
+		PreferLeader: {{Key: "zone", Value: "z1"}},
+	}
+	tc := mockcluster.NewCluster(opt)
+	newStore := func(id uint64, labels ...*metapb.StoreLabel) *core.StoreInfo {
+		return core.NewStoreInfo(&metapb.Store{Id: id, Labels: labels})
+	}
+	store1 := newStore(1, &metapb.StoreLabel{Key: "zone", Value: "z1"})
+	store2 := newStore(2, &metapb.StoreLabel{Key: "zone", Value: "z2"}, &metapb.StoreLabel{Key: "disk", Value: "hdd"})
+	store3 := newStore(3, &metapb.StoreLabel{Key: "zone", Value: "z3"})
+
+	rejectPeer := NewRejectPeerFilter()
+	c.Assert(rejectPeer.FilterTarget(tc, store1), IsFalse)
+	c.Assert(rejectPeer.FilterTarget(tc, store2), IsTrue)
+	c.Assert(rejectPeer.FilterTarget(tc, store3), IsTrue)
+	c.Assert(rejectPeer.FilterSource(tc, store2), IsFalse)
+
+	rejectLeader := NewRejectLeaderFilter()
+	c.Assert(rejectLeader.FilterTarget(tc, store2), IsFalse)
+	c.Assert(rejectLeader.FilterTarget(tc, store3), IsTrue)
+
+	preferLeader := NewPreferLeaderFilter(store1)
+	c.Assert(preferLeader.FilterTarget(tc, store2), IsTrue)
+	preferLeader = NewPreferLeaderFilter(store2)
+	c.Assert(preferLeader.FilterTarget(tc, store1), IsFalse)
+	c.Assert(preferLeader.FilterTarget(tc, store3), IsFalse)
+	c.Assert(NewPreferLeaderFilter(nil).FilterTarget(tc, store2), IsFalse)
+}
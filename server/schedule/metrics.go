@@ -74,6 +74,22 @@ var (
 			Name:      "store_limit",
 			Help:      "Limit of store.",
 		}, []string{"store", "type"})
+
+	storeTypeLimitGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "schedule",
+			Name:      "store_type_limit",
+			Help:      "Limit of store by the limit type.",
+		}, []string{"store", "type", "limit_type"})
+
+	waitingOperatorGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "schedule",
+			Name:      "waiting_operators",
+			Help:      "Number of waiting operators of each priority class.",
+		}, []string{"class"})
 )
 
 func init() {
@@ -83,5 +99,7 @@ func init() {
 	prometheus.MustRegister(operatorDuration)
 	prometheus.MustRegister(operatorWaitDuration)
 	prometheus.MustRegister(storeLimitGauge)
+	prometheus.MustRegister(storeTypeLimitGauge)
 	prometheus.MustRegister(operatorWaitCounter)
+	prometheus.MustRegister(waitingOperatorGauge)
 }
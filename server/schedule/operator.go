@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"math/rand"
 	"reflect"
+	"sort"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -37,6 +38,10 @@ const (
 	// RegionOperatorWaitTime is the duration that when a region operator lives
 	// longer than it, the operator will be considered timeout.
 	RegionOperatorWaitTime = 10 * time.Minute
+	// ConfChangeStepWaitTime is the duration that when a step which changes
+	// the peers of a region without sending snapshots lasts longer than it,
+	// the operator will be considered timeout.
+	ConfChangeStepWaitTime = 2 * time.Minute
 	// RegionInfluence represents the influence of a operator step, which is used by ratelimit.
 	RegionInfluence int64 = 1000
 )
@@ -176,6 +181,7 @@ func (rp RemovePeer) Influence(opInfluence OpInfluence, region *core.RegionInfo)
 
 	from.RegionSize -= region.GetApproximateSize()
 	from.RegionCount--
+	from.RemoveStepCost += RegionInfluence
 }
 
 // MergeRegion is an OperatorStep that merge two regions.
@@ -313,6 +319,9 @@ type Operator struct {
 	startTime time.Time
 	stepTime  int64
 	level     core.PriorityLevel
+	// source is the name of the scheduler or checker which creates the
+	// operator.
+	source string
 }
 
 // NewOperator creates a new operator.
@@ -355,6 +364,18 @@ func (o *Operator) SetDesc(desc string) {
 	o.desc = desc
 }
 
+// Source returns the name of the scheduler or checker which creates the
+// operator.
+func (o *Operator) Source() string {
+	return o.source
+}
+
+// SetSource sets the name of the scheduler or checker which creates the
+// operator.
+func (o *Operator) SetSource(source string) {
+	o.source = source
+}
+
 // AttachKind attaches an operator kind for the operator.
 func (o *Operator) AttachKind(kind OperatorKind) {
 	o.kind |= kind
@@ -439,7 +460,8 @@ func (o *Operator) IsFinish() bool {
 	return atomic.LoadInt32(&o.currentStep) >= int32(len(o.steps))
 }
 
-// IsTimeout checks the operator's create time and determines if it is timeout.
+// IsTimeout checks the operator's start time and the start time of its
+// current step, and determines if it is timeout.
 func (o *Operator) IsTimeout() bool {
 	var timeout bool
 	if o.IsFinish() {
@@ -456,7 +478,33 @@ func (o *Operator) IsTimeout() bool {
 	if timeout {
 		return true
 	}
-	return false
+	return o.isStepTimeout()
+}
+
+// isStepTimeout checks if the current step lasts longer than its wait time.
+func (o *Operator) isStepTimeout() bool {
+	current := int(atomic.LoadInt32(&o.currentStep))
+	if current >= len(o.steps) {
+		return false
+	}
+	stepStart := time.Unix(0, atomic.LoadInt64(&o.stepTime))
+	if stepStart.Before(o.startTime) {
+		stepStart = o.startTime
+	}
+	return time.Since(stepStart) > stepWaitTime(o.steps[current])
+}
+
+// stepWaitTime returns the max duration of a step. The steps which need to
+// send snapshots can last as long as the whole region operator.
+func stepWaitTime(step OperatorStep) time.Duration {
+	switch step.(type) {
+	case TransferLeader:
+		return LeaderOperatorWaitTime
+	case PromoteLearner, RemovePeer:
+		return ConfChangeStepWaitTime
+	default:
+		return RegionOperatorWaitTime
+	}
 }
 
 // UnfinishedInfluence calculates the store difference which unfinished operator steps make.
@@ -480,6 +528,9 @@ type OperatorHistory struct {
 	FinishTime time.Time
 	From, To   uint64
 	Kind       core.ResourceKind
+	// Rollback indicates the history is the cleanup of a timed out operator.
+	// To is 0 for the orphaned peers removed by the cleanup.
+	Rollback bool
 }
 
 // History transfers the operator's steps to operator histories.
@@ -508,6 +559,19 @@ func (o *Operator) History() []OperatorHistory {
 			removePeerStores = append(removePeerStores, s.FromStore)
 		}
 	}
+	if o.kind&OpRollback != 0 {
+		for _, storeID := range removePeerStores {
+			histories = append(histories, OperatorHistory{
+				FinishTime: now,
+				From:       storeID,
+				Kind:       core.RegionKind,
+			})
+		}
+		for i := range histories {
+			histories[i].Rollback = true
+		}
+		return histories
+	}
 	for i := range addPeerStores {
 		if i < len(removePeerStores) {
 			histories = append(histories, OperatorHistory{
@@ -523,7 +587,11 @@ func (o *Operator) History() []OperatorHistory {
 
 // CreateAddPeerOperator creates an operator that adds a new peer.
 func CreateAddPeerOperator(desc string, cluster Cluster, region *core.RegionInfo, peerID uint64, toStoreID uint64, kind OperatorKind) *Operator {
-	steps := CreateAddPeerSteps(toStoreID, peerID, cluster)
+	_, steps := snapshotSourceSteps(cluster, region, 0)
+	if len(steps) > 0 {
+		kind |= OpLeader
+	}
+	steps = append(steps, CreateAddPeerSteps(toStoreID, peerID, cluster)...)
 	return NewOperator(desc, region.GetID(), region.GetRegionEpoch(), kind|OpRegion, steps...)
 }
 
@@ -629,13 +697,18 @@ func CreateMoveRegionOperator(desc string, cluster Cluster, region *core.RegionI
 
 // CreateMovePeerOperator creates an operator that replaces an old peer with a new peer.
 func CreateMovePeerOperator(desc string, cluster Cluster, region *core.RegionInfo, kind OperatorKind, oldStore, newStore uint64, peerID uint64) (*Operator, error) {
+	origin := region
+	region, st := snapshotSourceSteps(cluster, region, oldStore)
+	if len(st) > 0 {
+		kind |= OpLeader
+	}
 	removeKind, steps, err := removePeerSteps(cluster, region, oldStore, append(getRegionFollowerIDs(region), newStore))
 	if err != nil {
 		return nil, err
 	}
-	st := CreateAddPeerSteps(newStore, peerID, cluster)
+	st = append(st, CreateAddPeerSteps(newStore, peerID, cluster)...)
 	steps = append(st, steps...)
-	return NewOperator(desc, region.GetID(), region.GetRegionEpoch(), removeKind|kind|OpRegion, steps...), nil
+	return NewOperator(desc, origin.GetID(), origin.GetRegionEpoch(), removeKind|kind|OpRegion, steps...), nil
 }
 
 // CreateMoveLeaderOperator creates an operator that replaces an old leader with a new leader.
@@ -668,12 +741,37 @@ func getRegionFollowerIDs(region *core.RegionInfo) []uint64 {
 	return ids
 }
 
+// snapshotSourceSteps returns the step to transfer the leader to a follower
+// before adding peers if the leader store is marked as noSnapshotSource, as
+// the new peers receive snapshots from the leader, and the region after the
+// leader is transferred. The follower on the excluded store is not chosen. It
+// returns the region itself and no steps if the leader needs not or cannot be
+// transferred.
+func snapshotSourceSteps(cluster Cluster, region *core.RegionInfo, excludedStore uint64) (*core.RegionInfo, []OperatorStep) {
+	leaderStore := cluster.GetStore(region.GetLeader().GetStoreId())
+	if leaderStore == nil || !cluster.CheckLabelProperty(NoSnapshotSource, leaderStore.GetLabels()) {
+		return region, nil
+	}
+	for _, peer := range region.GetFollowers() {
+		if peer.GetStoreId() == excludedStore || region.GetDownPeer(peer.GetId()) != nil || region.GetPendingPeer(peer.GetId()) != nil {
+			continue
+		}
+		store := cluster.GetStore(peer.GetStoreId())
+		if store == nil || isLeaderRejected(cluster, store) || cluster.CheckLabelProperty(NoSnapshotSource, store.GetLabels()) {
+			continue
+		}
+		step := TransferLeader{FromStore: leaderStore.GetID(), ToStore: store.GetID()}
+		return region.Clone(core.WithLeader(peer)), []OperatorStep{step}
+	}
+	return region, nil
+}
+
 // removePeerSteps returns the steps to safely remove a peer. It prevents removing leader by transfer its leadership first.
 func removePeerSteps(cluster Cluster, region *core.RegionInfo, storeID uint64, followerIDs []uint64) (kind OperatorKind, steps []OperatorStep, err error) {
 	if region.GetLeader() != nil && region.GetLeader().GetStoreId() == storeID {
 		for _, id := range followerIDs {
 			follower := cluster.GetStore(id)
-			if follower != nil && !cluster.CheckLabelProperty(RejectLeader, follower.GetLabels()) {
+			if follower != nil && !isLeaderRejected(cluster, follower) {
 				steps = append(steps, TransferLeader{FromStore: storeID, ToStore: id})
 				kind = OpLeader
 				break
@@ -904,6 +1002,72 @@ func CreateScatterRegionOperator(desc string, cluster Cluster, origin *core.Regi
 	return op
 }
 
+// CreateRollbackOperator creates an operator that cleans up the steps applied
+// by a timed out operator. It removes the learners added by the operator, and
+// the voters added by the operator if the operator has not finished removing
+// the peers they replace. It also transfers the leader back if the operator
+// has moved it. It returns nil if there is nothing to clean up.
+func CreateRollbackOperator(op *Operator, region *core.RegionInfo) *Operator {
+	if op.Kind()&OpRollback != 0 || op.IsFinish() {
+		return nil
+	}
+	current := int(atomic.LoadInt32(&op.currentStep))
+	addedPeers := make(map[uint64]uint64) // store ID -> peer ID
+	var originLeader uint64
+	var removing bool
+	for i, step := range op.steps {
+		switch s := step.(type) {
+		case AddPeer:
+			addedPeers[s.ToStore] = s.PeerID
+		case AddLightPeer:
+			addedPeers[s.ToStore] = s.PeerID
+		case AddLearner:
+			addedPeers[s.ToStore] = s.PeerID
+		case AddLightLearner:
+			addedPeers[s.ToStore] = s.PeerID
+		case TransferLeader:
+			if originLeader == 0 && i < current {
+				originLeader = s.FromStore
+			}
+		case RemovePeer:
+			if i >= current {
+				removing = true
+			}
+		}
+	}
+
+	var steps []OperatorStep
+	kind := OpRollback
+	leader := region.GetLeader().GetStoreId()
+	if originLeader != 0 && originLeader != leader && region.GetStoreVoter(originLeader) != nil {
+		steps = append(steps, TransferLeader{FromStore: leader, ToStore: originLeader})
+		kind |= OpLeader
+		leader = originLeader
+	}
+	var orphans []uint64
+	for storeID, peerID := range addedPeers {
+		if storeID == leader {
+			continue
+		}
+		if p := region.GetStoreLearner(storeID); p != nil && p.GetId() == peerID {
+			orphans = append(orphans, storeID)
+		} else if p := region.GetStoreVoter(storeID); p != nil && p.GetId() == peerID && removing {
+			orphans = append(orphans, storeID)
+		}
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i] < orphans[j] })
+	for _, storeID := range orphans {
+		steps = append(steps, RemovePeer{FromStore: storeID})
+		kind |= OpRegion
+	}
+	if len(steps) == 0 {
+		return nil
+	}
+	rollback := NewOperator(fmt.Sprintf("rollback-%s", op.Desc()), region.GetID(), region.GetRegionEpoch(), kind, steps...)
+	rollback.SetPriorityLevel(core.HighPriority)
+	return rollback
+}
+
 // CheckOperatorValid checks if the operator is valid.
 func CheckOperatorValid(op *Operator) bool {
 	removeStores := []uint64{}
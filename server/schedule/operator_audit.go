@@ -0,0 +1,329 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	log "github.com/pingcap/log"
+	"github.com/pingcap/pd/server/core"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// MaxOperatorAuditRecords is the max number of operator audit records kept in
+// storage. The oldest records are removed when it is exceeded.
+const MaxOperatorAuditRecords = 100000
+
+// operatorAuditQueryWindow is the initial time window to load the latest
+// records of a query with a limit, which is doubled until enough records are
+// found.
+const operatorAuditQueryWindow = time.Minute
+
+// OperatorAuditRecord is the persisted record of an operator which has
+// started and then finished, timed out, been canceled or been replaced.
+type OperatorAuditRecord struct {
+	RegionID   uint64    `json:"region_id"`
+	Desc       string    `json:"desc"`
+	Kind       string    `json:"kind"`
+	Source     string    `json:"source"`
+	Steps      []string  `json:"steps"`
+	Stores     []uint64  `json:"stores"`
+	Status     string    `json:"status"`
+	CreateTime time.Time `json:"create_time"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+}
+
+// NewOperatorAuditRecord creates the audit record of an operator which ends
+// with the status.
+func NewOperatorAuditRecord(op *Operator, status pdpb.OperatorStatus) *OperatorAuditRecord {
+	source := op.Source()
+	if source == "" && op.Kind()&OpAdmin != 0 {
+		source = "admin"
+	}
+	record := &OperatorAuditRecord{
+		RegionID:   op.RegionID(),
+		Desc:       op.Desc(),
+		Kind:       op.Kind().String(),
+		Source:     source,
+		Steps:      make([]string, 0, op.Len()),
+		Status:     status.String(),
+		CreateTime: op.createTime,
+		StartTime:  op.GetStartTime(),
+		EndTime:    time.Now(),
+	}
+	stores := make(map[uint64]struct{})
+	for i := 0; i < op.Len(); i++ {
+		step := op.Step(i)
+		record.Steps = append(record.Steps, fmt.Sprint(step))
+		for _, id := range stepStores(step) {
+			stores[id] = struct{}{}
+		}
+	}
+	for id := range stores {
+		record.Stores = append(record.Stores, id)
+	}
+	sort.Slice(record.Stores, func(i, j int) bool { return record.Stores[i] < record.Stores[j] })
+	return record
+}
+
+// stepStores returns the stores involved in a step.
+func stepStores(step OperatorStep) []uint64 {
+	switch s := step.(type) {
+	case TransferLeader:
+		return []uint64{s.FromStore, s.ToStore}
+	case AddPeer:
+		return []uint64{s.ToStore}
+	case AddLearner:
+		return []uint64{s.ToStore}
+	case AddLightPeer:
+		return []uint64{s.ToStore}
+	case AddLightLearner:
+		return []uint64{s.ToStore}
+	case PromoteLearner:
+		return []uint64{s.ToStore}
+	case RemovePeer:
+		return []uint64{s.FromStore}
+	}
+	return nil
+}
+
+// OperatorAuditFilter selects the operator audit records. The zero value of a
+// field matches all records.
+type OperatorAuditFilter struct {
+	RegionID uint64
+	StoreID  uint64
+	// Kind matches the records which have all the flags of it.
+	Kind OperatorKind
+	// Start and End select the records which end in [Start, End).
+	Start, End time.Time
+	// Limit is the max number of the latest records to return.
+	Limit int
+}
+
+func (f *OperatorAuditFilter) match(r *OperatorAuditRecord) bool {
+	if f.RegionID != 0 && r.RegionID != f.RegionID {
+		return false
+	}
+	if f.StoreID != 0 {
+		found := false
+		for _, id := range r.Stores {
+			if id == f.StoreID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Kind != 0 {
+		kind, err := ParseOperatorKind(r.Kind)
+		if err != nil || kind&f.Kind != f.Kind {
+			return false
+		}
+	}
+	return true
+}
+
+// OperatorAuditLog persists the audit records of the operators, so they are
+// kept after the PD leader changes. The records are buffered in memory and
+// saved by Flush.
+type OperatorAuditLog struct {
+	mu      sync.Mutex
+	pending []*OperatorAuditRecord
+
+	// storageMu serializes the operations on the storage.
+	storageMu sync.Mutex
+	kv        *core.KV
+	// count is the number of records in storage, which is -1 before being
+	// loaded by the first Prune.
+	count int
+	// seq tells apart the records ending at the same time in storage.
+	seq uint64
+}
+
+// NewOperatorAuditLog creates an OperatorAuditLog which saves the records to kv.
+func NewOperatorAuditLog(kv *core.KV) *OperatorAuditLog {
+	return &OperatorAuditLog{
+		kv:    kv,
+		count: -1,
+	}
+}
+
+// Append appends a record which will be saved by the next Flush.
+func (l *OperatorAuditLog) Append(record *OperatorAuditRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending = append(l.pending, record)
+	// Drop the oldest records if the storage is unavailable for a long time.
+	if len(l.pending) > MaxOperatorAuditRecords {
+		l.pending = append(l.pending[:0], l.pending[len(l.pending)-MaxOperatorAuditRecords:]...)
+	}
+}
+
+func (l *OperatorAuditLog) takePending() []*OperatorAuditRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	pending := l.pending
+	l.pending = nil
+	return pending
+}
+
+func (l *OperatorAuditLog) restorePending(records []*OperatorAuditRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending = append(records, l.pending...)
+}
+
+// Flush saves the pending records to storage. The records failed to save are
+// kept and saved by the next Flush.
+func (l *OperatorAuditLog) Flush() error {
+	l.storageMu.Lock()
+	defer l.storageMu.Unlock()
+	pending := l.takePending()
+	for i, record := range pending {
+		l.seq++
+		if err := l.kv.SaveOperatorHistory(record.EndTime, l.seq, record); err != nil {
+			l.restorePending(pending[i:])
+			return err
+		}
+		if l.count >= 0 {
+			l.count++
+		}
+	}
+	return nil
+}
+
+// Prune removes the records which end before the retention, and the oldest
+// records if there are more than MaxOperatorAuditRecords records.
+func (l *OperatorAuditLog) Prune(retention time.Duration) error {
+	l.storageMu.Lock()
+	defer l.storageMu.Unlock()
+	if l.count < 0 {
+		count := 0
+		if err := l.kv.LoadOperatorHistory(time.Unix(0, 0), time.Now().Add(time.Hour), func(string, string) bool {
+			count++
+			return true
+		}); err != nil {
+			return err
+		}
+		l.count = count
+	}
+
+	// Only the records to remove are loaded, which are the ones ending before
+	// the cutoff, and then the oldest ones beyond MaxOperatorAuditRecords.
+	var expired []string
+	cutoff := time.Now().Add(-retention)
+	if err := l.kv.LoadOperatorHistory(time.Unix(0, 0), cutoff, func(k, _ string) bool {
+		expired = append(expired, k)
+		return true
+	}); err != nil {
+		return err
+	}
+	if l.count-len(expired) > MaxOperatorAuditRecords {
+		if err := l.kv.LoadOperatorHistory(cutoff, time.Now().Add(time.Hour), func(k, _ string) bool {
+			expired = append(expired, k)
+			return l.count-len(expired) > MaxOperatorAuditRecords
+		}); err != nil {
+			return err
+		}
+	}
+	for _, key := range expired {
+		if err := l.kv.DeleteOperatorHistory(key); err != nil {
+			return err
+		}
+		l.count--
+	}
+	if len(expired) > 0 {
+		log.Info("prune operator audit records", zap.Int("count", len(expired)))
+	}
+	return nil
+}
+
+// Query returns the records selected by the filter from the latest to the
+// oldest. The pending records are saved first.
+func (l *OperatorAuditLog) Query(filter OperatorAuditFilter) ([]*OperatorAuditRecord, error) {
+	if err := l.Flush(); err != nil {
+		return nil, err
+	}
+	start, end := filter.Start, filter.End
+	if start.IsZero() {
+		start = time.Unix(0, 0)
+	}
+	if end.IsZero() {
+		end = time.Now().Add(time.Hour)
+	}
+	l.storageMu.Lock()
+	defer l.storageMu.Unlock()
+	if filter.Limit <= 0 {
+		return l.load(start, end, filter)
+	}
+
+	// The latest records are loaded in the windows going back from the end,
+	// so the older records are not loaded once there are enough.
+	var records []*OperatorAuditRecord
+	window := operatorAuditQueryWindow
+	for end.After(start) && len(records) < filter.Limit {
+		windowStart := end.Add(-window)
+		if windowStart.Before(start) {
+			windowStart = start
+		}
+		loaded, err := l.load(windowStart, end, filter)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, loaded...)
+		end = windowStart
+		window *= 2
+	}
+	if len(records) > filter.Limit {
+		records = records[:filter.Limit]
+	}
+	return records, nil
+}
+
+// load returns the records in storage which end in [start, end) and are
+// selected by the filter, from the latest to the oldest.
+func (l *OperatorAuditLog) load(start, end time.Time, filter OperatorAuditFilter) ([]*OperatorAuditRecord, error) {
+	var records []*OperatorAuditRecord
+	var loadErr error
+	err := l.kv.LoadOperatorHistory(start, end, func(_, v string) bool {
+		record := &OperatorAuditRecord{}
+		if err := json.Unmarshal([]byte(v), record); err != nil {
+			loadErr = errors.WithStack(err)
+			return false
+		}
+		if filter.match(record) {
+			records = append(records, record)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	return records, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/server/core"
+)
+
+var _ = Suite(&testOperatorAuditSuite{})
+
+type testOperatorAuditSuite struct{}
+
+func (s *testOperatorAuditSuite) TestAuditLog(c *C) {
+	kv := core.NewKV(core.NewMemoryKV())
+	l := NewOperatorAuditLog(kv)
+
+	transfer := NewOperator("transfer", 1, &metapb.RegionEpoch{}, OpLeader|OpAdmin, TransferLeader{FromStore: 1, ToStore: 2})
+	transfer.SetStartTime(time.Now())
+	record := NewOperatorAuditRecord(transfer, pdpb.OperatorStatus_SUCCESS)
+	c.Assert(record.Source, Equals, "admin")
+	c.Assert(record.Stores, DeepEquals, []uint64{1, 2})
+	c.Assert(record.Status, Equals, "SUCCESS")
+	l.Append(record)
+
+	move := NewOperator("move", 2, &metapb.RegionEpoch{}, OpRegion|OpBalance, AddPeer{ToStore: 3, PeerID: 3}, RemovePeer{FromStore: 1})
+	move.SetSource("balance-region-scheduler")
+	move.SetStartTime(time.Now())
+	record = NewOperatorAuditRecord(move, pdpb.OperatorStatus_TIMEOUT)
+	record.EndTime = record.EndTime.Add(time.Second)
+	l.Append(record)
+
+	// The pending records are saved before querying.
+	records, err := l.Query(OperatorAuditFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 2)
+	c.Assert(records[0].RegionID, Equals, uint64(2))
+	c.Assert(records[0].Source, Equals, "balance-region-scheduler")
+	c.Assert(records[0].Steps, DeepEquals, []string{AddPeer{ToStore: 3, PeerID: 3}.String(), RemovePeer{FromStore: 1}.String()})
+	c.Assert(records[1].RegionID, Equals, uint64(1))
+
+	// The records are kept by a new log.
+	l = NewOperatorAuditLog(kv)
+	records, err = l.Query(OperatorAuditFilter{StoreID: 1})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 2)
+	records, err = l.Query(OperatorAuditFilter{StoreID: 2})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].RegionID, Equals, uint64(1))
+	records, err = l.Query(OperatorAuditFilter{RegionID: 2})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 1)
+	records, err = l.Query(OperatorAuditFilter{Kind: OpRegion | OpBalance})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].RegionID, Equals, uint64(2))
+	records, err = l.Query(OperatorAuditFilter{Kind: OpRegion | OpAdmin})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 0)
+	records, err = l.Query(OperatorAuditFilter{Limit: 1})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].RegionID, Equals, uint64(2))
+	records, err = l.Query(OperatorAuditFilter{End: time.Now().Add(500 * time.Millisecond)})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].RegionID, Equals, uint64(1))
+
+	// Only the records in the retention are kept.
+	c.Assert(l.Prune(time.Hour), IsNil)
+	records, err = l.Query(OperatorAuditFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 2)
+	c.Assert(l.Prune(-500*time.Millisecond), IsNil)
+	records, err = l.Query(OperatorAuditFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].RegionID, Equals, uint64(2))
+}
+
+func (s *testOperatorAuditSuite) TestSameEndTime(c *C) {
+	l := NewOperatorAuditLog(core.NewKV(core.NewMemoryKV()))
+	now := time.Now()
+	for _, status := range []pdpb.OperatorStatus{pdpb.OperatorStatus_CANCEL, pdpb.OperatorStatus_SUCCESS} {
+		op := NewOperator("transfer", 1, &metapb.RegionEpoch{}, OpLeader, TransferLeader{FromStore: 1, ToStore: 2})
+		record := NewOperatorAuditRecord(op, status)
+		record.EndTime = now
+		l.Append(record)
+	}
+	// The records of the same region ending at the same time are both kept.
+	records, err := l.Query(OperatorAuditFilter{RegionID: 1})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 2)
+	c.Assert(records[0].Status, Equals, "SUCCESS")
+	c.Assert(records[1].Status, Equals, "CANCEL")
+}
+
+func (s *testOperatorAuditSuite) TestQueryLimit(c *C) {
+	l := NewOperatorAuditLog(core.NewKV(core.NewMemoryKV()))
+	now := time.Now()
+	// The records spread over a day, which take several windows to load.
+	for i := 0; i < 24; i++ {
+		op := NewOperator("transfer", uint64(i%2+1), &metapb.RegionEpoch{}, OpLeader, TransferLeader{FromStore: 1, ToStore: 2})
+		record := NewOperatorAuditRecord(op, pdpb.OperatorStatus_SUCCESS)
+		record.EndTime = now.Add(-time.Duration(i) * time.Hour)
+		l.Append(record)
+	}
+	records, err := l.Query(OperatorAuditFilter{RegionID: 1, Limit: 5})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 5)
+	for i, record := range records {
+		c.Assert(record.RegionID, Equals, uint64(1))
+		c.Assert(record.EndTime.Equal(now.Add(-time.Duration(2*i)*time.Hour)), IsTrue)
+	}
+	records, err = l.Query(OperatorAuditFilter{Start: now.Add(-90 * time.Minute), Limit: 5})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 2)
+	records, err = l.Query(OperatorAuditFilter{Limit: 100})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 24)
+}
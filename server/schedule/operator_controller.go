@@ -16,9 +16,11 @@ package schedule
 import (
 	"container/heap"
 	"container/list"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/juju/ratelimit"
@@ -28,6 +30,7 @@ import (
 	log "github.com/pingcap/log"
 	"github.com/pingcap/pd/server/cache"
 	"github.com/pingcap/pd/server/core"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
@@ -63,10 +66,14 @@ type OperatorController struct {
 	counts    map[OperatorKind]uint64
 	opRecords *OperatorRecords
 	// TODO: Need to clean up the unused store ID.
-	storesLimit     map[uint64]*ratelimit.Bucket
-	wop             WaitingOperator
+	storesLimit     map[uint64]map[StoreLimitType]*storeLimit
+	limitSettings   map[string]*StoreLimitSetting
+	kv              *core.KV
+	wop             *FairQueue
 	wopStatus       *WaitingOperatorStatus
 	opNotifierQueue operatorQueue
+	auditLog        *OperatorAuditLog
+	events          *OperatorEventHub
 }
 
 // NewOperatorController creates a OperatorController.
@@ -78,19 +85,109 @@ func NewOperatorController(cluster Cluster, hbStreams HeartbeatStreams) *Operato
 		histories:       list.New(),
 		counts:          make(map[OperatorKind]uint64),
 		opRecords:       NewOperatorRecords(),
-		storesLimit:     make(map[uint64]*ratelimit.Bucket),
-		wop:             NewRandBuckets(),
+		storesLimit:     make(map[uint64]map[StoreLimitType]*storeLimit),
+		limitSettings:   make(map[string]*StoreLimitSetting),
+		wop:             NewFairQueue(DefaultPriorityClasses),
 		wopStatus:       NewWaitingOperatorStatus(),
 		opNotifierQueue: make(operatorQueue, 0),
+		events:          NewOperatorEventHub(),
 	}
 }
 
+// SetAuditLog sets the log which persists the records of the operators. It
+// should be called before the controller is used.
+func (oc *OperatorController) SetAuditLog(auditLog *OperatorAuditLog) {
+	oc.auditLog = auditLog
+}
+
+// GetAuditLog returns the log which persists the records of the operators.
+func (oc *OperatorController) GetAuditLog() *OperatorAuditLog {
+	return oc.auditLog
+}
+
+// audit records an operator which has started and ends with the status.
+func (oc *OperatorController) audit(op *Operator, status pdpb.OperatorStatus) {
+	if oc.auditLog == nil || op.GetStartTime().IsZero() {
+		return
+	}
+	oc.auditLog.Append(NewOperatorAuditRecord(op, status))
+}
+
+// SubscribeEvents adds a subscriber of the operator events selected by the
+// filter.
+func (oc *OperatorController) SubscribeEvents(filter OperatorEventFilter) *OperatorEventSubscription {
+	return oc.events.Subscribe(filter)
+}
+
+// UnsubscribeEvents removes a subscriber of the operator events.
+func (oc *OperatorController) UnsubscribeEvents(s *OperatorEventSubscription) {
+	oc.events.Unsubscribe(s)
+}
+
+// CloseEvents removes all the subscribers of the operator events.
+func (oc *OperatorController) CloseEvents() {
+	oc.events.Close()
+}
+
+// publishEvent publishes an event of the operator. stepIndex is the index of
+// the step which the event is about.
+func (oc *OperatorController) publishEvent(eventType string, op *Operator, stepIndex int) {
+	if !oc.events.hasSubscribers() {
+		return
+	}
+	oc.events.Publish(newOperatorEvent(eventType, op, stepIndex, oc.cluster.GetRegion(op.RegionID())))
+}
+
+// publishOperatorEvent publishes an event of the operator about its current
+// step.
+func (oc *OperatorController) publishOperatorEvent(eventType string, op *Operator) {
+	oc.publishEvent(eventType, op, int(atomic.LoadInt32(&op.currentStep)))
+}
+
+// checkOperator checks if the current step of the operator is finished and
+// returns the next step to take action. The events of the finished steps are
+// published.
+func (oc *OperatorController) checkOperator(op *Operator, region *core.RegionInfo) OperatorStep {
+	before := atomic.LoadInt32(&op.currentStep)
+	stepStart := time.Unix(0, atomic.LoadInt64(&op.stepTime))
+	step := op.Check(region)
+	for i := before; i < atomic.LoadInt32(&op.currentStep); i++ {
+		oc.publishEvent(OperatorEventStepFinished, op, int(i))
+	}
+	if before < atomic.LoadInt32(&op.currentStep) && !op.GetStartTime().IsZero() {
+		oc.observeSnapshot(op.Step(int(before)), stepStart, op.GetStartTime(), region)
+	}
+	return step
+}
+
+// observeSnapshot records the snapshot applied by the add-peer step which has
+// just finished. The step starts when the previous step finishes, or when
+// the operator starts.
+func (oc *OperatorController) observeSnapshot(step OperatorStep, stepStart, opStart time.Time, region *core.RegionInfo) {
+	var storeID uint64
+	switch s := step.(type) {
+	case AddPeer:
+		storeID = s.ToStore
+	case AddLearner:
+		storeID = s.ToStore
+	default:
+		return
+	}
+	if region.GetApproximateSize() <= 0 {
+		return
+	}
+	if stepStart.Before(opStart) {
+		stepStart = opStart
+	}
+	oc.cluster.ObserveSnapshot(storeID, uint64(region.GetApproximateSize())<<20, time.Since(stepStart))
+}
+
 // Dispatch is used to dispatch the operator of a region.
 func (oc *OperatorController) Dispatch(region *core.RegionInfo, source string) {
 	// Check existed operator.
 	if op := oc.GetOperator(region.GetID()); op != nil {
 		timeout := op.IsTimeout()
-		if step := op.Check(region); step != nil && !timeout {
+		if step := oc.checkOperator(op, region); step != nil && !timeout {
 			operatorCounter.WithLabelValues(op.Desc(), "check").Inc()
 			oc.SendScheduleCommand(region, step, source)
 			return
@@ -101,6 +198,8 @@ func (oc *OperatorController) Dispatch(region *core.RegionInfo, source string) {
 			operatorDuration.WithLabelValues(op.Desc()).Observe(op.RunningTime().Seconds())
 			oc.pushHistory(op)
 			oc.opRecords.Put(op, pdpb.OperatorStatus_SUCCESS)
+			oc.audit(op, pdpb.OperatorStatus_SUCCESS)
+			oc.publishOperatorEvent(OperatorEventFinished, op)
 			oc.RemoveOperator(op)
 			oc.PromoteWaitingOperator()
 		} else if timeout {
@@ -140,7 +239,7 @@ func (oc *OperatorController) pollNeedDispatchRegion() (r *core.RegionInfo, next
 	if r == nil {
 		return nil, true
 	}
-	step := op.Check(r)
+	step := oc.checkOperator(op, r)
 	if step == nil {
 		return nil, true
 	}
@@ -191,13 +290,10 @@ func (oc *OperatorController) AddWaitingOperator(ops ...*Operator) bool {
 		oc.Unlock()
 		return false
 	}
-	oc.wop.PutOperator(op)
+	oc.wop.PutOperator(ops...)
 	operatorWaitCounter.WithLabelValues(op.Desc(), "put").Inc()
-	// This step is especially for the merge operation.
-	if len(ops) > 1 {
-		oc.wop.PutOperator(ops[1])
-	}
 	oc.wopStatus.ops[desc]++
+	oc.updateWaitingGauge()
 	oc.Unlock()
 	oc.PromoteWaitingOperator()
 	return true
@@ -221,10 +317,104 @@ func (oc *OperatorController) AddOperator(ops ...*Operator) bool {
 	return true
 }
 
+// AddOperatorBatch adds the groups of operators atomically, which means all
+// of them are added or none of them is added. A group is the operators of an
+// operation, such as the two operators to merge regions. It returns nil if the
+// operators are added, otherwise the reasons why each group can not be added,
+// in which the groups can be added have nil reasons.
+func (oc *OperatorController) AddOperatorBatch(groups ...[]*Operator) []error {
+	oc.Lock()
+	defer oc.Unlock()
+
+	reasons := make([]error, len(groups))
+	rejected := false
+	reject := func(i int, err error) {
+		if reasons[i] == nil {
+			reasons[i] = err
+		}
+		rejected = true
+	}
+
+	var ops []*Operator
+	regions := make(map[uint64]int)
+	for i, group := range groups {
+		for _, op := range group {
+			if j, ok := regions[op.RegionID()]; ok {
+				reject(i, errors.Errorf("region %d is already in operation %d of the batch", op.RegionID(), j))
+				continue
+			}
+			regions[op.RegionID()] = i
+			if err := oc.checkAddOperatorReason(op); err != nil {
+				reject(i, err)
+			}
+		}
+		ops = append(ops, group...)
+	}
+
+	// The store limits and the schedule limits are checked with all the
+	// operators of the batch.
+	opInfluence := NewTotalOpInfluence(ops, oc.cluster)
+	for storeID := range opInfluence.storesInfluence {
+		for _, typ := range StoreLimitTypes {
+			stepCost := opInfluence.GetStoreInfluence(storeID).GetStepCost(typ)
+			if stepCost == 0 || oc.getOrCreateStoreLimit(storeID, typ).Available() >= stepCost {
+				continue
+			}
+			for i, group := range groups {
+				if NewTotalOpInfluence(group, oc.cluster).GetStoreInfluence(storeID).GetStepCost(typ) > 0 {
+					reject(i, errors.Errorf("store %d exceeds the %s store limit", storeID, typ))
+				}
+			}
+		}
+	}
+	limits := []struct {
+		kind  OperatorKind
+		name  string
+		limit uint64
+	}{
+		{OpLeader, "leader-schedule-limit", oc.cluster.GetLeaderScheduleLimit()},
+		{OpRegion, "region-schedule-limit", oc.cluster.GetRegionScheduleLimit()},
+		{OpMerge, "merge-schedule-limit", oc.cluster.GetMergeScheduleLimit()},
+	}
+	for _, l := range limits {
+		count := oc.operatorCountLocked(l.kind)
+		for _, op := range ops {
+			if op.Kind()&l.kind != 0 {
+				count++
+			}
+		}
+		if count <= l.limit {
+			continue
+		}
+		for i, group := range groups {
+			for _, op := range group {
+				if op.Kind()&l.kind != 0 {
+					reject(i, errors.Errorf("%d %s operators exceed the %s %d", count, l.kind, l.name, l.limit))
+					break
+				}
+			}
+		}
+	}
+
+	if rejected {
+		for _, op := range ops {
+			operatorCounter.WithLabelValues(op.Desc(), "canceled").Inc()
+			oc.opRecords.Put(op, pdpb.OperatorStatus_CANCEL)
+		}
+		return reasons
+	}
+	for _, op := range ops {
+		oc.addOperatorLocked(op)
+	}
+	return nil
+}
+
 // PromoteWaitingOperator promotes operators from waiting operators.
 func (oc *OperatorController) PromoteWaitingOperator() {
 	oc.Lock()
 	defer oc.Unlock()
+	oc.wop.SetPriorityClasses(oc.cluster.GetOperatorPriorityClasses())
+	defer oc.updateWaitingGauge()
 	var ops []*Operator
 	for {
 		ops = oc.wop.GetOperator()
@@ -257,23 +447,30 @@ func (oc *OperatorController) PromoteWaitingOperator() {
 // - The region already has a higher priority or same priority operator.
 func (oc *OperatorController) checkAddOperator(ops ...*Operator) bool {
 	for _, op := range ops {
-		region := oc.cluster.GetRegion(op.RegionID())
-		if region == nil {
-			log.Debug("region not found, cancel add operator", zap.Uint64("region-id", op.RegionID()))
-			return false
-		}
-		if region.GetRegionEpoch().GetVersion() != op.RegionEpoch().GetVersion() || region.GetRegionEpoch().GetConfVer() != op.RegionEpoch().GetConfVer() {
-			log.Debug("region epoch not match, cancel add operator", zap.Uint64("region-id", op.RegionID()), zap.Reflect("old", region.GetRegionEpoch()), zap.Reflect("new", op.RegionEpoch()))
-			return false
-		}
-		if old := oc.operators[op.RegionID()]; old != nil && !isHigherPriorityOperator(op, old) {
-			log.Debug("already have operator, cancel add operator", zap.Uint64("region-id", op.RegionID()), zap.Reflect("old", old))
+		if err := oc.checkAddOperatorReason(op); err != nil {
+			log.Debug("cancel add operator", zap.Uint64("region-id", op.RegionID()), zap.Error(err))
 			return false
 		}
 	}
 	return true
 }
 
+// checkAddOperatorReason returns the reason why the operator can not be added,
+// or nil if it can be added.
+func (oc *OperatorController) checkAddOperatorReason(op *Operator) error {
+	region := oc.cluster.GetRegion(op.RegionID())
+	if region == nil {
+		return errors.Errorf("region %d not found", op.RegionID())
+	}
+	if region.GetRegionEpoch().GetVersion() != op.RegionEpoch().GetVersion() || region.GetRegionEpoch().GetConfVer() != op.RegionEpoch().GetConfVer() {
+		return errors.Errorf("region %d epoch not match, the region epoch is %v, the operator epoch is %v", op.RegionID(), region.GetRegionEpoch(), op.RegionEpoch())
+	}
+	if old := oc.operators[op.RegionID()]; old != nil && !isHigherPriorityOperator(op, old) {
+		return errors.Errorf("region %d already has operator %s", op.RegionID(), old.Desc())
+	}
+	return nil
+}
+
 func isHigherPriorityOperator(new, old *Operator) bool {
 	return new.GetPriorityLevel() > old.GetPriorityLevel()
 }
@@ -289,27 +486,38 @@ func (oc *OperatorController) addOperatorLocked(op *Operator) bool {
 		log.Info("replace old operator", zap.Uint64("region-id", regionID), zap.Reflect("operator", old))
 		operatorCounter.WithLabelValues(old.Desc(), "replaced").Inc()
 		oc.opRecords.Put(old, pdpb.OperatorStatus_REPLACE)
+		oc.audit(old, pdpb.OperatorStatus_REPLACE)
+		oc.publishOperatorEvent(OperatorEventReplace, old)
 		oc.removeOperatorLocked(old)
 	}
 
 	oc.operators[regionID] = op
 	op.startTime = time.Now()
+	oc.publishOperatorEvent(OperatorEventCreate, op)
 	operatorCounter.WithLabelValues(op.Desc(), "start").Inc()
 	operatorWaitDuration.WithLabelValues(op.Desc()).Observe(op.ElapsedTime().Seconds())
 	opInfluence := NewTotalOpInfluence([]*Operator{op}, oc.cluster)
 	for storeID := range opInfluence.storesInfluence {
-		stepCost := opInfluence.GetStoreInfluence(storeID).StepCost
-		if stepCost == 0 {
-			continue
+		store := strconv.FormatUint(storeID, 10)
+		var totalCost int64
+		for _, typ := range StoreLimitTypes {
+			stepCost := opInfluence.GetStoreInfluence(storeID).GetStepCost(typ)
+			if stepCost == 0 {
+				continue
+			}
+			totalCost += stepCost
+			storeTypeLimitGauge.WithLabelValues(store, "take", typ.String()).Set(float64(stepCost) / float64(RegionInfluence))
+			oc.getOrCreateStoreLimit(storeID, typ).Take(stepCost)
+		}
+		if totalCost > 0 {
+			storeLimitGauge.WithLabelValues(store, "take").Set(float64(totalCost) / float64(RegionInfluence))
 		}
-		storeLimitGauge.WithLabelValues(strconv.FormatUint(storeID, 10), "take").Set(float64(stepCost) / float64(RegionInfluence))
-		oc.storesLimit[storeID].Take(stepCost)
 	}
 	oc.updateCounts(oc.operators)
 
 	var step OperatorStep
 	if region := oc.cluster.GetRegion(op.RegionID()); region != nil {
-		if step = op.Check(region); step != nil {
+		if step = oc.checkOperator(op, region); step != nil {
 			oc.SendScheduleCommand(region, step, DispatchFromCreate)
 		}
 	}
@@ -326,12 +534,36 @@ func (oc *OperatorController) RemoveOperator(op *Operator) {
 	oc.removeOperatorLocked(op)
 }
 
-// RemoveTimeoutOperator removes a operator which is timeout from the running operators.
+// CancelOperator removes a operator from the running operators and records
+// it as canceled.
+func (oc *OperatorController) CancelOperator(op *Operator) {
+	oc.Lock()
+	defer oc.Unlock()
+	operatorCounter.WithLabelValues(op.Desc(), "cancel").Inc()
+	oc.removeOperatorLocked(op)
+	oc.opRecords.Put(op, pdpb.OperatorStatus_CANCEL)
+	oc.audit(op, pdpb.OperatorStatus_CANCEL)
+	oc.publishOperatorEvent(OperatorEventCancel, op)
+}
+
+// RemoveTimeoutOperator removes a operator which is timeout from the running
+// operators, and adds an operator to clean up the steps it has applied.
 func (oc *OperatorController) RemoveTimeoutOperator(op *Operator) {
 	oc.Lock()
 	defer oc.Unlock()
 	operatorCounter.WithLabelValues(op.Desc(), "timeout").Inc()
 	oc.removeOperatorLocked(op)
+	oc.audit(op, pdpb.OperatorStatus_TIMEOUT)
+	oc.publishOperatorEvent(OperatorEventTimeout, op)
+	region := oc.cluster.GetRegion(op.RegionID())
+	if region == nil {
+		return
+	}
+	if rollback := CreateRollbackOperator(op, region); rollback != nil {
+		log.Info("rollback timeout operator", zap.Uint64("region-id", op.RegionID()), zap.Reflect("operator", rollback))
+		operatorCounter.WithLabelValues(op.Desc(), "rollback").Inc()
+		oc.addOperatorLocked(rollback)
+	}
 }
 
 // GetOperatorStatus gets the operator and its status with the specify id.
@@ -374,6 +606,21 @@ func (oc *OperatorController) GetOperators() []*Operator {
 	return operators
 }
 
+// GetWaitingOperatorStats returns the status of the waiting operators of each
+// priority class.
+func (oc *OperatorController) GetWaitingOperatorStats() []WaitingClassStats {
+	oc.Lock()
+	defer oc.Unlock()
+	oc.wop.SetPriorityClasses(oc.cluster.GetOperatorPriorityClasses())
+	return oc.wop.Stats()
+}
+
+func (oc *OperatorController) updateWaitingGauge() {
+	for _, s := range oc.wop.Stats() {
+		waitingOperatorGauge.WithLabelValues(s.Class).Set(float64(s.Depth))
+	}
+}
+
 // GetWaitingOperators gets operators from the waiting operators.
 func (oc *OperatorController) GetWaitingOperators() []*Operator {
 	oc.RLock()
@@ -545,6 +792,10 @@ func (oc *OperatorController) updateCounts(operators map[uint64]*Operator) {
 func (oc *OperatorController) OperatorCount(mask OperatorKind) uint64 {
 	oc.RLock()
 	defer oc.RUnlock()
+	return oc.operatorCountLocked(mask)
+}
+
+func (oc *OperatorController) operatorCountLocked(mask OperatorKind) uint64 {
 	var total uint64
 	for k, count := range oc.counts {
 		if k&mask != 0 {
@@ -622,11 +873,22 @@ func (m OpInfluence) GetStoreInfluence(id uint64) *StoreInfluence {
 
 // StoreInfluence records influences that pending operators will make.
 type StoreInfluence struct {
-	RegionSize  int64
-	RegionCount int64
-	LeaderSize  int64
-	LeaderCount int64
-	StepCost    int64
+	RegionSize  int64 `json:"region_size"`
+	RegionCount int64 `json:"region_count"`
+	LeaderSize  int64 `json:"leader_size"`
+	LeaderCount int64 `json:"leader_count"`
+	// StepCost and RemoveStepCost are the costs taken from the add-peer and
+	// remove-peer limits of the store.
+	StepCost       int64 `json:"step_cost"`
+	RemoveStepCost int64 `json:"remove_step_cost"`
+}
+
+// GetStepCost returns the cost taken from the type of limit of the store.
+func (s StoreInfluence) GetStepCost(typ StoreLimitType) int64 {
+	if typ == StoreLimitRemovePeer {
+		return s.RemoveStepCost
+	}
+	return s.StepCost
 }
 
 // ResourceSize returns delta size of leader/region by influence.
@@ -696,69 +958,232 @@ func (o *OperatorRecords) Put(op *Operator, status pdpb.OperatorStatus) {
 func (oc *OperatorController) exceedStoreLimit(ops ...*Operator) bool {
 	opInfluence := NewTotalOpInfluence(ops, oc.cluster)
 	for storeID := range opInfluence.storesInfluence {
-		stepCost := opInfluence.GetStoreInfluence(storeID).StepCost
-		if stepCost == 0 {
-			continue
-		}
+		store := strconv.FormatUint(storeID, 10)
+		// The gauge without the limit type reports the most limiting type.
+		minAvailable, checked, exceeded := int64(0), false, false
+		for _, typ := range StoreLimitTypes {
+			stepCost := opInfluence.GetStoreInfluence(storeID).GetStepCost(typ)
+			if stepCost == 0 {
+				continue
+			}
 
-		available := oc.getOrCreateStoreLimit(storeID).Available()
-		storeLimitGauge.WithLabelValues(strconv.FormatUint(storeID, 10), "available").Set(float64(available) / float64(RegionInfluence))
-		if available < stepCost {
+			available := oc.getOrCreateStoreLimit(storeID, typ).Available()
+			storeTypeLimitGauge.WithLabelValues(store, "available", typ.String()).Set(float64(available) / float64(RegionInfluence))
+			if !checked || available < minAvailable {
+				minAvailable, checked = available, true
+			}
+			if available < stepCost {
+				exceeded = true
+			}
+		}
+		if checked {
+			storeLimitGauge.WithLabelValues(store, "available").Set(float64(minAvailable) / float64(RegionInfluence))
+		}
+		if exceeded {
 			return true
 		}
 	}
 	return false
 }
 
-// SetAllStoresLimit is used to set limit of all stores.
-func (oc *OperatorController) SetAllStoresLimit(rate float64) {
+// LoadStoreLimits loads the store limit settings from kv, and persists the
+// settings to it later. It should be called before the controller is used.
+// The invalid settings in kv are skipped.
+func (oc *OperatorController) LoadStoreLimits(kv *core.KV) error {
 	oc.Lock()
 	defer oc.Unlock()
-	for storeID := range oc.storesLimit {
-		oc.newStoreLimit(storeID, rate)
-	}
+	// The settings are persisted even if some of them fail to load.
+	oc.kv = kv
+	return kv.LoadStoreLimits(func(k, v string) {
+		setting := &StoreLimitSetting{}
+		if err := json.Unmarshal([]byte(v), setting); err != nil {
+			log.Error("invalid store limit in storage", zap.String("limit-key", k), zap.Error(err))
+			return
+		}
+		if err := setting.validate(); err != nil {
+			log.Error("invalid store limit in storage", zap.String("limit-key", k), zap.Error(err))
+			return
+		}
+		oc.limitSettings[setting.key()] = setting
+	})
+}
+
+// SetAllStoresLimit is used to set the limits of the types of all stores, or
+// of all types if no type is given. The rates set for some of the stores are
+// overridden.
+func (oc *OperatorController) SetAllStoresLimit(rate float64, types ...StoreLimitType) error {
+	return oc.setStoreLimit(&StoreLimitSetting{Rate: rate}, types)
+}
+
+// SetLabelStoresLimit is used to set the limits of the types of the stores
+// with the label, or of all types if no type is given. The rates set for some
+// of the stores are overridden.
+func (oc *OperatorController) SetLabelStoresLimit(key, value string, rate float64, types ...StoreLimitType) error {
+	return oc.setStoreLimit(&StoreLimitSetting{LabelKey: key, LabelValue: value, Rate: rate}, types)
+}
+
+// SetStoreLimit is used to set the limits of the types of a store, or of all
+// types if no type is given.
+func (oc *OperatorController) SetStoreLimit(storeID uint64, rate float64, types ...StoreLimitType) error {
+	return oc.setStoreLimit(&StoreLimitSetting{StoreID: storeID, Rate: rate}, types)
 }
 
-// SetStoreLimit is used to set the limit of a store.
-func (oc *OperatorController) SetStoreLimit(storeID uint64, rate float64) {
+func (oc *OperatorController) setStoreLimit(setting *StoreLimitSetting, types []StoreLimitType) error {
+	if len(types) == 0 {
+		types = StoreLimitTypes
+	}
+	settings := make([]*StoreLimitSetting, 0, len(types))
+	for _, typ := range types {
+		s := *setting
+		s.Type = typ.String()
+		if err := s.validate(); err != nil {
+			return err
+		}
+		settings = append(settings, &s)
+	}
 	oc.Lock()
 	defer oc.Unlock()
-	oc.newStoreLimit(storeID, rate)
+	// All the settings are persisted before any of them is applied, so that
+	// the types are not left inconsistent if some of them fail to be saved.
+	overridden := make([][]*StoreLimitSetting, 0, len(settings))
+	for _, s := range settings {
+		overridden = append(overridden, oc.getOverriddenStoreLimitSettings(s))
+	}
+	if oc.kv != nil {
+		for i, s := range settings {
+			if err := oc.kv.SaveStoreLimit(s.key(), s); err != nil {
+				return err
+			}
+			for _, o := range overridden[i] {
+				if err := oc.kv.DeleteStoreLimit(o.key()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for i, s := range settings {
+		oc.limitSettings[s.key()] = s
+		for _, o := range overridden[i] {
+			delete(oc.limitSettings, o.key())
+		}
+	}
+	return nil
+}
+
+// getOverriddenStoreLimitSettings returns the settings of the same type for
+// some of the stores the setting applies to, which are removed when the
+// setting is saved.
+func (oc *OperatorController) getOverriddenStoreLimitSettings(setting *StoreLimitSetting) []*StoreLimitSetting {
+	var overridden []*StoreLimitSetting
+	for _, s := range oc.limitSettings {
+		if s.Type != setting.Type || s.key() == setting.key() || setting.StoreID != 0 {
+			continue
+		}
+		// A label setting only overrides the settings of the stores with the
+		// label, while the setting for all stores overrides all the others.
+		if setting.LabelKey != "" && (s.StoreID == 0 || !setting.match(s.StoreID, oc.cluster.GetStore(s.StoreID))) {
+			continue
+		}
+		overridden = append(overridden, s)
+	}
+	return overridden
+}
+
+// getStoreLimitRate returns the rate of the type of limit of a store, and the
+// scope which the rate is set for. The rate set for the store is used first,
+// then the lowest rate set for its labels, and then the rate set for all
+// stores.
+func (oc *OperatorController) getStoreLimitRate(storeID uint64, typ StoreLimitType) (float64, string) {
+	store := oc.cluster.GetStore(storeID)
+	var label, all *StoreLimitSetting
+	for _, s := range oc.limitSettings {
+		if s.Type != typ.String() {
+			continue
+		}
+		if !s.match(storeID, store) {
+			continue
+		}
+		switch {
+		case s.StoreID != 0:
+			return s.Rate, s.Scope()
+		case s.LabelKey != "":
+			if label == nil || s.Rate < label.Rate {
+				label = s
+			}
+		default:
+			all = s
+		}
+	}
+	if label != nil {
+		return label.Rate, label.Scope()
+	}
+	if all != nil {
+		return all.Rate, all.Scope()
+	}
+	return oc.cluster.GetStoreBalanceRate() / StoreBalanceBaseTime, StoreLimitScopeDefault
+}
+
+// storeLimit is a token bucket which limits the operator steps of a store.
+type storeLimit struct {
+	*ratelimit.Bucket
+	// rate is the number of the operator steps per second, which is the one
+	// set rather than the one of the bucket.
+	rate float64
 }
 
 // newStoreLimit is used to create the limit of a store.
-func (oc *OperatorController) newStoreLimit(storeID uint64, rate float64) {
+func (oc *OperatorController) newStoreLimit(storeID uint64, typ StoreLimitType, rate float64) {
 	capacity := RegionInfluence
 	if rate > 1 {
 		capacity = int64(rate * float64(RegionInfluence))
 	}
-	rate *= float64(RegionInfluence)
-	oc.storesLimit[storeID] = ratelimit.NewBucketWithRate(rate, capacity)
+	if oc.storesLimit[storeID] == nil {
+		oc.storesLimit[storeID] = make(map[StoreLimitType]*storeLimit)
+	}
+	oc.storesLimit[storeID][typ] = &storeLimit{
+		Bucket: ratelimit.NewBucketWithRate(rate*float64(RegionInfluence), capacity),
+		rate:   rate,
+	}
 }
 
-// getOrCreateStoreLimit is used to get or create the limit of a store.
-func (oc *OperatorController) getOrCreateStoreLimit(storeID uint64) *ratelimit.Bucket {
-	if oc.storesLimit[storeID] == nil {
-		rate := oc.cluster.GetStoreBalanceRate() / StoreBalanceBaseTime
-		oc.newStoreLimit(storeID, rate)
+// getOrCreateStoreLimit is used to get the limit of a store. The limit is
+// created again if its rate is changed.
+func (oc *OperatorController) getOrCreateStoreLimit(storeID uint64, typ StoreLimitType) *storeLimit {
+	rate, _ := oc.getStoreLimitRate(storeID, typ)
+	limit := oc.storesLimit[storeID][typ]
+	if limit == nil && typ == StoreLimitAddPeer {
 		oc.cluster.AttachOverloadStatus(storeID, func() bool {
 			oc.RLock()
 			defer oc.RUnlock()
-			return oc.storesLimit[storeID].Available() < RegionInfluence
+			return oc.storesLimit[storeID][StoreLimitAddPeer].Available() < RegionInfluence
 		})
 	}
-	return oc.storesLimit[storeID]
+	if limit == nil || limit.rate != rate {
+		oc.newStoreLimit(storeID, typ, rate)
+	}
+	return oc.storesLimit[storeID][typ]
 }
 
-// GetAllStoresLimit is used to get limit of all stores.
-func (oc *OperatorController) GetAllStoresLimit() map[uint64]float64 {
-	oc.RLock()
-	defer oc.RUnlock()
-	ret := make(map[uint64]float64)
-	for storeID, limit := range oc.storesLimit {
-		store := oc.cluster.GetStore(storeID)
-		if !store.IsTombstone() {
-			ret[storeID] = limit.Rate() / float64(RegionInfluence)
+// GetAllStoresLimit is used to get the status of the limits of all stores.
+func (oc *OperatorController) GetAllStoresLimit() map[uint64]map[string]*StoreLimitStatus {
+	oc.Lock()
+	defer oc.Unlock()
+	ret := make(map[uint64]map[string]*StoreLimitStatus)
+	for _, store := range oc.cluster.GetStores() {
+		if store.IsTombstone() {
+			continue
+		}
+		storeID := store.GetID()
+		ret[storeID] = make(map[string]*StoreLimitStatus)
+		for _, typ := range StoreLimitTypes {
+			limit := oc.getOrCreateStoreLimit(storeID, typ)
+			_, scope := oc.getStoreLimitRate(storeID, typ)
+			ret[storeID][typ.String()] = &StoreLimitStatus{
+				Rate:      limit.rate,
+				Available: float64(limit.Available()) / float64(RegionInfluence),
+				Capacity:  float64(limit.Capacity()) / float64(RegionInfluence),
+				Scope:     scope,
+			}
 		}
 	}
 	return ret
@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/pd/pkg/mock/mockcluster"
 	"github.com/pingcap/pd/pkg/mock/mockhbstream"
 	"github.com/pingcap/pd/pkg/mock/mockoption"
+	"github.com/pingcap/pd/server/core"
 )
 
 var _ = Suite(&testOperatorControllerSuite{})
@@ -92,6 +93,179 @@ func (t *testOperatorControllerSuite) TestOperatorStatus(c *C) {
 	c.Assert(oc.GetOperatorStatus(2).Status, Equals, pdpb.OperatorStatus_SUCCESS)
 }
 
+func (t *testOperatorControllerSuite) TestRollbackTimeoutOperator(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := NewOperatorController(tc, mockhbstream.NewHeartbeatStream())
+	oc.SetAuditLog(NewOperatorAuditLog(core.NewKV(core.NewMemoryKV())))
+	tc.AddLeaderStore(1, 1)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderStore(3, 0)
+	tc.AddLeaderRegion(1, 1, 2)
+	op := NewOperator("test", 1, tc.GetRegion(1).GetRegionEpoch(), OpRegion,
+		AddLearner{ToStore: 3, PeerID: 10}, PromoteLearner{ToStore: 3, PeerID: 10}, RemovePeer{FromStore: 2})
+	c.Assert(oc.AddOperator(op), IsTrue)
+	region := tc.GetRegion(1).Clone(core.WithAddPeer(&metapb.Peer{Id: 10, StoreId: 3, IsLearner: true}))
+	tc.PutRegion(region)
+
+	op.startTime = time.Now().Add(-RegionOperatorWaitTime - time.Second)
+	oc.Dispatch(region, "test")
+	rollback := oc.GetOperator(1)
+	c.Assert(rollback, NotNil)
+	c.Assert(rollback.Kind()&OpRollback, Equals, OpRollback)
+	c.Assert(rollback.Step(0), Equals, RemovePeer{FromStore: 3})
+	c.Assert(oc.opRecords.Get(1).Op, Equals, op)
+	c.Assert(oc.opRecords.Get(1).Status, Equals, pdpb.OperatorStatus_TIMEOUT)
+
+	ApplyOperator(tc, rollback)
+	oc.Dispatch(tc.GetRegion(1), "test")
+	c.Assert(oc.GetOperator(1), IsNil)
+	histories := oc.GetHistory(time.Now().Add(-time.Minute))
+	c.Assert(histories, HasLen, 1)
+	c.Assert(histories[0].From, Equals, uint64(3))
+	c.Assert(histories[0].Rollback, IsTrue)
+
+	records, err := oc.GetAuditLog().Query(OperatorAuditFilter{RegionID: 1})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 2)
+	c.Assert(records[0].Desc, Equals, "rollback-test")
+	c.Assert(records[0].Status, Equals, pdpb.OperatorStatus_SUCCESS.String())
+	c.Assert(records[1].Desc, Equals, "test")
+	c.Assert(records[1].Status, Equals, pdpb.OperatorStatus_TIMEOUT.String())
+}
+
+func (t *testOperatorControllerSuite) TestAddOperatorBatch(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := NewOperatorController(tc, mockhbstream.NewHeartbeatStream())
+	tc.AddLeaderStore(1, 4)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderStore(3, 0)
+	for i := uint64(1); i <= 4; i++ {
+		tc.AddLeaderRegion(i, 1, 2)
+	}
+	transferLeader := func(regionID uint64) []*Operator {
+		return []*Operator{NewOperator("test", regionID, tc.GetRegion(regionID).GetRegionEpoch(), OpLeader|OpAdmin, TransferLeader{FromStore: 1, ToStore: 2})}
+	}
+	addPeer := func(regionID uint64) []*Operator {
+		return []*Operator{NewOperator("test", regionID, tc.GetRegion(regionID).GetRegionEpoch(), OpRegion|OpAdmin, AddPeer{ToStore: 3, PeerID: 10 + regionID})}
+	}
+
+	// The operators of a region can not be added twice in a batch.
+	reasons := oc.AddOperatorBatch(transferLeader(1), transferLeader(1))
+	c.Assert(reasons, HasLen, 2)
+	c.Assert(reasons[0], IsNil)
+	c.Assert(reasons[1], NotNil)
+	c.Assert(oc.GetOperator(1), IsNil)
+
+	c.Assert(oc.AddOperatorBatch(transferLeader(1), transferLeader(2)), IsNil)
+	c.Assert(oc.GetOperator(1), NotNil)
+	c.Assert(oc.GetOperator(2), NotNil)
+
+	// The batch is rejected if it exceeds the schedule limit.
+	opt.LeaderScheduleLimit = 3
+	reasons = oc.AddOperatorBatch(transferLeader(3), transferLeader(4))
+	c.Assert(reasons[0], ErrorMatches, ".*leader-schedule-limit.*")
+	c.Assert(reasons[1], ErrorMatches, ".*leader-schedule-limit.*")
+	c.Assert(oc.GetOperator(3), IsNil)
+	c.Assert(oc.GetOperator(4), IsNil)
+
+	// The batch is rejected if it exceeds the store limit.
+	oc.SetStoreLimit(3, 1)
+	reasons = oc.AddOperatorBatch(addPeer(3), addPeer(4))
+	c.Assert(reasons[0], ErrorMatches, ".*store 3 exceeds the add-peer store limit.*")
+	c.Assert(reasons[1], ErrorMatches, ".*store 3 exceeds the add-peer store limit.*")
+	c.Assert(oc.GetOperator(3), IsNil)
+	c.Assert(oc.AddOperatorBatch(addPeer(3), transferLeader(4)), IsNil)
+	c.Assert(oc.GetOperator(3), NotNil)
+	c.Assert(oc.GetOperator(4), NotNil)
+}
+
+func (t *testOperatorControllerSuite) TestStoreLimit(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	kv := core.NewKV(core.NewMemoryKV())
+	oc := NewOperatorController(tc, mockhbstream.NewHeartbeatStream())
+	c.Assert(oc.LoadStoreLimits(kv), IsNil)
+	tc.AddLabelsStore(1, 0, map[string]string{"zone": "z1"})
+	tc.AddLabelsStore(2, 0, map[string]string{"zone": "z1"})
+	tc.AddLabelsStore(3, 0, map[string]string{"zone": "z2"})
+	for i := uint64(1); i <= 3; i++ {
+		tc.AddLeaderRegion(i, 3, 2)
+	}
+
+	checkLimit := func(oc *OperatorController, storeID uint64, typ StoreLimitType, rate float64, scope string) {
+		status := oc.GetAllStoresLimit()[storeID][typ.String()]
+		c.Assert(status.Rate, Equals, rate)
+		c.Assert(status.Scope, Equals, scope)
+	}
+	checkLimit(oc, 1, StoreLimitAddPeer, 1, StoreLimitScopeDefault)
+	checkLimit(oc, 1, StoreLimitRemovePeer, 1, StoreLimitScopeDefault)
+
+	c.Assert(oc.SetLabelStoresLimit("zone", "z1", 2, StoreLimitRemovePeer), IsNil)
+	checkLimit(oc, 1, StoreLimitAddPeer, 1, StoreLimitScopeDefault)
+	checkLimit(oc, 1, StoreLimitRemovePeer, 2, "zone=z1")
+	checkLimit(oc, 2, StoreLimitRemovePeer, 2, "zone=z1")
+	checkLimit(oc, 3, StoreLimitRemovePeer, 1, StoreLimitScopeDefault)
+
+	c.Assert(oc.SetStoreLimit(1, 3), IsNil)
+	checkLimit(oc, 1, StoreLimitAddPeer, 3, StoreLimitScopeStore)
+	checkLimit(oc, 1, StoreLimitRemovePeer, 3, StoreLimitScopeStore)
+
+	// The rates set for all stores override the ones set for some stores.
+	c.Assert(oc.SetAllStoresLimit(4, StoreLimitRemovePeer), IsNil)
+	checkLimit(oc, 1, StoreLimitAddPeer, 3, StoreLimitScopeStore)
+	for i := uint64(1); i <= 3; i++ {
+		checkLimit(oc, i, StoreLimitRemovePeer, 4, StoreLimitScopeAll)
+	}
+	c.Assert(oc.SetStoreLimit(2, 0), NotNil)
+
+	// The settings are loaded by a new controller, and the invalid ones are
+	// skipped.
+	c.Assert(kv.Save("store_limit/add-peer/invalid", "invalid"), IsNil)
+	c.Assert(kv.Save("store_limit/unknown/all", `{"type":"unknown","rate":1}`), IsNil)
+	oc2 := NewOperatorController(tc, mockhbstream.NewHeartbeatStream())
+	c.Assert(oc2.LoadStoreLimits(kv), IsNil)
+	checkLimit(oc2, 1, StoreLimitAddPeer, 3, StoreLimitScopeStore)
+	checkLimit(oc2, 2, StoreLimitAddPeer, 1, StoreLimitScopeDefault)
+	checkLimit(oc2, 3, StoreLimitRemovePeer, 4, StoreLimitScopeAll)
+
+	// Removing peers only takes the remove-peer limit.
+	removePeer := func(regionID uint64) *Operator {
+		return NewOperator("test", regionID, tc.GetRegion(regionID).GetRegionEpoch(), OpRegion, RemovePeer{FromStore: 2})
+	}
+	addPeer := func(regionID uint64) *Operator {
+		return NewOperator("test", regionID, tc.GetRegion(regionID).GetRegionEpoch(), OpRegion, AddPeer{ToStore: 2, PeerID: 10 + regionID})
+	}
+	c.Assert(oc2.SetStoreLimit(2, 1, StoreLimitRemovePeer), IsNil)
+	c.Assert(oc2.AddOperator(removePeer(1)), IsTrue)
+	c.Assert(oc2.AddOperator(removePeer(2)), IsFalse)
+	c.Assert(oc2.AddOperator(addPeer(3)), IsTrue)
+	c.Assert(oc2.GetAllStoresLimit()[2][StoreLimitRemovePeer.String()].Available, Less, 1.0)
+}
+
+func (t *testOperatorControllerSuite) TestObserveSnapshot(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := NewOperatorController(tc, mockhbstream.NewHeartbeatStream())
+	tc.AddLeaderStore(1, 1)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderRegion(1, 1)
+	op := NewOperator("test", 1, tc.GetRegion(1).GetRegionEpoch(), OpRegion, AddPeer{ToStore: 2, PeerID: 2}, TransferLeader{FromStore: 1, ToStore: 2})
+	c.Assert(oc.AddOperator(op), IsTrue)
+	c.Assert(tc.GetStore(2).GetSnapshotStats().Count, Equals, uint64(0))
+
+	// The snapshot is observed when the add-peer step finishes.
+	region := ApplyOperatorStep(tc.GetRegion(1), op)
+	tc.PutRegion(region)
+	oc.Dispatch(region, "test")
+	stats := tc.GetStore(2).GetSnapshotStats()
+	c.Assert(stats.Count, Equals, uint64(1))
+	c.Assert(stats.TotalBytes, Equals, uint64(10<<20))
+	c.Assert(stats.Throughput, Greater, 0.0)
+	c.Assert(tc.GetStore(1).GetSnapshotStats().Count, Equals, uint64(0))
+}
+
 func (t *testOperatorControllerSuite) TestPollDispatchRegion(c *C) {
 	opt := mockoption.NewScheduleOptions()
 	tc := mockcluster.NewCluster(opt)
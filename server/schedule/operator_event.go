@@ -0,0 +1,208 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/pingcap/log"
+	"github.com/pingcap/pd/server/core"
+	"go.uber.org/zap"
+)
+
+// The types of the operator events.
+const (
+	OperatorEventCreate       = "create"
+	OperatorEventStepFinished = "step-finished"
+	OperatorEventFinished     = "finished"
+	OperatorEventTimeout      = "timeout"
+	OperatorEventCancel       = "cancel"
+	OperatorEventReplace      = "replace"
+)
+
+// OperatorEventBufferSize is the number of events buffered for a subscriber.
+// A subscriber is closed when its buffer is full.
+const OperatorEventBufferSize = 1024
+
+// OperatorEvent is an event in the lifecycle of an operator.
+type OperatorEvent struct {
+	Type     string    `json:"type"`
+	Time     time.Time `json:"time"`
+	RegionID uint64    `json:"region_id"`
+	Desc     string    `json:"desc"`
+	Kind     string    `json:"kind"`
+	Source   string    `json:"source,omitempty"`
+	// StepIndex and Step are the index and the description of the finished
+	// step for step-finished events, or the current step for other events.
+	StepIndex int      `json:"step_index"`
+	Step      string   `json:"step,omitempty"`
+	Stores    []uint64 `json:"stores"`
+
+	kind             OperatorKind
+	startKey, endKey []byte
+}
+
+func newOperatorEvent(eventType string, op *Operator, stepIndex int, region *core.RegionInfo) *OperatorEvent {
+	event := &OperatorEvent{
+		Type:      eventType,
+		Time:      time.Now(),
+		RegionID:  op.RegionID(),
+		Desc:      op.Desc(),
+		Kind:      op.Kind().String(),
+		Source:    op.Source(),
+		StepIndex: stepIndex,
+		kind:      op.Kind(),
+	}
+	if step := op.Step(stepIndex); step != nil {
+		event.Step = fmt.Sprint(step)
+	}
+	stores := make(map[uint64]struct{})
+	for i := 0; i < op.Len(); i++ {
+		for _, id := range stepStores(op.Step(i)) {
+			stores[id] = struct{}{}
+		}
+	}
+	for id := range stores {
+		event.Stores = append(event.Stores, id)
+	}
+	sort.Slice(event.Stores, func(i, j int) bool { return event.Stores[i] < event.Stores[j] })
+	if region != nil {
+		event.startKey, event.endKey = region.GetStartKey(), region.GetEndKey()
+	}
+	return event
+}
+
+// OperatorEventFilter selects the operator events. The zero value of a field
+// matches all events.
+type OperatorEventFilter struct {
+	// Kind matches the operators which have all the flags of it.
+	Kind    OperatorKind
+	StoreID uint64
+	// StartKey and EndKey match the operators of the regions which overlap
+	// with [StartKey, EndKey). An empty EndKey means no upper bound.
+	StartKey, EndKey []byte
+}
+
+func (f *OperatorEventFilter) match(e *OperatorEvent) bool {
+	if e.kind&f.Kind != f.Kind {
+		return false
+	}
+	if f.StoreID != 0 {
+		found := false
+		for _, id := range e.Stores {
+			if id == f.StoreID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.EndKey) > 0 && bytes.Compare(e.startKey, f.EndKey) >= 0 {
+		return false
+	}
+	if len(e.endKey) > 0 && bytes.Compare(e.endKey, f.StartKey) <= 0 {
+		return false
+	}
+	return true
+}
+
+// OperatorEventSubscription receives the operator events selected by its
+// filter.
+type OperatorEventSubscription struct {
+	filter OperatorEventFilter
+	ch     chan *OperatorEvent
+}
+
+// Events returns the channel of the events. It is closed when the
+// subscription is canceled, or when the subscriber is too slow to receive
+// the events.
+func (s *OperatorEventSubscription) Events() <-chan *OperatorEvent {
+	return s.ch
+}
+
+// OperatorEventHub publishes the operator events to the subscribers.
+type OperatorEventHub struct {
+	sync.RWMutex
+	subscribers map[*OperatorEventSubscription]struct{}
+}
+
+// NewOperatorEventHub creates an OperatorEventHub.
+func NewOperatorEventHub() *OperatorEventHub {
+	return &OperatorEventHub{
+		subscribers: make(map[*OperatorEventSubscription]struct{}),
+	}
+}
+
+// Subscribe adds a subscriber of the events selected by the filter.
+func (h *OperatorEventHub) Subscribe(filter OperatorEventFilter) *OperatorEventSubscription {
+	h.Lock()
+	defer h.Unlock()
+	s := &OperatorEventSubscription{
+		filter: filter,
+		ch:     make(chan *OperatorEvent, OperatorEventBufferSize),
+	}
+	h.subscribers[s] = struct{}{}
+	return s
+}
+
+// Unsubscribe removes a subscriber and closes its channel.
+func (h *OperatorEventHub) Unsubscribe(s *OperatorEventSubscription) {
+	h.Lock()
+	defer h.Unlock()
+	if _, ok := h.subscribers[s]; ok {
+		delete(h.subscribers, s)
+		close(s.ch)
+	}
+}
+
+// Close removes all the subscribers and closes their channels.
+func (h *OperatorEventHub) Close() {
+	h.Lock()
+	defer h.Unlock()
+	for s := range h.subscribers {
+		delete(h.subscribers, s)
+		close(s.ch)
+	}
+}
+
+func (h *OperatorEventHub) hasSubscribers() bool {
+	h.RLock()
+	defer h.RUnlock()
+	return len(h.subscribers) > 0
+}
+
+// Publish sends the event to the subscribers which select it. The subscribers
+// whose buffers are full are removed.
+func (h *OperatorEventHub) Publish(e *OperatorEvent) {
+	h.Lock()
+	defer h.Unlock()
+	for s := range h.subscribers {
+		if !s.filter.match(e) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			log.Warn("operator event subscriber is too slow, remove it", zap.Uint64("region-id", e.RegionID))
+			delete(h.subscribers, s)
+			close(s.ch)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/pkg/mock/mockhbstream"
+	"github.com/pingcap/pd/pkg/mock/mockoption"
+	"github.com/pingcap/pd/server/core"
+)
+
+var _ = Suite(&testOperatorEventSuite{})
+
+type testOperatorEventSuite struct{}
+
+func (s *testOperatorEventSuite) TestFilter(c *C) {
+	op := NewOperator("test", 1, &metapb.RegionEpoch{}, OpRegion|OpBalance, AddPeer{ToStore: 3, PeerID: 3}, RemovePeer{FromStore: 1})
+	region := core.NewRegionInfo(&metapb.Region{Id: 1, StartKey: []byte("b"), EndKey: []byte("d")}, nil)
+	event := newOperatorEvent(OperatorEventCreate, op, 0, region)
+	c.Assert(event.Stores, DeepEquals, []uint64{1, 3})
+	c.Assert(event.Step, Equals, AddPeer{ToStore: 3, PeerID: 3}.String())
+
+	testCases := []struct {
+		filter OperatorEventFilter
+		match  bool
+	}{
+		{OperatorEventFilter{}, true},
+		{OperatorEventFilter{Kind: OpRegion | OpBalance}, true},
+		{OperatorEventFilter{Kind: OpLeader}, false},
+		{OperatorEventFilter{StoreID: 3}, true},
+		{OperatorEventFilter{StoreID: 2}, false},
+		{OperatorEventFilter{StartKey: []byte("c")}, true},
+		{OperatorEventFilter{StartKey: []byte("d")}, false},
+		{OperatorEventFilter{StartKey: []byte("a"), EndKey: []byte("b")}, false},
+		{OperatorEventFilter{StartKey: []byte("a"), EndKey: []byte("c")}, true},
+	}
+	for _, t := range testCases {
+		c.Assert(t.filter.match(event), Equals, t.match)
+	}
+}
+
+func (s *testOperatorEventSuite) TestHub(c *C) {
+	h := NewOperatorEventHub()
+	op := NewOperator("test", 1, &metapb.RegionEpoch{}, OpLeader, TransferLeader{FromStore: 1, ToStore: 2})
+	leaderSub := h.Subscribe(OperatorEventFilter{Kind: OpLeader})
+	regionSub := h.Subscribe(OperatorEventFilter{Kind: OpRegion})
+	h.Publish(newOperatorEvent(OperatorEventCreate, op, 0, nil))
+	c.Assert((<-leaderSub.Events()).Type, Equals, OperatorEventCreate)
+	c.Assert(regionSub.Events(), HasLen, 0)
+
+	// A slow subscriber is removed.
+	for i := 0; i <= OperatorEventBufferSize; i++ {
+		h.Publish(newOperatorEvent(OperatorEventCreate, op, 0, nil))
+	}
+	c.Assert(h.subscribers, HasLen, 1)
+	for range leaderSub.Events() {
+	}
+
+	h.Unsubscribe(regionSub)
+	_, ok := <-regionSub.Events()
+	c.Assert(ok, IsFalse)
+	c.Assert(h.hasSubscribers(), IsFalse)
+}
+
+func (s *testOperatorEventSuite) TestControllerEvents(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := NewOperatorController(tc, mockhbstream.NewHeartbeatStream())
+	tc.AddLeaderStore(1, 1)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderRegion(1, 1)
+	sub := oc.SubscribeEvents(OperatorEventFilter{StoreID: 2})
+	defer oc.UnsubscribeEvents(sub)
+
+	op := NewOperator("test", 1, tc.GetRegion(1).GetRegionEpoch(), OpRegion, AddPeer{ToStore: 2, PeerID: 10}, TransferLeader{FromStore: 1, ToStore: 2})
+	op.SetSource("test-scheduler")
+	c.Assert(oc.AddOperator(op), IsTrue)
+	event := <-sub.Events()
+	c.Assert(event.Type, Equals, OperatorEventCreate)
+	c.Assert(event.Source, Equals, "test-scheduler")
+	c.Assert(event.StepIndex, Equals, 0)
+
+	region := tc.GetRegion(1).Clone(core.WithAddPeer(&metapb.Peer{Id: 10, StoreId: 2}))
+	tc.PutRegion(region)
+	oc.Dispatch(region, "test")
+	event = <-sub.Events()
+	c.Assert(event.Type, Equals, OperatorEventStepFinished)
+	c.Assert(event.StepIndex, Equals, 0)
+	c.Assert(event.Step, Equals, AddPeer{ToStore: 2, PeerID: 10}.String())
+
+	region = region.Clone(core.WithLeader(region.GetStorePeer(2)))
+	tc.PutRegion(region)
+	oc.Dispatch(region, "test")
+	c.Assert((<-sub.Events()).Type, Equals, OperatorEventStepFinished)
+	c.Assert((<-sub.Events()).Type, Equals, OperatorEventFinished)
+
+	op = NewOperator("test", 1, region.GetRegionEpoch(), OpLeader, TransferLeader{FromStore: 2, ToStore: 1})
+	c.Assert(oc.AddOperator(op), IsTrue)
+	c.Assert((<-sub.Events()).Type, Equals, OperatorEventCreate)
+	oc.CancelOperator(op)
+	c.Assert((<-sub.Events()).Type, Equals, OperatorEventCancel)
+	c.Assert(sub.Events(), HasLen, 0)
+}
@@ -33,6 +33,7 @@ const (
 	OpBalance                            // Initiated by balancers.
 	OpMerge                              // Initiated by merge checkers or merge schedulers.
 	OpRange                              // Initiated by range scheduler.
+	OpRollback                           // Initiated by rollback of timed out operators.
 	opMax
 )
 
@@ -46,6 +47,7 @@ var flagToName = map[OperatorKind]string{
 	OpBalance:   "balance",
 	OpMerge:     "merge",
 	OpRange:     "range",
+	OpRollback:  "rollback",
 }
 
 var nameToFlag = map[string]OperatorKind{
@@ -58,6 +60,7 @@ var nameToFlag = map[string]OperatorKind{
 	"balance":    OpBalance,
 	"merge":      OpMerge,
 	"range":      OpRange,
+	"rollback":   OpRollback,
 }
 
 func (k OperatorKind) String() string {
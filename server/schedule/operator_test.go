@@ -20,6 +20,8 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/pkg/mock/mockoption"
 	"github.com/pingcap/pd/server/core"
 )
 
@@ -113,6 +115,73 @@ func (s *testOperatorSuite) TestOperator(c *C) {
 	c.Assert(op.IsTimeout(), IsTrue)
 }
 
+func (s *testOperatorSuite) TestStepTimeout(c *C) {
+	region := s.newTestRegion(1, 2, [2]uint64{1, 1}, [2]uint64{2, 2})
+	// The current step is transferLeader1.
+	op := s.newTestOperator(1, OpLeader|OpRegion, AddPeer{ToStore: 1, PeerID: 1}, TransferLeader{FromStore: 2, ToStore: 1})
+	c.Assert(op.Check(region), Equals, TransferLeader{FromStore: 2, ToStore: 1})
+	op.startTime = time.Now()
+	atomic.StoreInt64(&op.stepTime, op.startTime.Add(-LeaderOperatorWaitTime-time.Second).UnixNano())
+	c.Assert(op.IsTimeout(), IsFalse)
+	op.startTime = op.startTime.Add(-LeaderOperatorWaitTime - time.Second)
+	c.Assert(op.IsTimeout(), IsTrue)
+
+	// The first step starts when the operator starts.
+	op = s.newTestOperator(1, OpRegion, RemovePeer{FromStore: 2})
+	atomic.StoreInt64(&op.stepTime, 0)
+	op.startTime = time.Now().Add(-LeaderOperatorWaitTime - time.Second)
+	c.Assert(op.IsTimeout(), IsFalse)
+	op.startTime = time.Now().Add(-ConfChangeStepWaitTime - time.Second)
+	c.Assert(op.IsTimeout(), IsTrue)
+
+	op = s.newTestOperator(1, OpRegion, AddPeer{ToStore: 3, PeerID: 3})
+	atomic.StoreInt64(&op.stepTime, 0)
+	op.startTime = time.Now().Add(-ConfChangeStepWaitTime - time.Second)
+	c.Assert(op.IsTimeout(), IsFalse)
+}
+
+func (s *testOperatorSuite) TestRollback(c *C) {
+	learner := &metapb.Peer{Id: 3, StoreId: 3, IsLearner: true}
+	region := s.newTestRegion(1, 1, [2]uint64{1, 1}, [2]uint64{2, 2}).Clone(core.WithAddPeer(learner))
+	// The learner is not promoted.
+	op := s.newTestOperator(1, OpRegion, AddLearner{ToStore: 3, PeerID: 3}, PromoteLearner{ToStore: 3, PeerID: 3}, RemovePeer{FromStore: 2})
+	c.Assert(op.Check(region), Equals, PromoteLearner{ToStore: 3, PeerID: 3})
+	rollback := CreateRollbackOperator(op, region)
+	c.Assert(rollback, NotNil)
+	c.Assert(rollback.Desc(), Equals, "rollback-test")
+	c.Assert(rollback.Kind(), Equals, OpRollback|OpRegion)
+	c.Assert(rollback.GetPriorityLevel(), Equals, core.HighPriority)
+	s.checkSteps(c, rollback, []OperatorStep{RemovePeer{FromStore: 3}})
+	// Nothing to clean up for a rollback operator.
+	c.Assert(CreateRollbackOperator(rollback, region), IsNil)
+
+	// The added peer is promoted and becomes the leader.
+	op = s.newTestOperator(1, OpLeader|OpRegion, AddLearner{ToStore: 3, PeerID: 3}, PromoteLearner{ToStore: 3, PeerID: 3},
+		TransferLeader{FromStore: 1, ToStore: 3}, RemovePeer{FromStore: 1})
+	c.Assert(op.Check(region), Equals, PromoteLearner{ToStore: 3, PeerID: 3})
+	region = s.newTestRegion(1, 3, [2]uint64{1, 1}, [2]uint64{2, 2}, [2]uint64{3, 3})
+	c.Assert(op.Check(region), Equals, RemovePeer{FromStore: 1})
+	rollback = CreateRollbackOperator(op, region)
+	c.Assert(rollback, NotNil)
+	c.Assert(rollback.Kind(), Equals, OpRollback|OpLeader|OpRegion)
+	s.checkSteps(c, rollback, []OperatorStep{TransferLeader{FromStore: 3, ToStore: 1}, RemovePeer{FromStore: 3}})
+	histories := rollback.History()
+	c.Assert(histories, HasLen, 2)
+	c.Assert(histories[0].Kind, Equals, core.ResourceKind(core.LeaderKind))
+	c.Assert(histories[0].From, Equals, uint64(3))
+	c.Assert(histories[0].To, Equals, uint64(1))
+	c.Assert(histories[0].Rollback, IsTrue)
+	c.Assert(histories[1].Kind, Equals, core.ResourceKind(core.RegionKind))
+	c.Assert(histories[1].From, Equals, uint64(3))
+	c.Assert(histories[1].To, Equals, uint64(0))
+	c.Assert(histories[1].Rollback, IsTrue)
+
+	// The added voter is kept if there is no peer to remove.
+	op = s.newTestOperator(1, OpRegion, AddPeer{ToStore: 3, PeerID: 3}, AddPeer{ToStore: 4, PeerID: 4})
+	c.Assert(op.Check(region), Equals, AddPeer{ToStore: 4, PeerID: 4})
+	c.Assert(CreateRollbackOperator(op, region), IsNil)
+}
+
 func (s *testOperatorSuite) TestInfluence(c *C) {
 	region := s.newTestRegion(1, 1, [2]uint64{1, 1}, [2]uint64{2, 2})
 	opInfluence := OpInfluence{storesInfluence: make(map[uint64]*StoreInfluence)}
@@ -147,11 +216,12 @@ func (s *testOperatorSuite) TestInfluence(c *C) {
 
 	RemovePeer{FromStore: 1}.Influence(opInfluence, region)
 	c.Assert(*storeOpInfluence[1], DeepEquals, StoreInfluence{
-		LeaderSize:  -10,
-		LeaderCount: -1,
-		RegionSize:  -10,
-		RegionCount: -1,
-		StepCost:    0,
+		LeaderSize:     -10,
+		LeaderCount:    -1,
+		RegionSize:     -10,
+		RegionCount:    -1,
+		StepCost:       0,
+		RemoveStepCost: 1000,
 	})
 	c.Assert(*storeOpInfluence[2], DeepEquals, StoreInfluence{
 		LeaderSize:  10,
@@ -163,11 +233,12 @@ func (s *testOperatorSuite) TestInfluence(c *C) {
 
 	MergeRegion{IsPassive: false}.Influence(opInfluence, region)
 	c.Assert(*storeOpInfluence[1], DeepEquals, StoreInfluence{
-		LeaderSize:  -10,
-		LeaderCount: -1,
-		RegionSize:  -10,
-		RegionCount: -1,
-		StepCost:    0,
+		LeaderSize:     -10,
+		LeaderCount:    -1,
+		RegionSize:     -10,
+		RegionCount:    -1,
+		StepCost:       0,
+		RemoveStepCost: 1000,
 	})
 	c.Assert(*storeOpInfluence[2], DeepEquals, StoreInfluence{
 		LeaderSize:  10,
@@ -179,11 +250,12 @@ func (s *testOperatorSuite) TestInfluence(c *C) {
 
 	MergeRegion{IsPassive: true}.Influence(opInfluence, region)
 	c.Assert(*storeOpInfluence[1], DeepEquals, StoreInfluence{
-		LeaderSize:  -10,
-		LeaderCount: -2,
-		RegionSize:  -10,
-		RegionCount: -2,
-		StepCost:    0,
+		LeaderSize:     -10,
+		LeaderCount:    -2,
+		RegionSize:     -10,
+		RegionCount:    -2,
+		StepCost:       0,
+		RemoveStepCost: 1000,
 	})
 	c.Assert(*storeOpInfluence[2], DeepEquals, StoreInfluence{
 		LeaderSize:  10,
@@ -205,3 +277,46 @@ func (s *testOperatorSuite) TestOperatorKind(c *C) {
 	_, err = ParseOperatorKind("foobar")
 	c.Assert(err, NotNil)
 }
+
+func (s *testOperatorSuite) TestNoSnapshotSource(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	opt.LabelProperties = map[string][]*metapb.StoreLabel{
+		NoSnapshotSource: {{Key: "zone", Value: "z1"}},
+	}
+	opt.DisableLearner = true
+	tc := mockcluster.NewCluster(opt)
+	tc.AddLabelsStore(1, 1, map[string]string{"zone": "z1"})
+	tc.AddLabelsStore(2, 1, map[string]string{"zone": "z1"})
+	tc.AddLabelsStore(3, 1, map[string]string{"zone": "z2"})
+	tc.AddLabelsStore(4, 1, map[string]string{"zone": "z2"})
+	region := s.newTestRegion(1, 1, [2]uint64{1, 1}, [2]uint64{2, 2}, [2]uint64{3, 3})
+
+	// Transfer the leader to the follower which can send snapshots first.
+	op := CreateAddPeerOperator("test", tc, region, 4, 4, OpReplica)
+	s.checkSteps(c, op, []OperatorStep{
+		TransferLeader{FromStore: 1, ToStore: 3},
+		AddPeer{ToStore: 4, PeerID: 4},
+	})
+	c.Assert(op.Kind()&OpLeader, Equals, OpLeader)
+
+	op, err := CreateMovePeerOperator("test", tc, region, OpReplica, 1, 4, 4)
+	c.Assert(err, IsNil)
+	s.checkSteps(c, op, []OperatorStep{
+		TransferLeader{FromStore: 1, ToStore: 3},
+		AddPeer{ToStore: 4, PeerID: 4},
+		RemovePeer{FromStore: 1},
+	})
+
+	// The follower on the store to remove is not chosen.
+	op, err = CreateMovePeerOperator("test", tc, region, OpReplica, 3, 4, 4)
+	c.Assert(err, IsNil)
+	s.checkSteps(c, op, []OperatorStep{
+		AddPeer{ToStore: 4, PeerID: 4},
+		RemovePeer{FromStore: 3},
+	})
+
+	// No need to transfer the leader.
+	region = s.newTestRegion(1, 3, [2]uint64{1, 1}, [2]uint64{2, 2}, [2]uint64{3, 3})
+	op = CreateAddPeerOperator("test", tc, region, 4, 4, OpReplica)
+	s.checkSteps(c, op, []OperatorStep{AddPeer{ToStore: 4, PeerID: 4}})
+}
@@ -47,6 +47,7 @@ type Options interface {
 	GetLowSpaceRatio() float64
 	GetHighSpaceRatio() float64
 	GetSchedulerMaxWaitingOperator() uint64
+	GetOperatorPriorityClasses() []string
 
 	IsRaftLearnerEnabled() bool
 
@@ -56,6 +57,7 @@ type Options interface {
 	IsRemoveExtraReplicaEnabled() bool
 	IsLocationReplacementEnabled() bool
 	IsNamespaceRelocationEnabled() bool
+	IsPlacementRulesEnabled() bool
 
 	CheckLabelProperty(typ string, labels []*metapb.StoreLabel) bool
 }
@@ -64,4 +66,30 @@ const (
 	// RejectLeader is the label property type that suggests a store should not
 	// have any region leaders.
 	RejectLeader = "reject-leader"
+	// RejectPeer is the label property type that suggests a store should not
+	// have any region peers. The existing peers are moved to other stores.
+	RejectPeer = "reject-peer"
+	// PreferLeader is the label property type that suggests the region leaders
+	// should be placed on the store if it has a peer of the region.
+	PreferLeader = "prefer-leader"
+	// NoSnapshotSource is the label property type that suggests a store should
+	// not send snapshots to the new peers, so the leader is transferred away
+	// from it before adding peers.
+	NoSnapshotSource = "no-snapshot-source"
+	// ReadOnly is the label property type that suggests a store should not
+	// receive new peers or region leaders, while keeping its existing peers.
+	ReadOnly = "read-only"
 )
+
+// LabelPropertyTypes are all the types of the label properties.
+var LabelPropertyTypes = []string{RejectLeader, RejectPeer, PreferLeader, NoSnapshotSource, ReadOnly}
+
+// IsLabelPropertyType checks if typ is a type of the label properties.
+func IsLabelPropertyType(typ string) bool {
+	for _, t := range LabelPropertyTypes {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
@@ -76,6 +76,7 @@ func NewRegionScatterer(cluster Cluster, classifier namespace.Classifier) *Regio
 		classifier: classifier,
 		filters: []Filter{
 			StoreStateFilter{},
+			NewRejectPeerFilter(),
 		},
 		selected: newSelectedStores(),
 	}
@@ -87,7 +88,7 @@ func (r *RegionScatterer) Scatter(region *core.RegionInfo) (*Operator, error) {
 		return nil, errors.Errorf("region %d is a hot region", region.GetID())
 	}
 
-	if len(region.GetPeers()) != r.cluster.GetMaxReplicas() {
+	if len(region.GetPeers()) != GetRegionMaxReplicas(r.cluster, region) {
 		return nil, errors.Errorf("the number replicas of region %d is not expected", region.GetID())
 	}
 
@@ -140,7 +141,7 @@ func (r *RegionScatterer) selectPeerToReplace(stores map[uint64]*core.StoreInfo,
 	// scoreGuard guarantees that the distinct score will not decrease.
 	regionStores := r.cluster.GetRegionStores(region)
 	sourceStore := r.cluster.GetStore(oldPeer.GetStoreId())
-	scoreGuard := NewDistinctScoreFilter(r.cluster.GetLocationLabels(), regionStores, sourceStore)
+	scoreGuard := NewDistinctScoreFilter(GetRegionLocationLabels(r.cluster, region), regionStores, sourceStore)
 
 	candidates := make([]*core.StoreInfo, 0, len(stores))
 	for _, store := range stores {
@@ -59,3 +59,21 @@ func compareStoreScore(opt Options, storeA *core.StoreInfo, scoreA float64, stor
 	}
 	return 0
 }
+
+// GetRegionMaxReplicas returns the number of replicas for the region, which
+// may be overridden by the range replication covering the region.
+func GetRegionMaxReplicas(cluster Cluster, region *core.RegionInfo) int {
+	if r := cluster.GetRangeReplication(region); r != nil && r.MaxReplicas > 0 {
+		return int(r.MaxReplicas)
+	}
+	return cluster.GetMaxReplicas()
+}
+
+// GetRegionLocationLabels returns the location labels for the region, which
+// may be overridden by the range replication covering the region.
+func GetRegionLocationLabels(cluster Cluster, region *core.RegionInfo) []string {
+	if r := cluster.GetRangeReplication(region); r != nil && r.LocationLabels != nil {
+		return r.LocationLabels
+	}
+	return cluster.GetLocationLabels()
+}
@@ -39,12 +39,15 @@ type Cluster interface {
 	GetFollowerStores(region *core.RegionInfo) []*core.StoreInfo
 	GetLeaderStore(region *core.RegionInfo) *core.StoreInfo
 	GetAdjacentRegions(region *core.RegionInfo) (*core.RegionInfo, *core.RegionInfo)
+	GetLeaderPolicy(region *core.RegionInfo) *core.LeaderPolicy
+	GetRangeReplication(region *core.RegionInfo) *core.RangeReplication
 	ScanRegions(startKey []byte, limit int) []*core.RegionInfo
 
 	BlockStore(id uint64) error
 	UnblockStore(id uint64)
 
 	AttachOverloadStatus(id uint64, f func() bool)
+	ObserveSnapshot(id uint64, size uint64, duration time.Duration)
 
 	IsRegionHot(id uint64) bool
 	RegionWriteStats() []*statistics.RegionStat
@@ -73,8 +76,50 @@ type Scheduler interface {
 	IsScheduleAllowed(cluster Cluster) bool
 }
 
-// CreateSchedulerFunc is for creating scheduler.
-type CreateSchedulerFunc func(opController *OperatorController, args []string) (Scheduler, error)
+// ExplainableScheduler is a scheduler which explains how it makes decisions
+// in its latest scheduling rounds.
+type ExplainableScheduler interface {
+	Scheduler
+	GetExplanations(limit int) []*ExplainRound
+}
+
+// ConfigurableScheduler is a scheduler whose config can be read and updated
+// while it is running. The config is persisted by the scheduler, and is
+// restored when the scheduler is created again.
+type ConfigurableScheduler interface {
+	Scheduler
+	// GetConfig returns a copy of the typed config, which is marshaled to
+	// JSON by the caller.
+	GetConfig() interface{}
+	// SetConfig updates the config by JSON data, the fields absent in the
+	// data are unchanged.
+	SetConfig(data []byte) error
+}
+
+// KeyRange is a key range [StartKey, EndKey) in hex format. An empty EndKey
+// means +inf.
+type KeyRange struct {
+	StartKey string `json:"start_key"`
+	EndKey   string `json:"end_key"`
+}
+
+// StoreScheduler is a scheduler working on a set of stores, such as
+// evict-leader and grant-leader. There is only one instance of it in the
+// cluster, and stores can be added to or removed from it while it is running.
+type StoreScheduler interface {
+	Scheduler
+	// GetStores returns the stores and their key ranges. A store without
+	// ranges is scheduled as a whole.
+	GetStores() map[uint64][]KeyRange
+	// AddStore adds a store or updates its ranges.
+	AddStore(cluster Cluster, storeID uint64, ranges []KeyRange) error
+	// RemoveStore removes a store, and returns true if there is no store left.
+	RemoveStore(cluster Cluster, storeID uint64) (bool, error)
+}
+
+// CreateSchedulerFunc is for creating scheduler. The storage is used by the
+// schedulers which persist their states.
+type CreateSchedulerFunc func(opController *OperatorController, storage *core.KV, args []string) (Scheduler, error)
 
 var schedulerMap = make(map[string]CreateSchedulerFunc)
 
@@ -94,10 +139,10 @@ func IsSchedulerRegistered(name string) bool {
 }
 
 // CreateScheduler creates a scheduler with registered creator func.
-func CreateScheduler(name string, opController *OperatorController, args ...string) (Scheduler, error) {
+func CreateScheduler(name string, opController *OperatorController, storage *core.KV, args ...string) (Scheduler, error) {
 	fn, ok := schedulerMap[name]
 	if !ok {
 		return nil, errors.Errorf("create func of %v is not registered", name)
 	}
-	return fn(opController, args)
+	return fn(opController, storage, args)
 }
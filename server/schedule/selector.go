@@ -37,9 +37,17 @@ func NewBalanceSelector(kind core.ResourceKind, filters []Filter) *BalanceSelect
 // SelectSource selects the store that can pass all filters and has the minimal
 // resource score.
 func (s *BalanceSelector) SelectSource(opt Options, stores []*core.StoreInfo) *core.StoreInfo {
+	return s.SelectSourceExplained(opt, stores, nil)
+}
+
+// SelectSourceExplained is the same as SelectSource, and it records the
+// candidate stores to the round.
+func (s *BalanceSelector) SelectSourceExplained(opt Options, stores []*core.StoreInfo, round *ExplainRound) *core.StoreInfo {
 	var result *core.StoreInfo
 	for _, store := range stores {
-		if FilterSource(opt, store, s.filters) {
+		filter := SourceFilteredBy(opt, store, s.filters)
+		round.AddCandidate(SourceCandidate, store.GetID(), store.ResourceScore(s.kind, opt.GetHighSpaceRatio(), opt.GetLowSpaceRatio(), 0), filter)
+		if filter != nil {
 			continue
 		}
 		if result == nil ||
@@ -0,0 +1,136 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/pingcap/pd/server/core"
+	"github.com/pkg/errors"
+)
+
+// StoreLimitType is the type of a store limit.
+type StoreLimitType int
+
+const (
+	// StoreLimitAddPeer limits the peers added to a store, which receive
+	// snapshots from other stores.
+	StoreLimitAddPeer StoreLimitType = iota
+	// StoreLimitRemovePeer limits the peers removed from a store.
+	StoreLimitRemovePeer
+)
+
+// StoreLimitTypes are all the types of the store limits.
+var StoreLimitTypes = []StoreLimitType{StoreLimitAddPeer, StoreLimitRemovePeer}
+
+var storeLimitTypeNames = map[StoreLimitType]string{
+	StoreLimitAddPeer:    "add-peer",
+	StoreLimitRemovePeer: "remove-peer",
+}
+
+func (t StoreLimitType) String() string {
+	if name, ok := storeLimitTypeNames[t]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseStoreLimitType parses the name of a store limit type.
+func ParseStoreLimitType(name string) (StoreLimitType, error) {
+	for t, n := range storeLimitTypeNames {
+		if n == name {
+			return t, nil
+		}
+	}
+	return 0, errors.Errorf("unknown store limit type %q", name)
+}
+
+// The scopes of the store limit rates which are not set by a label.
+const (
+	StoreLimitScopeDefault = "default"
+	StoreLimitScopeAll     = "all"
+	StoreLimitScopeStore   = "store"
+)
+
+// StoreLimitSetting is a rate of a type of store limit set for some stores,
+// which is persisted. It applies to a store if StoreID is set, to the stores
+// with the label if LabelKey is set, or else to all the stores.
+type StoreLimitSetting struct {
+	Type       string `json:"type"`
+	StoreID    uint64 `json:"store_id,omitempty"`
+	LabelKey   string `json:"label_key,omitempty"`
+	LabelValue string `json:"label_value,omitempty"`
+	// Rate is the number of the operator steps per second.
+	Rate float64 `json:"rate"`
+}
+
+// validate checks the type and the rate of the setting.
+func (s *StoreLimitSetting) validate() error {
+	if _, err := ParseStoreLimitType(s.Type); err != nil {
+		return err
+	}
+	if s.Rate <= 0 {
+		return errors.Errorf("invalid store limit rate %v", s.Rate)
+	}
+	return nil
+}
+
+func (s *StoreLimitSetting) key() string {
+	switch {
+	case s.StoreID != 0:
+		return path.Join(s.Type, "store", fmt.Sprintf("%020d", s.StoreID))
+	case s.LabelKey != "":
+		return path.Join(s.Type, "label", s.LabelKey, s.LabelValue)
+	default:
+		return path.Join(s.Type, "all")
+	}
+}
+
+// Scope returns the stores the setting applies to.
+func (s *StoreLimitSetting) Scope() string {
+	switch {
+	case s.StoreID != 0:
+		return StoreLimitScopeStore
+	case s.LabelKey != "":
+		return s.LabelKey + "=" + s.LabelValue
+	default:
+		return StoreLimitScopeAll
+	}
+}
+
+// match returns true if the setting applies to a store. store is nil if the
+// store is not found.
+func (s *StoreLimitSetting) match(storeID uint64, store *core.StoreInfo) bool {
+	switch {
+	case s.StoreID != 0:
+		return storeID == s.StoreID
+	case s.LabelKey != "":
+		return store != nil && store.GetLabelValue(s.LabelKey) == s.LabelValue
+	default:
+		return true
+	}
+}
+
+// StoreLimitStatus is the status of a type of store limit of a store.
+type StoreLimitStatus struct {
+	// Rate is the number of the operator steps per second.
+	Rate float64 `json:"rate"`
+	// Available and Capacity are the number of the operator steps which can
+	// be taken now and at most.
+	Available float64 `json:"available"`
+	Capacity  float64 `json:"capacity"`
+	// Scope is the stores the rate is set for.
+	Scope string `json:"scope"`
+}
@@ -16,6 +16,8 @@ package schedule
 import (
 	"math/rand"
 	"time"
+
+	"github.com/pkg/errors"
 )
 
 // PriorityWeight is used to represent the weight of different priorities of operators.
@@ -23,7 +25,9 @@ var PriorityWeight = []float64{1.0, 4.0, 9.0}
 
 // WaitingOperator is an interface of waiting operators.
 type WaitingOperator interface {
-	PutOperator(op *Operator)
+	// PutOperator puts the operators of an operation, such as the two
+	// operators to merge regions.
+	PutOperator(ops ...*Operator)
 	GetOperator() []*Operator
 	ListOperator() []*Operator
 }
@@ -51,14 +55,16 @@ func NewRandBuckets() *RandBuckets {
 	return &RandBuckets{buckets: buckets}
 }
 
-// PutOperator puts an operator into the random buckets.
-func (b *RandBuckets) PutOperator(op *Operator) {
-	priority := op.GetPriorityLevel()
-	bucket := b.buckets[priority]
-	if len(bucket.ops) == 0 {
-		b.totalWeight += bucket.weight
+// PutOperator puts operators into the random buckets.
+func (b *RandBuckets) PutOperator(ops ...*Operator) {
+	for _, op := range ops {
+		priority := op.GetPriorityLevel()
+		bucket := b.buckets[priority]
+		if len(bucket.ops) == 0 {
+			b.totalWeight += bucket.weight
+		}
+		bucket.ops = append(bucket.ops, op)
 	}
-	bucket.ops = append(bucket.ops, op)
 }
 
 // ListOperator lists all operator in the random buckets.
@@ -106,6 +112,270 @@ func (b *RandBuckets) GetOperator() []*Operator {
 	return nil
 }
 
+// The priority classes of the waiting operators.
+const (
+	// UrgentClass is the class of the operators to repair replicas.
+	UrgentClass = "urgent"
+	// AdminClass is the class of the operators added by the administrators.
+	AdminClass = "admin"
+	// MergeClass is the class of the operators to merge regions.
+	MergeClass = "merge"
+	// BalanceClass is the class of the other operators, most of which are
+	// generated by the balance schedulers.
+	BalanceClass = "balance"
+	// HotClass is the class of the operators to schedule hot regions.
+	HotClass = "hot"
+)
+
+// DefaultPriorityClasses is the default priority classes of the waiting
+// operators, from the highest priority to the lowest one.
+var DefaultPriorityClasses = []string{UrgentClass, AdminClass, MergeClass, BalanceClass, HotClass}
+
+// WaitingOperatorStarvationTime is the max time an operation waits before it
+// is promoted regardless of the priority classes.
+const WaitingOperatorStarvationTime = time.Minute
+
+// PriorityClassOf returns the priority class of the operator.
+func PriorityClassOf(op *Operator) string {
+	kind := op.Kind()
+	switch {
+	case kind&OpReplica != 0:
+		return UrgentClass
+	case kind&OpAdmin != 0:
+		return AdminClass
+	case kind&OpMerge != 0:
+		return MergeClass
+	case kind&OpHotRegion != 0:
+		return HotClass
+	default:
+		return BalanceClass
+	}
+}
+
+// ValidatePriorityClasses checks if the classes are a permutation of the
+// DefaultPriorityClasses.
+func ValidatePriorityClasses(classes []string) error {
+	if len(classes) != len(DefaultPriorityClasses) {
+		return errors.Errorf("priority classes should be a permutation of %v", DefaultPriorityClasses)
+	}
+	seen := make(map[string]struct{})
+	for _, class := range classes {
+		if _, ok := seen[class]; ok {
+			return errors.Errorf("priority class %s is duplicated", class)
+		}
+		seen[class] = struct{}{}
+	}
+	for _, class := range DefaultPriorityClasses {
+		if _, ok := seen[class]; !ok {
+			return errors.Errorf("priority class %s is missing", class)
+		}
+	}
+	return nil
+}
+
+// waitingOperation is the operators of an operation in the waiting queue.
+type waitingOperation struct {
+	ops  []*Operator
+	time time.Time
+}
+
+// sourceQueue is the waiting operations generated by a source.
+type sourceQueue struct {
+	source     string
+	operations []*waitingOperation
+}
+
+// priorityClass is the waiting operations of a priority class.
+type priorityClass struct {
+	name    string
+	weight  int
+	current int
+	sources []*sourceQueue
+	next    int
+	depth   int
+}
+
+func (c *priorityClass) put(source string, operation *waitingOperation) {
+	for _, q := range c.sources {
+		if q.source == source {
+			q.operations = append(q.operations, operation)
+			c.depth += len(operation.ops)
+			return
+		}
+	}
+	c.sources = append(c.sources, &sourceQueue{source: source, operations: []*waitingOperation{operation}})
+	c.depth += len(operation.ops)
+}
+
+// oldest returns the index of the source queue whose head is the oldest.
+func (c *priorityClass) oldest() int {
+	index := -1
+	for i, q := range c.sources {
+		if index < 0 || q.operations[0].time.Before(c.sources[index].operations[0].time) {
+			index = i
+		}
+	}
+	return index
+}
+
+// pop pops the head of the i-th source queue.
+func (c *priorityClass) pop(i int) []*Operator {
+	q := c.sources[i]
+	operation := q.operations[0]
+	q.operations = q.operations[1:]
+	c.depth -= len(operation.ops)
+	if len(q.operations) == 0 {
+		c.sources = append(c.sources[:i], c.sources[i+1:]...)
+		if c.next > i {
+			c.next--
+		}
+	} else if c.next == i {
+		c.next++
+	}
+	if c.next >= len(c.sources) {
+		c.next = 0
+	}
+	return operation.ops
+}
+
+// WaitingClassStats is the status of the waiting operators of a priority
+// class.
+type WaitingClassStats struct {
+	Class  string `json:"class"`
+	Weight int    `json:"weight"`
+	// Depth is the number of the waiting operators.
+	Depth int `json:"depth"`
+	// Sources is the number of the waiting operations of each source.
+	Sources map[string]int `json:"sources,omitempty"`
+}
+
+// FairQueue is an implementation of waiting operators with weighted fair
+// queuing. The operations are put into the priority classes, and the class
+// of the i-th highest priority in n classes has a weight of 2^(n-1-i). The
+// classes are served by smooth weighted round robin, so a class gets a share
+// proportional to its weight and can not be starved by the others. In a
+// class, the sources, such as the schedulers and checkers, are served by
+// round robin. An operation waiting longer than
+// WaitingOperatorStarvationTime is served first.
+type FairQueue struct {
+	classes []*priorityClass
+	byName  map[string]*priorityClass
+}
+
+// NewFairQueue creates a FairQueue with the priority classes, which are from
+// the highest priority to the lowest one.
+func NewFairQueue(classes []string) *FairQueue {
+	q := &FairQueue{byName: make(map[string]*priorityClass)}
+	for _, name := range DefaultPriorityClasses {
+		q.byName[name] = &priorityClass{name: name}
+	}
+	q.SetPriorityClasses(classes)
+	return q
+}
+
+// SetPriorityClasses changes the priorities of the classes. The
+// DefaultPriorityClasses are used if the classes are invalid.
+func (q *FairQueue) SetPriorityClasses(classes []string) {
+	if ValidatePriorityClasses(classes) != nil {
+		classes = DefaultPriorityClasses
+	}
+	if len(q.classes) == len(classes) {
+		same := true
+		for i, c := range q.classes {
+			if c.name != classes[i] {
+				same = false
+				break
+			}
+		}
+		if same {
+			return
+		}
+	}
+	q.classes = q.classes[:0]
+	for i, name := range classes {
+		c := q.byName[name]
+		c.weight, c.current = 1<<uint(len(classes)-1-i), 0
+		q.classes = append(q.classes, c)
+	}
+}
+
+// PutOperator puts the operators of an operation into the queue.
+func (q *FairQueue) PutOperator(ops ...*Operator) {
+	if len(ops) == 0 {
+		return
+	}
+	q.byName[PriorityClassOf(ops[0])].put(ops[0].Source(), &waitingOperation{ops: ops, time: time.Now()})
+}
+
+// GetOperator gets the operators of an operation from the queue.
+func (q *FairQueue) GetOperator() []*Operator {
+	var starving *priorityClass
+	starvingIndex := -1
+	for _, c := range q.classes {
+		if c.depth == 0 {
+			continue
+		}
+		i := c.oldest()
+		if time.Since(c.sources[i].operations[0].time) < WaitingOperatorStarvationTime {
+			continue
+		}
+		if starving == nil || c.sources[i].operations[0].time.Before(starving.sources[starvingIndex].operations[0].time) {
+			starving, starvingIndex = c, i
+		}
+	}
+	if starving != nil {
+		return starving.pop(starvingIndex)
+	}
+
+	var selected *priorityClass
+	total := 0
+	for _, c := range q.classes {
+		if c.depth == 0 {
+			continue
+		}
+		c.current += c.weight
+		total += c.weight
+		if selected == nil || c.current > selected.current {
+			selected = c
+		}
+	}
+	if selected == nil {
+		return nil
+	}
+	selected.current -= total
+	return selected.pop(selected.next)
+}
+
+// ListOperator lists all operators in the queue.
+func (q *FairQueue) ListOperator() []*Operator {
+	var ops []*Operator
+	for _, c := range q.classes {
+		for _, s := range c.sources {
+			for _, operation := range s.operations {
+				ops = append(ops, operation.ops...)
+			}
+		}
+	}
+	return ops
+}
+
+// Stats returns the status of the priority classes, from the highest
+// priority to the lowest one.
+func (q *FairQueue) Stats() []WaitingClassStats {
+	stats := make([]WaitingClassStats, 0, len(q.classes))
+	for _, c := range q.classes {
+		s := WaitingClassStats{Class: c.name, Weight: c.weight, Depth: c.depth}
+		for _, source := range c.sources {
+			if s.Sources == nil {
+				s.Sources = make(map[string]int)
+			}
+			s.Sources[source.source] = len(source.operations)
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
+
 // WaitingOperatorStatus is used to limit the count of each kind of operators.
 type WaitingOperatorStatus struct {
 	ops map[string]uint64
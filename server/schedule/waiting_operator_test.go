@@ -14,6 +14,8 @@
 package schedule
 
 import (
+	"time"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/pd/server/core"
@@ -103,3 +105,80 @@ func (s *testWaitingOperatorSuite) TestRandomBucketsWithMergeRegion(c *C) {
 		c.Assert(rb.GetOperator(), IsNil)
 	}
 }
+
+func newWaitingOperator(regionID uint64, kind OperatorKind, source string) *Operator {
+	op := NewOperator("test", regionID, &metapb.RegionEpoch{}, kind, RemovePeer{FromStore: 1})
+	op.SetSource(source)
+	return op
+}
+
+func (s *testWaitingOperatorSuite) TestPriorityClass(c *C) {
+	c.Assert(PriorityClassOf(newWaitingOperator(1, OpRegion|OpReplica, "")), Equals, UrgentClass)
+	c.Assert(PriorityClassOf(newWaitingOperator(1, OpRegion|OpAdmin, "")), Equals, AdminClass)
+	c.Assert(PriorityClassOf(newWaitingOperator(1, OpRegion|OpMerge, "")), Equals, MergeClass)
+	c.Assert(PriorityClassOf(newWaitingOperator(1, OpLeader|OpBalance, "")), Equals, BalanceClass)
+	c.Assert(PriorityClassOf(newWaitingOperator(1, OpLeader|OpHotRegion, "")), Equals, HotClass)
+
+	c.Assert(ValidatePriorityClasses(DefaultPriorityClasses), IsNil)
+	c.Assert(ValidatePriorityClasses([]string{HotClass, BalanceClass, MergeClass, AdminClass, UrgentClass}), IsNil)
+	c.Assert(ValidatePriorityClasses([]string{HotClass, BalanceClass}), NotNil)
+	c.Assert(ValidatePriorityClasses([]string{HotClass, HotClass, MergeClass, AdminClass, UrgentClass}), NotNil)
+	c.Assert(ValidatePriorityClasses([]string{"unknown", BalanceClass, MergeClass, AdminClass, UrgentClass}), NotNil)
+}
+
+func (s *testWaitingOperatorSuite) TestFairQueue(c *C) {
+	q := NewFairQueue(nil)
+	// A flood of merge operators can not block the hot region operators.
+	for i := uint64(0); i < 100; i++ {
+		q.PutOperator(newWaitingOperator(i, OpRegion|OpMerge, "merge-checker"))
+	}
+	for i := uint64(100); i < 110; i++ {
+		q.PutOperator(newWaitingOperator(i, OpLeader|OpHotRegion, "hot-region-scheduler"))
+	}
+	stats := q.Stats()
+	c.Assert(stats, HasLen, len(DefaultPriorityClasses))
+	c.Assert(stats[2], DeepEquals, WaitingClassStats{Class: MergeClass, Weight: 4, Depth: 100, Sources: map[string]int{"merge-checker": 100}})
+	c.Assert(stats[4], DeepEquals, WaitingClassStats{Class: HotClass, Weight: 1, Depth: 10, Sources: map[string]int{"hot-region-scheduler": 10}})
+	hot := 0
+	for i := 0; i < 50; i++ {
+		if PriorityClassOf(q.GetOperator()[0]) == HotClass {
+			hot++
+		}
+	}
+	c.Assert(hot, Equals, 10)
+
+	// The sources of a class are served by round robin.
+	q = NewFairQueue(DefaultPriorityClasses)
+	for i := uint64(0); i < 3; i++ {
+		q.PutOperator(newWaitingOperator(i, OpRegion|OpBalance, "balance-region-scheduler"))
+	}
+	q.PutOperator(newWaitingOperator(3, OpLeader|OpBalance, "balance-leader-scheduler"))
+	var regions []uint64
+	for ops := q.GetOperator(); ops != nil; ops = q.GetOperator() {
+		regions = append(regions, ops[0].RegionID())
+	}
+	c.Assert(regions, DeepEquals, []uint64{0, 3, 1, 2})
+
+	// The priority classes can be changed.
+	q.SetPriorityClasses([]string{HotClass, BalanceClass, MergeClass, AdminClass, UrgentClass})
+	q.PutOperator(newWaitingOperator(1, OpRegion|OpReplica, "replica-checker"))
+	q.PutOperator(newWaitingOperator(2, OpLeader|OpHotRegion, "hot-region-scheduler"))
+	c.Assert(q.GetOperator()[0].RegionID(), Equals, uint64(2))
+	c.Assert(q.GetOperator()[0].RegionID(), Equals, uint64(1))
+
+	// The operators of an operation are got together.
+	q.PutOperator(newWaitingOperator(1, OpRegion|OpMerge, "merge-checker"), newWaitingOperator(2, OpRegion|OpMerge, "merge-checker"))
+	c.Assert(q.GetOperator(), HasLen, 2)
+	c.Assert(q.GetOperator(), IsNil)
+}
+
+func (s *testWaitingOperatorSuite) TestStarvation(c *C) {
+	q := NewFairQueue(DefaultPriorityClasses)
+	q.PutOperator(newWaitingOperator(1, OpLeader|OpHotRegion, "hot-region-scheduler"))
+	for i := uint64(2); i < 10; i++ {
+		q.PutOperator(newWaitingOperator(i, OpRegion|OpReplica, "replica-checker"))
+	}
+	q.byName[HotClass].sources[0].operations[0].time = time.Now().Add(-WaitingOperatorStarvationTime)
+	c.Assert(q.GetOperator()[0].RegionID(), Equals, uint64(1))
+	c.Assert(q.ListOperator(), HasLen, 8)
+}
@@ -15,7 +15,9 @@ package schedulers
 
 import (
 	"bytes"
+	"encoding/json"
 	"strconv"
+	"sync"
 	"time"
 
 	log "github.com/pingcap/log"
@@ -34,7 +36,8 @@ const (
 )
 
 func init() {
-	schedule.RegisterScheduler("adjacent-region", func(opController *schedule.OperatorController, args []string) (schedule.Scheduler, error) {
+	schedule.RegisterScheduler("adjacent-region", func(opController *schedule.OperatorController, storage *core.KV, args []string) (schedule.Scheduler, error) {
+		var s *balanceAdjacentRegionScheduler
 		l := len(args)
 		if l == 2 {
 			leaderLimit, err := strconv.ParseUint(args[0], 10, 64)
@@ -45,18 +48,29 @@ func init() {
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
-			return newBalanceAdjacentRegionScheduler(opController, leaderLimit, peerLimit), nil
+			s = newBalanceAdjacentRegionScheduler(opController, leaderLimit, peerLimit)
 		} else if l == 1 {
 			leaderLimit, err := strconv.ParseUint(args[0], 10, 64)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
-			return newBalanceAdjacentRegionScheduler(opController, leaderLimit), nil
+			s = newBalanceAdjacentRegionScheduler(opController, leaderLimit)
+		} else {
+			s = newBalanceAdjacentRegionScheduler(opController)
+		}
+		s.storage = storage
+		if _, err := storage.LoadSchedulerConfig(s.GetName(), &s.conf); err != nil {
+			return nil, err
 		}
-		return newBalanceAdjacentRegionScheduler(opController), nil
+		return s, nil
 	})
 }
 
+type balanceAdjacentRegionSchedulerConfig struct {
+	LeaderLimit uint64 `json:"leader_limit"`
+	PeerLimit   uint64 `json:"peer_limit"`
+}
+
 // balanceAdjacentRegionScheduler will disperse adjacent regions.
 // we will scan a part regions order by key, then select the longest
 // adjacent regions and disperse them. finally, we will guarantee
@@ -65,11 +79,13 @@ func init() {
 type balanceAdjacentRegionScheduler struct {
 	*baseScheduler
 	selector             *schedule.RandomSelector
-	leaderLimit          uint64
-	peerLimit            uint64
+	storage              *core.KV
 	lastKey              []byte
 	cacheRegions         *adjacentState
 	adjacentRegionsCount int
+
+	mu   sync.RWMutex
+	conf balanceAdjacentRegionSchedulerConfig
 }
 
 type adjacentState struct {
@@ -90,7 +106,7 @@ func (a *adjacentState) len() int {
 
 // newBalanceAdjacentRegionScheduler creates a scheduler that tends to disperse adjacent region
 // on each store.
-func newBalanceAdjacentRegionScheduler(opController *schedule.OperatorController, args ...uint64) schedule.Scheduler {
+func newBalanceAdjacentRegionScheduler(opController *schedule.OperatorController, args ...uint64) *balanceAdjacentRegionScheduler {
 	filters := []schedule.Filter{
 		schedule.StoreStateFilter{TransferLeader: true, MoveRegion: true},
 	}
@@ -98,16 +114,18 @@ func newBalanceAdjacentRegionScheduler(opController *schedule.OperatorController
 	s := &balanceAdjacentRegionScheduler{
 		baseScheduler: base,
 		selector:      schedule.NewRandomSelector(filters),
-		leaderLimit:   defaultAdjacentLeaderLimit,
-		peerLimit:     defaultAdjacentPeerLimit,
 		lastKey:       []byte(""),
+		conf: balanceAdjacentRegionSchedulerConfig{
+			LeaderLimit: defaultAdjacentLeaderLimit,
+			PeerLimit:   defaultAdjacentPeerLimit,
+		},
 	}
 	l := len(args)
 	if l == 1 {
-		s.leaderLimit = args[0]
+		s.conf.LeaderLimit = args[0]
 	} else if l == 2 {
-		s.leaderLimit = args[0]
-		s.peerLimit = args[1]
+		s.conf.LeaderLimit = args[0]
+		s.conf.PeerLimit = args[1]
 	}
 	return s
 }
@@ -128,16 +146,40 @@ func (l *balanceAdjacentRegionScheduler) GetNextInterval(interval time.Duration)
 	return intervalGrow(interval, maxAdjacentSchedulerInterval, linearGrowth)
 }
 
+func (l *balanceAdjacentRegionScheduler) GetConfig() interface{} {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.conf
+}
+
+func (l *balanceAdjacentRegionScheduler) SetConfig(data []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	conf := l.conf
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := l.storage.SaveSchedulerConfig(l.GetName(), conf); err != nil {
+		return err
+	}
+	l.conf = conf
+	return nil
+}
+
 func (l *balanceAdjacentRegionScheduler) IsScheduleAllowed(cluster schedule.Cluster) bool {
 	return l.allowBalanceLeader() || l.allowBalancePeer()
 }
 
 func (l *balanceAdjacentRegionScheduler) allowBalanceLeader() bool {
-	return l.opController.OperatorCount(schedule.OpAdjacent|schedule.OpLeader) < l.leaderLimit
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.opController.OperatorCount(schedule.OpAdjacent|schedule.OpLeader) < l.conf.LeaderLimit
 }
 
 func (l *balanceAdjacentRegionScheduler) allowBalancePeer() bool {
-	return l.opController.OperatorCount(schedule.OpAdjacent|schedule.OpRegion) < l.peerLimit
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.opController.OperatorCount(schedule.OpAdjacent|schedule.OpRegion) < l.conf.PeerLimit
 }
 
 func (l *balanceAdjacentRegionScheduler) Schedule(cluster schedule.Cluster) []*schedule.Operator {
@@ -232,7 +274,7 @@ func (l *balanceAdjacentRegionScheduler) process(cluster schedule.Cluster) []*sc
 }
 
 func (l *balanceAdjacentRegionScheduler) unsafeToBalance(cluster schedule.Cluster, region *core.RegionInfo) bool {
-	if len(region.GetPeers()) != cluster.GetMaxReplicas() {
+	if len(region.GetPeers()) != schedule.GetRegionMaxReplicas(cluster, region) {
 		return true
 	}
 	store := cluster.GetStore(region.GetLeader().GetStoreId())
@@ -287,7 +329,7 @@ func (l *balanceAdjacentRegionScheduler) dispersePeer(cluster schedule.Cluster,
 		return nil
 	}
 
-	scoreGuard := schedule.NewDistinctScoreFilter(cluster.GetLocationLabels(), stores, source)
+	scoreGuard := schedule.NewDistinctScoreFilter(schedule.GetRegionLocationLabels(cluster, region), stores, source)
 	excludeStores := region.GetStoreIds()
 	for _, storeID := range l.cacheRegions.assignedStoreIds {
 		if _, ok := excludeStores[storeID]; !ok {
@@ -24,7 +24,7 @@ import (
 )
 
 func init() {
-	schedule.RegisterScheduler("balance-leader", func(opController *schedule.OperatorController, args []string) (schedule.Scheduler, error) {
+	schedule.RegisterScheduler("balance-leader", func(opController *schedule.OperatorController, storage *core.KV, args []string) (schedule.Scheduler, error) {
 		return newBalanceLeaderScheduler(opController), nil
 	})
 }
@@ -132,7 +132,8 @@ func (l *balanceLeaderScheduler) transferLeaderOut(source *core.StoreInfo, clust
 		schedulerCounter.WithLabelValues(l.GetName(), "no_leader_region").Inc()
 		return nil
 	}
-	target := l.selector.SelectTarget(cluster, cluster.GetFollowerStores(region))
+	policyFilter := schedule.NewLeaderPolicyFilter(cluster.GetLeaderPolicy(region), source)
+	target := l.selector.SelectTarget(cluster, cluster.GetFollowerStores(region), policyFilter, schedule.NewPreferLeaderFilter(source))
 	if target == nil {
 		log.Debug("region has no target store", zap.String("scheduler", l.GetName()), zap.Uint64("region-id", region.GetID()))
 		schedulerCounter.WithLabelValues(l.GetName(), "no_target_store").Inc()
@@ -158,6 +159,12 @@ func (l *balanceLeaderScheduler) transferLeaderIn(target *core.StoreInfo, cluste
 		schedulerCounter.WithLabelValues(l.GetName(), "no_leader").Inc()
 		return nil
 	}
+	policyFilter := schedule.NewLeaderPolicyFilter(cluster.GetLeaderPolicy(region), source)
+	if schedule.FilterTarget(cluster, target, []schedule.Filter{policyFilter, schedule.NewPreferLeaderFilter(source)}) {
+		log.Debug("target store is not preferred by leader policy", zap.String("scheduler", l.GetName()), zap.Uint64("region-id", region.GetID()))
+		schedulerCounter.WithLabelValues(l.GetName(), "leader_policy").Inc()
+		return nil
+	}
 	return l.createOperator(region, source, target, cluster, opInfluence)
 }
 
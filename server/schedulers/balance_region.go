@@ -26,7 +26,7 @@ import (
 )
 
 func init() {
-	schedule.RegisterScheduler("balance-region", func(opController *schedule.OperatorController, args []string) (schedule.Scheduler, error) {
+	schedule.RegisterScheduler("balance-region", func(opController *schedule.OperatorController, storage *core.KV, args []string) (schedule.Scheduler, error) {
 		return newBalanceRegionScheduler(opController), nil
 	})
 }
@@ -39,6 +39,7 @@ type balanceRegionScheduler struct {
 	selector     *schedule.BalanceSelector
 	taintStores  *cache.TTLUint64
 	opController *schedule.OperatorController
+	explainer    *schedule.Explainer
 }
 
 // newBalanceRegionScheduler creates a scheduler that tends to keep regions on
@@ -48,6 +49,7 @@ func newBalanceRegionScheduler(opController *schedule.OperatorController) schedu
 	filters := []schedule.Filter{
 		schedule.StoreStateFilter{MoveRegion: true},
 		schedule.NewCacheFilter(taintStores),
+		schedule.NewSnapshotThroughputFilter(),
 	}
 	base := newBaseScheduler(opController)
 	s := &balanceRegionScheduler{
@@ -55,6 +57,7 @@ func newBalanceRegionScheduler(opController *schedule.OperatorController) schedu
 		selector:      schedule.NewBalanceSelector(core.RegionKind, filters),
 		taintStores:   taintStores,
 		opController:  opController,
+		explainer:     schedule.NewExplainer(schedule.DefaultExplainRounds),
 	}
 	return s
 }
@@ -67,19 +70,26 @@ func (s *balanceRegionScheduler) GetType() string {
 	return "balance-region"
 }
 
+func (s *balanceRegionScheduler) GetExplanations(limit int) []*schedule.ExplainRound {
+	return s.explainer.GetRounds(limit)
+}
+
 func (s *balanceRegionScheduler) IsScheduleAllowed(cluster schedule.Cluster) bool {
 	return s.opController.OperatorCount(schedule.OpRegion) < cluster.GetRegionScheduleLimit()
 }
 
 func (s *balanceRegionScheduler) Schedule(cluster schedule.Cluster) []*schedule.Operator {
 	schedulerCounter.WithLabelValues(s.GetName(), "schedule").Inc()
+	round := s.explainer.NewRound()
+	defer s.explainer.Record(round)
 
 	stores := cluster.GetStores()
 
 	// source is the store with highest region score in the list that can be selected as balance source.
-	source := s.selector.SelectSource(cluster, stores)
+	source := s.selector.SelectSourceExplained(cluster, stores, round)
 	if source == nil {
 		schedulerCounter.WithLabelValues(s.GetName(), "no_store").Inc()
+		round.SetResult("no_store")
 		// Unlike the balanceLeaderScheduler, we don't need to clear the taintCache
 		// here. Because normally region score won't change rapidly, and the region
 		// balance requires lower sensitivity compare to leader balance.
@@ -103,14 +113,16 @@ func (s *balanceRegionScheduler) Schedule(cluster schedule.Cluster) []*schedule.
 		}
 		if region == nil {
 			schedulerCounter.WithLabelValues(s.GetName(), "no_region").Inc()
+			round.Skip("no_region")
 			continue
 		}
 		log.Debug("select region", zap.String("scheduler", s.GetName()), zap.Uint64("region-id", region.GetID()))
 
 		// We don't schedule region with abnormal number of replicas.
-		if len(region.GetPeers()) != cluster.GetMaxReplicas() {
+		if len(region.GetPeers()) != schedule.GetRegionMaxReplicas(cluster, region) {
 			log.Debug("region has abnormal replica count", zap.String("scheduler", s.GetName()), zap.Uint64("region-id", region.GetID()))
 			schedulerCounter.WithLabelValues(s.GetName(), "abnormal_replica").Inc()
+			round.Skip("abnormal_replica")
 			continue
 		}
 
@@ -118,42 +130,48 @@ func (s *balanceRegionScheduler) Schedule(cluster schedule.Cluster) []*schedule.
 		if cluster.IsRegionHot(region.GetID()) {
 			log.Debug("region is hot", zap.String("scheduler", s.GetName()), zap.Uint64("region-id", region.GetID()))
 			schedulerCounter.WithLabelValues(s.GetName(), "region_hot").Inc()
+			round.Skip("region_hot")
 			continue
 		}
 
-		if !s.hasPotentialTarget(cluster, region, source, opInfluence) {
+		if !s.hasPotentialTarget(cluster, region, source, opInfluence, round) {
+			round.Skip("no_potential_target")
 			continue
 		}
 		hasPotentialTarget = true
 
 		oldPeer := region.GetStorePeer(sourceID)
-		if op := s.transferPeer(cluster, region, oldPeer, opInfluence); op != nil {
+		if op := s.transferPeer(cluster, region, oldPeer, opInfluence, round); op != nil {
 			schedulerCounter.WithLabelValues(s.GetName(), "new_operator").Inc()
+			round.SetResult("new_operator")
 			return []*schedule.Operator{op}
 		}
 	}
+	round.SetResult("no_operator")
 
 	if !hasPotentialTarget {
 		// If no potential target store can be found for the selected store, ignore it for a while.
 		log.Debug("no operator created for selected store", zap.String("scheduler", s.GetName()), zap.Uint64("store-id", sourceID))
 		balanceRegionCounter.WithLabelValues("add_taint", sourceAddress, sourceLabel).Inc()
 		s.taintStores.Put(sourceID)
+		round.SetResult("no_potential_target")
 	}
 
 	return nil
 }
 
 // transferPeer selects the best store to create a new peer to replace the old peer.
-func (s *balanceRegionScheduler) transferPeer(cluster schedule.Cluster, region *core.RegionInfo, oldPeer *metapb.Peer, opInfluence schedule.OpInfluence) *schedule.Operator {
+func (s *balanceRegionScheduler) transferPeer(cluster schedule.Cluster, region *core.RegionInfo, oldPeer *metapb.Peer, opInfluence schedule.OpInfluence, round *schedule.ExplainRound) *schedule.Operator {
 	// scoreGuard guarantees that the distinct score will not decrease.
 	stores := cluster.GetRegionStores(region)
 	source := cluster.GetStore(oldPeer.GetStoreId())
-	scoreGuard := schedule.NewDistinctScoreFilter(cluster.GetLocationLabels(), stores, source)
+	scoreGuard := schedule.NewDistinctScoreFilter(schedule.GetRegionLocationLabels(cluster, region), stores, source)
 
 	checker := checker.NewReplicaChecker(cluster, nil)
 	storeID, _ := checker.SelectBestReplacementStore(region, oldPeer, scoreGuard)
 	if storeID == 0 {
 		schedulerCounter.WithLabelValues(s.GetName(), "no_replacement").Inc()
+		round.Skip("no_replacement")
 		return nil
 	}
 
@@ -163,7 +181,9 @@ func (s *balanceRegionScheduler) transferPeer(cluster schedule.Cluster, region *
 	targetID := target.GetID()
 	log.Debug("", zap.Uint64("region-id", regionID), zap.Uint64("source-store", sourceID), zap.Uint64("target-store", targetID))
 
-	if !shouldBalance(cluster, source, target, region, core.RegionKind, opInfluence) {
+	comparison := compareBalanceScore(cluster, source, target, region, core.RegionKind, opInfluence)
+	round.AddComparison(comparison)
+	if !comparison.ShouldBalance {
 		log.Debug("skip balance region",
 			zap.String("scheduler", s.GetName()), zap.Uint64("region-id", regionID), zap.Uint64("source-store", sourceID), zap.Uint64("target-store", targetID),
 			zap.Int64("source-size", source.GetRegionSize()), zap.Float64("source-score", source.RegionScore(cluster.GetHighSpaceRatio(), cluster.GetLowSpaceRatio(), 0)),
@@ -172,19 +192,23 @@ func (s *balanceRegionScheduler) transferPeer(cluster schedule.Cluster, region *
 			zap.Int64("target-influence", opInfluence.GetStoreInfluence(targetID).ResourceSize(core.RegionKind)),
 			zap.Int64("average-region-size", cluster.GetAverageRegionSize()))
 		schedulerCounter.WithLabelValues(s.GetName(), "skip").Inc()
+		round.Skip("skip")
 		return nil
 	}
 
 	newPeer, err := cluster.AllocPeer(storeID)
 	if err != nil {
 		schedulerCounter.WithLabelValues(s.GetName(), "no_peer").Inc()
+		round.Skip("no_peer")
 		return nil
 	}
 	op, err := schedule.CreateMovePeerOperator("balance-region", cluster, region, schedule.OpBalance, oldPeer.GetStoreId(), newPeer.GetStoreId(), newPeer.GetId())
 	if err != nil {
 		schedulerCounter.WithLabelValues(s.GetName(), "create_operator_fail").Inc()
+		round.Skip("create_operator_fail")
 		return nil
 	}
+	round.SetDecision(regionID, sourceID, targetID)
 	sourceLabel := strconv.FormatUint(sourceID, 10)
 	targetLabel := strconv.FormatUint(targetID, 10)
 	balanceRegionCounter.WithLabelValues("move_peer", source.GetAddress()+"-out", sourceLabel).Inc()
@@ -199,22 +223,29 @@ func (s *balanceRegionScheduler) transferPeer(cluster schedule.Cluster, region *
 // The main factor for judgment includes StoreState, DistinctScore, and
 // ResourceScore, while excludes factors such as ServerBusy, too many snapshot,
 // which may recover soon.
-func (s *balanceRegionScheduler) hasPotentialTarget(cluster schedule.Cluster, region *core.RegionInfo, source *core.StoreInfo, opInfluence schedule.OpInfluence) bool {
+func (s *balanceRegionScheduler) hasPotentialTarget(cluster schedule.Cluster, region *core.RegionInfo, source *core.StoreInfo, opInfluence schedule.OpInfluence, round *schedule.ExplainRound) bool {
 	filters := []schedule.Filter{
 		schedule.NewExcludedFilter(nil, region.GetStoreIds()),
-		schedule.NewDistinctScoreFilter(cluster.GetLocationLabels(), cluster.GetRegionStores(region), source),
+		schedule.NewDistinctScoreFilter(schedule.GetRegionLocationLabels(cluster, region), cluster.GetRegionStores(region), source),
 	}
 
 	for _, store := range cluster.GetStores() {
-		if schedule.FilterTarget(cluster, store, filters) {
+		score := store.RegionScore(cluster.GetHighSpaceRatio(), cluster.GetLowSpaceRatio(), 0)
+		if filter := schedule.TargetFilteredBy(cluster, store, filters); filter != nil {
+			round.AddCandidate(schedule.TargetCandidate, store.GetID(), score, filter)
 			continue
 		}
 		if !store.IsUp() || store.DownTime() > cluster.GetMaxStoreDownTime() {
+			round.AddRejectedCandidate(schedule.TargetCandidate, store.GetID(), score, "store-not-up")
 			continue
 		}
-		if !shouldBalance(cluster, source, store, region, core.RegionKind, opInfluence) {
+		comparison := compareBalanceScore(cluster, source, store, region, core.RegionKind, opInfluence)
+		round.AddComparison(comparison)
+		if !comparison.ShouldBalance {
+			round.AddRejectedCandidate(schedule.TargetCandidate, store.GetID(), score, "score-not-balanced")
 			continue
 		}
+		round.AddCandidate(schedule.TargetCandidate, store.GetID(), score, nil)
 		return true
 	}
 	return false
@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
@@ -130,7 +131,7 @@ func (s *testBalanceLeaderSchedulerSuite) SetUpTest(c *C) {
 	opt := mockoption.NewScheduleOptions()
 	s.tc = mockcluster.NewCluster(opt)
 	s.oc = schedule.NewOperatorController(nil, nil)
-	lb, err := schedule.CreateScheduler("balance-leader", s.oc)
+	lb, err := schedule.CreateScheduler("balance-leader", s.oc, core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 	s.lb = lb
 }
@@ -234,6 +235,34 @@ func (s *testBalanceLeaderSchedulerSuite) TestBalanceFilter(c *C) {
 	c.Assert(s.schedule(), HasLen, 0)
 }
 
+func (s *testBalanceLeaderSchedulerSuite) TestLeaderPolicy(c *C) {
+	// Stores:     1    2    3    4
+	// Zones:     z1   z2   z3   z4
+	// Leaders:    1    2    3   16
+	// Region1:    F    F    F    L
+	for i := uint64(1); i <= 4; i++ {
+		s.tc.AddLabelsStore(i, 0, map[string]string{"zone": fmt.Sprintf("z%d", i)})
+	}
+	s.tc.UpdateLeaderCount(1, 1)
+	s.tc.UpdateLeaderCount(2, 2)
+	s.tc.UpdateLeaderCount(3, 3)
+	s.tc.UpdateLeaderCount(4, 16)
+	s.tc.AddLeaderRegion(1, 4, 1, 2, 3)
+	testutil.CheckTransferLeader(c, s.schedule()[0], schedule.OpBalance, 4, 1)
+
+	// Leaders are not moved to the stores less preferred than store 4.
+	policy := &core.LeaderPolicy{
+		ID:          "test",
+		Preferences: []core.LeaderPreference{{Key: "zone", Value: "z2"}, {Key: "zone", Value: "z4"}},
+	}
+	c.Assert(policy.Adjust(), IsNil)
+	s.tc.LeaderPolicies.SetLeaderPolicy(policy)
+	testutil.CheckTransferLeader(c, s.schedule()[0], schedule.OpBalance, 4, 2)
+
+	s.tc.SetStoreBusy(2, true)
+	c.Assert(s.schedule(), HasLen, 0)
+}
+
 func (s *testBalanceLeaderSchedulerSuite) TestLeaderWeight(c *C) {
 	// Stores:	1	2	3	4
 	// Leaders:    10      10      10      10
@@ -327,7 +356,7 @@ func (s *testBalanceRegionSchedulerSuite) TestBalance(c *C) {
 	tc := mockcluster.NewCluster(opt)
 	oc := schedule.NewOperatorController(nil, nil)
 
-	sb, err := schedule.CreateScheduler("balance-region", oc)
+	sb, err := schedule.CreateScheduler("balance-region", oc, core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 
 	cache := sb.(*balanceRegionScheduler).taintStores
@@ -357,6 +386,62 @@ func (s *testBalanceRegionSchedulerSuite) TestBalance(c *C) {
 	c.Assert(sb.Schedule(tc), NotNil)
 }
 
+func (s *testBalanceRegionSchedulerSuite) TestExplain(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := schedule.NewOperatorController(nil, nil)
+
+	sb, err := schedule.CreateScheduler("balance-region", oc, core.NewKV(core.NewMemoryKV()))
+	c.Assert(err, IsNil)
+	explainer := sb.(schedule.ExplainableScheduler)
+	opt.SetMaxReplicas(1)
+
+	tc.AddRegionStore(1, 6)
+	tc.AddRegionStore(2, 8)
+	tc.AddRegionStore(3, 16)
+	tc.SetStoreOffline(1)
+	tc.AddLeaderRegion(1, 3)
+	c.Assert(sb.Schedule(tc), NotNil)
+
+	rounds := explainer.GetExplanations(0)
+	c.Assert(rounds, HasLen, 1)
+	round := rounds[0]
+	c.Assert(round.Result, Equals, "new_operator")
+	c.Assert(round.RegionID, Equals, uint64(1))
+	c.Assert(round.SourceStore, Equals, uint64(3))
+	c.Assert(round.TargetStore, Equals, uint64(2))
+	filtered := make(map[uint64]string)
+	for _, candidate := range round.Candidates {
+		if candidate.Role == schedule.SourceCandidate {
+			filtered[candidate.StoreID] = candidate.FilteredBy
+		}
+	}
+	c.Assert(filtered, DeepEquals, map[uint64]string{1: "", 2: "", 3: ""})
+	c.Assert(round.Comparisons, Not(HasLen), 0)
+	c.Assert(round.Comparisons[len(round.Comparisons)-1].ShouldBalance, IsTrue)
+
+	// No region can be scheduled with abnormal replica count.
+	opt.SetMaxReplicas(3)
+	c.Assert(sb.Schedule(tc), IsNil)
+	rounds = explainer.GetExplanations(1)
+	c.Assert(rounds, HasLen, 1)
+	c.Assert(rounds[0].Result, Equals, "no_potential_target")
+	c.Assert(rounds[0].Skipped["abnormal_replica"], Equals, balanceRegionRetryLimit)
+
+	// Store 3 is tainted now, so it is filtered as the source store.
+	c.Assert(sb.Schedule(tc), IsNil)
+	rounds = explainer.GetExplanations(0)
+	c.Assert(rounds, HasLen, 3)
+	var tainted bool
+	for _, candidate := range rounds[0].Candidates {
+		if candidate.StoreID == 3 && candidate.Role == schedule.SourceCandidate {
+			c.Assert(candidate.FilteredBy, Equals, "cache-filter")
+			tainted = true
+		}
+	}
+	c.Assert(tainted, IsTrue)
+}
+
 func (s *testBalanceRegionSchedulerSuite) TestReplicas3(c *C) {
 	opt := mockoption.NewScheduleOptions()
 	tc := mockcluster.NewCluster(opt)
@@ -364,7 +449,7 @@ func (s *testBalanceRegionSchedulerSuite) TestReplicas3(c *C) {
 
 	newTestReplication(opt, 3, "zone", "rack", "host")
 
-	sb, err := schedule.CreateScheduler("balance-region", oc)
+	sb, err := schedule.CreateScheduler("balance-region", oc, core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 
 	cache := sb.(*balanceRegionScheduler).taintStores
@@ -433,7 +518,7 @@ func (s *testBalanceRegionSchedulerSuite) TestReplicas5(c *C) {
 
 	newTestReplication(opt, 5, "zone", "rack", "host")
 
-	sb, err := schedule.CreateScheduler("balance-region", oc)
+	sb, err := schedule.CreateScheduler("balance-region", oc, core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 
 	tc.AddLabelsStore(1, 4, map[string]string{"zone": "z1", "rack": "r1", "host": "h1"})
@@ -469,7 +554,7 @@ func (s *testBalanceRegionSchedulerSuite) TestStoreWeight(c *C) {
 	tc := mockcluster.NewCluster(opt)
 	oc := schedule.NewOperatorController(nil, nil)
 
-	sb, err := schedule.CreateScheduler("balance-region", oc)
+	sb, err := schedule.CreateScheduler("balance-region", oc, core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 	opt.SetMaxReplicas(1)
 
@@ -567,6 +652,38 @@ func (s *testReplicaCheckerSuite) TestBasic(c *C) {
 	testutil.CheckTransferPeer(c, rc.Check(region), schedule.OpReplica, 3, 1)
 }
 
+func (s *testReplicaCheckerSuite) TestMaintenance(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	rc := checker.NewReplicaChecker(tc, namespace.DefaultClassifier)
+
+	tc.AddRegionStore(1, 1)
+	tc.AddRegionStore(2, 1)
+	tc.AddRegionStore(3, 1)
+	tc.AddRegionStore(4, 1)
+	tc.AddLeaderRegion(1, 1, 2)
+	region := tc.GetRegion(1)
+
+	// No new peers are placed on the store in maintenance.
+	tc.SetStoreMaintenance(3, time.Now().Add(time.Hour))
+	testutil.CheckAddPeer(c, rc.Check(region), schedule.OpReplica, 4)
+	tc.SetStoreMaintenance(4, time.Now().Add(time.Hour))
+	c.Assert(rc.Check(region), IsNil)
+
+	// The down peer is not replaced until the maintenance ends.
+	tc.SetStoreMaintenance(4, time.Time{})
+	region = tc.GetRegion(1).Clone(core.WithAddPeer(&metapb.Peer{Id: 100, StoreId: 3}))
+	tc.SetStoreDown(3)
+	tc.SetStoreMaintenance(3, time.Now().Add(time.Hour))
+	region = region.Clone(core.WithDownPeers([]*pdpb.PeerStats{{
+		Peer:        region.GetStorePeer(3),
+		DownSeconds: 24 * 60 * 60,
+	}}))
+	c.Assert(rc.Check(region), IsNil)
+	tc.SetStoreMaintenance(3, time.Now().Add(-time.Second))
+	testutil.CheckTransferPeer(c, rc.Check(region), schedule.OpReplica, 3, 4)
+}
+
 func (s *testReplicaCheckerSuite) TestLostStore(c *C) {
 	opt := mockoption.NewScheduleOptions()
 	tc := mockcluster.NewCluster(opt)
@@ -784,6 +901,101 @@ func (s *testReplicaCheckerSuite) TestStorageThreshold(c *C) {
 	testutil.CheckAddPeer(c, rc.Check(region), schedule.OpReplica, 2)
 }
 
+func (s *testReplicaCheckerSuite) TestSnapshotThroughput(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	rc := checker.NewReplicaChecker(tc, namespace.DefaultClassifier)
+
+	tc.AddRegionStore(1, 100)
+	tc.AddRegionStore(2, 100)
+	tc.AddRegionStore(3, 100)
+	tc.AddRegionStore(4, 50)
+	tc.AddLeaderRegion(1, 1, 2)
+	region := tc.GetRegion(1)
+	testutil.CheckAddPeer(c, rc.Check(region), schedule.OpReplica, 4)
+
+	// Store 4 is much slower than the others at applying snapshots.
+	for i := 0; i < core.MinSnapshotCount; i++ {
+		tc.ObserveSnapshot(1, 100<<20, time.Second)
+		tc.ObserveSnapshot(2, 100<<20, time.Second)
+		tc.ObserveSnapshot(3, 100<<20, time.Second)
+		tc.ObserveSnapshot(4, 100<<20, 10*time.Second)
+	}
+	c.Assert(tc.GetStore(4).IsSnapshotSlow(), IsTrue)
+	c.Assert(tc.GetStore(3).IsSnapshotSlow(), IsFalse)
+	f := schedule.NewSnapshotThroughputFilter()
+	c.Assert(f.FilterTarget(tc, tc.GetStore(4)), IsTrue)
+	c.Assert(f.FilterTarget(tc, tc.GetStore(3)), IsFalse)
+	// The slow store is avoided if there is another target.
+	testutil.CheckAddPeer(c, rc.Check(region), schedule.OpReplica, 3)
+	// The replica repair is not blocked by the slow store.
+	tc.SetStoreOffline(3)
+	testutil.CheckAddPeer(c, rc.Check(region), schedule.OpReplica, 4)
+	tc.SetStoreUp(3)
+
+	// Store 4 is not slow after it catches up.
+	for i := 0; i < 10; i++ {
+		tc.ObserveSnapshot(4, 100<<20, time.Second)
+	}
+	c.Assert(tc.GetStore(4).IsSnapshotSlow(), IsFalse)
+	c.Assert(f.FilterTarget(tc, tc.GetStore(4)), IsFalse)
+}
+
+func (s *testReplicaCheckerSuite) TestRejectPeer(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	opt.LabelProperties = map[string][]*metapb.StoreLabel{
+		schedule.RejectPeer: {{Key: "disk", Value: "hdd"}},
+	}
+	tc := mockcluster.NewCluster(opt)
+	rc := checker.NewReplicaChecker(tc, namespace.DefaultClassifier)
+
+	tc.AddLabelsStore(1, 1, map[string]string{"disk": "ssd"})
+	tc.AddLabelsStore(2, 1, map[string]string{"disk": "hdd"})
+	tc.AddLabelsStore(3, 1, map[string]string{"disk": "ssd"})
+	tc.AddLabelsStore(4, 1, map[string]string{"disk": "hdd"})
+	tc.AddLabelsStore(5, 1, map[string]string{"disk": "ssd"})
+	tc.AddLeaderRegion(1, 1, 2, 3)
+	region := tc.GetRegion(1)
+
+	// Store 2 and 4 reject peers, so the peer on store 2 is moved to store 5.
+	testutil.CheckTransferPeer(c, rc.Check(region), schedule.OpReplica, 2, 5)
+
+	// Read-only stores keep their peers but receive no new peers.
+	opt.LabelProperties[schedule.ReadOnly] = []*metapb.StoreLabel{{Key: "disk", Value: "ssd"}}
+	delete(opt.LabelProperties, schedule.RejectPeer)
+	c.Assert(rc.Check(region), IsNil)
+}
+
+func (s *testReplicaCheckerSuite) TestRangeReplication(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	rc := checker.NewReplicaChecker(tc, namespace.DefaultClassifier)
+
+	for i := uint64(1); i <= 5; i++ {
+		tc.AddRegionStore(i, 10)
+	}
+	tc.AddLeaderRegionWithRange(1, "a", "b", 1, 2, 3)
+	tc.AddLeaderRegionWithRange(2, "b", "c", 1, 2, 3)
+	c.Assert(rc.Check(tc.GetRegion(1)), IsNil)
+
+	// The regions in [a, b) need 5 replicas.
+	r := &core.RangeReplication{ID: "test", StartKeyHex: "61", EndKeyHex: "62", MaxReplicas: 5}
+	c.Assert(r.Adjust(), IsNil)
+	tc.RangeReplications.SetRangeReplication(r)
+	op := rc.Check(tc.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "make-up-replica")
+	c.Assert(rc.Check(tc.GetRegion(2)), IsNil)
+
+	// Remove the extra replicas after the override is removed.
+	tc.AddLeaderRegionWithRange(1, "a", "b", 1, 2, 3, 4, 5)
+	c.Assert(rc.Check(tc.GetRegion(1)), IsNil)
+	tc.RangeReplications.DeleteRangeReplication("test")
+	op = rc.Check(tc.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "remove-extra-replica")
+}
+
 func (s *testReplicaCheckerSuite) TestOpts(c *C) {
 	opt := mockoption.NewScheduleOptions()
 	tc := mockcluster.NewCluster(opt)
@@ -824,7 +1036,7 @@ func (s *testRandomMergeSchedulerSuite) TestMerge(c *C) {
 	hb := mockhbstream.NewHeartbeatStreams(tc.ID)
 	oc := schedule.NewOperatorController(tc, hb)
 
-	mb, err := schedule.CreateScheduler("random-merge", oc)
+	mb, err := schedule.CreateScheduler("random-merge", oc, core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 
 	tc.AddRegionStore(1, 4)
@@ -851,7 +1063,7 @@ func (s *testBalanceHotWriteRegionSchedulerSuite) TestBalance(c *C) {
 	opt := mockoption.NewScheduleOptions()
 	newTestReplication(opt, 3, "zone", "host")
 	tc := mockcluster.NewCluster(opt)
-	hb, err := schedule.CreateScheduler("hot-write-region", schedule.NewOperatorController(nil, nil))
+	hb, err := schedule.CreateScheduler("hot-write-region", schedule.NewOperatorController(nil, nil), core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 
 	// Add stores 1, 2, 3, 4, 5, 6  with region counts 3, 2, 2, 2, 0, 0.
@@ -956,7 +1168,7 @@ type testBalanceHotReadRegionSchedulerSuite struct{}
 func (s *testBalanceHotReadRegionSchedulerSuite) TestBalance(c *C) {
 	opt := mockoption.NewScheduleOptions()
 	tc := mockcluster.NewCluster(opt)
-	hb, err := schedule.CreateScheduler("hot-read-region", schedule.NewOperatorController(nil, nil))
+	hb, err := schedule.CreateScheduler("hot-read-region", schedule.NewOperatorController(nil, nil), core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 
 	// Add stores 1, 2, 3, 4, 5 with region counts 3, 2, 2, 2, 0.
@@ -1076,7 +1288,7 @@ func (s *testScatterRangeLeaderSuite) TestBalance(c *C) {
 		tc.UpdateStoreStatus(uint64(i))
 	}
 	oc := schedule.NewOperatorController(nil, nil)
-	hb, err := schedule.CreateScheduler("scatter-range", oc, "s_00", "s_50", "t")
+	hb, err := schedule.CreateScheduler("scatter-range", oc, core.NewKV(core.NewMemoryKV()), "s_00", "s_50", "t")
 	c.Assert(err, IsNil)
 	limit := 0
 	for {
@@ -1163,3 +1375,23 @@ func (s *testScatterRangeLeaderSuite) TestBalanceWhenRegionNotHeartbeat(c *C) {
 		schedule.ApplyOperator(tc, ops[0])
 	}
 }
+
+func (s *testScatterRangeLeaderSuite) TestConfig(c *C) {
+	storage := core.NewKV(core.NewMemoryKV())
+	oc := schedule.NewOperatorController(nil, nil)
+	// The keys are not valid UTF-8 strings.
+	sche, err := schedule.CreateScheduler("scatter-range", oc, storage, "%80%ff", "%fe", "t")
+	c.Assert(err, IsNil)
+	sr := sche.(*scatterRangeScheduler)
+	c.Assert(sr.GetConfig(), DeepEquals, scatterRangeSchedulerConfig{RangeName: "t", StartKey: "80ff", EndKey: "fe"})
+
+	c.Assert(sr.SetConfig([]byte(`{"start_key":"zz"}`)), NotNil)
+	c.Assert(sr.SetConfig([]byte(`{"range_name":"t","start_key":"81"}`)), IsNil)
+
+	// The persisted config overrides the arguments and keeps the raw keys.
+	sche, err = schedule.CreateScheduler("scatter-range", oc, storage, "a", "b", "t")
+	c.Assert(err, IsNil)
+	startKey, endKey := sche.(*scatterRangeScheduler).getRange()
+	c.Assert(startKey, DeepEquals, []byte{0x81})
+	c.Assert(endKey, DeepEquals, []byte{0xfe})
+}
@@ -14,51 +14,45 @@
 package schedulers
 
 import (
-	"fmt"
-	"strconv"
-
 	"github.com/pingcap/pd/server/core"
 	"github.com/pingcap/pd/server/schedule"
-	"github.com/pkg/errors"
 )
 
+const evictLeaderName = "evict-leader-scheduler"
+
 func init() {
-	schedule.RegisterScheduler("evict-leader", func(opController *schedule.OperatorController, args []string) (schedule.Scheduler, error) {
-		if len(args) != 1 {
-			return nil, errors.New("evict-leader needs 1 argument")
-		}
-		id, err := strconv.ParseUint(args[0], 10, 64)
+	schedule.RegisterScheduler("evict-leader", func(opController *schedule.OperatorController, storage *core.KV, args []string) (schedule.Scheduler, error) {
+		conf, err := newStoreRangesConfig(evictLeaderName, storage, args)
 		if err != nil {
-			return nil, errors.WithStack(err)
+			return nil, err
 		}
-		return newEvictLeaderScheduler(opController, id), nil
+		return newEvictLeaderScheduler(opController, conf), nil
 	})
 }
 
 type evictLeaderScheduler struct {
 	*baseScheduler
-	name     string
-	storeID  uint64
+	*storeRangesConfig
 	selector *schedule.RandomSelector
 }
 
 // newEvictLeaderScheduler creates an admin scheduler that transfers all leaders
-// out of a store.
-func newEvictLeaderScheduler(opController *schedule.OperatorController, storeID uint64) schedule.Scheduler {
+// out of a set of stores. If key ranges are specified for a store, only the
+// leaders of the regions in the ranges are transferred.
+func newEvictLeaderScheduler(opController *schedule.OperatorController, conf *storeRangesConfig) schedule.Scheduler {
 	filters := []schedule.Filter{
 		schedule.StoreStateFilter{TransferLeader: true},
 	}
 	base := newBaseScheduler(opController)
 	return &evictLeaderScheduler{
-		baseScheduler: base,
-		name:          fmt.Sprintf("evict-leader-scheduler-%d", storeID),
-		storeID:       storeID,
-		selector:      schedule.NewRandomSelector(filters),
+		baseScheduler:     base,
+		storeRangesConfig: conf,
+		selector:          schedule.NewRandomSelector(filters),
 	}
 }
 
 func (s *evictLeaderScheduler) GetName() string {
-	return s.name
+	return evictLeaderName
 }
 
 func (s *evictLeaderScheduler) GetType() string {
@@ -66,11 +60,11 @@ func (s *evictLeaderScheduler) GetType() string {
 }
 
 func (s *evictLeaderScheduler) Prepare(cluster schedule.Cluster) error {
-	return cluster.BlockStore(s.storeID)
+	return s.prepare(cluster)
 }
 
 func (s *evictLeaderScheduler) Cleanup(cluster schedule.Cluster) {
-	cluster.UnblockStore(s.storeID)
+	s.cleanup(cluster)
 }
 
 func (s *evictLeaderScheduler) IsScheduleAllowed(cluster schedule.Cluster) bool {
@@ -79,7 +73,24 @@ func (s *evictLeaderScheduler) IsScheduleAllowed(cluster schedule.Cluster) bool
 
 func (s *evictLeaderScheduler) Schedule(cluster schedule.Cluster) []*schedule.Operator {
 	schedulerCounter.WithLabelValues(s.GetName(), "schedule").Inc()
-	region := cluster.RandLeaderRegion(s.storeID, core.HealthRegion())
+	var ops []*schedule.Operator
+	for _, storeID := range s.getStoreIDs() {
+		if op := s.scheduleStore(cluster, storeID); op != nil {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+func (s *evictLeaderScheduler) scheduleStore(cluster schedule.Cluster, storeID uint64) *schedule.Operator {
+	var region *core.RegionInfo
+	if ranges := s.getRanges(storeID); len(ranges) > 0 {
+		region = selectRegionInRanges(cluster, ranges, core.HealthRegion(), func(region *core.RegionInfo) bool {
+			return region.GetLeader().GetStoreId() == storeID
+		})
+	} else {
+		region = cluster.RandLeaderRegion(storeID, core.HealthRegion())
+	}
 	if region == nil {
 		schedulerCounter.WithLabelValues(s.GetName(), "no_leader").Inc()
 		return nil
@@ -92,5 +103,5 @@ func (s *evictLeaderScheduler) Schedule(cluster schedule.Cluster) []*schedule.Op
 	schedulerCounter.WithLabelValues(s.GetName(), "new_operator").Inc()
 	op := schedule.CreateTransferLeaderOperator("evict-leader", region, region.GetLeader().GetStoreId(), target.GetID(), schedule.OpLeader)
 	op.SetPriorityLevel(core.HighPriority)
-	return []*schedule.Operator{op}
+	return op
 }
@@ -14,58 +14,53 @@
 package schedulers
 
 import (
-	"fmt"
-	"strconv"
-
 	"github.com/pingcap/pd/server/core"
 	"github.com/pingcap/pd/server/schedule"
-	"github.com/pkg/errors"
 )
 
+const grantLeaderName = "grant-leader-scheduler"
+
 func init() {
-	schedule.RegisterScheduler("grant-leader", func(opController *schedule.OperatorController, args []string) (schedule.Scheduler, error) {
-		if len(args) != 1 {
-			return nil, errors.New("grant-leader needs 1 argument")
-		}
-		id, err := strconv.ParseUint(args[0], 10, 64)
+	schedule.RegisterScheduler("grant-leader", func(opController *schedule.OperatorController, storage *core.KV, args []string) (schedule.Scheduler, error) {
+		conf, err := newStoreRangesConfig(grantLeaderName, storage, args)
 		if err != nil {
-			return nil, errors.WithStack(err)
+			return nil, err
 		}
-		return newGrantLeaderScheduler(opController, id), nil
+		return newGrantLeaderScheduler(opController, conf), nil
 	})
 }
 
-// grantLeaderScheduler transfers all leaders to peers in the store.
+// grantLeaderScheduler transfers all leaders to peers in a set of stores.
 type grantLeaderScheduler struct {
 	*baseScheduler
-	name    string
-	storeID uint64
+	*storeRangesConfig
 }
 
 // newGrantLeaderScheduler creates an admin scheduler that transfers all leaders
-// to a store.
-func newGrantLeaderScheduler(opController *schedule.OperatorController, storeID uint64) schedule.Scheduler {
+// to a set of stores. If key ranges are specified for a store, only the
+// leaders of the regions in the ranges are transferred.
+func newGrantLeaderScheduler(opController *schedule.OperatorController, conf *storeRangesConfig) schedule.Scheduler {
 	base := newBaseScheduler(opController)
 	return &grantLeaderScheduler{
-		baseScheduler: base,
-		name:          fmt.Sprintf("grant-leader-scheduler-%d", storeID),
-		storeID:       storeID,
+		baseScheduler:     base,
+		storeRangesConfig: conf,
 	}
 }
 
 func (s *grantLeaderScheduler) GetName() string {
-	return s.name
+	return grantLeaderName
 }
 
 func (s *grantLeaderScheduler) GetType() string {
 	return "grant-leader"
 }
+
 func (s *grantLeaderScheduler) Prepare(cluster schedule.Cluster) error {
-	return cluster.BlockStore(s.storeID)
+	return s.prepare(cluster)
 }
 
 func (s *grantLeaderScheduler) Cleanup(cluster schedule.Cluster) {
-	cluster.UnblockStore(s.storeID)
+	s.cleanup(cluster)
 }
 
 func (s *grantLeaderScheduler) IsScheduleAllowed(cluster schedule.Cluster) bool {
@@ -74,13 +69,30 @@ func (s *grantLeaderScheduler) IsScheduleAllowed(cluster schedule.Cluster) bool
 
 func (s *grantLeaderScheduler) Schedule(cluster schedule.Cluster) []*schedule.Operator {
 	schedulerCounter.WithLabelValues(s.GetName(), "schedule").Inc()
-	region := cluster.RandFollowerRegion(s.storeID, core.HealthRegion())
+	var ops []*schedule.Operator
+	for _, storeID := range s.getStoreIDs() {
+		if op := s.scheduleStore(cluster, storeID); op != nil {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+func (s *grantLeaderScheduler) scheduleStore(cluster schedule.Cluster, storeID uint64) *schedule.Operator {
+	var region *core.RegionInfo
+	if ranges := s.getRanges(storeID); len(ranges) > 0 {
+		region = selectRegionInRanges(cluster, ranges, core.HealthRegion(), func(region *core.RegionInfo) bool {
+			return region.GetStoreVoter(storeID) != nil && region.GetLeader().GetStoreId() != storeID
+		})
+	} else {
+		region = cluster.RandFollowerRegion(storeID, core.HealthRegion())
+	}
 	if region == nil {
 		schedulerCounter.WithLabelValues(s.GetName(), "no_follower").Inc()
 		return nil
 	}
 	schedulerCounter.WithLabelValues(s.GetName(), "new_operator").Inc()
-	op := schedule.CreateTransferLeaderOperator("grant-leader", region, region.GetLeader().GetStoreId(), s.storeID, schedule.OpLeader)
+	op := schedule.CreateTransferLeaderOperator("grant-leader", region, region.GetLeader().GetStoreId(), storeID, schedule.OpLeader)
 	op.SetPriorityLevel(core.HighPriority)
-	return []*schedule.Operator{op}
+	return op
 }
@@ -28,14 +28,14 @@ import (
 )
 
 func init() {
-	schedule.RegisterScheduler("hot-region", func(opController *schedule.OperatorController, args []string) (schedule.Scheduler, error) {
+	schedule.RegisterScheduler("hot-region", func(opController *schedule.OperatorController, storage *core.KV, args []string) (schedule.Scheduler, error) {
 		return newBalanceHotRegionsScheduler(opController), nil
 	})
 	// FIXME: remove this two schedule after the balance test move in schedulers package
-	schedule.RegisterScheduler("hot-write-region", func(opController *schedule.OperatorController, args []string) (schedule.Scheduler, error) {
+	schedule.RegisterScheduler("hot-write-region", func(opController *schedule.OperatorController, storage *core.KV, args []string) (schedule.Scheduler, error) {
 		return newBalanceHotWriteRegionsScheduler(opController), nil
 	})
-	schedule.RegisterScheduler("hot-read-region", func(opController *schedule.OperatorController, args []string) (schedule.Scheduler, error) {
+	schedule.RegisterScheduler("hot-read-region", func(opController *schedule.OperatorController, storage *core.KV, args []string) (schedule.Scheduler, error) {
 		return newBalanceHotReadRegionsScheduler(opController), nil
 	})
 }
@@ -78,6 +78,10 @@ type balanceHotRegionsScheduler struct {
 	// store id -> hot regions statistics as the role of leader
 	stats *storeStatistics
 	r     *rand.Rand
+
+	explainer *schedule.Explainer
+	// round is the explanation of the running scheduling round.
+	round *schedule.ExplainRound
 }
 
 func newBalanceHotRegionsScheduler(opController *schedule.OperatorController) *balanceHotRegionsScheduler {
@@ -89,6 +93,7 @@ func newBalanceHotRegionsScheduler(opController *schedule.OperatorController) *b
 		stats:         newStoreStaticstics(),
 		types:         []BalanceType{hotWriteRegionBalance, hotReadRegionBalance},
 		r:             rand.New(rand.NewSource(time.Now().UnixNano())),
+		explainer:     schedule.NewExplainer(schedule.DefaultExplainRounds),
 	}
 }
 
@@ -101,6 +106,7 @@ func newBalanceHotReadRegionsScheduler(opController *schedule.OperatorController
 		stats:         newStoreStaticstics(),
 		types:         []BalanceType{hotReadRegionBalance},
 		r:             rand.New(rand.NewSource(time.Now().UnixNano())),
+		explainer:     schedule.NewExplainer(schedule.DefaultExplainRounds),
 	}
 }
 
@@ -113,6 +119,7 @@ func newBalanceHotWriteRegionsScheduler(opController *schedule.OperatorControlle
 		stats:         newStoreStaticstics(),
 		types:         []BalanceType{hotWriteRegionBalance},
 		r:             rand.New(rand.NewSource(time.Now().UnixNano())),
+		explainer:     schedule.NewExplainer(schedule.DefaultExplainRounds),
 	}
 }
 
@@ -124,6 +131,10 @@ func (h *balanceHotRegionsScheduler) GetType() string {
 	return "hot-region"
 }
 
+func (h *balanceHotRegionsScheduler) GetExplanations(limit int) []*schedule.ExplainRound {
+	return h.explainer.GetRounds(limit)
+}
+
 func (h *balanceHotRegionsScheduler) IsScheduleAllowed(cluster schedule.Cluster) bool {
 	return h.allowBalanceLeader(cluster) || h.allowBalanceRegion(cluster)
 }
@@ -145,6 +156,11 @@ func (h *balanceHotRegionsScheduler) Schedule(cluster schedule.Cluster) []*sched
 func (h *balanceHotRegionsScheduler) dispatch(typ BalanceType, cluster schedule.Cluster) []*schedule.Operator {
 	h.Lock()
 	defer h.Unlock()
+	h.round = h.explainer.NewRound()
+	defer func() {
+		h.explainer.Record(h.round)
+		h.round = nil
+	}()
 	switch typ {
 	case hotReadRegionBalance:
 		h.stats.readStatAsLeader = calcScore(cluster.RegionReadStats(), cluster, core.LeaderKind)
@@ -162,6 +178,7 @@ func (h *balanceHotRegionsScheduler) balanceHotReadRegions(cluster schedule.Clus
 	srcRegion, newLeader := h.balanceByLeader(cluster, h.stats.readStatAsLeader)
 	if srcRegion != nil {
 		schedulerCounter.WithLabelValues(h.GetName(), "move_leader").Inc()
+		h.round.SetResult("move_leader")
 		op := schedule.CreateTransferLeaderOperator("transfer-hot-read-leader", srcRegion, srcRegion.GetLeader().GetStoreId(), newLeader.GetStoreId(), schedule.OpHotRegion)
 		op.SetPriorityLevel(core.HighPriority)
 		return []*schedule.Operator{op}
@@ -173,13 +190,16 @@ func (h *balanceHotRegionsScheduler) balanceHotReadRegions(cluster schedule.Clus
 		op, err := schedule.CreateMovePeerOperator("move-hot-read-region", cluster, srcRegion, schedule.OpHotRegion, srcPeer.GetStoreId(), destPeer.GetStoreId(), destPeer.GetId())
 		if err != nil {
 			schedulerCounter.WithLabelValues(h.GetName(), "create_operator_fail").Inc()
+			h.round.SetResult("create_operator_fail")
 			return nil
 		}
 		op.SetPriorityLevel(core.HighPriority)
 		schedulerCounter.WithLabelValues(h.GetName(), "move_peer").Inc()
+		h.round.SetResult("move_peer")
 		return []*schedule.Operator{op}
 	}
 	schedulerCounter.WithLabelValues(h.GetName(), "skip").Inc()
+	h.round.SetResult("skip")
 	return nil
 }
 
@@ -196,10 +216,12 @@ func (h *balanceHotRegionsScheduler) balanceHotWriteRegions(cluster schedule.Clu
 				op, err := schedule.CreateMovePeerOperator("move-hot-write-region", cluster, srcRegion, schedule.OpHotRegion, srcPeer.GetStoreId(), destPeer.GetStoreId(), destPeer.GetId())
 				if err != nil {
 					schedulerCounter.WithLabelValues(h.GetName(), "create_operator_fail").Inc()
+					h.round.SetResult("create_operator_fail")
 					return nil
 				}
 				op.SetPriorityLevel(core.HighPriority)
 				schedulerCounter.WithLabelValues(h.GetName(), "move_peer").Inc()
+				h.round.SetResult("move_peer")
 				return []*schedule.Operator{op}
 			}
 		case 1:
@@ -207,6 +229,7 @@ func (h *balanceHotRegionsScheduler) balanceHotWriteRegions(cluster schedule.Clu
 			srcRegion, newLeader := h.balanceByLeader(cluster, h.stats.writeStatAsLeader)
 			if srcRegion != nil {
 				schedulerCounter.WithLabelValues(h.GetName(), "move_leader").Inc()
+				h.round.SetResult("move_leader")
 				op := schedule.CreateTransferLeaderOperator("transfer-hot-write-leader", srcRegion, srcRegion.GetLeader().GetStoreId(), newLeader.GetStoreId(), schedule.OpHotRegion)
 				op.SetPriorityLevel(core.HighPriority)
 				return []*schedule.Operator{op}
@@ -215,6 +238,7 @@ func (h *balanceHotRegionsScheduler) balanceHotWriteRegions(cluster schedule.Clu
 	}
 
 	schedulerCounter.WithLabelValues(h.GetName(), "skip").Inc()
+	h.round.SetResult("skip")
 	return nil
 }
 
@@ -272,11 +296,13 @@ func calcScore(items []*statistics.RegionStat, cluster schedule.Cluster, kind co
 // balanceByPeer balances the peer distribution of hot regions.
 func (h *balanceHotRegionsScheduler) balanceByPeer(cluster schedule.Cluster, storesStat statistics.StoreHotRegionsStat) (*core.RegionInfo, *metapb.Peer, *metapb.Peer) {
 	if !h.allowBalanceRegion(cluster) {
+		h.round.Skip("peer_limit")
 		return nil, nil, nil
 	}
 
 	srcStoreID := h.selectSrcStore(storesStat)
 	if srcStoreID == 0 {
+		h.round.Skip("no_source_store")
 		return nil, nil, nil
 	}
 
@@ -289,6 +315,7 @@ func (h *balanceHotRegionsScheduler) balanceByPeer(cluster schedule.Cluster, sto
 		rs := storesStat[srcStoreID].RegionsStat[i]
 		srcRegion := cluster.GetRegion(rs.RegionID)
 		if srcRegion == nil || len(srcRegion.GetDownPeers()) != 0 || len(srcRegion.GetPendingPeers()) != 0 {
+			h.round.Skip("unhealthy_region")
 			continue
 		}
 
@@ -296,17 +323,25 @@ func (h *balanceHotRegionsScheduler) balanceByPeer(cluster schedule.Cluster, sto
 		filters := []schedule.Filter{
 			schedule.StoreStateFilter{MoveRegion: true},
 			schedule.NewExcludedFilter(srcRegion.GetStoreIds(), srcRegion.GetStoreIds()),
-			schedule.NewDistinctScoreFilter(cluster.GetLocationLabels(), cluster.GetRegionStores(srcRegion), srcStore),
+			schedule.NewDistinctScoreFilter(schedule.GetRegionLocationLabels(cluster, srcRegion), cluster.GetRegionStores(srcRegion), srcStore),
+		}
+		if srcRegion.GetLeader().GetStoreId() == srcStoreID {
+			filters = append(filters, schedule.NewLeaderPolicyFilter(cluster.GetLeaderPolicy(srcRegion), srcStore), schedule.NewPreferLeaderFilter(srcStore))
 		}
 		candidateStoreIDs := make([]uint64, 0, len(stores))
 		for _, store := range stores {
-			if schedule.FilterTarget(cluster, store, filters) {
+			filter := schedule.TargetFilteredBy(cluster, store, filters)
+			h.round.AddCandidate(schedule.TargetCandidate, store.GetID(), hotFlowBytes(storesStat, store.GetID()), filter)
+			if filter != nil {
 				continue
 			}
 			candidateStoreIDs = append(candidateStoreIDs, store.GetID())
 		}
 
 		destStoreID = h.selectDestStore(candidateStoreIDs, rs.FlowBytes, srcStoreID, storesStat)
+		if destStoreID == 0 {
+			h.round.Skip("no_target_store")
+		}
 		if destStoreID != 0 {
 			h.peerLimit = h.adjustBalanceLimit(srcStoreID, storesStat)
 
@@ -323,6 +358,7 @@ func (h *balanceHotRegionsScheduler) balanceByPeer(cluster schedule.Cluster, sto
 				return nil, nil, nil
 			}
 
+			h.round.SetDecision(srcRegion.GetID(), srcStoreID, destStoreID)
 			return srcRegion, srcPeer, destPeer
 		}
 	}
@@ -333,11 +369,13 @@ func (h *balanceHotRegionsScheduler) balanceByPeer(cluster schedule.Cluster, sto
 // balanceByLeader balances the leader distribution of hot regions.
 func (h *balanceHotRegionsScheduler) balanceByLeader(cluster schedule.Cluster, storesStat statistics.StoreHotRegionsStat) (*core.RegionInfo, *metapb.Peer) {
 	if !h.allowBalanceLeader(cluster) {
+		h.round.Skip("leader_limit")
 		return nil, nil
 	}
 
 	srcStoreID := h.selectSrcStore(storesStat)
 	if srcStoreID == 0 {
+		h.round.Skip("no_source_store")
 		return nil, nil
 	}
 
@@ -346,27 +384,37 @@ func (h *balanceHotRegionsScheduler) balanceByLeader(cluster schedule.Cluster, s
 		rs := storesStat[srcStoreID].RegionsStat[i]
 		srcRegion := cluster.GetRegion(rs.RegionID)
 		if srcRegion == nil || len(srcRegion.GetDownPeers()) != 0 || len(srcRegion.GetPendingPeers()) != 0 {
+			h.round.Skip("unhealthy_region")
 			continue
 		}
 
-		filters := []schedule.Filter{schedule.StoreStateFilter{TransferLeader: true}}
+		filters := []schedule.Filter{
+			schedule.StoreStateFilter{TransferLeader: true},
+			schedule.NewLeaderPolicyFilter(cluster.GetLeaderPolicy(srcRegion), cluster.GetStore(srcStoreID)),
+			schedule.NewPreferLeaderFilter(cluster.GetStore(srcStoreID)),
+		}
 		candidateStoreIDs := make([]uint64, 0, len(srcRegion.GetPeers())-1)
 		for _, store := range cluster.GetFollowerStores(srcRegion) {
-			if !schedule.FilterTarget(cluster, store, filters) {
+			filter := schedule.TargetFilteredBy(cluster, store, filters)
+			h.round.AddCandidate(schedule.TargetCandidate, store.GetID(), hotFlowBytes(storesStat, store.GetID()), filter)
+			if filter == nil {
 				candidateStoreIDs = append(candidateStoreIDs, store.GetID())
 			}
 		}
 		if len(candidateStoreIDs) == 0 {
+			h.round.Skip("no_target_store")
 			continue
 		}
 		destStoreID := h.selectDestStore(candidateStoreIDs, rs.FlowBytes, srcStoreID, storesStat)
 		if destStoreID == 0 {
+			h.round.Skip("no_target_store")
 			continue
 		}
 
 		destPeer := srcRegion.GetStoreVoter(destStoreID)
 		if destPeer != nil {
 			h.leaderLimit = h.adjustBalanceLimit(srcStoreID, storesStat)
+			h.round.SetDecision(srcRegion.GetID(), srcStoreID, destStoreID)
 
 			return srcRegion, destPeer
 		}
@@ -385,6 +433,11 @@ func (h *balanceHotRegionsScheduler) selectSrcStore(stats statistics.StoreHotReg
 
 	for storeID, statistics := range stats {
 		count, flowBytes := statistics.RegionsStat.Len(), statistics.TotalFlowBytes
+		if count < 2 {
+			h.round.AddRejectedCandidate(schedule.SourceCandidate, storeID, float64(flowBytes), "too-few-hot-regions")
+		} else {
+			h.round.AddCandidate(schedule.SourceCandidate, storeID, float64(flowBytes), nil)
+		}
 		if count >= 2 && (count > maxHotStoreRegionCount || (count == maxHotStoreRegionCount && flowBytes > maxFlowBytes)) {
 			maxHotStoreRegionCount = count
 			maxFlowBytes = flowBytes
@@ -426,6 +479,14 @@ func (h *balanceHotRegionsScheduler) selectDestStore(candidateStoreIDs []uint64,
 	return
 }
 
+// hotFlowBytes returns the total flow bytes of the hot regions on the store.
+func hotFlowBytes(storesStat statistics.StoreHotRegionsStat, storeID uint64) float64 {
+	if s, ok := storesStat[storeID]; ok {
+		return float64(s.TotalFlowBytes)
+	}
+	return 0
+}
+
 func (h *balanceHotRegionsScheduler) adjustBalanceLimit(storeID uint64, storesStat statistics.StoreHotRegionsStat) uint64 {
 	srcStoreStatistics := storesStat[storeID]
 
@@ -21,7 +21,7 @@ import (
 )
 
 func init() {
-	schedule.RegisterScheduler("label", func(opController *schedule.OperatorController, args []string) (schedule.Scheduler, error) {
+	schedule.RegisterScheduler("label", func(opController *schedule.OperatorController, storage *core.KV, args []string) (schedule.Scheduler, error) {
 		return newLabelScheduler(opController), nil
 	})
 }
@@ -33,7 +33,8 @@ type labelScheduler struct {
 
 // LabelScheduler is mainly based on the store's label information for scheduling.
 // Now only used for reject leader schedule, that will move the leader out of
-// the store with the specific label.
+// the store with the specific label marked as rejectLeader or readOnly, and
+// the store in maintenance.
 func newLabelScheduler(opController *schedule.OperatorController) schedule.Scheduler {
 	filters := []schedule.Filter{
 		schedule.StoreStateFilter{TransferLeader: true},
@@ -61,7 +62,9 @@ func (s *labelScheduler) Schedule(cluster schedule.Cluster) []*schedule.Operator
 	stores := cluster.GetStores()
 	rejectLeaderStores := make(map[uint64]struct{})
 	for _, s := range stores {
-		if cluster.CheckLabelProperty(schedule.RejectLeader, s.GetLabels()) {
+		if cluster.CheckLabelProperty(schedule.RejectLeader, s.GetLabels()) ||
+			cluster.CheckLabelProperty(schedule.ReadOnly, s.GetLabels()) ||
+			s.IsInMaintenance() {
 			rejectLeaderStores[s.GetID()] = struct{}{}
 		}
 	}
@@ -21,7 +21,7 @@ import (
 )
 
 func init() {
-	schedule.RegisterScheduler("random-merge", func(opController *schedule.OperatorController, args []string) (schedule.Scheduler, error) {
+	schedule.RegisterScheduler("random-merge", func(opController *schedule.OperatorController, storage *core.KV, args []string) (schedule.Scheduler, error) {
 		return newRandomMergeScheduler(opController), nil
 	})
 }
@@ -14,15 +14,19 @@
 package schedulers
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"sync"
 
+	"github.com/pingcap/pd/server/core"
 	"github.com/pingcap/pd/server/schedule"
 	"github.com/pkg/errors"
 )
 
 func init() {
-	schedule.RegisterScheduler("scatter-range", func(opController *schedule.OperatorController, args []string) (schedule.Scheduler, error) {
+	schedule.RegisterScheduler("scatter-range", func(opController *schedule.OperatorController, storage *core.KV, args []string) (schedule.Scheduler, error) {
 		if len(args) != 3 {
 			return nil, errors.New("should specify the range and the name")
 		}
@@ -35,29 +39,63 @@ func init() {
 			return nil, err
 		}
 		name := args[2]
-		return newScatterRangeScheduler(opController, []string{startKey, endKey, name}), nil
+		s := newScatterRangeScheduler(opController, []string{startKey, endKey, name})
+		s.storage = storage
+		if _, err := storage.LoadSchedulerConfig(s.GetName(), &s.conf); err != nil {
+			return nil, err
+		}
+		if _, _, err := s.conf.getRange(); err != nil {
+			return nil, err
+		}
+		return s, nil
 	})
 }
 
+// scatterRangeSchedulerConfig is the config of the scatter range scheduler.
+// The keys are hex-encoded, as the raw keys are not valid UTF-8 strings and get
+// corrupted by the JSON encoding.
+type scatterRangeSchedulerConfig struct {
+	RangeName string `json:"range_name"`
+	StartKey  string `json:"start_key"`
+	EndKey    string `json:"end_key"`
+}
+
+func (conf scatterRangeSchedulerConfig) getRange() ([]byte, []byte, error) {
+	startKey, err := hex.DecodeString(conf.StartKey)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	endKey, err := hex.DecodeString(conf.EndKey)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return startKey, endKey, nil
+}
+
 type scatterRangeScheduler struct {
 	*baseScheduler
 	rangeName     string
-	startKey      []byte
-	endKey        []byte
+	storage       *core.KV
 	balanceLeader schedule.Scheduler
 	balanceRegion schedule.Scheduler
+
+	mu   sync.RWMutex
+	conf scatterRangeSchedulerConfig
 }
 
 // newScatterRangeScheduler creates a scheduler that balances the distribution of leaders and regions that in the specified key range.
-func newScatterRangeScheduler(opController *schedule.OperatorController, args []string) schedule.Scheduler {
+func newScatterRangeScheduler(opController *schedule.OperatorController, args []string) *scatterRangeScheduler {
 	base := newBaseScheduler(opController)
 	return &scatterRangeScheduler{
 		baseScheduler: base,
-		startKey:      []byte(args[0]),
-		endKey:        []byte(args[1]),
 		rangeName:     args[2],
 		balanceLeader: newBalanceLeaderScheduler(opController),
 		balanceRegion: newBalanceRegionScheduler(opController),
+		conf: scatterRangeSchedulerConfig{
+			RangeName: args[2],
+			StartKey:  hex.EncodeToString([]byte(args[0])),
+			EndKey:    hex.EncodeToString([]byte(args[1])),
+		},
 	}
 }
 
@@ -69,6 +107,40 @@ func (l *scatterRangeScheduler) GetType() string {
 	return "scatter-range"
 }
 
+func (l *scatterRangeScheduler) GetConfig() interface{} {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.conf
+}
+
+func (l *scatterRangeScheduler) SetConfig(data []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	conf := l.conf
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return errors.WithStack(err)
+	}
+	if conf.RangeName != l.rangeName {
+		return errors.New("the range name cannot be changed")
+	}
+	if _, _, err := conf.getRange(); err != nil {
+		return err
+	}
+	if err := l.storage.SaveSchedulerConfig(l.GetName(), conf); err != nil {
+		return err
+	}
+	l.conf = conf
+	return nil
+}
+
+func (l *scatterRangeScheduler) getRange() ([]byte, []byte) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	// The keys are validated when the config is set or loaded.
+	startKey, endKey, _ := l.conf.getRange()
+	return startKey, endKey
+}
+
 func (l *scatterRangeScheduler) IsScheduleAllowed(cluster schedule.Cluster) bool {
 	return l.opController.OperatorCount(schedule.OpRange) < cluster.GetRegionScheduleLimit()
 }
@@ -76,7 +148,8 @@ func (l *scatterRangeScheduler) IsScheduleAllowed(cluster schedule.Cluster) bool
 func (l *scatterRangeScheduler) Schedule(cluster schedule.Cluster) []*schedule.Operator {
 	schedulerCounter.WithLabelValues(l.GetName(), "schedule").Inc()
 	// isolate a new cluster according to the key range
-	c := schedule.GenRangeCluster(cluster, l.startKey, l.endKey)
+	startKey, endKey := l.getRange()
+	c := schedule.GenRangeCluster(cluster, startKey, endKey)
 	c.SetTolerantSizeRatio(2)
 	ops := l.balanceLeader.Schedule(c)
 	if len(ops) > 0 {
@@ -34,7 +34,7 @@ func (s *testShuffleLeaderSuite) TestShuffle(c *C) {
 	opt := mockoption.NewScheduleOptions()
 	tc := mockcluster.NewCluster(opt)
 
-	sl, err := schedule.CreateScheduler("shuffle-leader", schedule.NewOperatorController(nil, nil))
+	sl, err := schedule.CreateScheduler("shuffle-leader", schedule.NewOperatorController(nil, nil), core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 	c.Assert(sl.Schedule(tc), IsNil)
 
@@ -64,21 +64,21 @@ func (s *testBalanceAdjacentRegionSuite) TestBalance(c *C) {
 	opt := mockoption.NewScheduleOptions()
 	tc := mockcluster.NewCluster(opt)
 
-	sc, err := schedule.CreateScheduler("adjacent-region", schedule.NewOperatorController(nil, nil), "32", "2")
+	sc, err := schedule.CreateScheduler("adjacent-region", schedule.NewOperatorController(nil, nil), core.NewKV(core.NewMemoryKV()), "32", "2")
 	c.Assert(err, IsNil)
 
-	c.Assert(sc.(*balanceAdjacentRegionScheduler).leaderLimit, Equals, uint64(32))
-	c.Assert(sc.(*balanceAdjacentRegionScheduler).peerLimit, Equals, uint64(2))
+	c.Assert(sc.(*balanceAdjacentRegionScheduler).conf.LeaderLimit, Equals, uint64(32))
+	c.Assert(sc.(*balanceAdjacentRegionScheduler).conf.PeerLimit, Equals, uint64(2))
 
-	sc.(*balanceAdjacentRegionScheduler).leaderLimit = 0
-	sc.(*balanceAdjacentRegionScheduler).peerLimit = 0
+	sc.(*balanceAdjacentRegionScheduler).conf.LeaderLimit = 0
+	sc.(*balanceAdjacentRegionScheduler).conf.PeerLimit = 0
 	c.Assert(sc.IsScheduleAllowed(tc), IsFalse)
-	sc.(*balanceAdjacentRegionScheduler).leaderLimit = defaultAdjacentLeaderLimit
+	sc.(*balanceAdjacentRegionScheduler).conf.LeaderLimit = defaultAdjacentLeaderLimit
 	c.Assert(sc.IsScheduleAllowed(tc), IsTrue)
-	sc.(*balanceAdjacentRegionScheduler).leaderLimit = 0
-	sc.(*balanceAdjacentRegionScheduler).peerLimit = defaultAdjacentPeerLimit
+	sc.(*balanceAdjacentRegionScheduler).conf.LeaderLimit = 0
+	sc.(*balanceAdjacentRegionScheduler).conf.PeerLimit = defaultAdjacentPeerLimit
 	c.Assert(sc.IsScheduleAllowed(tc), IsTrue)
-	sc.(*balanceAdjacentRegionScheduler).leaderLimit = defaultAdjacentLeaderLimit
+	sc.(*balanceAdjacentRegionScheduler).conf.LeaderLimit = defaultAdjacentLeaderLimit
 	c.Assert(sc.IsScheduleAllowed(tc), IsTrue)
 
 	c.Assert(sc.Schedule(tc), IsNil)
@@ -132,7 +132,7 @@ func (s *testBalanceAdjacentRegionSuite) TestNoNeedToBalance(c *C) {
 	opt := mockoption.NewScheduleOptions()
 	tc := mockcluster.NewCluster(opt)
 
-	sc, err := schedule.CreateScheduler("adjacent-region", schedule.NewOperatorController(nil, nil))
+	sc, err := schedule.CreateScheduler("adjacent-region", schedule.NewOperatorController(nil, nil), core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 	c.Assert(sc.Schedule(tc), IsNil)
 
@@ -273,7 +273,7 @@ func (s *testRejectLeaderSuite) TestRejectLeader(c *C) {
 
 	// The label scheduler transfers leader out of store1.
 	oc := schedule.NewOperatorController(nil, nil)
-	sl, err := schedule.CreateScheduler("label", oc)
+	sl, err := schedule.CreateScheduler("label", oc, core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 	op := sl.Schedule(tc)
 	testutil.CheckTransferLeader(c, op[0], schedule.OpLeader, 1, 3)
@@ -285,13 +285,13 @@ func (s *testRejectLeaderSuite) TestRejectLeader(c *C) {
 
 	// As store3 is disconnected, store1 rejects leader. Balancer will not create
 	// any operators.
-	bs, err := schedule.CreateScheduler("balance-leader", oc)
+	bs, err := schedule.CreateScheduler("balance-leader", oc, core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 	op = bs.Schedule(tc)
 	c.Assert(op, IsNil)
 
 	// Can't evict leader from store2, neither.
-	el, err := schedule.CreateScheduler("evict-leader", oc, "2")
+	el, err := schedule.CreateScheduler("evict-leader", oc, core.NewKV(core.NewMemoryKV()), "2")
 	c.Assert(err, IsNil)
 	op = el.Schedule(tc)
 	c.Assert(op, IsNil)
@@ -318,7 +318,7 @@ func (s *testShuffleHotRegionSchedulerSuite) TestBalance(c *C) {
 	opt := mockoption.NewScheduleOptions()
 	newTestReplication(opt, 3, "zone", "host")
 	tc := mockcluster.NewCluster(opt)
-	hb, err := schedule.CreateScheduler("shuffle-hot-region", schedule.NewOperatorController(nil, nil))
+	hb, err := schedule.CreateScheduler("shuffle-hot-region", schedule.NewOperatorController(nil, nil), core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 
 	// Add stores 1, 2, 3, 4, 5, 6  with hot peer counts 3, 2, 2, 2, 0, 0.
@@ -361,6 +361,26 @@ func (s *testShuffleHotRegionSchedulerSuite) TestBalance(c *C) {
 	c.Assert(op[0].Step(1).(schedule.PromoteLearner).ToStore, Not(Equals), 6)
 }
 
+func (s *testShuffleHotRegionSchedulerSuite) TestConfig(c *C) {
+	storage := core.NewKV(core.NewMemoryKV())
+	oc := schedule.NewOperatorController(nil, nil)
+	sche, err := schedule.CreateScheduler("shuffle-hot-region", oc, storage, "2")
+	c.Assert(err, IsNil)
+	hb := sche.(schedule.ConfigurableScheduler)
+	c.Assert(hb.GetConfig(), DeepEquals, shuffleHotRegionSchedulerConfig{Limit: 2})
+
+	c.Assert(hb.SetConfig([]byte(`{"limit":0}`)), NotNil)
+	c.Assert(hb.SetConfig([]byte(`{"limit":"a"}`)), NotNil)
+	c.Assert(hb.GetConfig(), DeepEquals, shuffleHotRegionSchedulerConfig{Limit: 2})
+	c.Assert(hb.SetConfig([]byte(`{"limit":4}`)), IsNil)
+	c.Assert(hb.GetConfig(), DeepEquals, shuffleHotRegionSchedulerConfig{Limit: 4})
+
+	// The persisted config overrides the arguments.
+	sche, err = schedule.CreateScheduler("shuffle-hot-region", oc, storage, "1")
+	c.Assert(err, IsNil)
+	c.Assert(sche.(schedule.ConfigurableScheduler).GetConfig(), DeepEquals, shuffleHotRegionSchedulerConfig{Limit: 4})
+}
+
 var _ = Suite(&testEvictLeaderSuite{})
 
 type testEvictLeaderSuite struct{}
@@ -378,13 +398,100 @@ func (s *testEvictLeaderSuite) TestEvictLeader(c *C) {
 	tc.AddLeaderRegion(2, 2, 1)
 	tc.AddLeaderRegion(3, 3, 1)
 
-	sl, err := schedule.CreateScheduler("evict-leader", schedule.NewOperatorController(nil, nil), "1")
+	sl, err := schedule.CreateScheduler("evict-leader", schedule.NewOperatorController(nil, nil), core.NewKV(core.NewMemoryKV()), "1")
 	c.Assert(err, IsNil)
 	c.Assert(sl.IsScheduleAllowed(tc), IsTrue)
 	op := sl.Schedule(tc)
 	testutil.CheckTransferLeader(c, op[0], schedule.OpLeader, 1, 2)
 }
 
+func (s *testEvictLeaderSuite) TestEvictLeaderStores(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	kv := core.NewKV(core.NewMemoryKV())
+
+	// Add stores 1, 2, 3
+	tc.AddLeaderStore(1, 0)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderStore(3, 0)
+	// Add regions 1, 2, 3 with leaders in stores 1, 2, 3
+	tc.AddLeaderRegionWithRange(1, "", "a", 1, 3)
+	tc.AddLeaderRegionWithRange(2, "a", "b", 2, 3)
+	tc.AddLeaderRegionWithRange(3, "b", "", 1, 3)
+
+	el, err := schedule.CreateScheduler("evict-leader", schedule.NewOperatorController(nil, nil), kv, "1")
+	c.Assert(err, IsNil)
+	c.Assert(el.GetName(), Equals, "evict-leader-scheduler")
+	c.Assert(el.Prepare(tc), IsNil)
+	c.Assert(tc.GetStore(1).IsBlocked(), IsTrue)
+	sl := el.(schedule.StoreScheduler)
+
+	// Evict the leaders of region 2 only.
+	c.Assert(sl.AddStore(tc, 2, nil), IsNil)
+	c.Assert(tc.GetStore(2).IsBlocked(), IsTrue)
+	c.Assert(sl.AddStore(tc, 1, []schedule.KeyRange{{StartKey: "62", EndKey: ""}}), IsNil)
+	ops := el.Schedule(tc)
+	c.Assert(ops, HasLen, 2)
+	testutil.CheckTransferLeader(c, ops[0], schedule.OpLeader, 1, 3)
+	c.Assert(ops[0].RegionID(), Equals, uint64(3))
+	testutil.CheckTransferLeader(c, ops[1], schedule.OpLeader, 2, 3)
+
+	// Invalid ranges are rejected.
+	c.Assert(sl.AddStore(tc, 3, []schedule.KeyRange{{StartKey: "zz"}}), NotNil)
+	c.Assert(sl.AddStore(tc, 3, []schedule.KeyRange{{StartKey: "62", EndKey: "61"}}), NotNil)
+	c.Assert(tc.GetStore(3).IsBlocked(), IsFalse)
+
+	// The stores are persisted.
+	el2, err := schedule.CreateScheduler("evict-leader", schedule.NewOperatorController(nil, nil), kv)
+	c.Assert(err, IsNil)
+	c.Assert(el2.(schedule.StoreScheduler).GetStores(), DeepEquals, sl.GetStores())
+
+	empty, err := sl.RemoveStore(tc, 1)
+	c.Assert(err, IsNil)
+	c.Assert(empty, IsFalse)
+	c.Assert(tc.GetStore(1).IsBlocked(), IsFalse)
+	_, err = sl.RemoveStore(tc, 1)
+	c.Assert(err, NotNil)
+	empty, err = sl.RemoveStore(tc, 2)
+	c.Assert(err, IsNil)
+	c.Assert(empty, IsTrue)
+	c.Assert(el.Schedule(tc), HasLen, 0)
+}
+
+var _ = Suite(&testGrantLeaderSuite{})
+
+type testGrantLeaderSuite struct{}
+
+func (s *testGrantLeaderSuite) TestGrantLeaderStores(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+
+	// Add stores 1, 2, 3
+	tc.AddLeaderStore(1, 0)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderStore(3, 0)
+	tc.AddLeaderRegionWithRange(1, "", "a", 3, 1, 2)
+	tc.AddLeaderRegionWithRange(2, "a", "", 3, 1, 2)
+
+	gl, err := schedule.CreateScheduler("grant-leader", schedule.NewOperatorController(nil, nil), core.NewKV(core.NewMemoryKV()), "1", "61", "")
+	c.Assert(err, IsNil)
+	c.Assert(gl.Prepare(tc), IsNil)
+	ops := gl.Schedule(tc)
+	c.Assert(ops, HasLen, 1)
+	testutil.CheckTransferLeader(c, ops[0], schedule.OpLeader, 3, 1)
+	c.Assert(ops[0].RegionID(), Equals, uint64(2))
+
+	c.Assert(gl.(schedule.StoreScheduler).AddStore(tc, 2, nil), IsNil)
+	c.Assert(gl.Schedule(tc), HasLen, 2)
+	gl.Cleanup(tc)
+	c.Assert(tc.GetStore(1).IsBlocked(), IsFalse)
+	c.Assert(tc.GetStore(2).IsBlocked(), IsFalse)
+
+	// The args should be a store ID with pairs of keys.
+	_, err = schedule.CreateScheduler("grant-leader", schedule.NewOperatorController(nil, nil), core.NewKV(core.NewMemoryKV()), "1", "61")
+	c.Assert(err, NotNil)
+}
+
 var _ = Suite(&testShuffleRegionSuite{})
 
 type testShuffleRegionSuite struct{}
@@ -393,7 +500,7 @@ func (s *testShuffleRegionSuite) TestShuffle(c *C) {
 	opt := mockoption.NewScheduleOptions()
 	tc := mockcluster.NewCluster(opt)
 
-	sl, err := schedule.CreateScheduler("shuffle-region", schedule.NewOperatorController(nil, nil))
+	sl, err := schedule.CreateScheduler("shuffle-region", schedule.NewOperatorController(nil, nil), core.NewKV(core.NewMemoryKV()))
 	c.Assert(err, IsNil)
 	c.Assert(sl.IsScheduleAllowed(tc), IsTrue)
 	c.Assert(sl.Schedule(tc), IsNil)
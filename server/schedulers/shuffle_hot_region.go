@@ -14,8 +14,10 @@
 package schedulers
 
 import (
+	"encoding/json"
 	"math/rand"
 	"strconv"
+	"sync"
 	"time"
 
 	log "github.com/pingcap/log"
@@ -27,7 +29,7 @@ import (
 )
 
 func init() {
-	schedule.RegisterScheduler("shuffle-hot-region", func(opController *schedule.OperatorController, args []string) (schedule.Scheduler, error) {
+	schedule.RegisterScheduler("shuffle-hot-region", func(opController *schedule.OperatorController, storage *core.KV, args []string) (schedule.Scheduler, error) {
 		limit := uint64(1)
 		if len(args) == 1 {
 			l, err := strconv.ParseUint(args[0], 10, 64)
@@ -36,28 +38,40 @@ func init() {
 			}
 			limit = l
 		}
-		return newShuffleHotRegionScheduler(opController, limit), nil
+		s := newShuffleHotRegionScheduler(opController, limit)
+		s.storage = storage
+		if _, err := storage.LoadSchedulerConfig(s.GetName(), &s.conf); err != nil {
+			return nil, err
+		}
+		return s, nil
 	})
 }
 
+type shuffleHotRegionSchedulerConfig struct {
+	Limit uint64 `json:"limit"`
+}
+
 // ShuffleHotRegionScheduler mainly used to test.
 // It will randomly pick a hot peer, and move the peer
 // to a random store, and then transfer the leader to
 // the hot peer.
 type shuffleHotRegionScheduler struct {
 	*baseScheduler
-	stats *storeStatistics
-	r     *rand.Rand
-	limit uint64
-	types []BalanceType
+	stats   *storeStatistics
+	r       *rand.Rand
+	types   []BalanceType
+	storage *core.KV
+
+	mu   sync.RWMutex
+	conf shuffleHotRegionSchedulerConfig
 }
 
 // newShuffleHotRegionScheduler creates an admin scheduler that random balance hot regions
-func newShuffleHotRegionScheduler(opController *schedule.OperatorController, limit uint64) schedule.Scheduler {
+func newShuffleHotRegionScheduler(opController *schedule.OperatorController, limit uint64) *shuffleHotRegionScheduler {
 	base := newBaseScheduler(opController)
 	return &shuffleHotRegionScheduler{
 		baseScheduler: base,
-		limit:         limit,
+		conf:          shuffleHotRegionSchedulerConfig{Limit: limit},
 		stats:         newStoreStaticstics(),
 		types:         []BalanceType{hotReadRegionBalance, hotWriteRegionBalance},
 		r:             rand.New(rand.NewSource(time.Now().UnixNano())),
@@ -72,8 +86,37 @@ func (s *shuffleHotRegionScheduler) GetType() string {
 	return "shuffle-hot-region"
 }
 
+func (s *shuffleHotRegionScheduler) GetConfig() interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.conf
+}
+
+func (s *shuffleHotRegionScheduler) SetConfig(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conf := s.conf
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return errors.WithStack(err)
+	}
+	if conf.Limit == 0 {
+		return errors.New("limit should be greater than 0")
+	}
+	if err := s.storage.SaveSchedulerConfig(s.GetName(), conf); err != nil {
+		return err
+	}
+	s.conf = conf
+	return nil
+}
+
+func (s *shuffleHotRegionScheduler) getLimit() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.conf.Limit
+}
+
 func (s *shuffleHotRegionScheduler) IsScheduleAllowed(cluster schedule.Cluster) bool {
-	return s.opController.OperatorCount(schedule.OpHotRegion) < s.limit &&
+	return s.opController.OperatorCount(schedule.OpHotRegion) < s.getLimit() &&
 		s.opController.OperatorCount(schedule.OpRegion) < cluster.GetRegionScheduleLimit() &&
 		s.opController.OperatorCount(schedule.OpLeader) < cluster.GetLeaderScheduleLimit()
 }
@@ -110,7 +153,7 @@ func (s *shuffleHotRegionScheduler) randomSchedule(cluster schedule.Cluster, sto
 		filters := []schedule.Filter{
 			schedule.StoreStateFilter{MoveRegion: true},
 			schedule.NewExcludedFilter(srcRegion.GetStoreIds(), srcRegion.GetStoreIds()),
-			schedule.NewDistinctScoreFilter(cluster.GetLocationLabels(), cluster.GetRegionStores(srcRegion), srcStore),
+			schedule.NewDistinctScoreFilter(schedule.GetRegionLocationLabels(cluster, srcRegion), cluster.GetRegionStores(srcRegion), srcStore),
 		}
 		stores := cluster.GetStores()
 		destStoreIDs := make([]uint64, 0, len(stores))
@@ -19,7 +19,7 @@ import (
 )
 
 func init() {
-	schedule.RegisterScheduler("shuffle-leader", func(opController *schedule.OperatorController, args []string) (schedule.Scheduler, error) {
+	schedule.RegisterScheduler("shuffle-leader", func(opController *schedule.OperatorController, storage *core.KV, args []string) (schedule.Scheduler, error) {
 		return newShuffleLeaderScheduler(opController), nil
 	})
 }
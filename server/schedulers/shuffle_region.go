@@ -22,7 +22,7 @@ import (
 )
 
 func init() {
-	schedule.RegisterScheduler("shuffle-region", func(opController *schedule.OperatorController, args []string) (schedule.Scheduler, error) {
+	schedule.RegisterScheduler("shuffle-region", func(opController *schedule.OperatorController, storage *core.KV, args []string) (schedule.Scheduler, error) {
 		return newShuffleRegionScheduler(opController), nil
 	})
 }
@@ -0,0 +1,252 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedulers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+
+	log "github.com/pingcap/log"
+	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/server/schedule"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// maxScanRegions is the max number of regions scanned in a key range to pick
+// a region to schedule.
+const maxScanRegions = 1024
+
+// storeRangesConfig is the persisted state of the schedulers working on a set
+// of stores. It implements the store related methods of
+// schedule.StoreScheduler.
+type storeRangesConfig struct {
+	mu      sync.RWMutex
+	name    string
+	storage *core.KV
+
+	StoreIDWithRanges map[uint64][]schedule.KeyRange `json:"store-id-ranges"`
+}
+
+// newStoreRangesConfig loads the config of the scheduler from storage, then
+// adds the store specified by args, which are a store ID followed by pairs of
+// hex-encoded start and end keys.
+func newStoreRangesConfig(name string, storage *core.KV, args []string) (*storeRangesConfig, error) {
+	conf := &storeRangesConfig{
+		name:              name,
+		storage:           storage,
+		StoreIDWithRanges: make(map[uint64][]schedule.KeyRange),
+	}
+	if _, err := storage.LoadSchedulerConfig(name, conf); err != nil {
+		return nil, err
+	}
+	if len(args) > 0 {
+		storeID, ranges, err := parseStoreRangesArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		conf.StoreIDWithRanges[storeID] = ranges
+	}
+	return conf, nil
+}
+
+func parseStoreRangesArgs(args []string) (uint64, []schedule.KeyRange, error) {
+	if len(args)%2 != 1 {
+		return 0, nil, errors.New("should specify the store ID and pairs of start and end keys")
+	}
+	storeID, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return 0, nil, errors.WithStack(err)
+	}
+	var ranges []schedule.KeyRange
+	for i := 1; i < len(args); i += 2 {
+		ranges = append(ranges, schedule.KeyRange{StartKey: args[i], EndKey: args[i+1]})
+	}
+	if err := validateKeyRanges(ranges); err != nil {
+		return 0, nil, err
+	}
+	return storeID, ranges, nil
+}
+
+func validateKeyRanges(ranges []schedule.KeyRange) error {
+	for _, r := range ranges {
+		startKey, endKey, err := decodeKeyRange(r)
+		if err != nil {
+			return err
+		}
+		if len(endKey) > 0 && bytes.Compare(endKey, startKey) <= 0 {
+			return errors.Errorf("end key %s should be greater than start key %s", r.EndKey, r.StartKey)
+		}
+	}
+	return nil
+}
+
+func decodeKeyRange(r schedule.KeyRange) ([]byte, []byte, error) {
+	startKey, err := hex.DecodeString(r.StartKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "start key is not hex format")
+	}
+	endKey, err := hex.DecodeString(r.EndKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "end key is not hex format")
+	}
+	return startKey, endKey, nil
+}
+
+func (conf *storeRangesConfig) persist() error {
+	return conf.storage.SaveSchedulerConfig(conf.name, conf)
+}
+
+// getStoreIDs returns the IDs of the stores in order.
+func (conf *storeRangesConfig) getStoreIDs() []uint64 {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	ids := make([]uint64, 0, len(conf.StoreIDWithRanges))
+	for id := range conf.StoreIDWithRanges {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func (conf *storeRangesConfig) getRanges(storeID uint64) []schedule.KeyRange {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	return conf.StoreIDWithRanges[storeID]
+}
+
+// prepare blocks all the stores and persists the config.
+func (conf *storeRangesConfig) prepare(cluster schedule.Cluster) error {
+	conf.mu.Lock()
+	defer conf.mu.Unlock()
+	var blocked []uint64
+	for id := range conf.StoreIDWithRanges {
+		if err := cluster.BlockStore(id); err != nil {
+			for _, b := range blocked {
+				cluster.UnblockStore(b)
+			}
+			return err
+		}
+		blocked = append(blocked, id)
+	}
+	if err := conf.persist(); err != nil {
+		for _, b := range blocked {
+			cluster.UnblockStore(b)
+		}
+		return err
+	}
+	return nil
+}
+
+// cleanup unblocks all the stores.
+func (conf *storeRangesConfig) cleanup(cluster schedule.Cluster) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	for id := range conf.StoreIDWithRanges {
+		cluster.UnblockStore(id)
+	}
+}
+
+// GetStores returns a copy of the stores and their key ranges.
+func (conf *storeRangesConfig) GetStores() map[uint64][]schedule.KeyRange {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	stores := make(map[uint64][]schedule.KeyRange, len(conf.StoreIDWithRanges))
+	for id, ranges := range conf.StoreIDWithRanges {
+		stores[id] = append([]schedule.KeyRange(nil), ranges...)
+	}
+	return stores
+}
+
+// AddStore adds a store or updates its key ranges.
+func (conf *storeRangesConfig) AddStore(cluster schedule.Cluster, storeID uint64, ranges []schedule.KeyRange) error {
+	if err := validateKeyRanges(ranges); err != nil {
+		return err
+	}
+	conf.mu.Lock()
+	defer conf.mu.Unlock()
+	old, exist := conf.StoreIDWithRanges[storeID]
+	if !exist {
+		if err := cluster.BlockStore(storeID); err != nil {
+			return err
+		}
+	}
+	conf.StoreIDWithRanges[storeID] = ranges
+	if err := conf.persist(); err != nil {
+		if exist {
+			conf.StoreIDWithRanges[storeID] = old
+		} else {
+			delete(conf.StoreIDWithRanges, storeID)
+			cluster.UnblockStore(storeID)
+		}
+		return err
+	}
+	log.Info("add store to scheduler", zap.String("scheduler-name", conf.name), zap.Uint64("store-id", storeID))
+	return nil
+}
+
+// RemoveStore removes a store, and returns true if there is no store left.
+func (conf *storeRangesConfig) RemoveStore(cluster schedule.Cluster, storeID uint64) (bool, error) {
+	conf.mu.Lock()
+	defer conf.mu.Unlock()
+	old, exist := conf.StoreIDWithRanges[storeID]
+	if !exist {
+		return false, errors.Errorf("store %d not found in %s", storeID, conf.name)
+	}
+	delete(conf.StoreIDWithRanges, storeID)
+	if err := conf.persist(); err != nil {
+		conf.StoreIDWithRanges[storeID] = old
+		return false, err
+	}
+	cluster.UnblockStore(storeID)
+	log.Info("remove store from scheduler", zap.String("scheduler-name", conf.name), zap.Uint64("store-id", storeID))
+	return len(conf.StoreIDWithRanges) == 0, nil
+}
+
+// selectRegionInRanges returns a random region which is in the key ranges and
+// satisfies the options.
+func selectRegionInRanges(cluster schedule.Cluster, ranges []schedule.KeyRange, opts ...core.RegionOption) *core.RegionInfo {
+	var candidates []*core.RegionInfo
+	for _, r := range ranges {
+		startKey, endKey, err := decodeKeyRange(r)
+		if err != nil {
+			continue
+		}
+		for _, region := range cluster.ScanRegions(startKey, maxScanRegions) {
+			if len(endKey) > 0 && bytes.Compare(region.GetStartKey(), endKey) >= 0 {
+				break
+			}
+			if isRegionSelected(region, opts) {
+				candidates = append(candidates, region)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[rand.Intn(len(candidates))]
+}
+
+func isRegionSelected(region *core.RegionInfo, opts []core.RegionOption) bool {
+	for _, opt := range opts {
+		if !opt(region) {
+			return false
+		}
+	}
+	return true
+}
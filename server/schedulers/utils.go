@@ -50,6 +50,12 @@ func minDuration(a, b time.Duration) time.Duration {
 }
 
 func shouldBalance(cluster schedule.Cluster, source, target *core.StoreInfo, region *core.RegionInfo, kind core.ResourceKind, opInfluence schedule.OpInfluence) bool {
+	return compareBalanceScore(cluster, source, target, region, kind, opInfluence).ShouldBalance
+}
+
+// compareBalanceScore compares the scores of the source and target stores
+// after moving the region, which is the reason of shouldBalance.
+func compareBalanceScore(cluster schedule.Cluster, source, target *core.StoreInfo, region *core.RegionInfo, kind core.ResourceKind, opInfluence schedule.OpInfluence) *schedule.ScoreComparison {
 	// The reason we use max(regionSize, averageRegionSize) to check is:
 	// 1. prevent moving small regions between stores with close scores, leading to unnecessary balance.
 	// 2. prevent moving huge regions, leading to over balance.
@@ -62,9 +68,18 @@ func shouldBalance(cluster schedule.Cluster, source, target *core.StoreInfo, reg
 	sourceDelta := opInfluence.GetStoreInfluence(source.GetID()).ResourceSize(kind) - regionSize
 	targetDelta := opInfluence.GetStoreInfluence(target.GetID()).ResourceSize(kind) + regionSize
 
-	// Make sure after move, source score is still greater than target score.
-	return source.ResourceScore(kind, cluster.GetHighSpaceRatio(), cluster.GetLowSpaceRatio(), sourceDelta) >
-		target.ResourceScore(kind, cluster.GetHighSpaceRatio(), cluster.GetLowSpaceRatio(), targetDelta)
+	sourceScore := source.ResourceScore(kind, cluster.GetHighSpaceRatio(), cluster.GetLowSpaceRatio(), sourceDelta)
+	targetScore := target.ResourceScore(kind, cluster.GetHighSpaceRatio(), cluster.GetLowSpaceRatio(), targetDelta)
+	return &schedule.ScoreComparison{
+		RegionID:    region.GetID(),
+		SourceStore: source.GetID(),
+		TargetStore: target.GetID(),
+		SourceScore: sourceScore,
+		TargetScore: targetScore,
+		RegionSize:  regionSize,
+		// Make sure after move, source score is still greater than target score.
+		ShouldBalance: sourceScore > targetScore,
+	}
 }
 
 func adjustTolerantRatio(cluster schedule.Cluster) float64 {
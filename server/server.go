@@ -96,8 +96,11 @@ type Server struct {
 	// for raft cluster
 	cluster *RaftCluster
 	// For tso, set after pd becomes leader.
-	ts            atomic.Value
-	lastSavedTime time.Time
+	tso *timestampOracle
+	// For local tso, set if the server is in a zone and local tso is enabled.
+	localTSO *localTSOAllocator
+	// For forwarding tso requests to leader when the server is a follower.
+	tsoProxy *tsoProxy
 	// For async region heartbeat.
 	hbStreams *heartbeatStreams
 	// Zap logger
@@ -218,6 +221,11 @@ func (s *Server) startServer() error {
 
 	s.rootPath = path.Join(pdRootPath, strconv.FormatUint(s.clusterID, 10))
 	s.member, s.memberValue = s.memberInfo()
+	s.tso = newTimestampOracle(s.client, s.getTimestampPath(), s.cfg.TsoSaveInterval.Duration, s.leaderCmp, s.tsoSuffixBits(), 0)
+	if zone := s.cfg.GetZone(); s.cfg.EnableLocalTSO && zone != "" {
+		s.localTSO = newLocalTSOAllocator(s, zone)
+	}
+	s.tsoProxy = newTSOProxy(s)
 
 	s.idAlloc = &idAllocator{s: s}
 	kvBase := newEtcdKVBase(s)
@@ -319,10 +327,15 @@ func (s *Server) Context() context.Context {
 
 func (s *Server) startServerLoop() {
 	s.serverLoopCtx, s.serverLoopCancel = context.WithCancel(context.Background())
-	s.serverLoopWg.Add(3)
+	s.serverLoopWg.Add(4)
 	go s.leaderLoop()
 	go s.etcdLeaderLoop()
 	go s.serverMetricsLoop()
+	go s.tsoProxy.run()
+	if s.localTSO != nil {
+		s.serverLoopWg.Add(1)
+		go s.localTSO.campaignLoop()
+	}
 }
 
 func (s *Server) stopServerLoop() {
@@ -558,6 +571,15 @@ func (s *Server) SetReplicationConfig(cfg ReplicationConfig) error {
 		return err
 	}
 	old := s.scheduleOpt.rep.load()
+	if cfg.EnablePlacementRules && !old.EnablePlacementRules {
+		// The default rule is created with the replication config that is
+		// used before enabling placement rules.
+		if cluster := s.GetRaftCluster(); cluster != nil {
+			if err := cluster.GetRuleManager().Initialize(int(old.MaxReplicas), old.LocationLabels); err != nil {
+				return err
+			}
+		}
+	}
 	s.scheduleOpt.rep.store(&cfg)
 	if err := s.scheduleOpt.persist(s.kv); err != nil {
 		s.scheduleOpt.rep.store(old)
@@ -71,6 +71,14 @@ var (
 			Name:      "label_level",
 			Help:      "Number of regions in the different label level.",
 		}, []string{"type"})
+
+	regionIsolationLevelGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "regions",
+			Name:      "isolation_level",
+			Help:      "Number of regions isolated by each location label.",
+		}, []string{"type"})
 )
 
 func init() {
@@ -81,4 +89,5 @@ func init() {
 	prometheus.MustRegister(placementStatusGauge)
 	prometheus.MustRegister(configStatusGauge)
 	prometheus.MustRegister(regionLabelLevelGauge)
+	prometheus.MustRegister(regionIsolationLevelGauge)
 }
@@ -32,23 +32,36 @@ const (
 	OfflinePeer
 	IncorrectNamespace
 	LearnerPeer
+	MissLearnerPeer
 )
 
+// RuleManager checks the peers of regions against the placement rules.
+type RuleManager interface {
+	IsInitialized() bool
+	CheckRegionPeers(region *core.RegionInfo, stores []*core.StoreInfo) (missVoter, missLearner, extra bool)
+}
+
 // RegionStatistics is used to record the status of regions.
 type RegionStatistics struct {
-	opt        ScheduleOptions
-	classifier namespace.Classifier
-	stats      map[RegionStatisticType]map[uint64]*core.RegionInfo
-	index      map[uint64]RegionStatisticType
+	opt               ScheduleOptions
+	classifier        namespace.Classifier
+	ruleManager       RuleManager
+	rangeReplications *core.RangeReplications
+	stats             map[RegionStatisticType]map[uint64]*core.RegionInfo
+	index             map[uint64]RegionStatisticType
 }
 
-// NewRegionStatistics creates a new RegionStatistics.
-func NewRegionStatistics(opt ScheduleOptions, classifier namespace.Classifier) *RegionStatistics {
+// NewRegionStatistics creates a new RegionStatistics. The ruleManager is used
+// to check peers when placement rules are enabled, both it and
+// rangeReplications can be nil.
+func NewRegionStatistics(opt ScheduleOptions, classifier namespace.Classifier, ruleManager RuleManager, rangeReplications *core.RangeReplications) *RegionStatistics {
 	r := &RegionStatistics{
-		opt:        opt,
-		classifier: classifier,
-		stats:      make(map[RegionStatisticType]map[uint64]*core.RegionInfo),
-		index:      make(map[uint64]RegionStatisticType),
+		opt:               opt,
+		classifier:        classifier,
+		ruleManager:       ruleManager,
+		rangeReplications: rangeReplications,
+		stats:             make(map[RegionStatisticType]map[uint64]*core.RegionInfo),
+		index:             make(map[uint64]RegionStatisticType),
 	}
 	r.stats[MissPeer] = make(map[uint64]*core.RegionInfo)
 	r.stats[ExtraPeer] = make(map[uint64]*core.RegionInfo)
@@ -57,6 +70,7 @@ func NewRegionStatistics(opt ScheduleOptions, classifier namespace.Classifier) *
 	r.stats[OfflinePeer] = make(map[uint64]*core.RegionInfo)
 	r.stats[IncorrectNamespace] = make(map[uint64]*core.RegionInfo)
 	r.stats[LearnerPeer] = make(map[uint64]*core.RegionInfo)
+	r.stats[MissLearnerPeer] = make(map[uint64]*core.RegionInfo)
 	return r
 }
 
@@ -77,6 +91,15 @@ func (r *RegionStatistics) deleteEntry(deleteIndex RegionStatisticType, regionID
 	}
 }
 
+func (r *RegionStatistics) getMaxReplicas(region *core.RegionInfo, namespace string) int {
+	if r.rangeReplications != nil {
+		if rr := r.rangeReplications.GetRangeReplication(region); rr != nil && rr.MaxReplicas > 0 {
+			return int(rr.MaxReplicas)
+		}
+	}
+	return r.opt.GetMaxReplicas(namespace)
+}
+
 // Observe records the current regions' status.
 func (r *RegionStatistics) Observe(region *core.RegionInfo, stores []*core.StoreInfo) {
 	// Region state.
@@ -86,10 +109,25 @@ func (r *RegionStatistics) Observe(region *core.RegionInfo, stores []*core.Store
 		peerTypeIndex RegionStatisticType
 		deleteIndex   RegionStatisticType
 	)
-	if len(region.GetPeers()) < r.opt.GetMaxReplicas(namespace) {
+	if r.ruleManager != nil && r.opt.IsPlacementRulesEnabled() && r.ruleManager.IsInitialized() {
+		// Learners required by rules are not counted as voters.
+		missVoter, missLearner, extra := r.ruleManager.CheckRegionPeers(region, stores)
+		if missVoter {
+			r.stats[MissPeer][regionID] = region
+			peerTypeIndex |= MissPeer
+		}
+		if missLearner {
+			r.stats[MissLearnerPeer][regionID] = region
+			peerTypeIndex |= MissLearnerPeer
+		}
+		if extra {
+			r.stats[ExtraPeer][regionID] = region
+			peerTypeIndex |= ExtraPeer
+		}
+	} else if len(region.GetPeers()) < r.getMaxReplicas(region, namespace) {
 		r.stats[MissPeer][regionID] = region
 		peerTypeIndex |= MissPeer
-	} else if len(region.GetPeers()) > r.opt.GetMaxReplicas(namespace) {
+	} else if len(region.GetPeers()) > r.getMaxReplicas(region, namespace) {
 		r.stats[ExtraPeer][regionID] = region
 		peerTypeIndex |= ExtraPeer
 	}
@@ -149,52 +187,113 @@ func (r *RegionStatistics) Collect() {
 	regionStatusGauge.WithLabelValues("offline_peer_region_count").Set(float64(len(r.stats[OfflinePeer])))
 	regionStatusGauge.WithLabelValues("incorrect_namespace_region_count").Set(float64(len(r.stats[IncorrectNamespace])))
 	regionStatusGauge.WithLabelValues("learner_peer_region_count").Set(float64(len(r.stats[LearnerPeer])))
+	regionStatusGauge.WithLabelValues("miss_learner_peer_region_count").Set(float64(len(r.stats[MissLearnerPeer])))
+}
+
+// NoneIsolationLevel is the isolation level of the regions whose replicas are
+// not isolated by any location label.
+const NoneIsolationLevel = "none"
+
+// regionLabelLevel is the isolation level of a region, and its name, which
+// depends on the location labels of the region.
+type regionLabelLevel struct {
+	level int
+	name  string
 }
 
 // LabelLevelStatistics is the statistics of the level of labels.
 type LabelLevelStatistics struct {
-	regionLabelLevelStats map[uint64]int
+	regionLabelLevelStats map[uint64]regionLabelLevel
 	labelLevelCounter     map[int]int
+	// labelLevelRegions are the regions of each isolation level by its name.
+	labelLevelRegions map[string]map[uint64]*core.RegionInfo
 }
 
 // NewLabelLevelStatistics creates a new LabelLevelStatistics.
 func NewLabelLevelStatistics() *LabelLevelStatistics {
 	return &LabelLevelStatistics{
-		regionLabelLevelStats: make(map[uint64]int),
+		regionLabelLevelStats: make(map[uint64]regionLabelLevel),
 		labelLevelCounter:     make(map[int]int),
+		labelLevelRegions:     make(map[string]map[uint64]*core.RegionInfo),
 	}
 }
 
 // Observe records the current label status.
 func (l *LabelLevelStatistics) Observe(region *core.RegionInfo, stores []*core.StoreInfo, labels []string) {
 	regionID := region.GetID()
-	regionLabelLevel := getRegionLabelIsolationLevel(stores, labels)
-	if level, ok := l.regionLabelLevelStats[regionID]; ok {
-		if level == regionLabelLevel {
+	level := getRegionLabelIsolationLevel(stores, labels)
+	regionLevel := regionLabelLevel{level: level, name: GetIsolationLevelName(level, labels)}
+	if old, ok := l.regionLabelLevelStats[regionID]; ok {
+		if old == regionLevel {
+			l.labelLevelRegions[old.name][regionID] = region
 			return
 		}
-		l.labelLevelCounter[level]--
+		l.labelLevelCounter[old.level]--
+		delete(l.labelLevelRegions[old.name], regionID)
+	}
+	l.regionLabelLevelStats[regionID] = regionLevel
+	l.labelLevelCounter[level]++
+	if _, ok := l.labelLevelRegions[regionLevel.name]; !ok {
+		l.labelLevelRegions[regionLevel.name] = make(map[uint64]*core.RegionInfo)
 	}
-	l.regionLabelLevelStats[regionID] = regionLabelLevel
-	l.labelLevelCounter[regionLabelLevel]++
+	l.labelLevelRegions[regionLevel.name][regionID] = region
 }
 
 // Collect collects the metrics of the label status.
 func (l *LabelLevelStatistics) Collect() {
+	regionLabelLevelGauge.Reset()
 	for level, count := range l.labelLevelCounter {
 		typ := fmt.Sprintf("level_%d", level)
 		regionLabelLevelGauge.WithLabelValues(typ).Set(float64(count))
 	}
+	regionIsolationLevelGauge.Reset()
+	for name, regions := range l.labelLevelRegions {
+		regionIsolationLevelGauge.WithLabelValues(name).Set(float64(len(regions)))
+	}
+}
+
+// GetLabelLevelCounts returns the number of regions of each isolation level
+// by its name.
+func (l *LabelLevelStatistics) GetLabelLevelCounts() map[string]int {
+	counts := make(map[string]int, len(l.labelLevelRegions))
+	for name, regions := range l.labelLevelRegions {
+		counts[name] = len(regions)
+	}
+	return counts
+}
+
+// GetLabelLevelRegions returns the regions of the isolation level by its name.
+func (l *LabelLevelStatistics) GetLabelLevelRegions(name string) []*core.RegionInfo {
+	res := make([]*core.RegionInfo, 0, len(l.labelLevelRegions[name]))
+	for _, r := range l.labelLevelRegions[name] {
+		res = append(res, r)
+	}
+	return res
 }
 
 // ClearDefunctRegion is used to handle the overlap region.
 func (l *LabelLevelStatistics) ClearDefunctRegion(regionID uint64) {
-	if level, ok := l.regionLabelLevelStats[regionID]; ok {
-		l.labelLevelCounter[level]--
+	if old, ok := l.regionLabelLevelStats[regionID]; ok {
+		l.labelLevelCounter[old.level]--
+		delete(l.labelLevelRegions[old.name], regionID)
 		delete(l.regionLabelLevelStats, regionID)
 	}
 }
 
+// GetIsolationLevelName returns the name of an isolation level, which is the
+// location label the replicas are isolated by, or NoneIsolationLevel.
+func GetIsolationLevelName(level int, labels []string) string {
+	if level <= 0 || level > len(labels) {
+		return NoneIsolationLevel
+	}
+	return labels[level-1]
+}
+
+// getRegionLabelIsolationLevel returns the isolation level of the replicas,
+// which is the number of the location labels needed to tell the stores apart.
+// Level 1 means the replicas are isolated by the first label. The stores
+// missing a label are not isolated from the others by that label, and 0 is
+// returned if the stores cannot be told apart by all labels.
 func getRegionLabelIsolationLevel(stores []*core.StoreInfo, labels []string) int {
 	if len(stores) == 0 || len(labels) == 0 {
 		return 0
@@ -217,11 +316,15 @@ func getRegionLabelIsolationLevel(stores []*core.StoreInfo, labels []string) int
 }
 
 func notIsolatedStoresWithLabel(stores []*core.StoreInfo, label string) [][]*core.StoreInfo {
+	if len(stores) < 2 {
+		return nil
+	}
 	m := make(map[string][]*core.StoreInfo)
 	for _, s := range stores {
 		labelValue := s.GetLabelValue(label)
 		if labelValue == "" {
-			continue
+			// The store may share the location with any other store.
+			return [][]*core.StoreInfo{stores}
 		}
 		m[labelValue] = append(m[labelValue], s)
 	}
@@ -20,6 +20,7 @@ import (
 	"github.com/pingcap/pd/pkg/mock/mockclassifier"
 	"github.com/pingcap/pd/pkg/mock/mockoption"
 	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/server/placement"
 )
 
 var _ = Suite(&testRegionStatisticsSuite{})
@@ -58,7 +59,7 @@ func (t *testRegionStatisticsSuite) TestRegionStatistics(c *C) {
 	r2 := &metapb.Region{Id: 2, Peers: peers[0:2], StartKey: []byte("cc"), EndKey: []byte("dd")}
 	region1 := core.NewRegionInfo(r1, peers[0])
 	region2 := core.NewRegionInfo(r2, peers[0])
-	regionStats := NewRegionStatistics(opt, mockclassifier.Classifier{})
+	regionStats := NewRegionStatistics(opt, mockclassifier.Classifier{}, nil, nil)
 	regionStats.Observe(region1, stores)
 	c.Assert(len(regionStats.stats[ExtraPeer]), Equals, 1)
 	c.Assert(len(regionStats.stats[LearnerPeer]), Equals, 1)
@@ -101,6 +102,43 @@ func (t *testRegionStatisticsSuite) TestRegionStatistics(c *C) {
 	c.Assert(len(regionStats.stats[OfflinePeer]), Equals, 0)
 }
 
+func (t *testRegionStatisticsSuite) TestRegionStatisticsWithRules(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	opt.EnablePlacementRules = true
+	ruleManager := placement.NewRuleManager(core.NewKV(core.NewMemoryKV()))
+	c.Assert(ruleManager.Initialize(3, nil), IsNil)
+	c.Assert(ruleManager.SetRule(&placement.Rule{ID: "learner", Role: placement.Learner, Count: 1}), IsNil)
+
+	var stores []*core.StoreInfo
+	for id := uint64(1); id <= 4; id++ {
+		stores = append(stores, core.NewStoreInfo(&metapb.Store{Id: id}))
+	}
+	peers := []*metapb.Peer{
+		{Id: 11, StoreId: 1},
+		{Id: 12, StoreId: 2},
+		{Id: 13, StoreId: 3},
+		{Id: 14, StoreId: 4, IsLearner: true},
+	}
+	regionStats := NewRegionStatistics(opt, mockclassifier.Classifier{}, ruleManager, nil)
+
+	// The learner is not counted toward the voters.
+	region := core.NewRegionInfo(&metapb.Region{Id: 1, Peers: peers}, peers[0])
+	regionStats.Observe(region, stores)
+	c.Assert(len(regionStats.stats[MissPeer]), Equals, 0)
+	c.Assert(len(regionStats.stats[MissLearnerPeer]), Equals, 0)
+	c.Assert(len(regionStats.stats[ExtraPeer]), Equals, 0)
+
+	region = core.NewRegionInfo(&metapb.Region{Id: 1, Peers: peers[:3]}, peers[0])
+	regionStats.Observe(region, stores[:3])
+	c.Assert(len(regionStats.stats[MissPeer]), Equals, 0)
+	c.Assert(len(regionStats.stats[MissLearnerPeer]), Equals, 1)
+
+	region = core.NewRegionInfo(&metapb.Region{Id: 1, Peers: []*metapb.Peer{peers[0], peers[1], peers[3]}}, peers[0])
+	regionStats.Observe(region, []*core.StoreInfo{stores[0], stores[1], stores[3]})
+	c.Assert(len(regionStats.stats[MissPeer]), Equals, 1)
+	c.Assert(len(regionStats.stats[MissLearnerPeer]), Equals, 0)
+}
+
 func (t *testRegionStatisticsSuite) TestRegionLabelIsolationLevel(c *C) {
 	labelLevelStats := NewLabelLevelStatistics()
 	labelsSet := [][]map[string]string{
@@ -168,3 +206,45 @@ func (t *testRegionStatisticsSuite) TestRegionLabelIsolationLevel(c *C) {
 	level = getRegionLabelIsolationLevel(nil, nil)
 	c.Assert(level, Equals, 0)
 }
+
+func (t *testRegionStatisticsSuite) TestRegionLabelIsolationLevelMissingLabel(c *C) {
+	newStore := func(id uint64, labels map[string]string) *core.StoreInfo {
+		var storeLabels []*metapb.StoreLabel
+		for k, v := range labels {
+			storeLabels = append(storeLabels, &metapb.StoreLabel{Key: k, Value: v})
+		}
+		return core.NewStoreInfo(&metapb.Store{Id: id}, core.SetStoreLabels(storeLabels))
+	}
+	labels := []string{"zone", "host"}
+	// Store 3 may be in the same zone as the others.
+	stores := []*core.StoreInfo{
+		newStore(1, map[string]string{"zone": "z1", "host": "h1"}),
+		newStore(2, map[string]string{"zone": "z2", "host": "h2"}),
+		newStore(3, map[string]string{"host": "h3"}),
+	}
+	c.Assert(getRegionLabelIsolationLevel(stores, labels), Equals, 2)
+	// Store 3 may be on the same host as the others.
+	stores[2] = newStore(3, nil)
+	c.Assert(getRegionLabelIsolationLevel(stores, labels), Equals, 0)
+	// A single replica is isolated.
+	c.Assert(getRegionLabelIsolationLevel(stores[2:], labels), Equals, 1)
+
+	labelLevelStats := NewLabelLevelStatistics()
+	region := core.NewRegionInfo(&metapb.Region{Id: 1}, nil)
+
+	labelLevelStats.Observe(region, stores, labels)
+	c.Assert(labelLevelStats.GetLabelLevelCounts(), DeepEquals, map[string]int{NoneIsolationLevel: 1})
+	labelLevelStats.Observe(region, stores[:2], labels)
+	c.Assert(labelLevelStats.GetLabelLevelCounts(), DeepEquals, map[string]int{NoneIsolationLevel: 0, "zone": 1})
+	c.Assert(labelLevelStats.GetLabelLevelRegions("zone"), HasLen, 1)
+	labelLevelStats.ClearDefunctRegion(1)
+	c.Assert(labelLevelStats.GetLabelLevelCounts(), DeepEquals, map[string]int{NoneIsolationLevel: 0, "zone": 0})
+	c.Assert(labelLevelStats.GetLabelLevelRegions("zone"), HasLen, 0)
+
+	// The same level of the regions with different location labels has
+	// different names.
+	labelLevelStats.Observe(region, stores[:2], labels)
+	labelLevelStats.Observe(core.NewRegionInfo(&metapb.Region{Id: 2}, nil), stores[:2], []string{"host", "zone"})
+	c.Assert(labelLevelStats.labelLevelCounter[1], Equals, 2)
+	c.Assert(labelLevelStats.GetLabelLevelCounts(), DeepEquals, map[string]int{NoneIsolationLevel: 0, "zone": 1, "host": 1})
+}
@@ -56,6 +56,7 @@ type ScheduleOptions interface {
 	IsRemoveExtraReplicaEnabled() bool
 	IsRemoveDownReplicaEnabled() bool
 	IsReplaceOfflineReplicaEnabled() bool
+	IsPlacementRulesEnabled() bool
 
 	GetMaxStoreDownTime() time.Duration
 }
@@ -134,6 +135,7 @@ func (s *storeStatistics) Observe(store *core.StoreInfo, stats *StoresStats) {
 	storeStatusGauge.WithLabelValues(s.namespace, storeAddress, id, "store_available").Set(float64(store.GetAvailable()))
 	storeStatusGauge.WithLabelValues(s.namespace, storeAddress, id, "store_used").Set(float64(store.GetUsedSize()))
 	storeStatusGauge.WithLabelValues(s.namespace, storeAddress, id, "store_capacity").Set(float64(store.GetCapacity()))
+	storeStatusGauge.WithLabelValues(s.namespace, storeAddress, id, "store_snapshot_throughput_bytes").Set(store.GetSnapshotStats().Throughput)
 
 	// Store flows.
 	storeFlowStats := stats.GetRollingStoreStats(store.GetID())
@@ -223,6 +225,7 @@ func (s *storeStatistics) resetStoreStatistics(storeAddress string, id string) {
 	storeStatusGauge.WithLabelValues(s.namespace, storeAddress, id, "store_available").Set(0)
 	storeStatusGauge.WithLabelValues(s.namespace, storeAddress, id, "store_used").Set(0)
 	storeStatusGauge.WithLabelValues(s.namespace, storeAddress, id, "store_capacity").Set(0)
+	storeStatusGauge.WithLabelValues(s.namespace, storeAddress, id, "store_snapshot_throughput_bytes").Set(0)
 }
 
 type storeStatisticsMap struct {
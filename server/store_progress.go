@@ -0,0 +1,244 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errcode"
+	"github.com/pingcap/pd/pkg/typeutil"
+	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/server/schedule"
+	"github.com/pkg/errors"
+)
+
+const (
+	// drainRateWindow is the period of the recent samples which the drain
+	// rate of a store being removed is calculated from.
+	drainRateWindow = 10 * time.Minute
+	// maxBlockedRegions is the max number of the blocked regions reported in
+	// the progress of a store being removed.
+	maxBlockedRegions = 16
+	// maxCheckedRegions is the max number of the regions of a store being
+	// removed checked for being blocked in a round of the background jobs.
+	maxCheckedRegions = 1024
+)
+
+// StoreProgress is the progress of removing a store, which is the number of
+// the regions and leaders moved away from the store since it is set offline.
+type StoreProgress struct {
+	StoreID          uint64    `json:"store_id"`
+	StartTime        time.Time `json:"start_time"`
+	StartRegionCount int       `json:"start_region_count"`
+	StartLeaderCount int       `json:"start_leader_count"`
+	RegionCount      int       `json:"region_count"`
+	LeaderCount      int       `json:"leader_count"`
+	// Progress is the ratio of the regions moved away, from 0 to 1.
+	Progress float64 `json:"progress"`
+	// DrainRate is the number of the regions moved away per second recently.
+	DrainRate float64 `json:"drain_rate"`
+	// ETA is the estimated time left, which is nil if the regions are not
+	// moved away recently.
+	ETA *typeutil.Duration `json:"eta,omitempty"`
+	// BlockedRegions are the regions which have no valid target store to
+	// move to, at most maxBlockedRegions of them are reported. They are found
+	// in the background from at most maxCheckedRegions random regions of the
+	// store, the number of which is CheckedRegionCount.
+	CheckedRegionCount int      `json:"checked_region_count"`
+	BlockedRegionCount int      `json:"blocked_region_count"`
+	BlockedRegions     []uint64 `json:"blocked_regions,omitempty"`
+}
+
+type drainSample struct {
+	time        time.Time
+	regionCount int
+}
+
+type storeDrain struct {
+	startTime        time.Time
+	startRegionCount int
+	startLeaderCount int
+	// samples are the region counts observed in drainRateWindow, the first
+	// is the oldest one.
+	samples []drainSample
+	// The blocked regions found in the last check.
+	checkedRegionCount int
+	blockedRegionCount int
+	blockedRegions     []uint64
+}
+
+// storeProgressTracker tracks the progress of the stores being removed. It is
+// kept in memory, so the progress of a store restarts when it is first
+// observed by a new PD leader.
+type storeProgressTracker struct {
+	sync.RWMutex
+	stores map[uint64]*storeDrain
+}
+
+func newStoreProgressTracker() *storeProgressTracker {
+	return &storeProgressTracker{
+		stores: make(map[uint64]*storeDrain),
+	}
+}
+
+// observe records the region count of a store being removed, which starts
+// tracking the store if it is not tracked yet.
+func (t *storeProgressTracker) observe(storeID uint64, regionCount, leaderCount int, now time.Time) {
+	t.Lock()
+	defer t.Unlock()
+	d, ok := t.stores[storeID]
+	if !ok {
+		d = &storeDrain{
+			startTime:        now,
+			startRegionCount: regionCount,
+			startLeaderCount: leaderCount,
+		}
+		t.stores[storeID] = d
+	}
+	d.samples = append(d.samples, drainSample{time: now, regionCount: regionCount})
+	i := 0
+	for i < len(d.samples)-1 && now.Sub(d.samples[i].time) > drainRateWindow {
+		i++
+	}
+	d.samples = append(d.samples[:0], d.samples[i:]...)
+}
+
+// setBlockedRegions records the blocked regions of a store being removed,
+// which are found from checkedRegionCount regions of the store.
+func (t *storeProgressTracker) setBlockedRegions(storeID uint64, checkedRegionCount, blockedRegionCount int, blockedRegions []uint64) {
+	t.Lock()
+	defer t.Unlock()
+	if d, ok := t.stores[storeID]; ok {
+		d.checkedRegionCount = checkedRegionCount
+		d.blockedRegionCount = blockedRegionCount
+		d.blockedRegions = blockedRegions
+	}
+}
+
+// remove stops tracking a store.
+func (t *storeProgressTracker) remove(storeID uint64) {
+	t.Lock()
+	defer t.Unlock()
+	delete(t.stores, storeID)
+}
+
+// get returns the progress of a store with its current counts, or nil if the
+// store is not tracked.
+func (t *storeProgressTracker) get(storeID uint64, regionCount, leaderCount int, now time.Time) *StoreProgress {
+	t.RLock()
+	defer t.RUnlock()
+	d, ok := t.stores[storeID]
+	if !ok {
+		return nil
+	}
+	p := &StoreProgress{
+		StoreID:          storeID,
+		StartTime:        d.startTime,
+		StartRegionCount: d.startRegionCount,
+		StartLeaderCount: d.startLeaderCount,
+		RegionCount:      regionCount,
+		LeaderCount:      leaderCount,
+
+		CheckedRegionCount: d.checkedRegionCount,
+		BlockedRegionCount: d.blockedRegionCount,
+		BlockedRegions:     append([]uint64(nil), d.blockedRegions...),
+	}
+	switch {
+	case d.startRegionCount == 0:
+		p.Progress = 1
+	case regionCount < d.startRegionCount:
+		p.Progress = float64(d.startRegionCount-regionCount) / float64(d.startRegionCount)
+	}
+	oldest := d.samples[0]
+	if elapsed := now.Sub(oldest.time).Seconds(); elapsed > 0 && oldest.regionCount > regionCount {
+		p.DrainRate = float64(oldest.regionCount-regionCount) / elapsed
+		eta := typeutil.NewDuration(time.Duration(float64(regionCount) / p.DrainRate * float64(time.Second)))
+		p.ETA = &eta
+	}
+	return p
+}
+
+// GetStoreProgress returns the progress of removing a store, which should be
+// offline.
+func (c *RaftCluster) GetStoreProgress(storeID uint64) (*StoreProgress, error) {
+	c.RLock()
+	defer c.RUnlock()
+
+	cluster := c.cachedCluster
+	store := cluster.GetStore(storeID)
+	if store == nil {
+		return nil, core.NewStoreNotFoundErr(storeID)
+	}
+	if !store.IsOffline() {
+		return nil, errcode.NewInvalidInputErr(errors.Errorf("store %d is not being removed", storeID))
+	}
+
+	regionCount, leaderCount, now := cluster.GetStoreRegionCount(storeID), store.GetLeaderCount(), time.Now()
+	progress := c.storeProgress.get(storeID, regionCount, leaderCount, now)
+	if progress == nil {
+		c.storeProgress.observe(storeID, regionCount, leaderCount, now)
+		progress = c.storeProgress.get(storeID, regionCount, leaderCount, now)
+	}
+	return progress, nil
+}
+
+// checkBlockedRegions finds the blocked regions of a store being removed. It
+// runs in the background, and only checks at most maxCheckedRegions random
+// regions of the store, so a large store is not checked in full each time.
+func (c *RaftCluster) checkBlockedRegions(storeID uint64) {
+	var (
+		checked, blocked int
+		blockedRegions   []uint64
+	)
+	regions := c.cachedCluster.getStoreRegions(storeID)
+	rand.Shuffle(len(regions), func(i, j int) {
+		regions[i], regions[j] = regions[j], regions[i]
+	})
+	for _, region := range regions {
+		if checked >= maxCheckedRegions {
+			break
+		}
+		checked++
+		if !c.isRegionBlocked(region, storeID) {
+			continue
+		}
+		blocked++
+		if len(blockedRegions) < maxBlockedRegions {
+			blockedRegions = append(blockedRegions, region.GetID())
+		}
+	}
+	c.storeProgress.setBlockedRegions(storeID, checked, blocked, blockedRegions)
+}
+
+// isRegionBlocked checks if the peer of a region on a store being removed
+// cannot be moved away, as there is no valid target store for it. The target
+// is selected by the rule checker if the placement rules are enabled, or else
+// by the replica checker.
+func (c *RaftCluster) isRegionBlocked(region *core.RegionInfo, storeID uint64) bool {
+	cluster := c.cachedCluster
+	peer := region.GetStorePeer(storeID)
+	if peer == nil || region.GetPendingPeer(peer.GetId()) != nil {
+		return false
+	}
+	if cluster.IsPlacementRulesEnabled() {
+		return !c.coordinator.ruleChecker.HasReplacementStore(region, peer)
+	}
+	if len(region.GetPeers()) > schedule.GetRegionMaxReplicas(cluster, region) {
+		return false
+	}
+	target, _ := c.coordinator.replicaChecker.SelectBestReplacementStore(region, peer, schedule.NewStorageThresholdFilter())
+	return target == 0
+}
@@ -0,0 +1,72 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testStoreProgressSuite{})
+
+type testStoreProgressSuite struct{}
+
+func (s *testStoreProgressSuite) TestTracker(c *C) {
+	t := newStoreProgressTracker()
+	start := time.Now()
+	c.Assert(t.get(1, 100, 10, start), IsNil)
+
+	t.observe(1, 100, 10, start)
+	p := t.get(1, 100, 10, start)
+	c.Assert(p, NotNil)
+	c.Assert(p.StartRegionCount, Equals, 100)
+	c.Assert(p.StartLeaderCount, Equals, 10)
+	c.Assert(p.Progress, Equals, 0.0)
+	c.Assert(p.DrainRate, Equals, 0.0)
+	c.Assert(p.ETA, IsNil)
+
+	// 40 regions are moved away in 40 seconds.
+	now := start.Add(40 * time.Second)
+	t.observe(1, 60, 5, now)
+	p = t.get(1, 60, 5, now)
+	c.Assert(p.StartRegionCount, Equals, 100)
+	c.Assert(p.Progress, Equals, 0.4)
+	c.Assert(p.DrainRate, Equals, 1.0)
+	c.Assert(p.ETA, NotNil)
+	c.Assert(p.ETA.Duration, Equals, time.Minute)
+
+	// The samples out of the window are dropped, so the rate is recent.
+	now = start.Add(drainRateWindow + 30*time.Second)
+	t.observe(1, 50, 5, now)
+	p = t.get(1, 50, 5, now)
+	c.Assert(p.Progress, Equals, 0.5)
+	c.Assert(p.DrainRate, Equals, 10.0/(drainRateWindow-10*time.Second).Seconds())
+
+	// A store without regions is done.
+	t.observe(2, 0, 0, start)
+	c.Assert(t.get(2, 0, 0, start).Progress, Equals, 1.0)
+
+	// The blocked regions found in the background are reported.
+	t.setBlockedRegions(1, 40, 2, []uint64{3, 4})
+	p = t.get(1, 50, 5, now)
+	c.Assert(p.CheckedRegionCount, Equals, 40)
+	c.Assert(p.BlockedRegionCount, Equals, 2)
+	c.Assert(p.BlockedRegions, DeepEquals, []uint64{3, 4})
+	t.setBlockedRegions(3, 1, 1, []uint64{5})
+	c.Assert(t.get(3, 1, 0, now), IsNil)
+
+	t.remove(1)
+	c.Assert(t.get(1, 50, 5, now), IsNil)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	log "github.com/pingcap/log"
+	"github.com/pingcap/pd/pkg/typeutil"
 	"github.com/pkg/errors"
 	"go.etcd.io/etcd/clientv3"
 	"go.uber.org/zap"
@@ -31,6 +32,14 @@ const (
 	updateTimestampStep  = 50 * time.Millisecond
 	updateTimestampGuard = time.Millisecond
 	maxLogical           = int64(1 << 18)
+	// The physical time is advanced before the next update step when the
+	// logical time allocated reaches preAdvanceLogical.
+	preAdvanceLogical = maxLogical / 2
+	// The interval to retry when the logical time is used up, which is short
+	// as the physical time is advanced at once. It is doubled on each retry up
+	// to updateTimestampStep, so the requests can still ride out a slow save of
+	// the timestamp for about maxRetryCount*updateTimestampStep.
+	logicalOverflowRetryInterval = 5 * time.Millisecond
 )
 
 var (
@@ -42,12 +51,85 @@ type atomicObject struct {
 	logical  int64
 }
 
-func (s *Server) getTimestampPath() string {
-	return path.Join(s.rootPath, "timestamp")
+// tsoStats counts the corrections made by the TSO allocator since the PD
+// server starts.
+type tsoStats struct {
+	// logicalOverflow is the number of the requests retried as the logical
+	// time is used up.
+	logicalOverflow int64
+	// preAdvance is the number of the times the physical time is advanced
+	// before the system time as the logical time is going to be used up.
+	preAdvance int64
+	// clockJump is the number of the times the physical time jumps forward
+	// as the update is much later than expected.
+	clockJump int64
+	// clockFallBack is the number of the times the system time is found
+	// behind the allocated physical time.
+	clockFallBack int64
+}
+
+// TSOStatus is the status of the TSO allocator of the PD leader.
+type TSOStatus struct {
+	// Physical and Logical are the current timestamp.
+	Physical time.Time `json:"physical"`
+	Logical  int64     `json:"logical"`
+	// SavedPhysical is the upper bound of the physical time persisted in etcd,
+	// no timestamp is allocated beyond it.
+	SavedPhysical time.Time `json:"saved_physical"`
+	// Window is the time left before the persisted upper bound.
+	Window       typeutil.Duration `json:"window"`
+	SaveInterval typeutil.Duration `json:"save_interval"`
+
+	LogicalOverflowCount int64 `json:"logical_overflow_count"`
+	PreAdvanceCount      int64 `json:"pre_advance_count"`
+	ClockJumpCount       int64 `json:"clock_jump_count"`
+	ClockFallBackCount   int64 `json:"clock_fall_back_count"`
+}
+
+// timestampOracle allocates the timestamps whose physical time is persisted
+// in etcd. It is used by the global allocator of the PD leader and by the
+// local allocators of the zones.
+type timestampOracle struct {
+	client *clientv3.Client
+	// timestampPath is the path to save the upper bound of the physical time.
+	timestampPath string
+	saveInterval  time.Duration
+	// leadershipCmp guards the transactions saving the timestamp, which
+	// should succeed only when the allocator is still the leader.
+	leadershipCmp func() clientv3.Cmp
+	// The counter of the logical time is shifted left by suffixBits, and the
+	// low bits are set to suffix to tell the allocators apart.
+	suffixBits uint
+	suffix     int64
+
+	ts            atomic.Value
+	lastSavedTime atomic.Value
+	stats         tsoStats
+	// updateCh triggers the timestamp update before the next update step.
+	updateCh chan struct{}
+}
+
+func newTimestampOracle(client *clientv3.Client, timestampPath string, saveInterval time.Duration, leadershipCmp func() clientv3.Cmp, suffixBits uint, suffix int64) *timestampOracle {
+	t := &timestampOracle{
+		client:        client,
+		timestampPath: timestampPath,
+		saveInterval:  saveInterval,
+		leadershipCmp: leadershipCmp,
+		suffixBits:    suffixBits,
+		suffix:        suffix,
+		updateCh:      make(chan struct{}, 1),
+	}
+	t.resetTimestamp()
+	return t
+}
+
+// maxCount is the max value of the logical counter before it is shifted.
+func (t *timestampOracle) maxCount() int64 {
+	return maxLogical >> t.suffixBits
 }
 
-func (s *Server) loadTimestamp() (time.Time, error) {
-	data, err := getValue(s.client, s.getTimestampPath())
+func (t *timestampOracle) loadTimestamp() (time.Time, error) {
+	data, err := getValue(t.client, t.timestampPath)
 	if err != nil {
 		return zeroTime, err
 	}
@@ -59,11 +141,10 @@ func (s *Server) loadTimestamp() (time.Time, error) {
 
 // save timestamp, if lastTs is 0, we think the timestamp doesn't exist, so create it,
 // otherwise, update it.
-func (s *Server) saveTimestamp(ts time.Time) error {
+func (t *timestampOracle) saveTimestamp(ts time.Time) error {
 	data := uint64ToBytes(uint64(ts.UnixNano()))
-	key := s.getTimestampPath()
-
-	resp, err := s.leaderTxn().Then(clientv3.OpPut(key, string(data))).Commit()
+	resp, err := newSlowLogTxn(t.client).If(t.leadershipCmp()).
+		Then(clientv3.OpPut(t.timestampPath, string(data))).Commit()
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -71,15 +152,61 @@ func (s *Server) saveTimestamp(ts time.Time) error {
 		return errors.New("save timestamp failed, maybe we lost leader")
 	}
 
-	s.lastSavedTime = ts
+	t.lastSavedTime.Store(ts)
 
 	return nil
 }
 
-func (s *Server) syncTimestamp() error {
+func (t *timestampOracle) getLastSavedTime() time.Time {
+	ts, ok := t.lastSavedTime.Load().(time.Time)
+	if !ok {
+		return zeroTime
+	}
+	return ts
+}
+
+// getStatus returns the status of the allocator, or nil if it is not serving
+// timestamps.
+func (t *timestampOracle) getStatus() *TSOStatus {
+	current, ok := t.ts.Load().(*atomicObject)
+	if !ok || current.physical == zeroTime {
+		return nil
+	}
+	saved := t.getLastSavedTime()
+	return &TSOStatus{
+		Physical:             current.physical,
+		Logical:              atomic.LoadInt64(&current.logical),
+		SavedPhysical:        saved,
+		Window:               typeutil.NewDuration(subTimeByWallClock(saved, current.physical)),
+		SaveInterval:         typeutil.NewDuration(t.saveInterval),
+		LogicalOverflowCount: atomic.LoadInt64(&t.stats.logicalOverflow),
+		PreAdvanceCount:      atomic.LoadInt64(&t.stats.preAdvance),
+		ClockJumpCount:       atomic.LoadInt64(&t.stats.clockJump),
+		ClockFallBackCount:   atomic.LoadInt64(&t.stats.clockFallBack),
+	}
+}
+
+// triggerUpdate asks the loop of the allocator to update the timestamp at
+// once instead of waiting for the next update step.
+func (t *timestampOracle) triggerUpdate() {
+	select {
+	case t.updateCh <- struct{}{}:
+	default:
+	}
+}
+
+// resetTimestamp stops allocating timestamps, which is called when the
+// allocator loses its leadership.
+func (t *timestampOracle) resetTimestamp() {
+	t.ts.Store(&atomicObject{
+		physical: zeroTime,
+	})
+}
+
+func (t *timestampOracle) syncTimestamp() error {
 	tsoCounter.WithLabelValues("sync").Inc()
 
-	last, err := s.loadTimestamp()
+	last, err := t.loadTimestamp()
 	if err != nil {
 		return err
 	}
@@ -93,11 +220,12 @@ func (s *Server) syncTimestamp() error {
 	// the timestamp allocation will start from the saved etcd timestamp temporarily.
 	if subTimeByWallClock(next, last) < updateTimestampGuard {
 		log.Error("system time may be incorrect", zap.Time("last", last), zap.Time("next", next))
+		atomic.AddInt64(&t.stats.clockFallBack, 1)
 		next = last.Add(updateTimestampGuard)
 	}
 
-	save := next.Add(s.cfg.TsoSaveInterval.Duration)
-	if err = s.saveTimestamp(save); err != nil {
+	save := next.Add(t.saveInterval)
+	if err = t.saveTimestamp(save); err != nil {
 		return err
 	}
 
@@ -107,7 +235,7 @@ func (s *Server) syncTimestamp() error {
 	current := &atomicObject{
 		physical: next,
 	}
-	s.ts.Store(current)
+	t.ts.Store(current)
 
 	return nil
 }
@@ -122,8 +250,8 @@ func (s *Server) syncTimestamp() error {
 // 1. The physical time is monotonically increasing.
 // 2. The saved time is monotonically increasing.
 // 3. The physical time is always less than the saved timestamp.
-func (s *Server) updateTimestamp() error {
-	prev := s.ts.Load().(*atomicObject)
+func (t *timestampOracle) updateTimestamp() error {
+	prev := t.ts.Load().(*atomicObject)
 	now := time.Now()
 
 	failpoint.Inject("fallBackUpdate", func() {
@@ -136,10 +264,12 @@ func (s *Server) updateTimestamp() error {
 	if jetLag > 3*updateTimestampStep {
 		log.Warn("clock offset", zap.Duration("jet-lag", jetLag), zap.Time("prev-physical", prev.physical), zap.Time("now", now))
 		tsoCounter.WithLabelValues("slow_save").Inc()
+		atomic.AddInt64(&t.stats.clockJump, 1)
 	}
 
 	if jetLag < 0 {
 		tsoCounter.WithLabelValues("system_time_slow").Inc()
+		atomic.AddInt64(&t.stats.clockFallBack, 1)
 	}
 
 	var next time.Time
@@ -147,10 +277,12 @@ func (s *Server) updateTimestamp() error {
 	// If the system time is greater, it will be synchronized with the system time.
 	if jetLag > updateTimestampGuard {
 		next = now
-	} else if prevLogical > maxLogical/2 {
+	} else if prevLogical >= preAdvanceLogical>>t.suffixBits {
 		// The reason choosing maxLogical/2 here is that it's big enough for common cases.
 		// Because there is enough timestamp can be allocated before next update.
 		log.Warn("the logical time may be not enough", zap.Int64("prev-logical", prevLogical))
+		tsoCounter.WithLabelValues("pre_advance").Inc()
+		atomic.AddInt64(&t.stats.preAdvance, 1)
 		next = prev.physical.Add(time.Millisecond)
 	} else {
 		// It will still use the previous physical time to alloc the timestamp.
@@ -160,9 +292,9 @@ func (s *Server) updateTimestamp() error {
 
 	// It is not safe to increase the physical time to `next`.
 	// The time window needs to be updated and saved to etcd.
-	if subTimeByWallClock(s.lastSavedTime, next) <= updateTimestampGuard {
-		save := next.Add(s.cfg.TsoSaveInterval.Duration)
-		if err := s.saveTimestamp(save); err != nil {
+	if subTimeByWallClock(t.getLastSavedTime(), next) <= updateTimestampGuard {
+		save := next.Add(t.saveInterval)
+		if err := t.saveTimestamp(save); err != nil {
 			return err
 		}
 	}
@@ -172,23 +304,54 @@ func (s *Server) updateTimestamp() error {
 		logical:  0,
 	}
 
-	s.ts.Store(current)
-	metadataGauge.WithLabelValues("tso").Set(float64(next.Unix()))
+	t.ts.Store(current)
+	if t.suffix == 0 {
+		metadataGauge.WithLabelValues("tso").Set(float64(next.Unix()))
+	}
 
 	return nil
 }
 
+// advanceTo advances the physical time to be greater than ts, so the
+// timestamps allocated later are greater than the ones allocated by another
+// allocator before ts. It should be called by the same loop which updates the
+// timestamp.
+func (t *timestampOracle) advanceTo(ts time.Time) error {
+	prev := t.ts.Load().(*atomicObject)
+	if prev.physical == zeroTime || prev.physical.After(ts) {
+		return nil
+	}
+	next := ts.Add(time.Millisecond)
+	if subTimeByWallClock(t.getLastSavedTime(), next) <= updateTimestampGuard {
+		if err := t.saveTimestamp(next.Add(t.saveInterval)); err != nil {
+			return err
+		}
+	}
+	tsoCounter.WithLabelValues("advance").Inc()
+	t.ts.Store(&atomicObject{
+		physical: next,
+	})
+	return nil
+}
+
+// getPhysical returns the current physical time, or zeroTime if the allocator
+// is not serving timestamps.
+func (t *timestampOracle) getPhysical() time.Time {
+	return t.ts.Load().(*atomicObject).physical
+}
+
 const maxRetryCount = 100
 
-func (s *Server) getRespTS(count uint32) (pdpb.Timestamp, error) {
+func (t *timestampOracle) getRespTS(count uint32) (pdpb.Timestamp, error) {
 	var resp pdpb.Timestamp
 
 	if count == 0 {
 		return resp, errors.New("tso count should be positive")
 	}
 
+	retryInterval := logicalOverflowRetryInterval
 	for i := 0; i < maxRetryCount; i++ {
-		current, ok := s.ts.Load().(*atomicObject)
+		current, ok := t.ts.Load().(*atomicObject)
 		if !ok || current.physical == zeroTime {
 			log.Error("we haven't synced timestamp ok, wait and retry", zap.Int("retry-count", i))
 			time.Sleep(200 * time.Millisecond)
@@ -197,15 +360,53 @@ func (s *Server) getRespTS(count uint32) (pdpb.Timestamp, error) {
 
 		resp.Physical = current.physical.UnixNano() / int64(time.Millisecond)
 		resp.Logical = atomic.AddInt64(&current.logical, int64(count))
-		if resp.Logical >= maxLogical {
+		if resp.Logical >= t.maxCount() {
 			log.Error("logical part outside of max logical interval, please check ntp time",
 				zap.Reflect("response", resp),
 				zap.Int("retry-count", i))
 			tsoCounter.WithLabelValues("logical_overflow").Inc()
-			time.Sleep(updateTimestampStep)
+			atomic.AddInt64(&t.stats.logicalOverflow, 1)
+			t.triggerUpdate()
+			time.Sleep(retryInterval)
+			if retryInterval *= 2; retryInterval > updateTimestampStep {
+				retryInterval = updateTimestampStep
+			}
 			continue
 		}
+		// Advance the physical time at once if the request uses the logical
+		// time up to preAdvanceLogical.
+		preAdvance := preAdvanceLogical >> t.suffixBits
+		if resp.Logical >= preAdvance && resp.Logical-int64(count) < preAdvance {
+			t.triggerUpdate()
+		}
+		resp.Logical = resp.Logical<<t.suffixBits | t.suffix
 		return resp, nil
 	}
 	return resp, errors.New("can not get timestamp")
 }
+
+func (s *Server) getTimestampPath() string {
+	return path.Join(s.rootPath, "timestamp")
+}
+
+// tsoSuffixBits returns the bits of the logical time used to tell the TSO
+// allocators apart, which are only used when the local TSO is enabled.
+func (s *Server) tsoSuffixBits() uint {
+	if s.cfg.EnableLocalTSO {
+		return localTSOSuffixBits
+	}
+	return 0
+}
+
+func (s *Server) getRespTS(count uint32) (pdpb.Timestamp, error) {
+	return s.tso.getRespTS(count)
+}
+
+// GetTSOStatus returns the status of the global TSO allocator, or nil if the
+// server is not the leader.
+func (s *Server) GetTSOStatus() *TSOStatus {
+	if s.tso == nil {
+		return nil
+	}
+	return s.tso.getStatus()
+}
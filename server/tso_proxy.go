@@ -0,0 +1,255 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	log "github.com/pingcap/log"
+	"github.com/pingcap/pd/pkg/logutil"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// tsoProxyMetadataKey is set by the client to let a follower forward its
+	// Tso requests to the leader, which should be the same as the one used by
+	// the client.
+	tsoProxyMetadataKey = "pd-tso-proxy"
+	// maxMergeProxyTSORequests is the max number of the requests forwarded in
+	// a batch.
+	maxMergeProxyTSORequests = 10000
+)
+
+type proxyTSORequest struct {
+	count      uint32
+	ts         pdpb.Timestamp
+	suffixBits uint
+	err        error
+	done       chan struct{}
+}
+
+// tsoProxy forwards the Tso requests received by a follower to the leader.
+// The requests of all the streams of the follower are merged into batches and
+// sent over a single stream, and the timestamps of a batch are sliced back to
+// the requests.
+type tsoProxy struct {
+	s        *Server
+	requests chan *proxyTSORequest
+}
+
+func newTSOProxy(s *Server) *tsoProxy {
+	return &tsoProxy{
+		s:        s,
+		requests: make(chan *proxyTSORequest, maxMergeProxyTSORequests),
+	}
+}
+
+// getRespTS forwards a request to the leader and returns the highest
+// timestamp allocated for it, along with the suffix bits used by the leader.
+func (p *tsoProxy) getRespTS(ctx context.Context, count uint32) (pdpb.Timestamp, uint, error) {
+	if count == 0 {
+		return pdpb.Timestamp{}, 0, errors.New("tso count should be positive")
+	}
+	req := &proxyTSORequest{
+		count: count,
+		done:  make(chan struct{}),
+	}
+	select {
+	case p.requests <- req:
+	case <-ctx.Done():
+		return pdpb.Timestamp{}, 0, errors.WithStack(ctx.Err())
+	}
+	select {
+	case <-req.done:
+		return req.ts, req.suffixBits, req.err
+	case <-ctx.Done():
+		return pdpb.Timestamp{}, 0, errors.WithStack(ctx.Err())
+	}
+}
+
+// tsoProxyStream is the stream used to forward the requests to the leader.
+type tsoProxyStream struct {
+	conn   *grpc.ClientConn
+	stream pdpb.PD_TsoClient
+	cancel context.CancelFunc
+}
+
+func (s *tsoProxyStream) close() {
+	s.cancel()
+	s.conn.Close()
+}
+
+func (p *tsoProxy) run() {
+	defer logutil.LogPanic()
+	defer p.s.serverLoopWg.Done()
+
+	var (
+		batch  []*proxyTSORequest
+		stream *tsoProxyStream
+	)
+	defer func() {
+		if stream != nil {
+			stream.close()
+		}
+	}()
+
+	for {
+		select {
+		case first := <-p.requests:
+			batch = append(batch[:0], first)
+			pending := len(p.requests)
+			for i := 0; i < pending; i++ {
+				batch = append(batch, <-p.requests)
+			}
+		case <-p.s.serverLoopCtx.Done():
+			return
+		}
+
+		var err error
+		if stream == nil {
+			stream, err = p.connect()
+		}
+		for err == nil && len(batch) > 0 {
+			n := p.splitBatch(batch)
+			if err = p.forward(stream.stream, batch[:n]); err == nil {
+				batch = batch[n:]
+			}
+		}
+		if err != nil {
+			log.Error("failed to forward tso requests to leader", zap.Error(err))
+			for _, req := range batch {
+				req.err = err
+				close(req.done)
+			}
+			if stream != nil {
+				stream.close()
+				stream = nil
+			}
+		}
+	}
+}
+
+// connect creates the stream to the leader.
+func (p *tsoProxy) connect() (*tsoProxyStream, error) {
+	leader := p.s.GetLeader()
+	if leader == nil || p.s.isSameLeader(leader) || len(leader.GetClientUrls()) == 0 {
+		return nil, errors.New("no leader to forward tso requests")
+	}
+	u, err := url.Parse(leader.GetClientUrls()[0])
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	opt := grpc.WithInsecure()
+	tlsCfg, err := p.s.cfg.Security.ToTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsCfg != nil {
+		opt = grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg))
+	}
+	conn, err := grpc.Dial(u.Host, opt)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ctx, cancel := context.WithCancel(p.s.serverLoopCtx)
+	// The suffix bits used by the leader are handled by the proxy.
+	ctx = metadata.AppendToOutgoingContext(ctx, tsoSuffixBitsSupportedMetadataKey, "true")
+	stream, err := pdpb.NewPDClient(conn).Tso(ctx)
+	if err != nil {
+		cancel()
+		conn.Close()
+		return nil, errors.WithStack(err)
+	}
+	log.Info("forward tso requests to leader", zap.Stringer("leader", leader))
+	return &tsoProxyStream{conn: conn, stream: stream, cancel: cancel}, nil
+}
+
+// splitBatch returns the number of the requests at the head of the batch to be
+// forwarded together. The total count is kept well under the max logical count
+// of the leader, or the leader can never satisfy it.
+func (p *tsoProxy) splitBatch(batch []*proxyTSORequest) int {
+	maxCount := p.s.tso.maxCount() / 2
+	var count int64
+	for i, req := range batch {
+		count += int64(req.count)
+		if count > maxCount && i > 0 {
+			return i
+		}
+	}
+	return len(batch)
+}
+
+// forward sends the requests of a batch to the leader as a single request, and
+// slices the timestamps allocated back to them.
+func (p *tsoProxy) forward(stream pdpb.PD_TsoClient, batch []*proxyTSORequest) error {
+	var count uint32
+	for _, req := range batch {
+		count += req.count
+	}
+	err := stream.Send(&pdpb.TsoRequest{
+		Header: &pdpb.RequestHeader{ClusterId: p.s.clusterID},
+		Count:  count,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if resp.GetCount() != count {
+		return errors.Errorf("tso count in response is %d, but %d is requested", resp.GetCount(), count)
+	}
+	header, err := stream.Header()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	suffixBits, err := parseTSOSuffixBits(header)
+	if err != nil {
+		return err
+	}
+	tsoProxyBatchSize.Observe(float64(len(batch)))
+
+	// The leader returns the highest timestamp, and the timestamps of the
+	// batch are consecutive except for the suffix bits.
+	physical := resp.GetTimestamp().GetPhysical()
+	logical := resp.GetTimestamp().GetLogical() - int64(count)<<suffixBits
+	for _, req := range batch {
+		logical += int64(req.count) << suffixBits
+		req.ts = pdpb.Timestamp{Physical: physical, Logical: logical}
+		req.suffixBits = suffixBits
+		close(req.done)
+	}
+	return nil
+}
+
+// parseTSOSuffixBits parses the suffix bits in the header of a Tso stream.
+func parseTSOSuffixBits(header metadata.MD) (uint, error) {
+	values := header.Get(tsoSuffixBitsMetadataKey)
+	if len(values) == 0 {
+		return 0, nil
+	}
+	bits, err := strconv.ParseUint(values[0], 10, 32)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return uint(bits), nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testTSOProxySuite{})
+
+type testTSOProxySuite struct{}
+
+func (s *testTSOProxySuite) TestSplitBatch(c *C) {
+	svr := &Server{tso: newTimestampOracle(nil, "", 0, nil, localTSOSuffixBits, 0)}
+	p := newTSOProxy(svr)
+	newBatch := func(counts ...uint32) []*proxyTSORequest {
+		var batch []*proxyTSORequest
+		for _, count := range counts {
+			batch = append(batch, &proxyTSORequest{count: count})
+		}
+		return batch
+	}
+	// The max count of the leader is 1<<14, so at most 1<<13 is forwarded.
+	c.Assert(p.splitBatch(newBatch(1, 2, 3)), Equals, 3)
+	c.Assert(p.splitBatch(newBatch(4096, 4096, 1)), Equals, 2)
+	c.Assert(p.splitBatch(newBatch(10000, 10000)), Equals, 1)
+	c.Assert(p.splitBatch(newBatch(1, 10000)), Equals, 1)
+}
@@ -103,6 +103,45 @@ func (s *testTsoSuite) TestTsoCount0(c *C) {
 	c.Assert(err, NotNil)
 }
 
+func (s *testTsoSuite) TestTsoLogicalOverflow(c *C) {
+	// Each request uses up almost all the logical time, so the physical time
+	// is advanced at once and the requests are retried.
+	last := s.testGetTimestamp(c, 1)
+	for i := 0; i < 10; i++ {
+		ts := s.testGetTimestamp(c, int(maxLogical)-1)
+		c.Assert(ts.GetPhysical(), Greater, last.GetPhysical())
+		last = ts
+	}
+	status := s.svr.GetTSOStatus()
+	c.Assert(status, NotNil)
+	c.Assert(status.LogicalOverflowCount, Greater, int64(0))
+	c.Assert(status.SavedPhysical.After(status.Physical), IsTrue)
+}
+
+func (s *testTsoSuite) TestTsoPreAdvance(c *C) {
+	t := newTimestampOracle(nil, "", time.Hour, nil, 0, 0)
+	t.lastSavedTime.Store(time.Now().Add(time.Hour))
+	// The physical time is ahead of the system time, so it is only advanced
+	// when the logical time reaches preAdvanceLogical.
+	physical := time.Now().Add(time.Minute)
+	t.ts.Store(&atomicObject{physical: physical, logical: preAdvanceLogical - 2})
+
+	ts, err := t.getRespTS(1)
+	c.Assert(err, IsNil)
+	c.Assert(ts.GetLogical(), Equals, preAdvanceLogical-1)
+	c.Assert(t.updateTimestamp(), IsNil)
+	c.Assert(t.getPhysical(), Equals, physical)
+
+	// The request reaching preAdvanceLogical triggers an update which
+	// advances the physical time.
+	ts, err = t.getRespTS(1)
+	c.Assert(err, IsNil)
+	c.Assert(ts.GetLogical(), Equals, preAdvanceLogical)
+	c.Assert(t.updateCh, HasLen, 1)
+	c.Assert(t.updateTimestamp(), IsNil)
+	c.Assert(t.getPhysical(), Equals, physical.Add(time.Millisecond))
+}
+
 var _ = Suite(&testTimeFallBackSuite{})
 
 type testTimeFallBackSuite struct {
@@ -172,6 +172,203 @@ func (s *serverTestSuite) TestLeaderTransfer(c *C) {
 	wg.Wait()
 }
 
+func (s *serverTestSuite) TestLocalTSO(c *C) {
+	c.Parallel()
+
+	// Each server is in its own zone, which is named after the server.
+	cluster, err := tests.NewTestCluster(2, func(conf *server.Config) {
+		conf.EnableLocalTSO = true
+		conf.Labels = map[string]string{"zone": conf.Name}
+	})
+	c.Assert(err, IsNil)
+	defer cluster.Destroy()
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+
+	var endpoints []string
+	for _, s := range cluster.GetServers() {
+		endpoints = append(endpoints, s.GetConfig().AdvertiseClientUrls)
+	}
+	cli, err := pd.NewClient(endpoints, pd.SecurityOption{})
+	c.Assert(err, IsNil)
+	defer cli.Close()
+
+	const suffixMask = 1<<4 - 1
+	suffixes := make(map[int64]bool)
+	for _, zone := range []string{"pd1", "pd2"} {
+		var physical, logical int64
+		testutil.WaitUntil(c, func(c *C) bool {
+			physical, logical, err = cli.GetLocalTS(context.TODO(), zone)
+			if err == nil {
+				return true
+			}
+			c.Log(err)
+			cli.(client).ScheduleCheckLeader()
+			return false
+		})
+		suffix := logical & suffixMask
+		c.Assert(suffix, Not(Equals), int64(0))
+		c.Assert(suffixes[suffix], IsFalse)
+		suffixes[suffix] = true
+
+		last := s.makeTS(physical, logical)
+		for i := 0; i < 10; i++ {
+			physical, logical, err = cli.GetLocalTS(context.TODO(), zone)
+			c.Assert(err, IsNil)
+			c.Assert(logical&suffixMask, Equals, suffix)
+			ts := s.makeTS(physical, logical)
+			c.Assert(last, Less, ts)
+			last = ts
+		}
+	}
+
+	// The global timestamps have the suffix 0.
+	_, logical, err := cli.GetTS(context.TODO())
+	c.Assert(err, IsNil)
+	c.Assert(logical&suffixMask, Equals, int64(0))
+
+	// The zones without a local TSO leader fail at once.
+	_, _, err = cli.GetLocalTS(context.TODO(), "pd3")
+	c.Assert(err, ErrorMatches, ".*no local tso leader of zone pd3.*")
+}
+
+func (s *serverTestSuite) TestTSOFollowerProxy(c *C) {
+	c.Parallel()
+
+	cluster, err := tests.NewTestCluster(3)
+	c.Assert(err, IsNil)
+	defer cluster.Destroy()
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+
+	var endpoints []string
+	for _, s := range cluster.GetServers() {
+		endpoints = append(endpoints, s.GetConfig().AdvertiseClientUrls)
+	}
+	var clis []pd.Client
+	for i := 0; i < 2; i++ {
+		cli, err := pd.NewClient(endpoints, pd.SecurityOption{}, pd.WithTSOFollowerProxy())
+		c.Assert(err, IsNil)
+		defer cli.Close()
+		testutil.WaitUntil(c, func(c *C) bool {
+			_, _, err = cli.GetTS(context.TODO())
+			if err == nil {
+				return true
+			}
+			c.Log(err)
+			return false
+		})
+		clis = append(clis, cli)
+	}
+
+	s.checkConcurrentTS(c, clis)
+}
+
+func (s *serverTestSuite) TestTSOBatching(c *C) {
+	c.Parallel()
+
+	cluster, err := tests.NewTestCluster(1)
+	c.Assert(err, IsNil)
+	defer cluster.Destroy()
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+
+	endpoints := []string{cluster.GetServer(cluster.GetLeader()).GetConfig().AdvertiseClientUrls}
+	cli, err := pd.NewClient(endpoints, pd.SecurityOption{},
+		pd.WithTSOMaxBatchWaitInterval(time.Millisecond), pd.WithTSOParallelStreams(3))
+	c.Assert(err, IsNil)
+	defer cli.Close()
+	testutil.WaitUntil(c, func(c *C) bool {
+		_, _, err = cli.GetTS(context.TODO())
+		if err == nil {
+			return true
+		}
+		c.Log(err)
+		return false
+	})
+
+	s.checkConcurrentTS(c, []pd.Client{cli})
+}
+
+func (s *serverTestSuite) TestFollowerRead(c *C) {
+	c.Parallel()
+
+	cluster, err := tests.NewTestCluster(3, func(conf *server.Config) {
+		conf.PDServerCfg.UseRegionStorage = true
+		conf.EnableFollowerRead = true
+	})
+	c.Assert(err, IsNil)
+	defer cluster.Destroy()
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	leaderServer := cluster.GetServer(cluster.WaitLeader())
+	c.Assert(leaderServer.BootstrapCluster(), IsNil)
+
+	var endpoints []string
+	for _, s := range cluster.GetServers() {
+		endpoints = append(endpoints, s.GetConfig().AdvertiseClientUrls)
+	}
+	cli, err := pd.NewClient(endpoints, pd.SecurityOption{}, pd.WithFollowerRead(time.Minute))
+	c.Assert(err, IsNil)
+	defer cli.Close()
+
+	// The lookups are answered by either the followers or the leader.
+	testutil.WaitUntil(c, func(c *C) bool {
+		region, _, err := cli.GetRegion(context.TODO(), []byte("a"))
+		if err != nil {
+			c.Log(err)
+			return false
+		}
+		return region.GetId() == 2
+	})
+	regions, _, err := cli.ScanRegions(context.TODO(), []byte(""), 10)
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 1)
+	c.Assert(regions[0].GetId(), Equals, uint64(2))
+	store, err := cli.GetStore(context.TODO(), 1)
+	c.Assert(err, IsNil)
+	c.Assert(store.GetAddress(), Equals, "mock://1")
+}
+
+// checkConcurrentTS checks the timestamps are increasing for each caller, and
+// unique among all of them.
+func (s *serverTestSuite) checkConcurrentTS(c *C, clis []pd.Client) {
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		tss = make(map[uint64]bool)
+	)
+	for _, cli := range clis {
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(cli pd.Client) {
+				defer wg.Done()
+				var last uint64
+				for j := 0; j < 100; j++ {
+					physical, logical, err := cli.GetTS(context.TODO())
+					c.Assert(err, IsNil)
+					ts := s.makeTS(physical, logical)
+					c.Assert(last, Less, ts)
+					last = ts
+
+					mu.Lock()
+					c.Assert(tss[ts], IsFalse)
+					tss[ts] = true
+					mu.Unlock()
+				}
+			}(cli)
+		}
+	}
+	wg.Wait()
+}
+
 func (s *serverTestSuite) waitLeader(c *C, cli client, leader string) {
 	testutil.WaitUntil(c, func(c *C) bool {
 		cli.ScheduleCheckLeader()
@@ -21,6 +21,7 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/core"
 	"github.com/pingcap/pd/tests"
 	"github.com/pingcap/pd/tests/pdctl"
 )
@@ -190,3 +191,59 @@ func (s *configTestSuite) TestConfig(c *C) {
 	scheduleCfg = cfg.Schedule
 	c.Assert(scheduleCfg.DisableLearner, Equals, svr.GetScheduleConfig().DisableLearner)
 }
+
+func (s *configTestSuite) TestRangeReplication(c *C) {
+	c.Parallel()
+
+	cluster, err := tests.NewTestCluster(1)
+	c.Assert(err, IsNil)
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+	pdAddr := cluster.GetConfig().GetClientURLs()
+	cmd := pdctl.InitCommand()
+
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	c.Assert(leaderServer.BootstrapCluster(), IsNil)
+	svr := leaderServer.GetServer()
+	pdctl.MustPutStore(c, svr, 1, metapb.StoreState_Up, nil)
+	defer cluster.Destroy()
+
+	// config set replication <option> <value> --range=<id>
+	args := []string{"-u", pdAddr, "config", "set", "replication", "max-replicas", "5", "--range=foo", "--start-key=7480", "--end-key=7481"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	args = []string{"-u", pdAddr, "config", "set", "replication", "location-labels", "zone,host", "--range=foo"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	r := svr.GetRaftCluster().GetRangeReplication("foo")
+	c.Assert(r, NotNil)
+	c.Assert(r.StartKeyHex, Equals, "7480")
+	c.Assert(r.EndKeyHex, Equals, "7481")
+	c.Assert(r.MaxReplicas, Equals, uint64(5))
+	c.Assert(r.LocationLabels, DeepEquals, []string{"zone", "host"})
+	// The cluster-wide config is not changed.
+	c.Assert(svr.GetReplicationConfig().MaxReplicas, Equals, uint64(3))
+
+	// config show replication --range=<id>
+	args = []string{"-u", pdAddr, "config", "show", "replication", "--range=foo"}
+	_, output, err := pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	got := core.RangeReplication{}
+	c.Assert(json.Unmarshal(output, &got), IsNil)
+	c.Assert(got.MaxReplicas, Equals, uint64(5))
+
+	// config show replication-ranges
+	args = []string{"-u", pdAddr, "config", "show", "replication-ranges"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	var ranges []*core.RangeReplication
+	c.Assert(json.Unmarshal(output, &ranges), IsNil)
+	c.Assert(ranges, HasLen, 1)
+
+	// config delete replication --range=<id>
+	args = []string{"-u", pdAddr, "config", "delete", "replication", "--range=foo"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(svr.GetRaftCluster().GetRangeReplication("foo"), IsNil)
+}
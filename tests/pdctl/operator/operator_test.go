@@ -14,6 +14,8 @@
 package operator_test
 
 import (
+	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -183,6 +185,45 @@ func (s *operatorTestSuite) TestOperator(c *C) {
 	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
 	c.Assert(err, IsNil)
 
+	// operator history [--region=<region_id>] [--limit=<limit>]
+	args = []string{"-u", pdAddr, "operator", "history", "--region=3", "--limit=1"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "merge region 1 into region 3"), IsTrue)
+	c.Assert(strings.Contains(string(output), "CANCEL"), IsTrue)
+
+	// operator queue
+	args = []string{"-u", pdAddr, "operator", "queue"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), `"class": "urgent"`), IsTrue)
+
+	// operator add-batch <file>
+	f, err := ioutil.TempFile("", "operators")
+	c.Assert(err, IsNil)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(`[
+		{"name": "transfer-leader", "region_id": 1, "to_store_id": 2},
+		{"name": "split-region", "region_id": 3, "policy": "scan"}
+	]`)
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+	args = []string{"-u", pdAddr, "operator", "add-batch", f.Name()}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "Success!"), IsTrue)
+	args = []string{"-u", pdAddr, "operator", "show"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "transfer leader from store 1 to store 2"), IsTrue)
+	c.Assert(strings.Contains(string(output), "split region with policy SCAN"), IsTrue)
+	args = []string{"-u", pdAddr, "operator", "remove", "1"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	args = []string{"-u", pdAddr, "operator", "remove", "3"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+
 	// operator add scatter-region <region_id>
 	args = []string{"-u", pdAddr, "operator", "add", "scatter-region", "3"}
 	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
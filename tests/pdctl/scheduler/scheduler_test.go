@@ -107,7 +107,7 @@ func (s *schedulerTestSuite) TestScheduler(c *C) {
 		"balance-leader-scheduler":     true,
 		"balance-hot-region-scheduler": true,
 		"label-scheduler":              true,
-		"grant-leader-scheduler-1":     true,
+		"grant-leader-scheduler":       true,
 	}
 	for _, scheduler := range schedulers {
 		c.Assert(expected[scheduler], Equals, true)
@@ -126,9 +126,29 @@ func (s *schedulerTestSuite) TestScheduler(c *C) {
 		"balance-leader-scheduler":     true,
 		"balance-hot-region-scheduler": true,
 		"label-scheduler":              true,
-		"grant-leader-scheduler-1":     true,
+		"grant-leader-scheduler":       true,
 	}
 	for _, scheduler := range schedulers {
 		c.Assert(expected[scheduler], Equals, true)
 	}
+
+	// scheduler pause and resume command
+	args = []string{"-u", pdAddr, "scheduler", "pause", "balance-leader-scheduler", "60"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	args = []string{"-u", pdAddr, "scheduler", "show", "--status", "paused"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	schedulers = schedulers[:0]
+	c.Assert(json.Unmarshal(output, &schedulers), IsNil)
+	c.Assert(schedulers, DeepEquals, []string{"balance-leader-scheduler"})
+	args = []string{"-u", pdAddr, "scheduler", "resume", "balance-leader-scheduler"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	args = []string{"-u", pdAddr, "scheduler", "show", "--status", "paused"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	schedulers = schedulers[:0]
+	c.Assert(json.Unmarshal(output, &schedulers), IsNil)
+	c.Assert(schedulers, HasLen, 0)
 }
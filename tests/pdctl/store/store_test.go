@@ -16,11 +16,13 @@ package store_test
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/pd/server"
 	"github.com/pingcap/pd/server/api"
+	"github.com/pingcap/pd/server/schedule"
 	"github.com/pingcap/pd/tests"
 	"github.com/pingcap/pd/tests/pdctl"
 )
@@ -119,6 +121,52 @@ func (s *storeTestSuite) TestStore(c *C) {
 	c.Assert(storeInfo.Status.LeaderWeight, Equals, float64(5))
 	c.Assert(storeInfo.Status.RegionWeight, Equals, float64(10))
 
+	// store maintenance <store_id> <duration> command
+	c.Assert(storeInfo.Status.MaintenanceDeadline, IsNil)
+	args = []string{"-u", pdAddr, "store", "maintenance", "1", "1h"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	args = []string{"-u", pdAddr, "store", "1"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(json.Unmarshal(output, &storeInfo), IsNil)
+	c.Assert(storeInfo.Status.MaintenanceDeadline, NotNil)
+	c.Assert(storeInfo.Status.MaintenanceDeadline.After(time.Now()), IsTrue)
+	// store maintenance delete <store_id> command
+	args = []string{"-u", pdAddr, "store", "maintenance", "delete", "1"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	args = []string{"-u", pdAddr, "store", "1"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	storeInfo = new(api.StoreInfo)
+	c.Assert(json.Unmarshal(output, &storeInfo), IsNil)
+	c.Assert(storeInfo.Status.MaintenanceDeadline, IsNil)
+
+	// store limit <store_id> <rate> command
+	args = []string{"-u", pdAddr, "store", "limit", "1", "30", "--type=remove-peer"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	// stores set limit <rate> <label_key> <label_value> command
+	args = []string{"-u", pdAddr, "stores", "set", "limit", "120", "zone", "cn", "--type=add-peer"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	// stores show limit command
+	args = []string{"-u", pdAddr, "stores", "show", "limit"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	limits := make(map[uint64]struct {
+		Rate  float64                               `json:"rate"`
+		Types map[string]*schedule.StoreLimitStatus `json:"types"`
+	})
+	c.Assert(json.Unmarshal(output, &limits), IsNil)
+	c.Assert(limits[1].Rate, Equals, float64(30))
+	c.Assert(limits[1].Types["remove-peer"].Rate, Equals, float64(30))
+	c.Assert(limits[1].Types["remove-peer"].Scope, Equals, schedule.StoreLimitScopeStore)
+	c.Assert(limits[1].Types["add-peer"].Rate, Equals, float64(120))
+	c.Assert(limits[1].Types["add-peer"].Scope, Equals, "zone=cn")
+	c.Assert(limits[3].Types["add-peer"].Scope, Equals, schedule.StoreLimitScopeDefault)
+
 	// store delete <store_id> command
 	c.Assert(storeInfo.Store.State, Equals, metapb.StoreState_Up)
 	args = []string{"-u", pdAddr, "store", "delete", "1"}
@@ -130,6 +178,14 @@ func (s *storeTestSuite) TestStore(c *C) {
 	c.Assert(json.Unmarshal(output, &storeInfo), IsNil)
 	c.Assert(storeInfo.Store.State, Equals, metapb.StoreState_Offline)
 
+	// store progress <store_id> command
+	args = []string{"-u", pdAddr, "store", "progress", "1"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	progress := new(server.StoreProgress)
+	c.Assert(json.Unmarshal(output, progress), IsNil)
+	c.Assert(progress.StoreID, Equals, uint64(1))
+
 	// store delete addr <address>
 	args = []string{"-u", pdAddr, "store", "delete", "addr", "tikv3"}
 	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
@@ -15,15 +15,19 @@ package syncer_test
 
 import (
 	"context"
+	"net/url"
 	"testing"
 	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
-
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/pkg/testutil"
 	"github.com/pingcap/pd/server"
 	"github.com/pingcap/pd/server/core"
 	"github.com/pingcap/pd/tests"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 func Test(t *testing.T) {
@@ -148,3 +152,84 @@ func (s *serverTestSuite) TestFullSyncWithAddMember(c *C) {
 	loadRegions := pd2.GetServer().GetRaftCluster().GetRegions()
 	c.Assert(len(loadRegions), Equals, regionLen)
 }
+
+func (s *serverTestSuite) TestFollowerRead(c *C) {
+	c.Parallel()
+	cluster, err := tests.NewTestCluster(2, func(conf *server.Config) {
+		conf.PDServerCfg.UseRegionStorage = true
+		conf.EnableFollowerRead = true
+	})
+	c.Assert(err, IsNil)
+	defer cluster.Destroy()
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	leader := cluster.WaitLeader()
+	leaderServer := cluster.GetServer(leader)
+	c.Assert(leaderServer.BootstrapCluster(), IsNil)
+	rc := leaderServer.GetServer().GetRaftCluster()
+	c.Assert(rc, NotNil)
+	region := &metapb.Region{
+		Id:          10,
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+		StartKey:    []byte("a"),
+		EndKey:      []byte("b"),
+		Peers:       []*metapb.Peer{{Id: 11, StoreId: 1}},
+	}
+	c.Assert(rc.HandleRegionHeartbeat(core.NewRegionInfo(region, region.Peers[0])), IsNil)
+
+	var follower *tests.TestServer
+	for name, s := range cluster.GetServers() {
+		if name != leader {
+			follower = s
+		}
+	}
+	u, err := url.Parse(follower.GetConfig().ClientUrls)
+	c.Assert(err, IsNil)
+	conn, err := grpc.Dial(u.Host, grpc.WithInsecure())
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	cli := pdpb.NewPDClient(conn)
+	header := &pdpb.RequestHeader{ClusterId: follower.GetClusterID()}
+	getRegion := func(maxStaleness string, key string) (*pdpb.GetRegionResponse, metadata.MD, error) {
+		ctx := context.Background()
+		if maxStaleness != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "pd-follower-read", maxStaleness)
+		}
+		var md metadata.MD
+		resp, err := cli.GetRegion(ctx, &pdpb.GetRegionRequest{Header: header, RegionKey: []byte(key)}, grpc.Header(&md))
+		return resp, md, err
+	}
+
+	// The follower answers with the synchronized region and its staleness.
+	testutil.WaitUntil(c, func(c *C) bool {
+		resp, md, err := getRegion("1m", "a")
+		if err != nil || resp.GetRegion() == nil {
+			return false
+		}
+		c.Assert(resp.GetRegion().GetId(), Equals, uint64(10))
+		c.Assert(resp.GetLeader(), IsNil)
+		c.Assert(md.Get("pd-follower-read-staleness"), HasLen, 1)
+		return true
+	})
+	resp, err := cli.ScanRegions(metadata.AppendToOutgoingContext(context.Background(), "pd-follower-read", "1m"),
+		&pdpb.ScanRegionsRequest{Header: header, StartKey: []byte(""), Limit: 10})
+	c.Assert(err, IsNil)
+	c.Assert(resp.GetRegions(), HasLen, 1)
+	c.Assert(resp.GetRegions()[0].GetId(), Equals, uint64(10))
+	store, err := cli.GetStore(metadata.AppendToOutgoingContext(context.Background(), "pd-follower-read", "1m"),
+		&pdpb.GetStoreRequest{Header: header, StoreId: 1})
+	c.Assert(err, IsNil)
+	c.Assert(store.GetStore().GetAddress(), Equals, "mock://1")
+
+	// The follower refuses to answer if the regions are too stale or the
+	// follower read is not requested.
+	_, _, err = getRegion("1ns", "a")
+	c.Assert(err, NotNil)
+	_, _, err = getRegion("", "a")
+	c.Assert(err, NotNil)
+	// The follower refuses to answer if the region is not found, so the client
+	// asks the leader instead.
+	_, _, err = getRegion("1m", "c")
+	c.Assert(err, NotNil)
+}
@@ -19,6 +19,7 @@ import (
 	"net/http"
 	"path"
 	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -27,6 +28,7 @@ var (
 	configPrefix         = "pd/api/v1/config"
 	schedulePrefix       = "pd/api/v1/config/schedule"
 	replicationPrefix    = "pd/api/v1/config/replicate"
+	rangeReplicaPrefix   = "pd/api/v1/config/replicate/ranges"
 	namespacePrefix      = "pd/api/v1/config/namespace"
 	labelPropertyPrefix  = "pd/api/v1/config/label-property"
 	clusterVersionPrefix = "pd/api/v1/config/cluster-version"
@@ -47,13 +49,14 @@ func NewConfigCommand() *cobra.Command {
 // NewShowConfigCommand return a show subcommand of configCmd
 func NewShowConfigCommand() *cobra.Command {
 	sc := &cobra.Command{
-		Use:   "show [namespace|replication|label-property|all]",
+		Use:   "show [namespace|replication|replication-ranges|label-property|all]",
 		Short: "show replication and schedule config of PD",
 		Run:   showConfigCommandFunc,
 	}
 	sc.AddCommand(NewShowAllConfigCommand())
 	sc.AddCommand(NewShowNamespaceConfigCommand())
 	sc.AddCommand(NewShowReplicationConfigCommand())
+	sc.AddCommand(NewShowReplicationRangesCommand())
 	sc.AddCommand(NewShowLabelPropertyCommand())
 	sc.AddCommand(NewShowClusterVersionCommand())
 	return sc
@@ -82,10 +85,21 @@ func NewShowAllConfigCommand() *cobra.Command {
 // NewShowReplicationConfigCommand return a show all subcommand of show subcommand
 func NewShowReplicationConfigCommand() *cobra.Command {
 	sc := &cobra.Command{
-		Use:   "replication",
-		Short: "show replication config of PD",
+		Use:   "replication [--range=<id>]",
+		Short: "show replication config of PD, or the replication config overridden for a key range",
 		Run:   showReplicationConfigCommandFunc,
 	}
+	sc.Flags().String("range", "", "the ID of the key range")
+	return sc
+}
+
+// NewShowReplicationRangesCommand returns a replication ranges subcommand of show subcommand.
+func NewShowReplicationRangesCommand() *cobra.Command {
+	sc := &cobra.Command{
+		Use:   "replication-ranges",
+		Short: "show the replication config overridden for all key ranges",
+		Run:   showReplicationRangesCommandFunc,
+	}
 	return sc
 }
 
@@ -112,11 +126,12 @@ func NewShowClusterVersionCommand() *cobra.Command {
 // NewSetConfigCommand return a set subcommand of configCmd
 func NewSetConfigCommand() *cobra.Command {
 	sc := &cobra.Command{
-		Use:   "set <option> <value>, set namespace <name> <option> <value>, set label-property <type> <key> <value>, set cluster-version <version>",
+		Use:   "set <option> <value>, set namespace <name> <option> <value>, set replication <option> <value> [--range=<id>], set label-property <type> <key> <value>, set cluster-version <version>",
 		Short: "set the option with value",
 		Run:   setConfigCommandFunc,
 	}
 	sc.AddCommand(NewSetNamespaceConfigCommand())
+	sc.AddCommand(NewSetReplicationConfigCommand())
 	sc.AddCommand(NewSetLabelPropertyCommand())
 	sc.AddCommand(NewSetClusterVersionCommand())
 	return sc
@@ -132,6 +147,19 @@ func NewSetNamespaceConfigCommand() *cobra.Command {
 	return sc
 }
 
+// NewSetReplicationConfigCommand creates a set subcommand of set subcommand
+func NewSetReplicationConfigCommand() *cobra.Command {
+	sc := &cobra.Command{
+		Use:   "replication <max-replicas|location-labels> <value> [--range=<id> [--start-key=<hex>] [--end-key=<hex>]]",
+		Short: "set the replication config's option with value, for the cluster or a key range",
+		Run:   setReplicationConfigCommandFunc,
+	}
+	sc.Flags().String("range", "", "the ID of the key range to override the replication config for")
+	sc.Flags().String("start-key", "", "the hex-encoded start key of the key range")
+	sc.Flags().String("end-key", "", "the hex-encoded end key of the key range, empty means +inf")
+	return sc
+}
+
 // NewSetLabelPropertyCommand creates a set subcommand of set subcommand
 func NewSetLabelPropertyCommand() *cobra.Command {
 	sc := &cobra.Command{
@@ -155,10 +183,11 @@ func NewSetClusterVersionCommand() *cobra.Command {
 // NewDeleteConfigCommand a set subcommand of cfgCmd
 func NewDeleteConfigCommand() *cobra.Command {
 	sc := &cobra.Command{
-		Use:   "delete namespace|label-property",
+		Use:   "delete namespace|replication|label-property",
 		Short: "delete the config option",
 	}
 	sc.AddCommand(NewDeleteNamespaceConfigCommand())
+	sc.AddCommand(NewDeleteReplicationConfigCommand())
 	sc.AddCommand(NewDeleteLabelPropertyConfigCommand())
 	return sc
 }
@@ -173,6 +202,17 @@ func NewDeleteNamespaceConfigCommand() *cobra.Command {
 	return sc
 }
 
+// NewDeleteReplicationConfigCommand a delete subcommand of delete subcommand.
+func NewDeleteReplicationConfigCommand() *cobra.Command {
+	sc := &cobra.Command{
+		Use:   "replication --range=<id>",
+		Short: "delete the replication config overridden for a key range",
+		Run:   deleteReplicationConfigCommandFunc,
+	}
+	sc.Flags().String("range", "", "the ID of the key range")
+	return sc
+}
+
 // NewDeleteLabelPropertyConfigCommand a set subcommand of delete subcommand.
 func NewDeleteLabelPropertyConfigCommand() *cobra.Command {
 	sc := &cobra.Command{
@@ -209,7 +249,20 @@ func showConfigCommandFunc(cmd *cobra.Command, args []string) {
 }
 
 func showReplicationConfigCommandFunc(cmd *cobra.Command, args []string) {
-	r, err := doRequest(cmd, replicationPrefix, http.MethodGet)
+	prefix := replicationPrefix
+	if id, _ := cmd.Flags().GetString("range"); id != "" {
+		prefix = path.Join(rangeReplicaPrefix, id)
+	}
+	r, err := doRequest(cmd, prefix, http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get config: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func showReplicationRangesCommandFunc(cmd *cobra.Command, args []string) {
+	r, err := doRequest(cmd, rangeReplicaPrefix, http.MethodGet)
 	if err != nil {
 		cmd.Printf("Failed to get config: %s\n", err)
 		return
@@ -310,6 +363,81 @@ func setNamespaceConfigCommandFunc(cmd *cobra.Command, args []string) {
 	cmd.Println("Success!")
 }
 
+func setReplicationConfigCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	opt, val := args[0], args[1]
+	id, _ := cmd.Flags().GetString("range")
+	if id == "" {
+		if err := postConfigDataWithPath(cmd, opt, val, replicationPrefix); err != nil {
+			cmd.Printf("Failed to set config: %s\n", err)
+			return
+		}
+		cmd.Println("Success!")
+		return
+	}
+
+	// Update the existing override of the key range, or create a new one.
+	r, err := doRequest(cmd, rangeReplicaPrefix, http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get config: %s\n", err)
+		return
+	}
+	var ranges []map[string]interface{}
+	if err = json.Unmarshal([]byte(r), &ranges); err != nil {
+		cmd.Printf("Failed to unmarshal config: %s\n", err)
+		return
+	}
+	input := map[string]interface{}{"id": id}
+	for _, rr := range ranges {
+		if rr["id"] == id {
+			input = rr
+			break
+		}
+	}
+	if cmd.Flags().Changed("start-key") {
+		input["start_key"], _ = cmd.Flags().GetString("start-key")
+	}
+	if cmd.Flags().Changed("end-key") {
+		input["end_key"], _ = cmd.Flags().GetString("end-key")
+	}
+	switch opt {
+	case "max-replicas":
+		n, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			cmd.Printf("Failed to parse max-replicas: %s\n", err)
+			return
+		}
+		input["max_replicas"] = n
+	case "location-labels":
+		labels := []string{}
+		if val != "" {
+			labels = strings.Split(val, ",")
+		}
+		input["location_labels"] = labels
+	default:
+		cmd.Printf("Unknown replication option %s for a key range\n", opt)
+		return
+	}
+	postJSON(cmd, rangeReplicaPrefix, input)
+}
+
+func deleteReplicationConfigCommandFunc(cmd *cobra.Command, args []string) {
+	id, _ := cmd.Flags().GetString("range")
+	if len(args) != 0 || id == "" {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	_, err := doRequest(cmd, path.Join(rangeReplicaPrefix, id), http.MethodDelete)
+	if err != nil {
+		cmd.Printf("Failed to delete replication config of range %s: %s\n", id, err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
 func deleteNamespaceConfigCommandFunc(cmd *cobra.Command, args []string) {
 	if len(args) != 1 && len(args) != 2 {
 		cmd.Println(cmd.UsageString())
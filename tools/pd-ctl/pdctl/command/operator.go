@@ -14,8 +14,13 @@
 package command
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/pkg/errors"
@@ -35,7 +40,12 @@ func NewOperatorCommand() *cobra.Command {
 	c.AddCommand(NewShowOperatorCommand())
 	c.AddCommand(NewCheckOperatorCommand())
 	c.AddCommand(NewAddOperatorCommand())
+	c.AddCommand(NewAddOperatorBatchCommand())
 	c.AddCommand(NewRemoveOperatorCommand())
+	c.AddCommand(NewDryRunOperatorCommand())
+	c.AddCommand(NewOperatorHistoryCommand())
+	c.AddCommand(NewOperatorQueueCommand())
+	c.AddCommand(NewOperatorEventsCommand())
 	return c
 }
 
@@ -386,6 +396,43 @@ func scatterRegionCommandFunc(cmd *cobra.Command, args []string) {
 	postJSON(cmd, operatorsPrefix, input)
 }
 
+// NewAddOperatorBatchCommand returns a command to add a batch of operators.
+func NewAddOperatorBatchCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "add-batch <file>",
+		Short: "add the operators in the JSON file atomically, which means none of them is added if any of them is rejected",
+		Run:   addOperatorBatchCommandFunc,
+	}
+	return c
+}
+
+func addOperatorBatchCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	data, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	var input []map[string]interface{}
+	if err = json.Unmarshal(data, &input); err != nil {
+		cmd.Printf("the file should contain an array of operators: %v\n", err)
+		return
+	}
+	req, err := getRequest(cmd, operatorsPrefix+"/batch", http.MethodPost, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	if _, err = dail(req); err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
 // NewRemoveOperatorCommand returns a command to remove operators.
 func NewRemoveOperatorCommand() *cobra.Command {
 	c := &cobra.Command{
@@ -410,6 +457,165 @@ func removeOperatorCommandFunc(cmd *cobra.Command, args []string) {
 	}
 }
 
+// NewDryRunOperatorCommand returns a command to show the operators recorded in dry-run mode.
+func NewDryRunOperatorCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "dry-run [clear]",
+		Short: "show or clear the operators recorded in dry-run mode",
+		Run:   dryRunOperatorCommandFunc,
+	}
+	return c
+}
+
+func dryRunOperatorCommandFunc(cmd *cobra.Command, args []string) {
+	path := operatorsPrefix + "/dry-run"
+	switch {
+	case len(args) == 0:
+		r, err := doRequest(cmd, path, http.MethodGet)
+		if err != nil {
+			cmd.Println(err)
+			return
+		}
+		cmd.Println(r)
+	case len(args) == 1 && args[0] == "clear":
+		_, err := doRequest(cmd, path, http.MethodDelete)
+		if err != nil {
+			cmd.Println(err)
+			return
+		}
+		cmd.Println("Success!")
+	default:
+		cmd.Println(cmd.UsageString())
+	}
+}
+
+// NewOperatorHistoryCommand returns a command to show the records of the finished operators.
+func NewOperatorHistoryCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "history [--region=<region_id>] [--store=<store_id>] [--kind=<kind>] [--start=<timestamp>] [--end=<timestamp>] [--limit=<limit>]",
+		Short: "show the records of the finished operators",
+		Run:   operatorHistoryCommandFunc,
+	}
+	c.Flags().String("region", "", "only show the operators of the region")
+	c.Flags().String("store", "", "only show the operators involving the store")
+	c.Flags().String("kind", "", "only show the operators of the kind, such as leader,balance")
+	c.Flags().String("start", "", "only show the operators finished since the unix timestamp")
+	c.Flags().String("end", "", "only show the operators finished before the unix timestamp")
+	c.Flags().String("limit", "", "the max number of the latest operators to show")
+	return c
+}
+
+func operatorHistoryCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	query := url.Values{}
+	for flag, param := range map[string]string{
+		"region": "region_id",
+		"store":  "store_id",
+		"kind":   "kind",
+		"start":  "start",
+		"end":    "end",
+		"limit":  "limit",
+	} {
+		if v, _ := cmd.Flags().GetString(flag); v != "" {
+			query.Set(param, v)
+		}
+	}
+	path := operatorsPrefix + "/history"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	r, err := doRequest(cmd, path, http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(r)
+}
+
+// NewOperatorQueueCommand returns a command to show the waiting operators of each priority class.
+func NewOperatorQueueCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "queue",
+		Short: "show the waiting operators of each priority class",
+		Run:   operatorQueueCommandFunc,
+	}
+	return c
+}
+
+func operatorQueueCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	r, err := doRequest(cmd, operatorsPrefix+"/queue", http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(r)
+}
+
+// NewOperatorEventsCommand returns a command to watch the operator events.
+func NewOperatorEventsCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "events [--kind=<kind>] [--store=<store_id>] [--start-key=<key>] [--end-key=<key>]",
+		Short: "watch the events of the operators",
+		Run:   operatorEventsCommandFunc,
+	}
+	c.Flags().String("kind", "", "only watch the operators of the kind, such as leader,balance")
+	c.Flags().String("store", "", "only watch the operators involving the store")
+	c.Flags().String("start-key", "", "only watch the operators of the regions overlapping with the key range starting from the key")
+	c.Flags().String("end-key", "", "only watch the operators of the regions overlapping with the key range ending at the key")
+	return c
+}
+
+func operatorEventsCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	query := url.Values{}
+	for flag, param := range map[string]string{
+		"kind":      "kind",
+		"store":     "store_id",
+		"start-key": "start_key",
+		"end-key":   "end_key",
+	} {
+		if v, _ := cmd.Flags().GetString(flag); v != "" {
+			query.Set(param, v)
+		}
+	}
+	path := operatorsPrefix + "/events"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	req, err := getRequest(cmd, path, http.MethodGet, "", nil)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	resp, err := dialClient.Do(req)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		cmd.Println(genResponseError(resp))
+		return
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		cmd.Println(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		cmd.Println(err)
+	}
+}
+
 func parseUint64s(args []string) ([]uint64, error) {
 	results := make([]uint64, 0, len(args))
 	for _, arg := range args {
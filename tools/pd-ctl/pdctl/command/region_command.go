@@ -414,7 +414,7 @@ func showRegionsFromStartKeyCommandFunc(cmd *cobra.Command, args []string) {
 // NewRegionWithCheckCommand returns a region with check subcommand of regionCmd
 func NewRegionWithCheckCommand() *cobra.Command {
 	r := &cobra.Command{
-		Use:   "check [miss-peer|extra-peer|down-peer|pending-peer|incorrect-ns]",
+		Use:   "check [miss-peer|miss-learner-peer|extra-peer|down-peer|pending-peer|incorrect-ns|isolation [<level>]]",
 		Short: "show the region with check specific status",
 		Run:   showRegionWithCheckCommandFunc,
 	}
@@ -422,12 +422,11 @@ func NewRegionWithCheckCommand() *cobra.Command {
 }
 
 func showRegionWithCheckCommandFunc(cmd *cobra.Command, args []string) {
-	if len(args) != 1 {
+	if len(args) != 1 && (len(args) != 2 || args[0] != "isolation") {
 		cmd.Println(cmd.UsageString())
 		return
 	}
-	state := args[0]
-	prefix := regionsCheckPrefix + "/" + state
+	prefix := regionsCheckPrefix + "/" + strings.Join(args, "/")
 	r, err := doRequest(cmd, prefix, http.MethodGet)
 	if err != nil {
 		cmd.Printf("Failed to get region: %s\n", err)
@@ -34,6 +34,10 @@ func NewSchedulerCommand() *cobra.Command {
 	c.AddCommand(NewShowSchedulerCommand())
 	c.AddCommand(NewAddSchedulerCommand())
 	c.AddCommand(NewRemoveSchedulerCommand())
+	c.AddCommand(NewPauseSchedulerCommand())
+	c.AddCommand(NewResumeSchedulerCommand())
+	c.AddCommand(NewConfigSchedulerCommand())
+	c.AddCommand(NewExplainSchedulerCommand())
 	return c
 }
 
@@ -44,6 +48,7 @@ func NewShowSchedulerCommand() *cobra.Command {
 		Short: "show schedulers",
 		Run:   showSchedulerCommandFunc,
 	}
+	c.Flags().String("status", "", "only show the schedulers in the status, such as paused")
 	return c
 }
 
@@ -53,7 +58,11 @@ func showSchedulerCommandFunc(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	r, err := doRequest(cmd, schedulersPrefix, http.MethodGet)
+	path := schedulersPrefix
+	if flag := cmd.Flag("status"); flag != nil && flag.Value.String() != "" {
+		path += "?status=" + url.QueryEscape(flag.Value.String())
+	}
+	r, err := doRequest(cmd, path, http.MethodGet)
 	if err != nil {
 		cmd.Println(err)
 		return
@@ -316,3 +325,127 @@ func removeSchedulerCommandFunc(cmd *cobra.Command, args []string) {
 		return
 	}
 }
+
+// NewPauseSchedulerCommand returns a command to pause a scheduler.
+func NewPauseSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "pause <scheduler> <delay_seconds>",
+		Short: "pause a scheduler for a number of seconds",
+		Run:   pauseSchedulerCommandFunc,
+	}
+	return c
+}
+
+func pauseSchedulerCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+
+	delay, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || delay <= 0 {
+		cmd.Println("delay should be a positive integer")
+		return
+	}
+	input := map[string]interface{}{"delay": delay}
+	postJSON(cmd, schedulersPrefix+"/"+args[0]+"/pause", input)
+}
+
+// NewResumeSchedulerCommand returns a command to resume a scheduler.
+func NewResumeSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "resume <scheduler>",
+		Short: "resume a paused scheduler",
+		Run:   resumeSchedulerCommandFunc,
+	}
+	return c
+}
+
+func resumeSchedulerCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+
+	postJSON(cmd, schedulersPrefix+"/"+args[0]+"/resume", nil)
+}
+
+// NewExplainSchedulerCommand returns a command to explain the latest scheduling rounds of a scheduler.
+func NewExplainSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "explain <scheduler> [limit]",
+		Short: "explain how the scheduler makes decisions in the latest scheduling rounds",
+		Run:   explainSchedulerCommandFunc,
+	}
+	return c
+}
+
+func explainSchedulerCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 && len(args) != 2 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+
+	path := schedulersPrefix + "/" + args[0] + "/explain"
+	if len(args) == 2 {
+		if _, err := strconv.Atoi(args[1]); err != nil {
+			cmd.Println("limit should be a number")
+			return
+		}
+		path += "?limit=" + args[1]
+	}
+	r, err := doRequest(cmd, path, http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(r)
+}
+
+// NewConfigSchedulerCommand returns a command to show or update the config of a scheduler.
+func NewConfigSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "config",
+		Short: "show or update the config of a scheduler",
+	}
+	c.AddCommand(&cobra.Command{
+		Use:   "show <scheduler>",
+		Short: "show the config of a scheduler",
+		Run:   showSchedulerConfigCommandFunc,
+	})
+	c.AddCommand(&cobra.Command{
+		Use:   "set <scheduler> <option> <value>",
+		Short: "set the option of a scheduler with value",
+		Run:   setSchedulerConfigCommandFunc,
+	})
+	return c
+}
+
+func showSchedulerConfigCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+
+	path := schedulersPrefix + "/" + args[0] + "/config"
+	r, err := doRequest(cmd, path, http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func setSchedulerConfigCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 3 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+
+	path := schedulersPrefix + "/" + args[0] + "/config"
+	if err := postConfigDataWithPath(cmd, args[1], args[2], path); err != nil {
+		cmd.Printf("Failed to set scheduler config: %s\n", err)
+		return
+	}
+	cmd.Println("Success!")
+}
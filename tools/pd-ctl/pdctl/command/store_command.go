@@ -19,6 +19,7 @@ import (
 	"net/http"
 	"path"
 	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -31,7 +32,7 @@ var (
 // NewStoreCommand return a stores subcommand of rootCmd
 func NewStoreCommand() *cobra.Command {
 	s := &cobra.Command{
-		Use:   `store [delete|label|weight|limit] <store_id> [--jq="<query string>"]`,
+		Use:   `store [delete|label|weight|limit|maintenance|progress] <store_id> [--jq="<query string>"]`,
 		Short: "show the store status",
 		Run:   showStoreCommandFunc,
 	}
@@ -39,6 +40,8 @@ func NewStoreCommand() *cobra.Command {
 	s.AddCommand(NewLabelStoreCommand())
 	s.AddCommand(NewSetStoreWeightCommand())
 	s.AddCommand(NewSetStoreLimitCommand())
+	s.AddCommand(NewStoreMaintenanceCommand())
+	s.AddCommand(NewStoreProgressCommand())
 	s.Flags().String("jq", "", "jq query")
 	return s
 }
@@ -85,11 +88,37 @@ func NewSetStoreWeightCommand() *cobra.Command {
 
 // NewSetStoreLimitCommand returns a limit subcommand of storeCmd.
 func NewSetStoreLimitCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "limit <store_id> <rate>",
+	c := &cobra.Command{
+		Use:   "limit <store_id> <rate> [--type=<add-peer|remove-peer>]",
 		Short: "set a store's rate limit",
 		Run:   setStoreLimitCommandFunc,
 	}
+	c.Flags().String("type", "", "the type of the limit, which is add-peer or remove-peer, and both if unset")
+	return c
+}
+
+// NewStoreMaintenanceCommand returns a maintenance subcommand of storeCmd.
+func NewStoreMaintenanceCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "maintenance <store_id> <duration>",
+		Short: "put a store in maintenance for the duration",
+		Run:   setStoreMaintenanceCommandFunc,
+	}
+	c.AddCommand(&cobra.Command{
+		Use:   "delete <store_id>",
+		Short: "end the maintenance of a store",
+		Run:   deleteStoreMaintenanceCommandFunc,
+	})
+	return c
+}
+
+// NewStoreProgressCommand returns a progress subcommand of storeCmd.
+func NewStoreProgressCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "progress <store_id>",
+		Short: "show the progress of removing a store",
+		Run:   showStoreProgressCommandFunc,
+	}
 }
 
 // NewStoresCommand returns a store subcommand of rootCmd
@@ -147,11 +176,13 @@ func NewSetStoresCommand() *cobra.Command {
 
 // NewSetAllLimitCommand returns a set limit subcommand of set command.
 func NewSetAllLimitCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "limit <rate>",
-		Short: "set all store's rate limit",
+	c := &cobra.Command{
+		Use:   "limit <rate> [<label_key> <label_value>] [--type=<add-peer|remove-peer>]",
+		Short: "set all store's rate limit, or the rate limit of the stores with the label",
 		Run:   setAllLimitCommandFunc,
 	}
+	c.Flags().String("type", "", "the type of the limit, which is add-peer or remove-peer, and both if unset")
+	return c
 }
 
 func showStoreCommandFunc(cmd *cobra.Command, args []string) {
@@ -281,18 +312,74 @@ func setStoreWeightCommandFunc(cmd *cobra.Command, args []string) {
 
 func setStoreLimitCommandFunc(cmd *cobra.Command, args []string) {
 	if len(args) != 2 {
-		cmd.Println("Usage: store limit <store_id> <rate>")
+		cmd.Println(cmd.UsageString())
 		return
 	}
 	rate, err := strconv.ParseFloat(args[1], 64)
-	if err != nil || rate < 0 {
-		cmd.Println("rate should be a number that >= 0.")
+	if err != nil || rate <= 0 {
+		cmd.Println("rate should be a number that > 0.")
 		return
 	}
-	prefix := fmt.Sprintf(path.Join(storePrefix, "limit"), args[0])
-	postJSON(cmd, prefix, map[string]interface{}{
+	input := map[string]interface{}{
 		"rate": rate,
-	})
+	}
+	if typ, _ := cmd.Flags().GetString("type"); typ != "" {
+		input["type"] = typ
+	}
+	prefix := fmt.Sprintf(path.Join(storePrefix, "limit"), args[0])
+	postJSON(cmd, prefix, input)
+}
+
+func setStoreMaintenanceCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	if _, err := strconv.ParseUint(args[0], 10, 64); err != nil {
+		cmd.Println("store_id should be a number")
+		return
+	}
+	if d, err := time.ParseDuration(args[1]); err != nil || d <= 0 {
+		cmd.Println("duration should be a positive duration, such as 30m")
+		return
+	}
+	prefix := fmt.Sprintf(path.Join(storePrefix, "maintenance"), args[0])
+	postJSON(cmd, prefix, map[string]interface{}{"duration": args[1]})
+}
+
+func deleteStoreMaintenanceCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	if _, err := strconv.ParseUint(args[0], 10, 64); err != nil {
+		cmd.Println("store_id should be a number")
+		return
+	}
+	prefix := fmt.Sprintf(path.Join(storePrefix, "maintenance"), args[0])
+	if _, err := doRequest(cmd, prefix, http.MethodDelete); err != nil {
+		cmd.Printf("Failed to end the maintenance of store %s: %s\n", args[0], err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
+func showStoreProgressCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	if _, err := strconv.ParseUint(args[0], 10, 64); err != nil {
+		cmd.Println("store_id should be a number")
+		return
+	}
+	prefix := fmt.Sprintf(path.Join(storePrefix, "progress"), args[0])
+	r, err := doRequest(cmd, prefix, http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get the progress of store %s: %s\n", args[0], err)
+		return
+	}
+	cmd.Println(r)
 }
 
 func showStoresCommandFunc(cmd *cobra.Command, args []string) {
@@ -330,17 +417,24 @@ func removeTombStoneCommandFunc(cmd *cobra.Command, args []string) {
 }
 
 func setAllLimitCommandFunc(cmd *cobra.Command, args []string) {
-	if len(args) != 1 {
-		cmd.Println("Usage: stores set limit <rate>")
+	if len(args) != 1 && len(args) != 3 {
+		cmd.Println(cmd.UsageString())
 		return
 	}
 	rate, err := strconv.ParseFloat(args[0], 64)
-	if err != nil || rate < 0 {
-		cmd.Println("rate should be a number that >= 0.")
+	if err != nil || rate <= 0 {
+		cmd.Println("rate should be a number that > 0.")
 		return
 	}
-	prefix := path.Join(storesPrefix, "limit")
-	postJSON(cmd, prefix, map[string]interface{}{
+	input := map[string]interface{}{
 		"rate": rate,
-	})
+	}
+	if len(args) == 3 {
+		input["label_key"], input["label_value"] = args[1], args[2]
+	}
+	if typ, _ := cmd.Flags().GetString("type"); typ != "" {
+		input["type"] = typ
+	}
+	prefix := path.Join(storesPrefix, "limit")
+	postJSON(cmd, prefix, input)
 }